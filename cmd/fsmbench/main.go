@@ -0,0 +1,236 @@
+// Command fsmbench benchmarks the execution backends of a DFA on an input
+// corpus and reports throughput, per-input latency percentiles and
+// allocation, so configurations can be compared empirically.
+//
+// Usage:
+//
+//	fsmbench [-machine file] [-corpus file] [-n 10000] [-len 64] [-seed 1] [-rounds 5]
+//
+// The machine is a fsm.Definition in JSON (see cmd/fsmgen and fsmdebug),
+// given with -machine; without it, the built-in mod-three machine in
+// modthree.json is used. The backends are the map-based DFA, the compiled
+// Table, a TableView over the binary table file, and, for the built-in
+// machine only, the Go code fsmgen generated from it (modthree_gen.go); a
+// machine loaded at run time has no generated code.
+//
+// The corpus file holds one input per line, decoded as fsmdebug decodes
+// its input: binary strings for the built-in machine (spaces, tabs and
+// underscores are ignored), and for other machines a string of symbols if
+// every symbol is one character, else whitespace-separated symbol names.
+// Without -corpus, random inputs are generated.
+package main
+
+//go:generate go run fsm/cmd/fsmgen -in modthree.json -out modthree_gen.go -pkg main -prefix ModThree
+
+import (
+	"bufio"
+	_ "embed"
+	"flag"
+	"fmt"
+	"fsm/fsm"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+//go:embed modthree.json
+var modThreeJSON []byte
+
+// backend is one way of executing the machine.
+type backend struct {
+	name string
+	run  func([]string) error
+}
+
+// result aggregates the measurements of one backend.
+type result struct {
+	name       string
+	symbols    int
+	elapsed    time.Duration
+	latencies  []time.Duration
+	allocBytes uint64
+	allocs     uint64
+}
+
+func loadCorpus(path string, dec fsm.SymbolDecoder[string]) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var corpus [][]string
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		in, err := fsm.DecodeString(dec, sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		corpus = append(corpus, in)
+	}
+	return corpus, sc.Err()
+}
+
+func randomCorpus(alphabet []string, n, length int, seed int64) [][]string {
+	r := rand.New(rand.NewSource(seed))
+	corpus := make([][]string, n)
+	for i := range corpus {
+		in := make([]string, length)
+		for j := range in {
+			in[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		corpus[i] = in
+	}
+	return corpus
+}
+
+// backends returns the backends for d; generated adds the fsmgen code.
+func backends(d *fsm.DFA[string, string], generated bool) ([]backend, error) {
+	table := d.Compile()
+	data, err := table.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	view, err := fsm.LoadTable(data)
+	if err != nil {
+		return nil, err
+	}
+	out := []backend{
+		{"map", func(in []string) error { _, err := d.Run(in); return err }},
+		{"table", func(in []string) error { _, err := table.Run(in); return err }},
+		{"view", func(in []string) error {
+			q := int32(0)
+			for i, a := range in {
+				if s, ok := table.Symbol(a); ok {
+					q = view.Step(q, s)
+				} else {
+					q = -1
+				}
+				if q < 0 {
+					return fmt.Errorf("symbol %d: %w", i, fsm.ErrUndefined)
+				}
+			}
+			return nil
+		}},
+	}
+	if generated {
+		out = append(out, backend{"gen", func(in []string) error {
+			q := int32(ModThreeStart)
+			for i, a := range in {
+				if q = ModThreeStep(q, ModThreeSymbol(a)); q < 0 {
+					return fmt.Errorf("symbol %d: %w", i, fsm.ErrUndefined)
+				}
+			}
+			return nil
+		}})
+	}
+	return out, nil
+}
+
+func measure(b backend, corpus [][]string, rounds int) (result, error) {
+	res := result{name: b.name, latencies: make([]time.Duration, 0, rounds*len(corpus))}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for r := 0; r < rounds; r++ {
+		for _, in := range corpus {
+			t0 := time.Now()
+			if err := b.run(in); err != nil {
+				return res, err
+			}
+			res.latencies = append(res.latencies, time.Since(t0))
+			res.symbols += len(in)
+		}
+	}
+	res.elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	res.allocBytes = after.TotalAlloc - before.TotalAlloc
+	res.allocs = after.Mallocs - before.Mallocs
+	return res, nil
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+func main() {
+	machine := flag.String("machine", "", "machine definition `file` in JSON (default: mod-three over 0 and 1)")
+	corpusPath := flag.String("corpus", "", "file with one input per line (default: random inputs)")
+	n := flag.Int("n", 10000, "number of random inputs")
+	length := flag.Int("len", 64, "length of random inputs")
+	seed := flag.Int64("seed", 1, "seed for random inputs")
+	rounds := flag.Int("rounds", 5, "passes over the corpus per backend")
+	flag.Parse()
+
+	var def *fsm.Definition
+	var err error
+	dec := fsm.BitDecoder("0", "1")
+	if *machine == "" {
+		def, err = fsm.ParseDefinition(modThreeJSON)
+	} else if def, err = fsm.LoadDefinition(*machine); err == nil {
+		dec = def.Decoder()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmbench:", err)
+		os.Exit(1)
+	}
+	d, err := def.DFA()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmbench: invalid machine:", err)
+		os.Exit(1)
+	}
+	if len(def.Alphabet) == 0 {
+		fmt.Fprintln(os.Stderr, "fsmbench: the machine has an empty alphabet")
+		os.Exit(1)
+	}
+
+	var corpus [][]string
+	if *corpusPath != "" {
+		if corpus, err = loadCorpus(*corpusPath, dec); err != nil {
+			fmt.Fprintln(os.Stderr, "Corpus error:", err)
+			os.Exit(1)
+		}
+	} else {
+		corpus = randomCorpus(def.Alphabet, *n, *length, *seed)
+	}
+
+	bs, err := backends(d, *machine == "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmbench:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Corpus: %d inputs, %d rounds\n\n", len(corpus), *rounds)
+	fmt.Printf("%-8s %14s %10s %10s %10s %12s %10s\n",
+		"backend", "symbols/sec", "p50", "p90", "p99", "bytes/input", "allocs")
+	for _, b := range bs {
+		res, err := measure(b, corpus, *rounds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: run error: %v\n", b.name, err)
+			os.Exit(1)
+		}
+		sort.Slice(res.latencies, func(i, j int) bool { return res.latencies[i] < res.latencies[j] })
+		runs := len(res.latencies)
+		perInput := uint64(0)
+		if runs > 0 {
+			perInput = res.allocBytes / uint64(runs)
+		}
+		fmt.Printf("%-8s %14.0f %10v %10v %10v %12d %10d\n",
+			res.name,
+			float64(res.symbols)/res.elapsed.Seconds(),
+			percentile(res.latencies, 0.50),
+			percentile(res.latencies, 0.90),
+			percentile(res.latencies, 0.99),
+			perInput,
+			res.allocs)
+	}
+	if *machine != "" {
+		fmt.Println("\n(gen: no generated code for a machine loaded at run time; see cmd/fsmgen)")
+	}
+}
@@ -0,0 +1,57 @@
+// Code generated by fsm; DO NOT EDIT.
+
+package main
+
+// Source fingerprint: 22cad28efc9f71614a72e7d8d2b85dc4b0c9c7bf47c6b023f929e66ec4957259
+//
+// States:
+//
+//	0 = S0
+//	1 = S1
+//	2 = S2
+//
+// Symbols:
+//
+//	0 = 0
+//	1 = 1
+const (
+	ModThreeStart      = 0
+	ModThreeNumStates  = 3
+	ModThreeNumSymbols = 2
+)
+
+var modThreeFinal = [ModThreeNumStates]bool{true, true, true}
+
+var modThreeNext = [ModThreeNumStates * ModThreeNumSymbols]int32{
+	0, 1,
+	2, 0,
+	1, 2,
+}
+
+// ModThreeStep returns the state reached from state on symbol, or -1.
+//
+//export modthree_step
+func ModThreeStep(state, symbol int32) int32 {
+	if state < 0 || state >= ModThreeNumStates || symbol < 0 || symbol >= ModThreeNumSymbols {
+		return -1
+	}
+	return modThreeNext[state*ModThreeNumSymbols+symbol]
+}
+
+// ModThreeIsFinal reports whether state is accepting.
+//
+//export modthree_is_final
+func ModThreeIsFinal(state int32) bool {
+	return state >= 0 && state < ModThreeNumStates && modThreeFinal[state]
+}
+
+// ModThreeSymbol maps a symbol name to its number, or -1 if it is not in the alphabet.
+func ModThreeSymbol(name string) int32 {
+	switch name {
+	case "0":
+		return 0
+	case "1":
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,212 @@
+// Command fsmdebug steps through a run of a DFA interactively. It shows the
+// transition table with the current state highlighted and lets you move
+// forward and backward through the input.
+//
+// The machine is a fsm.Definition in JSON (see cmd/fsmgen), given with
+// -machine; without it, the built-in mod-three machine over "0" and "1" is
+// used. If every symbol is a single character, the input is a string of
+// them in which spaces, tabs and underscores are ignored, e.g. "1101";
+// otherwise it is a whitespace-separated list of symbols, e.g.
+// "coin push push".
+//
+// Usage: fsmdebug [-plain] [-machine file] <input>
+//
+// Commands (followed by Enter):
+//
+//	n or empty line  step forward
+//	b                step back
+//	r                reset to q0
+//	q                quit
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"fsm/fsm"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// modThree is the machine used without -machine.
+var modThree = &fsm.Definition{
+	States:   []string{"S0", "S1", "S2"},
+	Alphabet: []string{"0", "1"},
+	Start:    "S0",
+	Final:    []string{"S0", "S1", "S2"},
+	Delta: map[string]map[string]string{
+		"S0": {"0": "S0", "1": "S1"},
+		"S1": {"0": "S2", "1": "S0"},
+		"S2": {"0": "S1", "1": "S2"},
+	},
+	Complete: true,
+}
+
+// loadDefinition reads a JSON definition, or returns modThree if path is
+// empty.
+func loadDefinition(path string) (*fsm.Definition, error) {
+	if path == "" {
+		return modThree, nil
+	}
+	return fsm.LoadDefinition(path)
+}
+
+// session is a run that can move in both directions.
+// history[i] is the state after consuming input[:i].
+type session struct {
+	def     *fsm.Definition
+	dfa     *fsm.DFA[string, string]
+	input   []string
+	history []string
+	err     error
+}
+
+func (s *session) forward() {
+	pos := len(s.history) - 1
+	if pos >= len(s.input) {
+		return
+	}
+	q, err := s.dfa.Step(s.history[pos], s.input[pos])
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.history = append(s.history, q)
+}
+
+func (s *session) back() {
+	s.err = nil
+	if len(s.history) > 1 {
+		s.history = s.history[:len(s.history)-1]
+	}
+}
+
+func (s *session) reset() {
+	s.err = nil
+	s.history = s.history[:1]
+}
+
+// render draws the input with a cursor and the transition table with the
+// current state (and the edge about to be taken) highlighted.
+func (s *session) render(w io.Writer, ansi bool) {
+	mark := func(text string, on bool) string {
+		if !on {
+			return text
+		}
+		if ansi {
+			return "\x1b[7m" + text + "\x1b[0m"
+		}
+		return "[" + text + "]"
+	}
+	if ansi {
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+	}
+
+	pos := len(s.history) - 1
+	cur := s.history[pos]
+	var nextSym *string
+	if pos < len(s.input) {
+		nextSym = &s.input[pos]
+	}
+
+	// Column width: the longest state or symbol name, plus a space.
+	width := len("δ")
+	for _, names := range [][]string{s.def.States, s.def.Alphabet} {
+		for _, name := range names {
+			if n := utf8.RuneCountInString(name); n > width {
+				width = n
+			}
+		}
+	}
+	cell := func(text string) string { return fmt.Sprintf("%-*s", width+1, text) }
+
+	var in strings.Builder
+	for i, a := range s.input {
+		if i > 0 && len(a) > 1 {
+			in.WriteString(" ")
+		}
+		in.WriteString(mark(a, i == pos))
+	}
+	fmt.Fprintf(w, "Input:    %s\n", in.String())
+	fmt.Fprintf(w, "Position: %d/%d\n\n", pos, len(s.input))
+
+	fmt.Fprint(w, cell("δ"))
+	for _, a := range s.def.Alphabet {
+		fmt.Fprint(w, cell(a))
+	}
+	fmt.Fprintln(w)
+	for _, q := range s.def.States {
+		fmt.Fprint(w, mark(cell(q), q == cur))
+		for _, a := range s.def.Alphabet {
+			next, err := s.dfa.Step(q, a)
+			text := "-"
+			if err == nil {
+				text = next
+			}
+			fmt.Fprint(w, mark(cell(text), q == cur && nextSym != nil && *nextSym == a))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	if s.err != nil {
+		fmt.Fprintln(w, "Error:", s.err)
+	}
+	if pos == len(s.input) {
+		_, accepted := s.dfa.F[cur]
+		fmt.Fprintf(w, "Done: final state %v, accepted=%v\n", cur, accepted)
+	}
+	fmt.Fprint(w, "(n)ext (b)ack (r)eset (q)uit > ")
+}
+
+func main() {
+	plain := flag.Bool("plain", false, "disable ANSI screen control and highlighting")
+	machine := flag.String("machine", "", "machine definition `file` in JSON (default: mod-three over 0 and 1)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-plain] [-machine file] <input>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	def, err := loadDefinition(*machine)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmdebug:", err)
+		os.Exit(1)
+	}
+	d, err := def.DFA()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmdebug: invalid machine:", err)
+		os.Exit(1)
+	}
+	input, err := fsm.DecodeString(def.Decoder(), strings.Join(flag.Args(), " "))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Parse error:", err)
+		os.Exit(1)
+	}
+
+	s := &session{def: def, dfa: d, input: input, history: []string{d.Q0}}
+	sc := bufio.NewScanner(os.Stdin)
+	for {
+		s.render(os.Stdout, !*plain)
+		if !sc.Scan() {
+			fmt.Println()
+			return
+		}
+		switch strings.TrimSpace(sc.Text()) {
+		case "", "n":
+			s.forward()
+		case "b":
+			s.back()
+		case "r":
+			s.reset()
+		case "q":
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Command fsmgen compiles a machine definition into a Go source file, for
+// use with go generate:
+//
+//	//go:generate go run fsm/cmd/fsmgen -in machine.yaml -out machine_gen.go
+//
+// The input is a fsm.Definition in JSON or in the simple YAML subset
+// documented in yaml.go:
+//
+//	states: [locked, unlocked]
+//	alphabet: [coin, push]
+//	start: locked
+//	final: [locked]
+//	complete: true
+//	delta:
+//	  locked:   {coin: unlocked, push: locked}
+//	  unlocked: {coin: unlocked, push: locked}
+//
+// The output is the table emitted by Table.WriteGo: gofmt-formatted,
+// stamped with the machine fingerprint, and identical for identical
+// machines, so regeneration in a build is a no-op. The file is only
+// rewritten when its content changes. With -prefix, every identifier
+// starts with it, so several machines can be generated into one package;
+// -doc adds a package doc comment, for a package holding only the
+// generated file.
+//
+// Usage: fsmgen -in file [-out file] [-pkg name] [-prefix name] [-doc]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"fsm/fsm"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadDefinition reads a JSON or YAML definition.
+func loadDefinition(path string) (*fsm.Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return fsm.ParseDefinition(data)
+	}
+	v, err := parseYAML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return fsm.ParseDefinition(js)
+}
+
+// writeIfChanged writes data to path unless the file already holds it.
+func writeIfChanged(path string, data []byte) error {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func main() {
+	in := flag.String("in", "", "machine definition `file` (.yaml, .yml or .json)")
+	out := flag.String("out", "", "output Go `file` (default: stdout)")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package `name` (default: $GOPACKAGE, set by go generate)")
+	prefix := flag.String("prefix", "", "`name` prepended to every generated identifier")
+	doc := flag.Bool("doc", false, "emit a package doc comment")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -in file [-out file] [-pkg name] [-prefix name] [-doc]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *in == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	def, err := loadDefinition(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsmgen:", err)
+		os.Exit(1)
+	}
+	d, err := def.DFA()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fsmgen: %s: %v\n", filepath.Base(*in), err)
+		os.Exit(1)
+	}
+	var buf bytes.Buffer
+	if err := d.Compile().WriteGo(&buf, *pkg, *prefix, *doc); err != nil {
+		fmt.Fprintln(os.Stderr, "fsmgen:", err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := writeIfChanged(*out, buf.Bytes()); err != nil {
+		fmt.Fprintln(os.Stderr, "fsmgen:", err)
+		os.Exit(1)
+	}
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"fsm/fsm"
+	"io"
 	"os"
 	"strings"
 )
@@ -58,25 +63,6 @@ func buildModThree() *fsm.DFA[State, Bit] {
 	return fsm.Must(fsm.NewDFA(states, alphabet, q0, finals, delta, true))
 }
 
-// Parse a string like "1011_000" into []Bit.
-// Spaces, underscores, and tabs are ignored.
-func parseBinary(s string) ([]Bit, error) {
-	var out []Bit
-	for _, r := range s {
-		switch r {
-		case '0':
-			out = append(out, Zero)
-		case '1':
-			out = append(out, One)
-		case ' ', '\t', '_':
-			continue
-		default:
-			return nil, fmt.Errorf("%w: %q", fsm.ErrInvalidInput, r)
-		}
-	}
-	return out, nil
-}
-
 // Map final state to remainder value.
 func remainderFromState(s State) int {
 	switch s {
@@ -91,36 +77,178 @@ func remainderFromState(s State) int {
 	}
 }
 
+// Exit statuses, for use in scripts.
+const (
+	exitAccepted = 0 // accepted, or remainder equals -expect
+	exitRejected = 1 // rejected, or remainder differs from -expect
+	exitError    = 2 // usage, parse, run or read error
+)
+
+// result is the outcome for one input, as printed in batch mode.
+type result struct {
+	Line      int    `json:"line"`
+	Input     string `json:"input"`
+	State     *State `json:"state,omitempty"`
+	Remainder *int   `json:"remainder,omitempty"`
+	Pass      bool   `json:"pass"`
+	Error     string `json:"error,omitempty"`
+}
+
+// evaluate parses one input and runs the DFA on it.
+func evaluate(d *fsm.DFA[State, Bit], input string) (State, error) {
+	// Spaces, underscores, and tabs are ignored.
+	syms, err := fsm.DecodeString(fsm.BitDecoder(Zero, One), input)
+	if err != nil {
+		return d.Q0, fmt.Errorf("parse error: %w", err)
+	}
+	final, err := d.Run(syms)
+	if err != nil {
+		return final, fmt.Errorf("run error: %w", err)
+	}
+	return final, nil
+}
+
+// passes reports whether a run ending in final counts as a success: its
+// remainder equals expect, or, when expect is negative, final is accepting.
+func passes(d *fsm.DFA[State, Bit], final State, expect int) bool {
+	if expect >= 0 {
+		return remainderFromState(final) == expect
+	}
+	return d.F.Has(final)
+}
+
+// runBatch evaluates every non-blank line of r independently and writes
+// one result per line, as text or JSON lines, to w (which may be
+// io.Discard). It returns the exit status for the whole batch: the worst
+// status of any line.
+func runBatch(d *fsm.DFA[State, Bit], r io.Reader, w io.Writer, asJSON bool, expect int) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+	enc := json.NewEncoder(out)
+	status := exitAccepted
+	for n := 1; sc.Scan(); n++ {
+		input := strings.TrimSpace(sc.Text())
+		if input == "" {
+			continue
+		}
+		res := result{Line: n, Input: input}
+		if final, err := evaluate(d, input); err != nil {
+			res.Error = err.Error()
+			status = exitError
+		} else {
+			rem := remainderFromState(final)
+			res.State, res.Remainder = &final, &rem
+			res.Pass = passes(d, final, expect)
+			if !res.Pass && status == exitAccepted {
+				status = exitRejected
+			}
+		}
+
+		switch {
+		case asJSON:
+			if err := enc.Encode(res); err != nil {
+				return exitError, err
+			}
+		case res.Error != "":
+			fmt.Fprintf(out, "%d\t%s\terror: %s\n", n, input, res.Error)
+		case !res.Pass:
+			fmt.Fprintf(out, "%d\t%s\t%d\treject\n", n, input, *res.Remainder)
+		default:
+			fmt.Fprintf(out, "%d\t%s\t%d\n", n, input, *res.Remainder)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return exitError, err
+	}
+	return status, nil
+}
+
 func main() {
-	// Require an input argument.
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <binary-string>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Example: %s 1111_000\n", os.Args[0])
-		os.Exit(1)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run is the command with its arguments and streams; it returns the exit
+// status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("modthree", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	batch := fs.String("batch", "", "read one input per line from `file` (\"-\" for stdin)")
+	asJSON := fs.Bool("json", false, "in batch mode, print JSON lines instead of text")
+	quiet := fs.Bool("quiet", false, "print nothing; report the result in the exit status only")
+	expect := fs.Int("expect", -1, "succeed only if the remainder equals `n`, 0 to 2 (default: if accepted)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s [-quiet] [-expect n] <binary-string>\n", fs.Name())
+		fmt.Fprintf(stderr, "       %s -batch file [-json] [-quiet] [-expect n]\n", fs.Name())
+		fmt.Fprintf(stderr, "Example: %s 1111_000\n", fs.Name())
+		fmt.Fprintf(stderr, "Exit status: %d accepted, %d rejected, %d error\n", exitAccepted, exitRejected, exitError)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitAccepted
+		}
+		return exitError
 	}
 
-	input := strings.TrimSpace(os.Args[1])
+	if *quiet {
+		stdout, stderr = io.Discard, io.Discard
+		fs.SetOutput(stderr)
+	}
+
+	// A remainder mod 3 is 0, 1 or 2; any other -expect could never pass.
+	expectSet := false
+	fs.Visit(func(f *flag.Flag) { expectSet = expectSet || f.Name == "expect" })
+	if expectSet && (*expect < 0 || *expect > 2) {
+		fmt.Fprintf(stderr, "invalid -expect %d: the remainder is 0, 1 or 2\n", *expect)
+		fs.Usage()
+		return exitError
+	}
 
 	// Build the DFA
 	d := buildModThree()
 
-	// Parse input string into symbols
-	syms, err := parseBinary(input)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Parse error:", err)
-		os.Exit(1)
+	if *batch != "" {
+		in := stdin
+		if *batch != "-" {
+			f, err := os.Open(*batch)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return exitError
+			}
+			defer f.Close()
+			in = f
+		}
+		status, err := runBatch(d, in, stdout, *asJSON, *expect)
+		if err != nil {
+			fmt.Fprintln(stderr, "Read error:", err)
+		}
+		return status
 	}
 
-	// Run DFA
-	final, err := d.Run(syms)
+	// Require an input argument.
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	input := strings.TrimSpace(fs.Arg(0))
+
+	// Parse and run
+	final, err := evaluate(d, input)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Run error:", err)
-		os.Exit(1)
+		fmt.Fprintln(stderr, err)
+		return exitError
 	}
 
 	// Map final state → remainder
 	rem := remainderFromState(final)
 
 	// Print result
-	fmt.Printf("Input: %s\nFinal state: %v\nRemainder (mod 3): %d\n", input, final, rem)
+	fmt.Fprintf(stdout, "Input: %s\nFinal state: %v\nRemainder (mod 3): %d\n", input, final, rem)
+	if !passes(d, final, *expect) {
+		return exitRejected
+	}
+	return exitAccepted
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestRun_Expect checks the exit status for -expect, which must be a
+// remainder: anything outside 0 to 2 is a usage error.
+func TestRun_Expect(t *testing.T) {
+	for _, tc := range []struct {
+		args   []string
+		status int
+		usage  bool
+	}{
+		{[]string{"110"}, exitAccepted, false},
+		{[]string{"-expect", "0", "110"}, exitAccepted, false},
+		{[]string{"-expect", "1", "110"}, exitRejected, false},
+		{[]string{"-expect", "3", "110"}, exitError, true},
+		{[]string{"-expect", "-1", "110"}, exitError, true},
+		{[]string{"-batch", "-", "-expect", "7"}, exitError, true},
+		{[]string{"-quiet", "-expect", "3", "110"}, exitError, false},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(tc.args, strings.NewReader("110\n"), &stdout, &stderr)
+		if status != tc.status {
+			t.Errorf("%v: status %d, want %d; stderr %q", tc.args, status, tc.status, stderr.String())
+		}
+		if got := strings.Contains(stderr.String(), "invalid -expect"); got != tc.usage {
+			t.Errorf("%v: usage error printed = %v, want %v", tc.args, got, tc.usage)
+		}
+		if tc.status == exitError && stdout.Len() > 0 {
+			t.Errorf("%v: printed %q after a usage error", tc.args, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package fsm
+
+import "fmt"
+
+// ---------- Alphabet harmonization ----------
+
+// AlphabetPolicy says how a machine treats symbols added to its alphabet.
+type AlphabetPolicy int
+
+const (
+	// RejectUnknown leaves the new symbols without transitions, so runs
+	// that read one fail as for any undefined transition.
+	RejectUnknown AlphabetPolicy = iota
+	// SelfLoopUnknown makes every state loop on the new symbols, so they
+	// are skipped without changing state.
+	SelfLoopUnknown
+)
+
+func (p AlphabetPolicy) String() string {
+	switch p {
+	case RejectUnknown:
+		return "RejectUnknown"
+	case SelfLoopUnknown:
+		return "SelfLoopUnknown"
+	}
+	return fmt.Sprintf("AlphabetPolicy(%d)", int(p))
+}
+
+// ExtendAlphabet returns a copy of d whose alphabet also contains extra,
+// with transitions on the new symbols set by policy. Symbols already in Σ
+// keep their transitions. Under RejectUnknown, defaults are expanded into
+// edges on the old symbols so they do not catch the new ones. d is not
+// modified.
+func (d *DFA[Q, Sigma]) ExtendAlphabet(extra []Sigma, policy AlphabetPolicy) (*DFA[Q, Sigma], error) {
+	if policy != RejectUnknown && policy != SelfLoopUnknown {
+		return nil, fmt.Errorf("%w: unknown alphabet policy %v", ErrInvalidInput, policy)
+	}
+	out := d.clone()
+	var added []Sigma
+	for _, a := range extra {
+		if !out.Sigma.Has(a) {
+			out.Sigma[a] = struct{}{}
+			added = append(added, a)
+		}
+	}
+	if policy == RejectUnknown && len(added) > 0 && len(out.Default) > 0 {
+		for q, qNext := range out.Default {
+			if out.Delta[q] == nil {
+				out.Delta[q] = make(map[Sigma]Q, len(d.Sigma))
+			}
+			for a := range d.Sigma {
+				if _, ok := out.Delta[q][a]; !ok {
+					out.Delta[q][a] = qNext
+				}
+			}
+		}
+		out.Default = nil
+	}
+	if policy == SelfLoopUnknown && len(added) > 0 {
+		for q := range out.Q {
+			if out.Delta[q] == nil {
+				out.Delta[q] = make(map[Sigma]Q, len(added))
+			}
+			for _, a := range added {
+				out.Delta[q][a] = q
+			}
+		}
+	}
+	return out, nil
+}
+
+// Harmonize extends a and b to the union of their alphabets under policy,
+// so they can be combined by operations that require a shared Σ. When the
+// alphabets already match, a and b are returned unchanged.
+func Harmonize[Q1 comparable, Q2 comparable, Sigma comparable](
+	a *DFA[Q1, Sigma],
+	b *DFA[Q2, Sigma],
+	policy AlphabetPolicy,
+) (*DFA[Q1, Sigma], *DFA[Q2, Sigma], error) {
+	if setsEqual(a.Sigma, b.Sigma) {
+		return a, b, nil
+	}
+	a2, err := a.ExtendAlphabet(b.Sigma.sorted(), policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	b2, err := b.ExtendAlphabet(a.Sigma.sorted(), policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a2, b2, nil
+}
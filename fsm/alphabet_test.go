@@ -0,0 +1,82 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestExtendAlphabet checks both policies on the mod-three machine.
+func TestExtendAlphabet(t *testing.T) {
+	const Sep Bit = 7
+	d := buildModThree()
+
+	loop, err := d.ExtendAlphabet([]Bit{Sep, One}, SelfLoopUnknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, err := loop.Run([]Bit{One, Sep, One}); err != nil || q != S0 {
+		t.Fatalf("self-loop run = %v, %v; want S0", q, err)
+	}
+	if len(d.Sigma) != 2 || loop.Delta[S1][One] != d.Delta[S1][One] {
+		t.Fatal("ExtendAlphabet changed existing symbols or the original")
+	}
+
+	reject, err := d.ExtendAlphabet([]Bit{Sep}, RejectUnknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reject.Sigma.Has(Sep) {
+		t.Fatal("Sep not added to Σ")
+	}
+	if _, err := reject.Run([]Bit{One, Sep}); err == nil {
+		t.Fatal("expected an error on a rejected symbol")
+	}
+
+	if _, err := d.ExtendAlphabet(nil, AlphabetPolicy(9)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestExtendAlphabet_Defaults checks that RejectUnknown keeps the new
+// symbols out of a state's default.
+func TestExtendAlphabet_Defaults(t *testing.T) {
+	delta := TransitionFn[string, rune]{"seek": {'a': "found"}}
+	defaults := map[string]string{"seek": "seek", "found": "found"}
+	d, err := NewDFAWithDefaults([]string{"seek", "found"}, []rune("ab"), "seek", []string{"found"}, delta, defaults, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reject, err := d.ExtendAlphabet([]rune("x"), RejectUnknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nt *ErrNoTransition[string, rune]
+	if _, err := reject.Run([]rune("bx")); !errors.As(err, &nt) || nt.Symbol != 'x' {
+		t.Fatalf("Run on a new symbol = %v, want ErrNoTransition", err)
+	}
+	if ok, _, err := reject.Accepts([]rune("bab")); !ok || err != nil {
+		t.Fatalf("old symbols lost their defaults: %v, %v", ok, err)
+	}
+	if len(d.Default) != 2 {
+		t.Fatal("ExtendAlphabet modified the original")
+	}
+}
+
+// TestHarmonize extends two machines to a shared alphabet.
+func TestHarmonize(t *testing.T) {
+	a := literalDFA("ab")
+	b := literalDFA("bc")
+	a2, b2, err := Harmonize(a, b, RejectUnknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !setsEqual(a2.Sigma, b2.Sigma) || len(a2.Sigma) != 3 {
+		t.Fatalf("alphabets %v and %v", a2.Sigma.sorted(), b2.Sigma.sorted())
+	}
+	if ok, _, err := a2.Accepts([]rune("ab")); !ok || err != nil {
+		t.Fatal("harmonized machine lost its language")
+	}
+	if a3, _, _ := Harmonize(a, a, RejectUnknown); a3 != a {
+		t.Fatal("equal alphabets should return the machines unchanged")
+	}
+}
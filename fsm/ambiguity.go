@@ -0,0 +1,365 @@
+package fsm
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// ---------- NFA ambiguity ----------
+
+// AmbiguityClass says how many accepting runs an NFA can have on one input.
+type AmbiguityClass int
+
+const (
+	// Unambiguous: every input has at most one accepting run.
+	Unambiguous AmbiguityClass = iota
+	// FinitelyAmbiguous: some input has several accepting runs, but their
+	// number is bounded by a constant.
+	FinitelyAmbiguous
+	// PolynomiallyAmbiguous: the number of runs is unbounded and grows
+	// polynomially with the input length.
+	PolynomiallyAmbiguous
+	// ExponentiallyAmbiguous: the number of runs grows exponentially with
+	// the input length.
+	ExponentiallyAmbiguous
+)
+
+var ambiguityClasses = [...]string{"unambiguous", "finitely ambiguous", "polynomially ambiguous", "exponentially ambiguous"}
+
+func (c AmbiguityClass) String() string {
+	if c >= 0 && int(c) < len(ambiguityClasses) {
+		return ambiguityClasses[c]
+	}
+	return fmt.Sprintf("AmbiguityClass(%d)", int(c))
+}
+
+// Ambiguity is the result of NFA.Ambiguity.
+type Ambiguity[Sigma comparable] struct {
+	Class AmbiguityClass
+	// Witness is a shortest input with two accepting runs for
+	// FinitelyAmbiguous, and Prefix+Pump+Pump+Suffix for the unbounded
+	// classes. It is nil for Unambiguous.
+	Witness []Sigma
+	// Prefix, Pump and Suffix are set for the unbounded classes: the input
+	// Prefix+Pumpᵏ+Suffix has at least k accepting runs, or at least 2ᵏ
+	// for ExponentiallyAmbiguous.
+	Prefix, Pump, Suffix []Sigma
+}
+
+// CountRuns returns the number of accepting runs of n on input, counted
+// as AcceptingRun defines a run: a sequence of states after each symbol,
+// so two ε-paths between the same pair of states count once.
+func (n *NFA[Q, Sigma]) CountRuns(input []Sigma) *big.Int {
+	cur := make(map[Q]*big.Int)
+	for q := range n.EpsilonClosure(NewSet(n.Q0)) {
+		cur[q] = big.NewInt(1)
+	}
+	for _, a := range input {
+		next := make(map[Q]*big.Int)
+		for q, c := range cur {
+			for p := range n.EpsilonClosure(n.Delta[q][a]) {
+				if next[p] == nil {
+					next[p] = new(big.Int)
+				}
+				next[p].Add(next[p], c)
+			}
+		}
+		cur = next
+	}
+	total := new(big.Int)
+	for q, c := range cur {
+		if n.F.Has(q) {
+			total.Add(total, c)
+		}
+	}
+	return total
+}
+
+// Ambiguity classifies n by the number of accepting runs an input can
+// have, with witness inputs, using the criteria of Weber and Seidl on the
+// useful part of n: it is exponentially ambiguous if some state has two
+// different cycles on the same word, polynomially ambiguous if two states
+// p ≠ q have cycles on a word v that also leads from p to q, and finitely
+// ambiguous if it is merely ambiguous. Runs are counted as by CountRuns.
+//
+// It searches products of n with itself, so it takes O(|Q|²) memory and,
+// for the polynomial case, up to O(|Q|⁵·|Σ|) time: it is meant for
+// machines of modest size, before trusting weights computed over them.
+func (n *NFA[Q, Sigma]) Ambiguity() Ambiguity[Sigma] {
+	g := n.runGraph()
+	m := len(g.succ)
+	if m == 0 {
+		return Ambiguity[Sigma]{Class: Unambiguous}
+	}
+	pairs := func(i, j, k int, fn func(i2, j2 int)) {
+		for _, i2 := range g.succ[i][k] {
+			for _, j2 := range g.succ[j][k] {
+				fn(i2, j2)
+			}
+		}
+	}
+
+	// Ambiguous: two runs from the start to F that differ somewhere.
+	// Nodes are pairs of states plus a flag set once the runs diverged.
+	var sources []int
+	for _, s1 := range g.starts {
+		for _, s2 := range g.starts {
+			div := 0
+			if s1 != s2 {
+				div = 1
+			}
+			sources = append(sources, (s1*m+s2)*2+div)
+		}
+	}
+	witness, _, ok := shortestWord(g.symbols, sources, func(v int) bool {
+		ij := v / 2
+		return v%2 == 1 && g.final[ij/m] && g.final[ij%m]
+	}, func(v, k int, fn func(int)) {
+		ij, div := v/2, v%2
+		pairs(ij/m, ij%m, k, func(i2, j2 int) {
+			d := div
+			if i2 != j2 {
+				d = 1
+			}
+			fn((i2*m+j2)*2 + d)
+		})
+	})
+	if !ok {
+		return Ambiguity[Sigma]{Class: Unambiguous}
+	}
+	if witness == nil {
+		witness = []Sigma{}
+	}
+
+	// The pair graph, for the cycle criteria.
+	adj := make([]map[int]float64, m*m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			row := make(map[int]float64)
+			for k := range g.symbols {
+				pairs(i, j, k, func(i2, j2 int) { row[i2*m+j2] = 0 })
+			}
+			adj[i*m+j] = row
+		}
+	}
+	pairNext := func(v, k int, fn func(int)) {
+		pairs(v/m, v%m, k, func(i2, j2 int) { fn(i2*m + j2) })
+	}
+	comp := make([]int, m*m)
+	for c, members := range sccs(adj) {
+		for _, v := range members {
+			comp[v] = c
+		}
+	}
+	pumped := func(class AmbiguityClass, p, q int, pump []Sigma) Ambiguity[Sigma] {
+		prefix, _, _ := shortestWord(g.symbols, g.starts, func(v int) bool { return v == p }, g.next)
+		suffix, _, _ := shortestWord(g.symbols, []int{q}, func(v int) bool { return g.final[v] }, g.next)
+		w := append(append(append(append([]Sigma(nil), prefix...), pump...), pump...), suffix...)
+		return Ambiguity[Sigma]{Class: class, Witness: w, Prefix: prefix, Pump: pump, Suffix: suffix}
+	}
+
+	// Exponential: a diagonal pair (p,p) on a cycle through a pair (x,y)
+	// with x ≠ y, i.e. two different cycles on p with the same label.
+	for p := 0; p < m; p++ {
+		for v := 0; v < m*m; v++ {
+			if v/m == v%m || comp[v] != comp[p*m+p] {
+				continue
+			}
+			there, _, _ := shortestWord(g.symbols, []int{p*m + p}, func(u int) bool { return u == v }, pairNext)
+			back, _, _ := shortestWord(g.symbols, []int{v}, func(u int) bool { return u == p*m+p }, pairNext)
+			return pumped(ExponentiallyAmbiguous, p, p, append(there, back...))
+		}
+	}
+
+	// Polynomial: p ≠ q and a word v leading p → p, p → q and q → q, i.e.
+	// a path from (p,p,q) to (p,q,q) in the triple product. The pair graph
+	// rules out most candidates first: (p,q) must be on a cycle and
+	// reachable from (p,p).
+	onCycle := make([]bool, m*m)
+	for v := range adj {
+		for u := range adj[v] {
+			if comp[u] == comp[v] {
+				onCycle[v] = true
+			}
+		}
+	}
+	tripleNext := func(v, k int, fn func(int)) {
+		a, b, c := v/(m*m), v/m%m, v%m
+		pairs(a, b, k, func(a2, b2 int) {
+			for _, c2 := range g.succ[c][k] {
+				fn((a2*m+b2)*m + c2)
+			}
+		})
+	}
+	for p := 0; p < m; p++ {
+		reach := reachable([]int{p*m + p}, len(g.symbols), pairNext)
+		for q := 0; q < m; q++ {
+			if q == p || !onCycle[p*m+q] || !reach[p*m+q] {
+				continue
+			}
+			goal := (p*m+q)*m + q
+			pump, _, ok := shortestWord(g.symbols, []int{(p*m+p)*m + q}, func(v int) bool { return v == goal }, tripleNext)
+			if ok {
+				return pumped(PolynomiallyAmbiguous, p, q, pump)
+			}
+		}
+	}
+	return Ambiguity[Sigma]{Class: FinitelyAmbiguous, Witness: witness}
+}
+
+// runGraph is the useful part of an NFA as an ε-free graph over state
+// numbers: succ[i][k] lists the states after reading symbols[k] from i,
+// ε-moves included.
+type runGraph[Sigma comparable] struct {
+	symbols []Sigma
+	succ    [][][]int
+	starts  []int
+	final   []bool
+}
+
+func (g *runGraph[Sigma]) next(v, k int, fn func(int)) {
+	for _, w := range g.succ[v][k] {
+		fn(w)
+	}
+}
+
+func (n *NFA[Q, Sigma]) runGraph() *runGraph[Sigma] {
+	states := n.Q.sorted()
+	symbols := n.Sigma.sorted()
+	index := make(map[Q]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	toIndexes := func(s Set[Q]) []int {
+		out := make([]int, 0, len(s))
+		for q := range s {
+			out = append(out, index[q])
+		}
+		sort.Ints(out)
+		return out
+	}
+	succ := make([][][]int, len(states))
+	pred := make([][]int, len(states))
+	for i, q := range states {
+		succ[i] = make([][]int, len(symbols))
+		for k, a := range symbols {
+			succ[i][k] = toIndexes(n.EpsilonClosure(n.Delta[q][a]))
+			for _, j := range succ[i][k] {
+				pred[j] = append(pred[j], i)
+			}
+		}
+	}
+	starts := toIndexes(n.EpsilonClosure(NewSet(n.Q0)))
+	var finals []int
+	for i, q := range states {
+		if n.F.Has(q) {
+			finals = append(finals, i)
+		}
+	}
+	acc := reachable(starts, len(symbols), func(v, k int, fn func(int)) {
+		for _, w := range succ[v][k] {
+			fn(w)
+		}
+	})
+	coacc := reachable(finals, 1, func(v, _ int, fn func(int)) {
+		for _, w := range pred[v] {
+			fn(w)
+		}
+	})
+
+	// Renumber the useful states 0..m-1.
+	renum := make(map[int]int)
+	for i := range states {
+		if acc[i] && coacc[i] {
+			renum[i] = len(renum)
+		}
+	}
+	g := &runGraph[Sigma]{
+		symbols: symbols,
+		succ:    make([][][]int, len(renum)),
+		final:   make([]bool, len(renum)),
+	}
+	for i := range states {
+		u, ok := renum[i]
+		if !ok {
+			continue
+		}
+		g.final[u] = n.F.Has(states[i])
+		g.succ[u] = make([][]int, len(symbols))
+		for k := range symbols {
+			for _, j := range succ[i][k] {
+				if w, ok := renum[j]; ok {
+					g.succ[u][k] = append(g.succ[u][k], w)
+				}
+			}
+		}
+	}
+	for _, s := range starts {
+		if u, ok := renum[s]; ok {
+			g.starts = append(g.starts, u)
+		}
+	}
+	return g
+}
+
+// reachable returns the nodes reachable from sources in a graph whose
+// edges on symbol k, for k < symbols, are given by next.
+func reachable(sources []int, symbols int, next func(v, k int, fn func(int))) map[int]bool {
+	seen := make(map[int]bool, len(sources))
+	stack := append([]int(nil), sources...)
+	for _, s := range sources {
+		seen[s] = true
+	}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for k := 0; k < symbols; k++ {
+			next(v, k, func(w int) {
+				if !seen[w] {
+					seen[w] = true
+					stack = append(stack, w)
+				}
+			})
+		}
+	}
+	return seen
+}
+
+// shortestWord returns a shortest word leading from one of sources to a
+// node satisfying goal, and that node, by breadth-first search over
+// symbols in order, so the answer is deterministic.
+func shortestWord[Sigma any](symbols []Sigma, sources []int, goal func(int) bool, next func(v, k int, fn func(int))) ([]Sigma, int, bool) {
+	type edge struct{ from, sym int }
+	parent := make(map[int]edge, len(sources))
+	queue := make([]int, 0, len(sources))
+	for _, s := range sources {
+		if _, ok := parent[s]; !ok {
+			parent[s] = edge{-1, -1}
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if goal(v) {
+			var word []Sigma
+			for u := v; parent[u].from >= 0; u = parent[u].from {
+				word = append(word, symbols[parent[u].sym])
+			}
+			for i, j := 0, len(word)-1; i < j; i, j = i+1, j-1 {
+				word[i], word[j] = word[j], word[i]
+			}
+			return word, v, true
+		}
+		for k := range symbols {
+			next(v, k, func(w int) {
+				if _, ok := parent[w]; !ok {
+					parent[w] = edge{v, k}
+					queue = append(queue, w)
+				}
+			})
+		}
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,89 @@
+package fsm
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestAmbiguity classifies one machine of each class and checks the
+// witnesses by counting runs.
+func TestAmbiguity(t *testing.T) {
+	a := []rune("a")
+	for _, c := range []struct {
+		name string
+		n    *NFA[int, rune]
+		want AmbiguityClass
+	}{
+		{"dfa", buildModThreeInts().NFA(), Unambiguous},
+		// 0 loops on a and may move to 1, which loops too: a^k has k runs.
+		{"poly", Must(NewNFA([]int{0, 1}, a, 0, []int{1}, NFATransitionFn[int, rune]{
+			0: {'a': NewSet(0, 1)},
+			1: {'a': NewSet(1)},
+		})), PolynomiallyAmbiguous},
+		// Both states go anywhere on a: a^k has 2^(k-1) runs.
+		{"exp", Must(NewNFA([]int{0, 1}, a, 0, []int{0}, NFATransitionFn[int, rune]{
+			0: {'a': NewSet(0, 1)},
+			1: {'a': NewSet(0, 1)},
+		})), ExponentiallyAmbiguous},
+	} {
+		amb := c.n.Ambiguity()
+		if amb.Class != c.want {
+			t.Errorf("%s: class %v, want %v", c.name, amb.Class, c.want)
+			continue
+		}
+		if c.want == Unambiguous {
+			if amb.Witness != nil {
+				t.Errorf("%s: witness %q", c.name, string(amb.Witness))
+			}
+			continue
+		}
+		if runs := c.n.CountRuns(amb.Witness); runs.Cmp(big.NewInt(2)) < 0 {
+			t.Errorf("%s: witness %q has %v runs", c.name, string(amb.Witness), runs)
+		}
+		if c.want < PolynomiallyAmbiguous {
+			continue
+		}
+		if len(amb.Pump) == 0 {
+			t.Fatalf("%s: empty pump", c.name)
+		}
+		for k := 1; k <= 6; k++ {
+			w := append([]rune(nil), amb.Prefix...)
+			for i := 0; i < k; i++ {
+				w = append(w, amb.Pump...)
+			}
+			w = append(w, amb.Suffix...)
+			min := big.NewInt(int64(k))
+			if c.want == ExponentiallyAmbiguous {
+				min = new(big.Int).Lsh(big.NewInt(1), uint(k))
+			}
+			if runs := c.n.CountRuns(w); runs.Cmp(min) < 0 {
+				t.Errorf("%s: %q has %v runs, want at least %v", c.name, string(w), runs, min)
+			}
+		}
+	}
+}
+
+// TestAmbiguity_Union finds the finite ambiguity of a union of a language
+// with itself, reached through ε-moves.
+func TestAmbiguity_Union(t *testing.T) {
+	u := UnionNFA(literalDFA("ab").NFA(), literalDFA("ab").NFA())
+	amb := u.Ambiguity()
+	if amb.Class != FinitelyAmbiguous || string(amb.Witness) != "ab" || amb.Pump != nil {
+		t.Fatalf("got %v %q", amb.Class, string(amb.Witness))
+	}
+	if runs := u.CountRuns([]rune("ab")); runs.Int64() != 2 {
+		t.Fatalf("CountRuns = %v", runs)
+	}
+	if amb := literalDFA("ab").NFA().Ambiguity(); amb.Class != Unambiguous || amb.Class.String() != "unambiguous" {
+		t.Fatalf("literal: %v", amb.Class)
+	}
+}
+
+// buildModThreeInts is the mod-three machine over runes with int states.
+func buildModThreeInts() *DFA[int, rune] {
+	return Must(NewDFA([]int{0, 1, 2}, []rune("01"), 0, []int{0}, TransitionFn[int, rune]{
+		0: {'0': 0, '1': 1},
+		1: {'0': 2, '1': 0},
+		2: {'0': 1, '1': 2},
+	}, true))
+}
@@ -0,0 +1,217 @@
+package fsm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ---------- Graph helpers ----------
+
+// reachable returns the states reachable from Q0.
+func (d *DFA[Q, Sigma]) reachable() Set[Q] {
+	seen := NewSet(d.Q0)
+	queue := []Q{d.Q0}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok && !seen.Has(qNext) {
+				seen[qNext] = struct{}{}
+				queue = append(queue, qNext)
+			}
+		}
+	}
+	return seen
+}
+
+// coreachable returns the states from which some state in F is reachable.
+func (d *DFA[Q, Sigma]) coreachable() Set[Q] {
+	preds := make(map[Q][]Q, len(d.Q))
+	for q := range d.Q {
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok {
+				preds[qNext] = append(preds[qNext], q)
+			}
+		}
+	}
+	seen := make(Set[Q], len(d.F))
+	var queue []Q
+	for f := range d.F {
+		seen[f] = struct{}{}
+		queue = append(queue, f)
+	}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		for _, p := range preds[q] {
+			if !seen.Has(p) {
+				seen[p] = struct{}{}
+				queue = append(queue, p)
+			}
+		}
+	}
+	return seen
+}
+
+// useful returns the states that are both reachable and co-reachable,
+// i.e. the states that lie on some accepting run.
+func (d *DFA[Q, Sigma]) useful() Set[Q] {
+	co := d.coreachable()
+	out := make(Set[Q])
+	for q := range d.reachable() {
+		if co.Has(q) {
+			out[q] = struct{}{}
+		}
+	}
+	return out
+}
+
+// ---------- Pruning ----------
+
+// PruneToFinals specializes the machine to accept only at the states in
+// subset, which must be a subset of F, and trims every state that is not
+// on a path from q0 to one of them. q0 is always kept. The result is
+// usually partial: the removed transitions led only to rejection.
+func (d *DFA[Q, Sigma]) PruneToFinals(subset Set[Q]) (*DFA[Q, Sigma], error) {
+	for _, f := range subset.sorted() {
+		if !d.F.Has(f) {
+			return nil, fmt.Errorf("%w: %v not in F", ErrInvalidInput, f)
+		}
+	}
+	target := &DFA[Q, Sigma]{Q: d.Q, Sigma: d.Sigma, Q0: d.Q0, F: subset, Delta: d.Delta, Default: d.Default}
+	keep := target.useful()
+	keep[d.Q0] = struct{}{}
+
+	out := &DFA[Q, Sigma]{
+		Q:     keep,
+		Sigma: copySet(d.Sigma),
+		Q0:    d.Q0,
+		F:     copySet(subset),
+		Delta: make(TransitionFn[Q, Sigma], len(keep)),
+	}
+	for q := range keep {
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok && keep.Has(qNext) {
+				if out.Delta[q] == nil {
+					out.Delta[q] = make(map[Sigma]Q)
+				}
+				out.Delta[q][a] = qNext
+			}
+		}
+	}
+	return out, nil
+}
+
+// TrimReport lists the states Trim removed, each in sorted order.
+type TrimReport[Q comparable] struct {
+	Unreachable []Q // not reachable from q0
+	Dead        []Q // reachable, but no state of F is reachable from them
+}
+
+// Trim returns d without its useless states, those not reachable from q0
+// and those from which F cannot be reached, along with what was removed.
+// q0 is always kept, so a machine with an empty language trims to q0
+// alone. The language is unchanged; the result is usually partial.
+func (d *DFA[Q, Sigma]) Trim() (*DFA[Q, Sigma], TrimReport[Q]) {
+	reachable, co := d.reachable(), d.coreachable()
+	var report TrimReport[Q]
+	keep := make(Set[Q], len(reachable))
+	for _, q := range d.Q.sorted() {
+		switch {
+		case q == d.Q0 || (reachable.Has(q) && co.Has(q)):
+			keep[q] = struct{}{}
+		case !reachable.Has(q):
+			report.Unreachable = append(report.Unreachable, q)
+		default:
+			report.Dead = append(report.Dead, q)
+		}
+	}
+	out := &DFA[Q, Sigma]{
+		Q:     keep,
+		Sigma: copySet(d.Sigma),
+		Q0:    d.Q0,
+		F:     make(Set[Q], len(d.F)),
+		Delta: make(TransitionFn[Q, Sigma], len(keep)),
+	}
+	for q := range keep {
+		if d.F.Has(q) {
+			out.F[q] = struct{}{}
+		}
+		row := make(map[Sigma]Q)
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok && keep.Has(qNext) {
+				row[a] = qNext
+			}
+		}
+		out.Delta[q] = row
+	}
+	return out, report
+}
+
+// TrapStates returns, in sorted order, the states from which no state of F
+// can be reached, reachable from q0 or not. A run entering one is bound to
+// reject, so scanners and validators can stop reading there.
+func (d *DFA[Q, Sigma]) TrapStates() []Q {
+	co := d.coreachable()
+	var out []Q
+	for _, q := range d.Q.sorted() {
+		if !co.Has(q) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// ---------- Language size ----------
+
+// Cardinality returns the exact number of words accepted by the DFA.
+// The second result is false (and the count nil) when the language is
+// infinite, i.e. when a cycle passes through a useful state.
+func (d *DFA[Q, Sigma]) Cardinality() (*big.Int, bool) {
+	useful := d.useful()
+	if !useful.Has(d.Q0) {
+		return new(big.Int), true
+	}
+
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	color := make(map[Q]int, len(useful))
+	count := make(map[Q]*big.Int, len(useful))
+
+	// visit counts the words accepted from q, reporting false on a cycle.
+	var visit func(q Q) bool
+	visit = func(q Q) bool {
+		switch color[q] {
+		case active:
+			return false
+		case done:
+			return true
+		}
+		color[q] = active
+		n := new(big.Int)
+		if d.F.Has(q) {
+			n.SetInt64(1)
+		}
+		for a := range d.Sigma {
+			qNext, ok := d.next(q, a)
+			if !ok || !useful.Has(qNext) {
+				continue
+			}
+			if !visit(qNext) {
+				return false
+			}
+			n.Add(n, count[qNext])
+		}
+		color[q] = done
+		count[q] = n
+		return true
+	}
+
+	if !visit(d.Q0) {
+		return nil, false
+	}
+	return count[d.Q0], true
+}
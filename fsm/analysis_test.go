@@ -0,0 +1,173 @@
+package fsm
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// finiteBits builds a DFA over {0,1} accepting every word of length 1..n.
+// State i counts symbols read; there is no transition out of state n.
+func finiteBits(n int) *DFA[int, Bit] {
+	states := make([]int, n+1)
+	delta := TransitionFn[int, Bit]{}
+	for i := 0; i < n; i++ {
+		states[i+1] = i + 1
+		delta[i] = map[Bit]int{Zero: i + 1, One: i + 1}
+	}
+	return Must(NewDFA(states, []Bit{Zero, One}, 0, states[1:], delta, false))
+}
+
+// TestCardinality checks finite counts and infinite detection.
+func TestCardinality(t *testing.T) {
+	// 2 + 4 + 8 words of length 1..3
+	if n, ok := finiteBits(3).Cardinality(); !ok || n.Int64() != 14 {
+		t.Fatalf("finiteBits(3): got %v,%v want 14,true", n, ok)
+	}
+	if n, ok := literalDFA("abc").Cardinality(); !ok || n.Int64() != 1 {
+		t.Fatalf("literal: got %v,%v want 1,true", n, ok)
+	}
+	if _, ok := buildModThree().Cardinality(); ok {
+		t.Fatal("mod-three language is infinite")
+	}
+}
+
+// TestCardinality_DeadCycle ignores cycles that cannot reach F.
+func TestCardinality_DeadCycle(t *testing.T) {
+	// 0 --0--> 1 (final); 0 --1--> 2 --*--> 2 (trap)
+	d := Must(NewDFA([]int{0, 1, 2}, []Bit{Zero, One}, 0, []int{1},
+		TransitionFn[int, Bit]{0: {Zero: 1, One: 2}, 2: {Zero: 2, One: 2}}, false))
+	if n, ok := d.Cardinality(); !ok || n.Int64() != 1 {
+		t.Fatalf("got %v,%v want 1,true", n, ok)
+	}
+}
+
+// TestGrowthRate checks λ and entropy on machines with known growth.
+func TestGrowthRate(t *testing.T) {
+	// No two consecutive ones: growth is the golden ratio.
+	fib := Must(NewDFA([]int{0, 1}, []Bit{Zero, One}, 0, []int{0, 1},
+		TransitionFn[int, Bit]{0: {Zero: 0, One: 1}, 1: {Zero: 0}}, false))
+	cases := []struct {
+		name    string
+		growth  float64
+		entropy float64
+		d       *DFA[int, Bit]
+	}{
+		{"finite", 0, 0, finiteBits(3)},
+		{"fibonacci", math.Phi, math.Log2(math.Phi), fib},
+	}
+	for _, c := range cases {
+		if g := c.d.GrowthRate(); math.Abs(g-c.growth) > 1e-9 {
+			t.Fatalf("%s: growth %v, want %v", c.name, g, c.growth)
+		}
+		if h := c.d.Entropy(); math.Abs(h-c.entropy) > 1e-9 {
+			t.Fatalf("%s: entropy %v, want %v", c.name, h, c.entropy)
+		}
+	}
+	// All binary strings: λ = 2, one bit per symbol.
+	if h := buildModThree().Entropy(); math.Abs(h-1) > 1e-9 {
+		t.Fatalf("mod-three entropy %v, want 1", h)
+	}
+}
+
+// TestAcceptanceProbability compares with brute-force counts.
+func TestAcceptanceProbability(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	fib := Must(NewDFA([]int{0, 1}, []Bit{Zero, One}, 0, []int{0, 1},
+		TransitionFn[int, Bit]{0: {Zero: 0, One: 1}, 1: {Zero: 0}}, false))
+	for n := 0; n <= 8; n++ {
+		words := allWords([]Bit{Zero, One}, n)
+		var accDiv3, accFib, total float64
+		for _, w := range words {
+			if len(w) != n {
+				continue
+			}
+			total++
+			if ok, _, _ := div3.Accepts(w); ok {
+				accDiv3++
+			}
+			if ok, _, _ := fib.Accepts(w); ok {
+				accFib++
+			}
+		}
+		if p := div3.AcceptanceProbability(n); math.Abs(p-accDiv3/total) > 1e-12 {
+			t.Errorf("div3, n=%d: %v, want %v", n, p, accDiv3/total)
+		}
+		if p := fib.AcceptanceProbability(n); math.Abs(p-accFib/total) > 1e-12 {
+			t.Errorf("fibonacci, n=%d: %v, want %v", n, p, accFib/total)
+		}
+	}
+	if p := fib.AcceptanceProbability(-1); p != 0 {
+		t.Errorf("n=-1: %v", p)
+	}
+}
+
+// TestTrim drops unreachable and dead states and reports them.
+func TestTrim(t *testing.T) {
+	d := Must(NewDFA([]int{0, 1, 2, 3, 4}, []Bit{Zero, One}, 0, []int{1, 4},
+		TransitionFn[int, Bit]{
+			0: {Zero: 1, One: 2},
+			1: {Zero: 1},
+			2: {Zero: 2, One: 2}, // trap
+			3: {Zero: 4},         // unreachable
+		}, false))
+	trimmed, report := d.Trim()
+	if !reflect.DeepEqual(report, TrimReport[int]{Unreachable: []int{3, 4}, Dead: []int{2}}) {
+		t.Fatalf("report %+v", report)
+	}
+	if len(trimmed.Q) != 2 || !setsEqual(trimmed.F, NewSet(1)) {
+		t.Fatalf("Q = %v, F = %v", trimmed.Q, trimmed.F)
+	}
+	if _, ok := trimmed.Delta[0][One]; ok {
+		t.Error("transition into the trap kept")
+	}
+	sameLanguage(t, d, trimmed, []Bit{Zero, One}, 5)
+}
+
+func TestTrapStates(t *testing.T) {
+	d := Must(NewDFA([]int{0, 1, 2, 3}, []Bit{Zero, One}, 0, []int{1},
+		TransitionFn[int, Bit]{
+			0: {Zero: 1, One: 2},
+			2: {Zero: 3, One: 2},
+			3: {Zero: 3},
+		}, false))
+	if got := d.TrapStates(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("TrapStates = %v, want [2 3]", got)
+	}
+	if got := buildModThree().TrapStates(); got != nil {
+		t.Errorf("mod-three traps: %v", got)
+	}
+}
+
+// TestPruneToFinals specializes acceptance and trims the rest.
+func TestPruneToFinals(t *testing.T) {
+	p, err := finiteBits(3).PruneToFinals(NewSet(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Q) != 3 || p.Q.Has(3) {
+		t.Fatalf("kept states %v, want 0..2", p.Q.sorted())
+	}
+	if n, ok := p.Cardinality(); !ok || n.Int64() != 4 {
+		t.Fatalf("pruned cardinality = %v,%v want 4,true", n, ok)
+	}
+	if _, err := p.Run([]Bit{Zero, One, Zero}); err == nil {
+		t.Fatal("expected the trimmed edge into state 3 to be gone")
+	}
+
+	m, err := buildModThree().PruneToFinals(NewSet(S0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := m.Accepts([]Bit{One, One}); !ok {
+		t.Fatal("3 should be accepted")
+	}
+	if ok, _, _ := m.Accepts([]Bit{One, Zero}); ok {
+		t.Fatal("2 should be rejected")
+	}
+
+	if _, err := finiteBits(3).PruneToFinals(NewSet(0)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a non-final state, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package fsm
+
+import "fmt"
+
+// ---------- Fluent construction ----------
+
+// Builder assembles a DFA step by step, as a readable alternative to
+// writing TransitionFn literals with Row:
+//
+//	d, err := fsm.NewBuilder[State, Bit]().
+//		Start(S0).Final(S0).
+//		From(S0).On(Zero).To(S0).
+//		From(S0).On(One).To(S1).
+//		Build()
+//
+// States and symbols are collected from the calls, in order of first
+// mention; States and Alphabet add ones no edge uses. Mistakes such as two
+// targets for one edge are reported by Build, which then validates the
+// machine as NewDFA does.
+type Builder[Q comparable, Sigma comparable] struct {
+	states   []Q
+	seenQ    Set[Q]
+	alphabet []Sigma
+	seenA    Set[Sigma]
+	q0       Q
+	hasStart bool
+	finals   []Q
+	delta    TransitionFn[Q, Sigma]
+	complete bool
+	err      error
+}
+
+// NewBuilder returns an empty builder.
+func NewBuilder[Q comparable, Sigma comparable]() *Builder[Q, Sigma] {
+	return &Builder[Q, Sigma]{
+		seenQ: make(Set[Q]),
+		seenA: make(Set[Sigma]),
+		delta: make(TransitionFn[Q, Sigma]),
+	}
+}
+
+// States adds states to Q.
+func (b *Builder[Q, Sigma]) States(states ...Q) *Builder[Q, Sigma] {
+	for _, q := range states {
+		if !b.seenQ.Has(q) {
+			b.seenQ[q] = struct{}{}
+			b.states = append(b.states, q)
+		}
+	}
+	return b
+}
+
+// Alphabet adds symbols to Σ.
+func (b *Builder[Q, Sigma]) Alphabet(symbols ...Sigma) *Builder[Q, Sigma] {
+	for _, a := range symbols {
+		if !b.seenA.Has(a) {
+			b.seenA[a] = struct{}{}
+			b.alphabet = append(b.alphabet, a)
+		}
+	}
+	return b
+}
+
+// Start sets the initial state.
+func (b *Builder[Q, Sigma]) Start(q Q) *Builder[Q, Sigma] {
+	if b.hasStart && b.q0 != q && b.err == nil {
+		b.err = fmt.Errorf("%w: start state set to both %v and %v", ErrInvalidInput, b.q0, q)
+	}
+	b.q0, b.hasStart = q, true
+	return b.States(q)
+}
+
+// Final marks states as accepting.
+func (b *Builder[Q, Sigma]) Final(states ...Q) *Builder[Q, Sigma] {
+	b.finals = append(b.finals, states...)
+	return b.States(states...)
+}
+
+// Complete makes Build require a transition for every (state, symbol).
+func (b *Builder[Q, Sigma]) Complete() *Builder[Q, Sigma] {
+	b.complete = true
+	return b
+}
+
+// From starts an edge from q; finish it with On(...).To(...).
+func (b *Builder[Q, Sigma]) From(q Q) BuilderFrom[Q, Sigma] {
+	b.States(q)
+	return BuilderFrom[Q, Sigma]{b: b, from: q}
+}
+
+// BuilderFrom is an edge under construction with its source chosen.
+type BuilderFrom[Q comparable, Sigma comparable] struct {
+	b    *Builder[Q, Sigma]
+	from Q
+}
+
+// On chooses the symbols of the edge; several symbols add one edge each.
+func (f BuilderFrom[Q, Sigma]) On(symbols ...Sigma) BuilderOn[Q, Sigma] {
+	f.b.Alphabet(symbols...)
+	return BuilderOn[Q, Sigma]{b: f.b, from: f.from, on: symbols}
+}
+
+// BuilderOn is an edge under construction with its source and symbols
+// chosen.
+type BuilderOn[Q comparable, Sigma comparable] struct {
+	b    *Builder[Q, Sigma]
+	from Q
+	on   []Sigma
+}
+
+// To completes the edge and returns the builder.
+func (o BuilderOn[Q, Sigma]) To(q Q) *Builder[Q, Sigma] {
+	b := o.b.States(q)
+	row := b.delta[o.from]
+	if row == nil {
+		row = make(map[Sigma]Q, len(o.on))
+		b.delta[o.from] = row
+	}
+	for _, a := range o.on {
+		if prev, ok := row[a]; ok && prev != q && b.err == nil {
+			b.err = fmt.Errorf("%w: delta(%v,%v) set to both %v and %v", ErrInvalidInput, o.from, a, prev, q)
+		}
+		row[a] = q
+	}
+	return b
+}
+
+// Build returns the machine, or the first mistake made while building it,
+// or the error NewDFAWith reports for it with opts.
+func (b *Builder[Q, Sigma]) Build(opts ...Option) (*DFA[Q, Sigma], error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if !b.hasStart {
+		return nil, fmt.Errorf("%w: no start state", ErrInvalidInput)
+	}
+	delta := make(TransitionFn[Q, Sigma], len(b.delta))
+	for q, row := range b.delta {
+		delta[q] = copyRow(row)
+	}
+	if b.complete {
+		opts = append([]Option{RequireComplete()}, opts...)
+	}
+	return NewDFAWith(b.states, b.alphabet, b.q0, b.finals, delta, opts...)
+}
@@ -0,0 +1,51 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestBuilder builds mod-three fluently.
+func TestBuilder(t *testing.T) {
+	d, err := NewBuilder[State, Bit]().
+		Start(S0).Final(S0, S1, S2).
+		From(S0).On(Zero).To(S0).
+		From(S0).On(One).To(S1).
+		From(S1).On(Zero).To(S2).
+		From(S1).On(One).To(S0).
+		From(S2).On(Zero).To(S1).
+		From(S2).On(One).To(S2).
+		Complete().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(buildModThree()) {
+		t.Fatal("built machine differs from mod-three")
+	}
+}
+
+// TestBuilder_Errors reports misuse and validation failures.
+func TestBuilder_Errors(t *testing.T) {
+	for name, b := range map[string]*Builder[int, rune]{
+		"no start":         NewBuilder[int, rune]().From(0).On('a').To(0),
+		"two starts":       NewBuilder[int, rune]().Start(0).Start(1),
+		"conflicting edge": NewBuilder[int, rune]().Start(0).From(0).On('a').To(0).From(0).On('a', 'b').To(1),
+		"incomplete":       NewBuilder[int, rune]().Start(0).Alphabet('a', 'b').From(0).On('a').To(0).Complete(),
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name != "incomplete" && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+
+	// Several symbols per edge, and states only mentioned explicitly.
+	d, err := NewBuilder[int, rune]().States(9).Start(0).Final(1).From(0).On('a', 'b').To(1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := d.Accepts([]rune("b")); !ok || !d.Q.Has(9) || len(d.Sigma) != 2 {
+		t.Fatalf("got %+v", d)
+	}
+}
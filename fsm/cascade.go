@@ -0,0 +1,246 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ---------- Cascade decomposition (experimental) ----------
+
+// ErrIndecomposable reports that a machine has no nontrivial cascade
+// decomposition.
+var ErrIndecomposable = errors.New("machine has no nontrivial SP partition")
+
+// CascadeInput is a symbol of the back component of a cascade: the current
+// state of the front component together with the original input symbol.
+type CascadeInput[Sigma comparable] struct {
+	Block  int
+	Symbol Sigma
+}
+
+// ComponentKind classifies the back component of a cascade.
+type ComponentKind int
+
+const (
+	// GeneralComponent has no special structure.
+	GeneralComponent ComponentKind = iota
+	// PermutationComponent: every input permutes the back states.
+	PermutationComponent
+	// ResetComponent: every input either leaves the back state alone or
+	// resets it to a constant.
+	ResetComponent
+	// PermutationResetComponent: every input either permutes the back
+	// states or resets them to a constant.
+	PermutationResetComponent
+)
+
+func (k ComponentKind) String() string {
+	switch k {
+	case PermutationComponent:
+		return "permutation"
+	case ResetComponent:
+		return "reset"
+	case PermutationResetComponent:
+		return "permutation-reset"
+	}
+	return "general"
+}
+
+// Cascade is a two-level decomposition of a minimal machine M: a Front
+// machine whose states are the blocks of a substitution-property (SP)
+// partition of M, and a Back machine that tracks which state of the current
+// block M is in, driven by the front state and the input. The pair
+// (front, back) determines M's state as Blocks[front][back].
+//
+// This is the Hartmanis–Stearns step of Krohn–Rhodes theory. Applying
+// Decompose again to Front splits it further. The API is experimental and
+// may change.
+type Cascade[Sigma comparable] struct {
+	Machine *DFA[int, Sigma] // the minimal machine that was decomposed
+	Blocks  [][]int          // SP partition of Machine's states
+	Front   *DFA[int, Sigma]
+	Back    *DFA[int, CascadeInput[Sigma]]
+	Kind    ComponentKind // of Back
+}
+
+// Decompose minimizes d and splits it into a cascade along the finest
+// nontrivial SP partition: a grouping of states such that states in the
+// same block always move to the same block on the same symbol. The
+// minimized machine must be complete. It returns ErrIndecomposable when
+// every such partition is trivial.
+//
+// Decompose is a function rather than a method because Back is itself a
+// DFA over a derived alphabet.
+func Decompose[Q comparable, Sigma comparable](d *DFA[Q, Sigma]) (*Cascade[Sigma], error) {
+	m := d.Minimize()
+	symbols := m.Sigma.sorted()
+	n := len(m.Q)
+	next := make([][]int, n)
+	for q := 0; q < n; q++ {
+		next[q] = make([]int, len(symbols))
+		for k, a := range symbols {
+			qNext, ok := m.Delta[q][a]
+			if !ok {
+				return nil, fmt.Errorf("%w: minimal machine has no transition for (%v,%v)", ErrInvalidInput, q, a)
+			}
+			next[q][k] = qNext
+		}
+	}
+
+	var best []int
+	bestBlocks := 1
+	for p := 0; p < n; p++ {
+		for q := p + 1; q < n; q++ {
+			label, blocks := spClosure(next, p, q)
+			if blocks > bestBlocks {
+				best, bestBlocks = label, blocks
+			}
+		}
+	}
+	if best == nil {
+		return nil, ErrIndecomposable
+	}
+
+	c := &Cascade[Sigma]{Machine: m, Blocks: make([][]int, bestBlocks)}
+	pos := make([]int, n) // index of each state within its block
+	for q := 0; q < n; q++ {
+		pos[q] = len(c.Blocks[best[q]])
+		c.Blocks[best[q]] = append(c.Blocks[best[q]], q)
+	}
+
+	front := &DFA[int, Sigma]{
+		Q:     make(Set[int]),
+		Sigma: copySet(m.Sigma),
+		Q0:    best[m.Q0],
+		F:     make(Set[int]),
+		Delta: make(TransitionFn[int, Sigma]),
+	}
+	for b, members := range c.Blocks {
+		front.Q[b] = struct{}{}
+		front.Delta[b] = make(map[Sigma]int, len(symbols))
+		for k, a := range symbols {
+			front.Delta[b][a] = best[next[members[0]][k]]
+		}
+		// F is only meaningful for blocks that are entirely accepting.
+		all := true
+		for _, q := range members {
+			all = all && m.F.Has(q)
+		}
+		if all {
+			front.F[b] = struct{}{}
+		}
+	}
+	c.Front = front
+
+	back := &DFA[int, CascadeInput[Sigma]]{
+		Q:     make(Set[int]),
+		Sigma: make(Set[CascadeInput[Sigma]]),
+		Q0:    pos[m.Q0],
+		F:     make(Set[int]),
+		Delta: make(TransitionFn[int, CascadeInput[Sigma]]),
+	}
+	permutation, reset, permReset := true, true, true
+	for b, members := range c.Blocks {
+		for k, a := range symbols {
+			in := CascadeInput[Sigma]{Block: b, Symbol: a}
+			back.Sigma[in] = struct{}{}
+			seen := make(map[int]bool, len(members))
+			injective, identity, constant := true, true, true
+			for i, q := range members {
+				j := pos[next[q][k]]
+				if back.Delta[i] == nil {
+					back.Delta[i] = make(map[CascadeInput[Sigma]]int)
+				}
+				back.Delta[i][in] = j
+				if seen[j] {
+					injective = false
+				}
+				seen[j] = true
+				identity = identity && j == i
+				constant = constant && j == pos[next[members[0]][k]]
+			}
+			permutation = permutation && injective
+			reset = reset && (identity || constant)
+			permReset = permReset && (injective || constant)
+		}
+	}
+	for b := range c.Blocks {
+		for i := range c.Blocks[b] {
+			back.Q[i] = struct{}{}
+		}
+	}
+	c.Back = back
+	switch {
+	case permutation:
+		c.Kind = PermutationComponent
+	case reset:
+		c.Kind = ResetComponent
+	case permReset:
+		c.Kind = PermutationResetComponent
+	}
+	return c, nil
+}
+
+// spClosure returns the finest SP partition in which p and q share a block,
+// as a block label per state, and the number of blocks.
+func spClosure(next [][]int, p, q int) ([]int, int) {
+	parent := make([]int, len(next))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	pairs := [][2]int{{p, q}}
+	for len(pairs) > 0 {
+		pr := pairs[len(pairs)-1]
+		pairs = pairs[:len(pairs)-1]
+		x, y := find(pr[0]), find(pr[1])
+		if x == y {
+			continue
+		}
+		parent[y] = x
+		for k := range next[pr[0]] {
+			pairs = append(pairs, [2]int{next[pr[0]][k], next[pr[1]][k]})
+		}
+	}
+
+	label := make([]int, len(next))
+	ids := make(map[int]int)
+	for i := range next {
+		r := find(i)
+		id, ok := ids[r]
+		if !ok {
+			id = len(ids)
+			ids[r] = id
+		}
+		label[i] = id
+	}
+	return label, len(ids)
+}
+
+// State returns the state of Machine for a front and back state.
+func (c *Cascade[Sigma]) State(front, back int) int { return c.Blocks[front][back] }
+
+// Run drives the front and back components in lockstep and returns the
+// resulting state of Machine.
+func (c *Cascade[Sigma]) Run(input []Sigma) (int, error) {
+	f, b := c.Front.Q0, c.Back.Q0
+	for _, a := range input {
+		fNext, err := c.Front.Step(f, a)
+		if err != nil {
+			return c.State(f, b), err
+		}
+		b, err = c.Back.Step(b, CascadeInput[Sigma]{Block: f, Symbol: a})
+		if err != nil {
+			return c.State(f, b), err
+		}
+		f = fNext
+	}
+	return c.State(f, b), nil
+}
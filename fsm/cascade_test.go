@@ -0,0 +1,60 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// counterDFA counts 'a' modulo n; 'r', if reset is set, returns to 0.
+func counterDFA(n int, reset bool) *DFA[int, rune] {
+	alphabet := []rune("a")
+	if reset {
+		alphabet = append(alphabet, 'r')
+	}
+	states := make([]int, n)
+	delta := TransitionFn[int, rune]{}
+	for i := range states {
+		states[i] = i
+		delta[i] = map[rune]int{'a': (i + 1) % n}
+		if reset {
+			delta[i]['r'] = 0
+		}
+	}
+	return Must(NewDFA(states, alphabet, 0, []int{0}, delta, true))
+}
+
+// TestDecompose_Counter splits a mod-4 counter into parity and a bit.
+func TestDecompose_Counter(t *testing.T) {
+	for _, tc := range []struct {
+		reset bool
+		kind  ComponentKind
+	}{{false, PermutationComponent}, {true, PermutationResetComponent}} {
+		d := counterDFA(4, tc.reset)
+		c, err := Decompose(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.Blocks) != 2 || len(c.Front.Q) != 2 || len(c.Back.Q) != 2 {
+			t.Fatalf("reset=%v: blocks %v", tc.reset, c.Blocks)
+		}
+		if c.Kind != tc.kind {
+			t.Fatalf("reset=%v: kind %v, want %v", tc.reset, c.Kind, tc.kind)
+		}
+		for _, w := range allWords(d.Sigma.sorted(), 6) {
+			want, _ := c.Machine.Run(w)
+			if got, err := c.Run(w); err != nil || got != want {
+				t.Fatalf("reset=%v %q: cascade %v,%v, machine %v", tc.reset, string(w), got, err, want)
+			}
+		}
+	}
+}
+
+// TestDecompose_Prime reports machines with no nontrivial SP partition.
+func TestDecompose_Prime(t *testing.T) {
+	if _, err := Decompose(counterDFA(3, false)); !errors.Is(err, ErrIndecomposable) {
+		t.Fatalf("mod-3 counter: got %v, want ErrIndecomposable", err)
+	}
+	if _, err := Decompose(literalDFA("ab")); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("partial machine: got %v, want ErrInvalidInput", err)
+	}
+}
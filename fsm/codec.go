@@ -0,0 +1,151 @@
+package fsm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ---------- Symbol decoding ----------
+
+// SymbolDecoder turns raw input into symbols. Decode reads the next symbol
+// from r, skipping any separators, and returns io.EOF at the end of input.
+type SymbolDecoder[Sigma any] interface {
+	Decode(r *bufio.Reader) (Sigma, error)
+}
+
+// SymbolDecoderFunc adapts a function to the SymbolDecoder interface.
+type SymbolDecoderFunc[Sigma any] func(r *bufio.Reader) (Sigma, error)
+
+func (f SymbolDecoderFunc[Sigma]) Decode(r *bufio.Reader) (Sigma, error) { return f(r) }
+
+// DefaultSeparators are the runes the built-in rune decoders skip, so that
+// input such as "1011_0000" or "10 11" can be grouped for readability.
+const DefaultSeparators = " \t_"
+
+// RuneDecoder decodes one symbol per rune through mapping and skips the
+// runes in ignore. Any other rune is an ErrInvalidInput.
+func RuneDecoder[Sigma any](mapping map[rune]Sigma, ignore string) SymbolDecoder[Sigma] {
+	return SymbolDecoderFunc[Sigma](func(r *bufio.Reader) (Sigma, error) {
+		var zero Sigma
+		for {
+			c, _, err := r.ReadRune()
+			if err != nil {
+				return zero, err
+			}
+			if a, ok := mapping[c]; ok {
+				return a, nil
+			}
+			if !strings.ContainsRune(ignore, c) {
+				return zero, fmt.Errorf("%w: %q", ErrInvalidInput, c)
+			}
+		}
+	})
+}
+
+// BitDecoder decodes the ASCII digits '0' and '1' to zero and one,
+// skipping DefaultSeparators.
+func BitDecoder[Sigma any](zero, one Sigma) SymbolDecoder[Sigma] {
+	return RuneDecoder(map[rune]Sigma{'0': zero, '1': one}, DefaultSeparators)
+}
+
+// DigitDecoder decodes the ASCII digit '0'+i to digits[i] (at most ten
+// digits), skipping DefaultSeparators.
+func DigitDecoder[Sigma any](digits ...Sigma) SymbolDecoder[Sigma] {
+	mapping := make(map[rune]Sigma, len(digits))
+	for i, a := range digits {
+		if i > 9 {
+			break
+		}
+		mapping[rune('0'+i)] = a
+	}
+	return RuneDecoder(mapping, DefaultSeparators)
+}
+
+// LineDecoder decodes one symbol per line with parse, for record-oriented
+// input. Blank lines are skipped; the line ending is not passed to parse.
+func LineDecoder[Sigma any](parse func(line string) (Sigma, error)) SymbolDecoder[Sigma] {
+	return SymbolDecoderFunc[Sigma](func(r *bufio.Reader) (Sigma, error) {
+		var zero Sigma
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+				return zero, err
+			}
+			if line = strings.TrimRight(line, "\r\n"); strings.TrimSpace(line) != "" {
+				return parse(line)
+			}
+		}
+	})
+}
+
+// DecodeAll decodes every symbol in r.
+func DecodeAll[Sigma any](dec SymbolDecoder[Sigma], r io.Reader) ([]Sigma, error) {
+	br := bufio.NewReader(r)
+	var out []Sigma
+	for {
+		a, err := dec.Decode(br)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+}
+
+// DecodeString decodes every symbol in s.
+func DecodeString[Sigma any](dec SymbolDecoder[Sigma], s string) ([]Sigma, error) {
+	return DecodeAll(dec, strings.NewReader(s))
+}
+
+// ---------- Symbol encoding ----------
+
+// SymbolEncoder turns symbols back into raw output, the inverse of a
+// SymbolDecoder. Encode writes a to w.
+type SymbolEncoder[Sigma any] interface {
+	Encode(w *bufio.Writer, a Sigma) error
+}
+
+// SymbolEncoderFunc adapts a function to the SymbolEncoder interface.
+type SymbolEncoderFunc[Sigma any] func(w *bufio.Writer, a Sigma) error
+
+func (f SymbolEncoderFunc[Sigma]) Encode(w *bufio.Writer, a Sigma) error { return f(w, a) }
+
+// RuneEncoder encodes each symbol as the rune mapping gives for it. Any
+// other symbol is an ErrInvalidInput.
+func RuneEncoder[Sigma comparable](mapping map[Sigma]rune) SymbolEncoder[Sigma] {
+	return SymbolEncoderFunc[Sigma](func(w *bufio.Writer, a Sigma) error {
+		c, ok := mapping[a]
+		if !ok {
+			return fmt.Errorf("%w: no rune for %v", ErrInvalidInput, a)
+		}
+		_, err := w.WriteRune(c)
+		return err
+	})
+}
+
+// RunReader decodes symbols from r with dec and runs them from q0 as they
+// arrive, without buffering the whole input. Errors carry the index of the
+// offending symbol; the returned state is the last one reached.
+func (d *DFA[Q, Sigma]) RunReader(r io.Reader, dec SymbolDecoder[Sigma]) (Q, error) {
+	br := bufio.NewReader(r)
+	q := d.Q0
+	for i := 0; ; i++ {
+		a, err := dec.Decode(br)
+		if errors.Is(err, io.EOF) {
+			return q, nil
+		}
+		if err != nil {
+			return q, fmt.Errorf("symbol %d: %w", i, err)
+		}
+		qNext, ok := d.next(q, a)
+		if !ok {
+			return q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}}
+		}
+		q = qNext
+	}
+}
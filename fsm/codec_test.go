@@ -0,0 +1,58 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestBitDecoder skips separators and rejects other runes.
+func TestBitDecoder(t *testing.T) {
+	dec := BitDecoder(Zero, One)
+	got, err := DecodeString(dec, "10 1_1\t0")
+	if err != nil || string(got) != "10110" {
+		t.Fatalf("DecodeString = %q, %v", got, err)
+	}
+	if _, err := DecodeString(dec, "102"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestDigitDecoder maps decimal digits to symbols.
+func TestDigitDecoder(t *testing.T) {
+	dec := DigitDecoder(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	got, err := DecodeString(dec, "4_07")
+	if err != nil || fmt.Sprint(got) != "[4 0 7]" {
+		t.Fatalf("DecodeString = %v, %v", got, err)
+	}
+}
+
+// TestLineDecoder parses one record per line, skipping blanks.
+func TestLineDecoder(t *testing.T) {
+	dec := LineDecoder(func(line string) (Bit, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			return 0, err
+		}
+		return Bit('0' + n%2), nil
+	})
+	got, err := DecodeString(dec, "3\n\n4\r\n 7")
+	if err != nil || string(got) != "101" {
+		t.Fatalf("DecodeString = %q, %v", got, err)
+	}
+}
+
+// TestRunReader streams decoded symbols through the machine.
+func TestRunReader(t *testing.T) {
+	d := buildModThree()
+	q, err := d.RunReader(strings.NewReader("1111_000"), BitDecoder(Zero, One)) // 120
+	if err != nil || q != S0 {
+		t.Fatalf("RunReader = %v, %v; want S0", q, err)
+	}
+	q, err = d.RunReader(strings.NewReader("11x"), BitDecoder(Zero, One))
+	if !errors.Is(err, ErrInvalidInput) || q != S0 || !strings.Contains(err.Error(), "symbol 2") {
+		t.Fatalf("RunReader = %v, %v; want S0 and an error at symbol 2", q, err)
+	}
+}
@@ -0,0 +1,134 @@
+package fsm
+
+// ---------- Code properties ----------
+
+// IsPrefixFree reports whether no word of L(d) is a proper prefix of
+// another, so a stream of codewords can be cut as soon as a codeword ends.
+// When it is not, word and longer witness it: both are in L(d) and word is
+// a proper prefix of longer.
+func (d *DFA[Q, Sigma]) IsPrefixFree() (ok bool, word, longer []Sigma) {
+	symbols := d.Sigma.sorted()
+	useful := d.useful()
+	if !useful.Has(d.Q0) {
+		return true, nil, nil
+	}
+	// Shortest path from q0 to every useful state.
+	paths := d.shortestPaths(d.Q0, symbols, useful)
+	for _, f := range d.F.sorted() {
+		p, ok := paths[f]
+		if !ok {
+			continue
+		}
+		// Shortest non-empty path from f back to any final state.
+		for _, a := range symbols {
+			qNext, ok := d.next(f, a)
+			if !ok || !useful.Has(qNext) {
+				continue
+			}
+			rest := d.shortestPaths(qNext, symbols, useful)
+			var tail []Sigma
+			found := false
+			for _, g := range d.F.sorted() {
+				if w, ok := rest[g]; ok && (!found || len(w) < len(tail)) {
+					tail, found = w, true
+				}
+			}
+			if found {
+				longer := append(append(append([]Sigma{}, p...), a), tail...)
+				return false, p, longer
+			}
+		}
+	}
+	return true, nil, nil
+}
+
+// shortestPaths returns a shortest word from q to every state of within
+// reachable through states of within.
+func (d *DFA[Q, Sigma]) shortestPaths(q Q, symbols []Sigma, within Set[Q]) map[Q][]Sigma {
+	paths := map[Q][]Sigma{q: {}}
+	queue := []Q{q}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, a := range symbols {
+			qNext, ok := d.next(p, a)
+			if !ok || !within.Has(qNext) {
+				continue
+			}
+			if _, seen := paths[qNext]; !seen {
+				paths[qNext] = append(append([]Sigma{}, paths[p]...), a)
+				queue = append(queue, qNext)
+			}
+		}
+	}
+	return paths
+}
+
+// parsePair is a node of the unique-decodability search: two parsers
+// reading the same input, each inside a codeword at state P or R, and
+// whether their cut points have differed yet.
+type parsePair[Q comparable] struct {
+	P, R     Q
+	Diverged bool
+}
+
+// IsUniquelyDecodable reports whether L(d) is a uniquely decodable code:
+// every concatenation of codewords splits back into codewords in only one
+// way. Prefix-free languages are uniquely decodable, but so are others
+// (e.g. {0, 01, 11}), which need lookahead to decode. When the language is
+// not uniquely decodable, witness is a shortest string with two
+// factorizations. A language containing the empty word is never uniquely
+// decodable; its witness is the empty word.
+//
+// The test runs two parsers in lockstep over the product of d with itself;
+// each parser may end a codeword whenever it is in an accepting state. The
+// language is ambiguous iff both parsers can finish together after making
+// different cuts.
+func (d *DFA[Q, Sigma]) IsUniquelyDecodable() (ok bool, witness []Sigma) {
+	if d.F.Has(d.Q0) {
+		return false, []Sigma{}
+	}
+	symbols := d.Sigma.sorted()
+	useful := d.useful()
+	// moves returns the states a parser at p can be in after reading a,
+	// tagged with whether it cut before reading.
+	type move struct {
+		q   Q
+		cut bool
+	}
+	moves := func(p Q, a Sigma) []move {
+		var out []move
+		if q, ok := d.next(p, a); ok && useful.Has(q) {
+			out = append(out, move{q, false})
+		}
+		if d.F.Has(p) {
+			if q, ok := d.next(d.Q0, a); ok && useful.Has(q) {
+				out = append(out, move{q, true})
+			}
+		}
+		return out
+	}
+
+	start := parsePair[Q]{d.Q0, d.Q0, false}
+	words := map[parsePair[Q]][]Sigma{start: {}}
+	queue := []parsePair[Q]{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.Diverged && d.F.Has(n.P) && d.F.Has(n.R) {
+			return false, words[n]
+		}
+		for _, a := range symbols {
+			for _, mp := range moves(n.P, a) {
+				for _, mr := range moves(n.R, a) {
+					m := parsePair[Q]{mp.q, mr.q, n.Diverged || mp.cut != mr.cut}
+					if _, seen := words[m]; !seen {
+						words[m] = append(append([]Sigma{}, words[n]...), a)
+						queue = append(queue, m)
+					}
+				}
+			}
+		}
+	}
+	return true, nil
+}
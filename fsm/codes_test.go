@@ -0,0 +1,65 @@
+package fsm
+
+import "testing"
+
+// codeDFA builds a trie accepting exactly the given words.
+func codeDFA(words ...string) *DFA[int, rune] {
+	delta := TransitionFn[int, rune]{}
+	states := []int{0}
+	var finals []int
+	var alphabet []rune
+	for _, w := range words {
+		q := 0
+		for _, r := range w {
+			alphabet = append(alphabet, r)
+			if delta[q] == nil {
+				delta[q] = map[rune]int{}
+			}
+			next, ok := delta[q][r]
+			if !ok {
+				next = len(states)
+				states = append(states, next)
+				delta[q][r] = next
+			}
+			q = next
+		}
+		finals = append(finals, q)
+	}
+	return Must(NewDFA(states, alphabet, 0, finals, delta, false))
+}
+
+// TestIsPrefixFree checks codes with and without prefix pairs.
+func TestIsPrefixFree(t *testing.T) {
+	if ok, _, _ := codeDFA("0", "10", "11").IsPrefixFree(); !ok {
+		t.Fatal("{0,10,11} is prefix-free")
+	}
+	ok, word, longer := codeDFA("0", "01", "11").IsPrefixFree()
+	if ok || string(word) != "0" || string(longer) != "01" {
+		t.Fatalf("got %v,%q,%q want false,\"0\",\"01\"", ok, string(word), string(longer))
+	}
+	// a*b is prefix-free although infinite; a* is not.
+	ab := Must(NewDFA([]int{0, 1}, []rune("ab"), 0, []int{1}, TransitionFn[int, rune]{0: {'a': 0, 'b': 1}}, false))
+	if ok, _, _ := ab.IsPrefixFree(); !ok {
+		t.Fatal("a*b is prefix-free")
+	}
+	star := Must(NewDFA([]int{0}, []rune("a"), 0, []int{0}, TransitionFn[int, rune]{0: {'a': 0}}, true))
+	if ok, word, longer := star.IsPrefixFree(); ok || len(word) != 0 || string(longer) != "a" {
+		t.Fatalf("a*: got %v,%q,%q", ok, string(word), string(longer))
+	}
+}
+
+// TestIsUniquelyDecodable compares with known codes.
+func TestIsUniquelyDecodable(t *testing.T) {
+	for _, code := range [][]string{{"0", "10", "11"}, {"0", "01", "11"}, {"1", "10", "100"}} {
+		if ok, w := codeDFA(code...).IsUniquelyDecodable(); !ok {
+			t.Fatalf("%v is uniquely decodable; witness %q", code, string(w))
+		}
+	}
+	ok, w := codeDFA("0", "01", "10").IsUniquelyDecodable()
+	if ok || string(w) != "010" {
+		t.Fatalf("{0,01,10}: got %v,%q want false,\"010\"", ok, string(w))
+	}
+	if ok, _ := codeDFA("a", "aa").IsUniquelyDecodable(); ok {
+		t.Fatal("{a,aa} is not uniquely decodable")
+	}
+}
@@ -0,0 +1,309 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ---------- Compiled tables ----------
+
+// Table is a compiled form of a DFA for fast execution and export.
+// States and symbols are numbered 0..n-1, with -1 for undefined transitions.
+// State 0 is q0; the rest follow in breadth-first order, so numbering is
+// stable for a given machine.
+//
+// Dense tables store δ in Next, indexed by state*len(Symbols)+symbol.
+// When most of each row repeats one target (typical of huge alphabets),
+// Compile picks a sparse layout instead: per state, a default target plus
+// the sorted exceptional edges, looked up by binary search. Next is then nil;
+// use At to read δ independently of the layout.
+type Table[Q comparable, Sigma comparable] struct {
+	States      []Q
+	Symbols     []Sigma
+	Final       []bool
+	Next        []int32
+	Fingerprint string // of the source DFA
+
+	sparse []sparseRow
+
+	// Only the symbols with an edge of their own are indexed: every other
+	// symbol of Σ takes the target of the row, like symbol other.
+	lookup map[Sigma]int32
+	other  int32      // -1 if every symbol is indexed
+	sigma  Set[Sigma] // Σ of the source DFA, shared with it
+}
+
+// sparseRow is one state of a sparse table.
+type sparseRow struct {
+	def  int32   // target of every symbol not listed in syms
+	syms []int32 // sorted symbol numbers with a different target
+	next []int32 // their targets
+}
+
+// tableEdge is an edge of the source DFA on a numbered symbol.
+type tableEdge[Q comparable] struct {
+	sym int32
+	to  Q
+}
+
+// Compile numbers the states and symbols of d and tabulates δ, choosing the
+// dense or sparse layout by estimated size. It works from the edges of d
+// and its defaults, not from every pair of state and symbol, so a sparse
+// table costs time and memory in the number of edges besides the sorted
+// Symbols. The table shares d.Sigma, which must not change afterwards.
+func (d *DFA[Q, Sigma]) Compile() *Table[Q, Sigma] {
+	t := &Table[Q, Sigma]{
+		Symbols:     d.Sigma.sorted(),
+		Fingerprint: d.Fingerprint(),
+		lookup:      make(map[Sigma]int32),
+		other:       -1,
+		sigma:       d.Sigma,
+	}
+	n := len(t.Symbols)
+	edges := make(map[Q][]tableEdge[Q], len(d.Delta))
+	for q := range d.Q {
+		var es []tableEdge[Q]
+		for a, qNext := range d.Delta[q] {
+			if !d.Sigma.Has(a) {
+				continue
+			}
+			j, ok := t.lookup[a]
+			if !ok {
+				j, _ = t.search(a)
+				t.lookup[a] = j
+			}
+			es = append(es, tableEdge[Q]{j, qNext})
+		}
+		sort.Slice(es, func(i, j int) bool { return es[i].sym < es[j].sym })
+		edges[q] = es
+	}
+
+	states := d.edgeOrder(edges, n)
+	t.States = states
+	t.Final = make([]bool, len(states))
+	index := make(map[Q]int32, len(states))
+	for i, q := range states {
+		index[q] = int32(i)
+	}
+
+	rows := make([]sparseRow, len(states))
+	sparseCells := 0
+	for i, q := range states {
+		t.Final[i] = d.F.Has(q)
+		es := edges[q]
+		implicit, nImplicit := int32(-1), n-len(es)
+		if qDef, ok := d.Default[q]; ok {
+			implicit = index[qDef]
+		}
+		counts := make(map[int32]int)
+		for _, e := range es {
+			counts[index[e.to]]++
+		}
+		if nImplicit > 0 {
+			counts[implicit] += nImplicit
+		}
+		def, best := int32(-1), -1
+		for target, c := range counts {
+			if c > best || (c == best && target < def) {
+				def, best = target, c
+			}
+		}
+		r := sparseRow{def: def}
+		add := func(j, target int32) {
+			if target != def {
+				r.syms = append(r.syms, j)
+				r.next = append(r.next, target)
+			}
+		}
+		if nImplicit > 0 && implicit != def {
+			// The edges outnumber the symbols without one, so listing
+			// those costs no more than the edges themselves.
+			k := 0
+			for j := range t.Symbols {
+				if k < len(es) && es[k].sym == int32(j) {
+					add(es[k].sym, index[es[k].to])
+					k++
+					continue
+				}
+				add(int32(j), implicit)
+				t.lookup[t.Symbols[j]] = int32(j)
+			}
+		} else {
+			for _, e := range es {
+				add(e.sym, index[e.to])
+			}
+		}
+		rows[i] = r
+		sparseCells += 1 + 2*len(r.syms)
+	}
+	for j, a := range t.Symbols {
+		if _, ok := t.lookup[a]; !ok {
+			t.other = int32(j)
+			break
+		}
+	}
+
+	if denseCells := len(states) * n; sparseCells*4 < denseCells {
+		t.sparse = rows
+		return t
+	}
+	t.Next = make([]int32, len(states)*n)
+	for i, r := range rows {
+		base := t.Next[i*n : (i+1)*n]
+		for j := range base {
+			base[j] = r.def
+		}
+		for k, j := range r.syms {
+			base[j] = r.next[k]
+		}
+	}
+	return t
+}
+
+// edgeOrder is bfsOrder over the numbered edges of each state, with the
+// default standing in for the first symbol without an edge.
+func (d *DFA[Q, Sigma]) edgeOrder(edges map[Q][]tableEdge[Q], n int) []Q {
+	seen := NewSet(d.Q0)
+	order := []Q{d.Q0}
+	visit := func(q Q) {
+		if !seen.Has(q) {
+			seen[q] = struct{}{}
+			order = append(order, q)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		es := edges[order[i]]
+		gap := int32(0)
+		for _, e := range es {
+			if e.sym != gap {
+				break
+			}
+			gap++
+		}
+		qDef, hasDef := d.Default[order[i]]
+		hasDef = hasDef && int(gap) < n
+		for _, e := range es {
+			if hasDef && gap < e.sym {
+				visit(qDef)
+				hasDef = false
+			}
+			visit(e.to)
+		}
+		if hasDef {
+			visit(qDef)
+		}
+	}
+	for _, q := range d.Q.sorted() {
+		if !seen.Has(q) {
+			order = append(order, q)
+		}
+	}
+	return order
+}
+
+// Sparse reports whether the table uses the sparse layout.
+func (t *Table[Q, Sigma]) Sparse() bool { return t.sparse != nil }
+
+// At returns δ for numbered states and symbols, or -1 when undefined.
+func (t *Table[Q, Sigma]) At(state, symbol int32) int32 {
+	if state < 0 || int(state) >= len(t.States) || symbol < 0 || int(symbol) >= len(t.Symbols) {
+		return -1
+	}
+	if t.sparse == nil {
+		return t.Next[int(state)*len(t.Symbols)+int(symbol)]
+	}
+	r := &t.sparse[state]
+	i := sort.Search(len(r.syms), func(i int) bool { return r.syms[i] >= symbol })
+	if i < len(r.syms) && r.syms[i] == symbol {
+		return r.next[i]
+	}
+	return r.def
+}
+
+// bfsOrder lists q0, then the states reachable from it in breadth-first
+// order (following symbols in the given order), then the unreachable states
+// in sorted order.
+func (d *DFA[Q, Sigma]) bfsOrder(symbols []Sigma) []Q {
+	seen := NewSet(d.Q0)
+	order := []Q{d.Q0}
+	for i := 0; i < len(order); i++ {
+		for _, a := range symbols {
+			if qNext, ok := d.next(order[i], a); ok && !seen.Has(qNext) {
+				seen[qNext] = struct{}{}
+				order = append(order, qNext)
+			}
+		}
+	}
+	for _, q := range d.Q.sorted() {
+		if !seen.Has(q) {
+			order = append(order, q)
+		}
+	}
+	return order
+}
+
+// Symbol returns the index of a, or false if a ∉ Σ.
+func (t *Table[Q, Sigma]) Symbol(a Sigma) (int32, bool) {
+	if i, ok := t.lookup[a]; ok {
+		return i, true
+	}
+	if !t.sigma.Has(a) {
+		return 0, false
+	}
+	return t.search(a)
+}
+
+// search finds a in the sorted Symbols by binary search.
+func (t *Table[Q, Sigma]) search(a Sigma) (int32, bool) {
+	key := func(x Sigma) string { return fmt.Sprintf("%#v", x) }
+	k := key(a)
+	i := sort.Search(len(t.Symbols), func(i int) bool { return key(t.Symbols[i]) >= k })
+	for ; i < len(t.Symbols) && key(t.Symbols[i]) == k; i++ {
+		if t.Symbols[i] == a {
+			return int32(i), true
+		}
+	}
+	return 0, false
+}
+
+// Step applies δ to numbered states; it returns -1 when undefined.
+func (t *Table[Q, Sigma]) Step(state int32, a Sigma) int32 {
+	i, ok := t.lookup[a]
+	if !ok {
+		if !t.sigma.Has(a) {
+			return -1
+		}
+		i = t.other
+	}
+	return t.At(state, i)
+}
+
+// run consumes input from state 0 and returns the final state number. A
+// symbol that cannot be consumed is reported as a *RunError.
+func (t *Table[Q, Sigma]) run(input []Sigma) (int32, error) {
+	s := int32(0)
+	for i, a := range input {
+		next := t.Step(s, a)
+		if next < 0 {
+			q := t.States[s]
+			return s, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}}
+		}
+		s = next
+	}
+	return s, nil
+}
+
+// Run consumes input from state 0 and returns the final state.
+func (t *Table[Q, Sigma]) Run(input []Sigma) (Q, error) {
+	s, err := t.run(input)
+	return t.States[s], err
+}
+
+// Accepts runs the table and checks whether the final state is accepting.
+func (t *Table[Q, Sigma]) Accepts(input []Sigma) (bool, Q, error) {
+	s, err := t.run(input)
+	if err != nil {
+		return false, t.States[s], err
+	}
+	return t.Final[s], t.States[s], nil
+}
@@ -0,0 +1,212 @@
+package fsm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestCompile_MatchesDFA runs random inputs through the map and table forms.
+func TestCompile_MatchesDFA(t *testing.T) {
+	d := buildModThree()
+	tab := d.Compile()
+	if tab.States[0] != S0 {
+		t.Fatalf("state 0 should be q0, got %v", tab.States[0])
+	}
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		in := make([]Bit, r.Intn(40))
+		for i := range in {
+			in[i] = []Bit{Zero, One}[r.Intn(2)]
+		}
+		want, _ := d.Run(in)
+		got, err := tab.Run(in)
+		if err != nil || got != want {
+			t.Fatalf("%v: table=%v,%v dfa=%v", in, got, err, want)
+		}
+	}
+	var re *RunError[int, rune]
+	if _, err := literalDFA("ab").Compile().Run([]rune("ac")); !errors.As(err, &re) || re.Index != 1 || re.State != 1 || !errors.Is(err, ErrUndefined) {
+		t.Fatalf("expected a RunError for an undefined transition, got %v", err)
+	}
+}
+
+// TestWriteGo emits formatted source with the table and entry points.
+func TestWriteGo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := buildModThree().Compile().WriteGo(&buf, "modthree", "", true); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package modthree",
+		"NumStates  = 3",
+		"//export step",
+		"func Symbol(value int32) int32",
+		"case 48:", // '0'
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated code lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+// TestWriteGo_StringSymbols maps symbol names for string alphabets.
+func TestWriteGo_StringSymbols(t *testing.T) {
+	def, _ := ParseDefinition([]byte(turnstileJSON))
+	d, _ := def.DFA()
+	var buf bytes.Buffer
+	if err := d.Compile().WriteGo(&buf, "turnstile", "", true); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{"func Symbol(name string) int32", `case "push":`} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated code lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+// TestWriteGo_Prefix generates two machines into one package, which must
+// type-check together and keep the package comment out.
+func TestWriteGo_Prefix(t *testing.T) {
+	def, _ := ParseDefinition([]byte(turnstileJSON))
+	d, _ := def.DFA()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i, c := range []struct {
+		prefix string
+		write  func(w io.Writer, pkg, prefix string, packageDoc bool) error
+	}{
+		{"ModThree", buildModThree().Compile().WriteGo},
+		{"Turnstile", d.Compile().WriteGo},
+	} {
+		var buf bytes.Buffer
+		if err := c.write(&buf, "machines", c.prefix, false); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, fmt.Sprintf("gen%d.go", i), buf.Bytes(), parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Doc != nil {
+			t.Errorf("package comment emitted: %q", f.Doc.Text())
+		}
+		files = append(files, f)
+	}
+	if _, err := new(types.Config).Check("machines", fset, files, nil); err != nil {
+		t.Fatalf("generated files do not compile together: %v", err)
+	}
+	var buf bytes.Buffer
+	buildModThree().Compile().WriteGo(&buf, "machines", "ModThree", false)
+	for _, want := range []string{"func ModThreeStep(", "//export modthree_step", "modThreeNext[", "ModThreeNumStates"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("generated code lacks %q", want)
+		}
+	}
+}
+
+// TestWriteC emits a header with guards, table and step function.
+func TestWriteC(t *testing.T) {
+	var buf bytes.Buffer
+	if err := buildModThree().Compile().WriteC(&buf, "modthree"); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"#ifndef MODTHREE_FSM_H",
+		"#define MODTHREE_NUM_STATES 3",
+		"static const int8_t modthree_next[MODTHREE_NUM_STATES][MODTHREE_NUM_SYMBOLS] = {",
+		"    {2, 0},",
+		"static inline int32_t modthree_step(int32_t state, int32_t symbol) {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("header lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+// TestCompile_Sparse picks the sparse layout for a huge, mostly-default alphabet.
+func TestCompile_Sparse(t *testing.T) {
+	const n = 5000
+	alphabet := make([]int, n)
+	for i := range alphabet {
+		alphabet[i] = i
+	}
+	// 0 --any--> 0, except 0 --42--> 1; 1 --any--> 0
+	row0 := make(map[int]int, n)
+	row1 := make(map[int]int, n)
+	for _, a := range alphabet {
+		row0[a], row1[a] = 0, 0
+	}
+	row0[42] = 1
+	d := Must(NewDFA([]int{0, 1}, alphabet, 0, []int{1}, TransitionFn[int, int]{0: row0, 1: row1}, true))
+
+	tab := d.Compile()
+	if !tab.Sparse() || tab.Next != nil {
+		t.Fatal("expected sparse layout")
+	}
+	for _, in := range [][]int{{1, 42}, {42, 7}, {42, 42, 42}, {4999, 42}} {
+		want, _, _ := d.Accepts(in)
+		got, _, err := tab.Accepts(in)
+		if err != nil || got != want {
+			t.Fatalf("%v: table=%v,%v dfa=%v", in, got, err, want)
+		}
+	}
+	if buildModThree().Compile().Sparse() {
+		t.Fatal("mod-three table should stay dense")
+	}
+}
+
+// TestCompile_Defaults builds a table from the edges and defaults alone: a
+// loop over every state and symbol would take |Q|·|Σ| = 2·10⁸ steps here.
+func TestCompile_Defaults(t *testing.T) {
+	const states, symbols = 2000, 100000
+	qs := make([]int, states)
+	alphabet := make([]int32, symbols)
+	for i := range alphabet {
+		alphabet[i] = int32(i)
+	}
+	// i --(7i mod Σ)--> i+1, anything else back to 0; the last state accepts.
+	delta := make(TransitionFn[int, int32], states)
+	defaults := make(map[int]int, states)
+	for i := range qs {
+		qs[i] = i
+		delta[i] = map[int32]int{int32(7 * i % symbols): (i + 1) % states}
+		defaults[i] = 0
+	}
+	d := Must(NewDFAWithDefaults(qs, alphabet, 0, []int{states - 1}, delta, defaults, true))
+
+	tab := d.Compile()
+	if !tab.Sparse() {
+		t.Fatal("expected sparse layout")
+	}
+	if len(tab.lookup) > states {
+		t.Fatalf("indexed %d symbols, want at most one per edge", len(tab.lookup))
+	}
+	path := make([]int32, states-1)
+	for i := range path {
+		path[i] = int32(7 * i % symbols)
+	}
+	for _, in := range [][]int32{path, append([]int32{3}, path...), path[:10], {99999, 0, 7}} {
+		want, _, _ := d.Accepts(in)
+		got, _, err := tab.Accepts(in)
+		if err != nil || got != want {
+			t.Fatalf("%v...: table=%v,%v dfa=%v", in[:3], got, err, want)
+		}
+	}
+	if i, ok := tab.Symbol(54321); !ok || tab.Symbols[i] != 54321 {
+		t.Fatalf("Symbol(54321) = %d, %v", i, ok)
+	}
+	if _, ok := tab.Symbol(symbols); ok || tab.Step(0, symbols) != -1 {
+		t.Fatal("a symbol outside Σ must be undefined")
+	}
+}
@@ -0,0 +1,46 @@
+package fsm
+
+// ---------- Debugging ----------
+
+// Edge identifies the transition taken from state From on symbol On.
+type Edge[Q comparable, Sigma comparable] struct {
+	From Q
+	On   Sigma
+}
+
+// Breakpoints selects where RunDebug pauses.
+type Breakpoints[Q comparable, Sigma comparable] struct {
+	States Set[Q]              // pause when one of these states is entered
+	Edges  Set[Edge[Q, Sigma]] // pause when one of these transitions is taken
+}
+
+// Hit describes a breakpoint that fired: the transition δ(From,On) = To was
+// taken while consuming input[Pos].
+type Hit[Q comparable, Sigma comparable] struct {
+	Pos  int
+	From Q
+	On   Sigma
+	To   Q
+}
+
+// RunDebug is Run with breakpoints. After every transition that enters a
+// state in bp.States or takes an edge in bp.Edges, it calls hook and waits
+// for it to return; the hook may inspect the run, block until a debugger
+// resumes it, or return an error to abort the run with that error. An
+// undefined transition is reported as by Run, with a *RunError.
+func (d *DFA[Q, Sigma]) RunDebug(input []Sigma, bp Breakpoints[Q, Sigma], hook func(Hit[Q, Sigma]) error) (Q, error) {
+	q := d.Q0
+	for i, a := range input {
+		qNext, err := d.Step(q, a)
+		if err != nil {
+			return q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
+		}
+		if bp.States.Has(qNext) || bp.Edges.Has(Edge[Q, Sigma]{From: q, On: a}) {
+			if err := hook(Hit[Q, Sigma]{Pos: i, From: q, On: a, To: qNext}); err != nil {
+				return qNext, err
+			}
+		}
+		q = qNext
+	}
+	return q, nil
+}
@@ -0,0 +1,46 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestRunDebug_Breakpoints checks that state and edge breakpoints fire in order.
+func TestRunDebug_Breakpoints(t *testing.T) {
+	d := buildModThree()
+	bp := Breakpoints[State, Bit]{
+		States: NewSet(S2),
+		Edges:  NewSet(Edge[State, Bit]{From: S1, On: One}),
+	}
+	var hits []Hit[State, Bit]
+	final, err := d.RunDebug([]Bit{One, One, One, Zero}, bp, func(h Hit[State, Bit]) error {
+		hits = append(hits, h)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final != S2 {
+		t.Fatalf("final = %v, want S2", final)
+	}
+	// 1: S0→S1, 1: S1→S0 (edge bp), 1: S0→S1, 0: S1→S2 (state bp)
+	want := []Hit[State, Bit]{
+		{Pos: 1, From: S1, On: One, To: S0},
+		{Pos: 3, From: S1, On: Zero, To: S2},
+	}
+	if !reflect.DeepEqual(hits, want) {
+		t.Fatalf("hits = %v, want %v", hits, want)
+	}
+}
+
+// TestRunDebug_Abort stops the run with the hook's error.
+func TestRunDebug_Abort(t *testing.T) {
+	d := buildModThree()
+	stop := errors.New("stop")
+	final, err := d.RunDebug([]Bit{One, Zero, One}, Breakpoints[State, Bit]{States: NewSet(S2)},
+		func(Hit[State, Bit]) error { return stop })
+	if !errors.Is(err, stop) || final != S2 {
+		t.Fatalf("got (%v, %v), want (S2, stop)", final, err)
+	}
+}
@@ -0,0 +1,106 @@
+package fsm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---------- Machine definitions ----------
+
+// Definition is a serializable machine description with string states and
+// symbols, for machines kept in data files beside the code (see
+// cmd/fsmgen). Delta maps each state to its row: symbol → next state.
+type Definition struct {
+	States   []string                     `json:"states"`
+	Alphabet []string                     `json:"alphabet"`
+	Start    string                       `json:"start"`
+	Final    []string                     `json:"final"`
+	Delta    map[string]map[string]string `json:"delta"`
+	Complete bool                         `json:"complete,omitempty"` // require a total δ
+}
+
+// ParseDefinition decodes a JSON definition, rejecting unknown fields so
+// that typos do not silently drop parts of a machine.
+func ParseDefinition(data []byte) (*Definition, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var def Definition
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("%w: definition: %v", ErrInvalidInput, err)
+	}
+	return &def, nil
+}
+
+// LoadDefinition reads a JSON definition from the file at path.
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+// Decoder returns a decoder for inputs over the alphabet. If every symbol
+// is a single character, the input is a string of them in which
+// DefaultSeparators are ignored, e.g. "1101"; otherwise it is a list of
+// symbols separated by white space, e.g. "coin push push".
+func (def *Definition) Decoder() SymbolDecoder[string] {
+	single := make(map[rune]string, len(def.Alphabet))
+	for _, a := range def.Alphabet {
+		r, size := utf8.DecodeRuneInString(a)
+		if size == 0 || size != len(a) {
+			single = nil
+			break
+		}
+		single[r] = a
+	}
+	if single != nil {
+		return RuneDecoder(single, DefaultSeparators)
+	}
+	known := NewSet(def.Alphabet...)
+	return SymbolDecoderFunc[string](func(r *bufio.Reader) (string, error) {
+		var word strings.Builder
+		for {
+			c, _, err := r.ReadRune()
+			if errors.Is(err, io.EOF) && word.Len() > 0 {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if !unicode.IsSpace(c) {
+				word.WriteRune(c)
+			} else if word.Len() > 0 {
+				break
+			}
+		}
+		if a := word.String(); !known.Has(a) {
+			return "", fmt.Errorf("%w: %q not in the alphabet", ErrInvalidInput, a)
+		}
+		return word.String(), nil
+	})
+}
+
+// DFA builds and validates the machine with NewDFA.
+func (def *Definition) DFA() (*DFA[string, string], error) {
+	delta := make(TransitionFn[string, string], len(def.Delta))
+	for q, row := range def.Delta {
+		delta[q] = make(map[string]string, len(row))
+		for a, qNext := range row {
+			delta[q][a] = qNext
+		}
+	}
+	return NewDFA(def.States, def.Alphabet, def.Start, def.Final, delta, def.Complete)
+}
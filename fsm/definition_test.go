@@ -0,0 +1,74 @@
+package fsm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const turnstileJSON = `{
+	"states": ["locked", "unlocked"],
+	"alphabet": ["coin", "push"],
+	"start": "locked",
+	"final": ["locked"],
+	"complete": true,
+	"delta": {
+		"locked":   {"coin": "unlocked", "push": "locked"},
+		"unlocked": {"coin": "unlocked", "push": "locked"}
+	}
+}`
+
+// TestDefinition builds a machine from JSON.
+func TestDefinition(t *testing.T) {
+	def, err := ParseDefinition([]byte(turnstileJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := def.DFA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := d.Run([]string{"coin", "push", "coin"}); q != "unlocked" {
+		t.Fatalf("Run = %q, want unlocked", q)
+	}
+}
+
+// TestDefinition_Invalid rejects unknown fields and incomplete machines.
+func TestDefinition_Invalid(t *testing.T) {
+	if _, err := ParseDefinition([]byte(`{"states": [], "finals": []}`)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an unknown field, got %v", err)
+	}
+	def, _ := ParseDefinition([]byte(turnstileJSON))
+	delete(def.Delta["unlocked"], "push")
+	if _, err := def.DFA(); err == nil {
+		t.Fatal("expected an error for a missing transition")
+	}
+}
+
+// TestDefinition_File loads a definition and decodes input for it.
+func TestDefinition_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "turnstile.json")
+	if err := os.WriteFile(path, []byte(turnstileJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	def, err := LoadDefinition(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeString(def.Decoder(), " coin\tpush  push\n")
+	if err != nil || !reflect.DeepEqual(got, []string{"coin", "push", "push"}) {
+		t.Fatalf("words: got %q, %v", got, err)
+	}
+	if _, err := DecodeString(def.Decoder(), "coin kick"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown word: err = %v", err)
+	}
+	bits := &Definition{Alphabet: []string{"0", "1"}}
+	if got, err := DecodeString(bits.Decoder(), "10_1"); err != nil || !reflect.DeepEqual(got, []string{"1", "0", "1"}) {
+		t.Errorf("characters: got %q, %v", got, err)
+	}
+	if _, err := LoadDefinition(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
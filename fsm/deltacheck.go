@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// ---------- Function delta verification ----------
+
+// ErrNondeterministicDelta reports a transition function that gave
+// different answers to the same (state, symbol) query.
+var ErrNondeterministicDelta = errors.New("nondeterministic transition function")
+
+// DeltaCheck configures VerifyDelta. Zero fields take the defaults noted.
+type DeltaCheck struct {
+	Samples    int   // (state, symbol) queries to sample; default 1000
+	Repeats    int   // sequential replays of the sample, each shuffled; default 3
+	Goroutines int   // concurrent replays of the sample; default 8
+	Seed       int64 // shuffling seed
+}
+
+// DeltaConflict describes two different answers to one query.
+type DeltaConflict[Q comparable, Sigma comparable] struct {
+	Edge       Edge[Q, Sigma]
+	First      Q
+	FirstOK    bool
+	Other      Q
+	OtherOK    bool
+	Concurrent bool // the other answer came from a concurrent replay
+}
+
+func (c *DeltaConflict[Q, Sigma]) Error() string {
+	mode := "sequential"
+	if c.Concurrent {
+		mode = "concurrent"
+	}
+	return fmt.Sprintf("%v: δ(%v,%v) = (%v,%v), then (%v,%v) on a %s replay",
+		ErrNondeterministicDelta, c.Edge.From, c.Edge.On, c.First, c.FirstOK, c.Other, c.OtherOK, mode)
+}
+
+func (c *DeltaConflict[Q, Sigma]) Is(target error) bool { return target == ErrNondeterministicDelta }
+
+// VerifyDelta checks that fn behaves as a pure function before it drives a
+// run. It samples queries by exploring breadth-first from the start states
+// with fn itself, then replays the sample several times in shuffled order,
+// sequentially and from several goroutines at once, and returns a
+// *DeltaConflict for the first query whose answer changed. Run it under
+// -race to catch shared state that happens not to change the answers.
+func VerifyDelta[Q comparable, Sigma comparable](fn DeltaFunc[Q, Sigma], starts []Q, alphabet []Sigma, opts DeltaCheck) error {
+	if opts.Samples <= 0 {
+		opts.Samples = 1000
+	}
+	if opts.Repeats <= 0 {
+		opts.Repeats = 3
+	}
+	if opts.Goroutines <= 0 {
+		opts.Goroutines = 8
+	}
+
+	type answer struct {
+		next Q
+		ok   bool
+	}
+	var edges []Edge[Q, Sigma]
+	want := make(map[Edge[Q, Sigma]]answer)
+	seen := NewSet[Q]()
+	var queue []Q
+	for _, q := range starts {
+		if !seen.Has(q) {
+			seen[q] = struct{}{}
+			queue = append(queue, q)
+		}
+	}
+	for len(queue) > 0 && len(edges) < opts.Samples {
+		q := queue[0]
+		queue = queue[1:]
+		for _, a := range alphabet {
+			if len(edges) == opts.Samples {
+				break
+			}
+			e := Edge[Q, Sigma]{From: q, On: a}
+			qNext, ok := fn(q, a)
+			edges = append(edges, e)
+			want[e] = answer{qNext, ok}
+			if ok && !seen.Has(qNext) {
+				seen[qNext] = struct{}{}
+				queue = append(queue, qNext)
+			}
+		}
+	}
+
+	check := func(e Edge[Q, Sigma], concurrent bool) error {
+		qNext, ok := fn(e.From, e.On)
+		if w := want[e]; qNext != w.next || ok != w.ok {
+			return &DeltaConflict[Q, Sigma]{Edge: e, First: w.next, FirstOK: w.ok, Other: qNext, OtherOK: ok, Concurrent: concurrent}
+		}
+		return nil
+	}
+	shuffled := func(seed int64) []Edge[Q, Sigma] {
+		out := append([]Edge[Q, Sigma](nil), edges...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+		return out
+	}
+
+	for r := 0; r < opts.Repeats; r++ {
+		for _, e := range shuffled(opts.Seed + int64(r)) {
+			if err := check(e, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for g := 0; g < opts.Goroutines; g++ {
+		wg.Add(1)
+		go func(order []Edge[Q, Sigma]) {
+			defer wg.Done()
+			for _, e := range order {
+				if err := check(e, true); err != nil {
+					once.Do(func() { first = err })
+					return
+				}
+			}
+		}(shuffled(opts.Seed + int64(opts.Repeats+g)))
+	}
+	wg.Wait()
+	return first
+}
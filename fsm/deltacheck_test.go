@@ -0,0 +1,32 @@
+package fsm
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// TestVerifyDelta_Pure accepts a function of (q,a) alone.
+func TestVerifyDelta_Pure(t *testing.T) {
+	mod := func(q int, a Bit) (int, bool) { return (2*q + int(a-Zero)) % 7, true }
+	if err := VerifyDelta(mod, []int{0}, []Bit{Zero, One}, DeltaCheck{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestVerifyDelta_Impure reports a function that drifts with hidden state.
+func TestVerifyDelta_Impure(t *testing.T) {
+	var calls int64
+	drift := func(q int, a Bit) (int, bool) {
+		n := atomic.AddInt64(&calls, 1)
+		return (q + int(a-Zero) + int(n/50)) % 5, true
+	}
+	err := VerifyDelta(drift, []int{0}, []Bit{Zero, One}, DeltaCheck{Samples: 10})
+	if !errors.Is(err, ErrNondeterministicDelta) {
+		t.Fatalf("expected ErrNondeterministicDelta, got %v", err)
+	}
+	var c *DeltaConflict[int, Bit]
+	if !errors.As(err, &c) || c.First == c.Other {
+		t.Fatalf("conflict = %+v", c)
+	}
+}
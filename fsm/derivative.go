@@ -0,0 +1,118 @@
+package fsm
+
+// ---------- Brzozowski derivatives ----------
+
+// Derivative returns a machine for the derivative of d's language by word,
+// {v : word·v ∈ L(d)}: the same machine started at δ*(q0, word). The
+// second result is false when word has no run in d, i.e. the derivative is
+// empty. d is not modified; the result shares nothing with it.
+func (d *DFA[Q, Sigma]) Derivative(word []Sigma) (*DFA[Q, Sigma], bool) {
+	q, err := d.Run(word)
+	if err != nil {
+		return nil, false
+	}
+	out := d.clone()
+	out.Q0 = q
+	return out, true
+}
+
+// Derivatives describes a regular language symbolically, by Brzozowski's
+// construction: L is a representation of a language (an expression, a
+// residue, a set of pending constraints), Nullable tells whether it
+// contains the empty word, Derive computes its derivative by one symbol,
+// and Key gives a canonical form, equal for representations known to
+// denote the same language. The language is regular when finitely many
+// keys arise from Start.
+//
+// Accepts decides membership lazily, deriving only along the input; DFA
+// enumerates every reachable derivative into a table. Neither is safe for
+// concurrent use, since Accepts caches the derivatives it computes.
+type Derivatives[L any, K comparable, Sigma comparable] struct {
+	Start    L
+	Alphabet []Sigma
+	Nullable func(L) bool
+	Derive   func(L, Sigma) L
+	Key      func(L) K
+
+	langs map[K]L
+	next  map[K]map[Sigma]K
+}
+
+// start returns the key of Start, setting up the caches on first use.
+func (ds *Derivatives[L, K, Sigma]) start() K {
+	k := ds.Key(ds.Start)
+	if ds.langs == nil {
+		ds.langs = map[K]L{k: ds.Start}
+		ds.next = make(map[K]map[Sigma]K)
+	}
+	return k
+}
+
+// step returns the key of the derivative of the language with key k by a,
+// computing and caching it on first use.
+func (ds *Derivatives[L, K, Sigma]) step(k K, a Sigma) K {
+	if kNext, ok := ds.next[k][a]; ok {
+		return kNext
+	}
+	l := ds.Derive(ds.langs[k], a)
+	kNext := ds.Key(l)
+	if _, ok := ds.langs[kNext]; !ok {
+		ds.langs[kNext] = l
+	}
+	if ds.next[k] == nil {
+		ds.next[k] = make(map[Sigma]K)
+	}
+	ds.next[k][a] = kNext
+	return kNext
+}
+
+// Accepts reports whether input is in the language, deriving it symbol by
+// symbol. Symbols outside Alphabet are derived like any other.
+func (ds *Derivatives[L, K, Sigma]) Accepts(input []Sigma) bool {
+	k := ds.start()
+	for _, a := range input {
+		k = ds.step(k, a)
+	}
+	return ds.Nullable(ds.langs[k])
+}
+
+// DFA builds the automaton of all derivatives reachable from Start over
+// Alphabet, with states named by Key. It is complete and minimal when Key
+// identifies exactly the equal languages. The budget bounds the number of
+// derivatives, which is infinite for a non-regular description; progress
+// is reported in the phase "derive".
+func (ds *Derivatives[L, K, Sigma]) DFA(budget Budget) (*DFA[K, Sigma], error) {
+	tracker := budget.track()
+	start := ds.start()
+	out := &DFA[K, Sigma]{
+		Q:     NewSet(start),
+		Sigma: NewSet(ds.Alphabet...),
+		Q0:    start,
+		F:     make(Set[K]),
+		Delta: make(TransitionFn[K, Sigma]),
+	}
+	queue := []K{start}
+	for processed := 0; processed < len(queue); processed++ {
+		if err := tracker.step("derive", len(out.Q), processed, len(queue)-processed, 0); err != nil {
+			return nil, err
+		}
+		k := queue[processed]
+		if ds.Nullable(ds.langs[k]) {
+			out.F[k] = struct{}{}
+		}
+		row := make(map[Sigma]K, len(ds.Alphabet))
+		for _, a := range ds.Alphabet {
+			kNext := ds.step(k, a)
+			row[a] = kNext
+			if !out.Q.Has(kNext) {
+				out.Q[kNext] = struct{}{}
+				queue = append(queue, kNext)
+			}
+		}
+		out.Delta[k] = row
+	}
+	if err := tracker.done("derive", len(out.Q), len(queue)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDerivative(t *testing.T) {
+	d := literalDFA("abc")
+	rest, ok := d.Derivative([]rune("ab"))
+	if !ok {
+		t.Fatal("ab has a run")
+	}
+	if ok, _, _ := rest.Accepts([]rune("c")); !ok {
+		t.Error("c ∉ ab⁻¹{abc}")
+	}
+	if d.Q0 != 0 {
+		t.Error("Derivative modified its receiver")
+	}
+	if _, ok := d.Derivative([]rune("x")); ok {
+		t.Error("x⁻¹{abc} reported non-empty")
+	}
+}
+
+// residues describes binary numbers ≡ 0 (mod 3) by their residue.
+func residues() *Derivatives[int, int, Bit] {
+	return &Derivatives[int, int, Bit]{
+		Start:    0,
+		Alphabet: []Bit{Zero, One},
+		Nullable: func(r int) bool { return r == 0 },
+		Derive:   func(r int, b Bit) int { return (2*r + int(b-Zero)) % 3 },
+		Key:      func(r int) int { return r },
+	}
+}
+
+func TestDerivativesDFA(t *testing.T) {
+	lazy := residues()
+	if !lazy.Accepts(nil) || !lazy.Accepts([]Bit("110")) || lazy.Accepts([]Bit("111")) {
+		t.Error("lazy membership wrong")
+	}
+	d, err := residues().DFA(Budget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	if ok, w, _ := Equivalent(d, div3); !ok || len(d.Q) != 3 {
+		t.Errorf("%d states, differs on %q", len(d.Q), string(w))
+	}
+
+	// Equal numbers of a and b: not regular, so the budget stops it.
+	balance := &Derivatives[int, int, rune]{
+		Alphabet: []rune{'a', 'b'},
+		Nullable: func(n int) bool { return n == 0 },
+		Derive: func(n int, a rune) int {
+			if a == 'a' {
+				return n + 1
+			}
+			return n - 1
+		},
+		Key: func(n int) int { return n },
+	}
+	if !balance.Accepts([]rune("abba")) {
+		t.Error("abba is balanced")
+	}
+	if _, err := balance.DFA(Budget{MaxStates: 50}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("err = %v, want ErrLimitExceeded", err)
+	}
+}
@@ -0,0 +1,111 @@
+package fsm
+
+import "fmt"
+
+// ---------- Structural equality ----------
+
+// Equal reports whether d and other are the same machine: equal Q, Σ, q0
+// and F, and the same defined transitions and defaults. It compares
+// structure, not languages, and does not depend on map iteration order. A
+// missing δ row and an empty one are considered equal.
+func (d *DFA[Q, Sigma]) Equal(other *DFA[Q, Sigma]) bool {
+	if d == other {
+		return true
+	}
+	if d == nil || other == nil {
+		return false
+	}
+	if d.Q0 != other.Q0 || !setsEqual(d.Q, other.Q) || !setsEqual(d.Sigma, other.Sigma) || !setsEqual(d.F, other.F) {
+		return false
+	}
+	return transitionsIn(d, other) && transitionsIn(other, d)
+}
+
+func setsEqual[T comparable](a, b Set[T]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for x := range a {
+		if !b.Has(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// transitionsIn reports whether every transition and default of a is also
+// in b.
+func transitionsIn[Q comparable, Sigma comparable](a, b *DFA[Q, Sigma]) bool {
+	for q, row := range a.Delta {
+		for s, qNext := range row {
+			if got, ok := b.Delta[q][s]; !ok || got != qNext {
+				return false
+			}
+		}
+	}
+	for q, qNext := range a.Default {
+		if got, ok := b.Default[q]; !ok || got != qNext {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------- Language equivalence and inclusion ----------
+
+// Equivalent reports whether a and b accept the same inputs, however their
+// states are built. When they differ it returns a shortest distinguishing
+// word, the first in breadth-first order over the sorted symbols, which
+// exactly one of them accepts. The alphabets must match; Harmonize them
+// first otherwise.
+func Equivalent[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) (bool, []Sigma, error) {
+	if err := sameAlphabet(a, b); err != nil {
+		return false, nil, err
+	}
+	if w, ok := shortestAccepted(SymmetricDifference(a, b)); ok {
+		return false, w, nil
+	}
+	return true, nil, nil
+}
+
+// Subset reports whether every input a accepts is also accepted by b, e.g.
+// that a stricter validator really is stricter. Otherwise it returns a
+// shortest witness accepted by a and rejected by b. The alphabets must
+// match, as for Equivalent.
+func Subset[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) (bool, []Sigma, error) {
+	if err := sameAlphabet(a, b); err != nil {
+		return false, nil, err
+	}
+	if w, ok := shortestAccepted(Difference(a, b)); ok {
+		return false, w, nil
+	}
+	return true, nil, nil
+}
+
+// IsEmpty reports whether d accepts no input at all. Otherwise it returns
+// a shortest accepted word, e.g. to show that the constraints combined by
+// Intersect are jointly satisfiable.
+func (d *DFA[Q, Sigma]) IsEmpty() (bool, []Sigma) {
+	w, ok := shortestAccepted(d)
+	return !ok, w
+}
+
+func sameAlphabet[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) error {
+	if !setsEqual(a.Sigma, b.Sigma) {
+		return fmt.Errorf("%w: alphabets differ (%d and %d symbols)", ErrInvalidInput, len(a.Sigma), len(b.Sigma))
+	}
+	return nil
+}
+
+// shortestAccepted returns a shortest word d accepts, the first in
+// breadth-first order over the sorted symbols, or false if there is none.
+func shortestAccepted[Q comparable, Sigma comparable](d *DFA[Q, Sigma]) ([]Sigma, bool) {
+	symbols := d.Sigma.sorted()
+	reachable := d.reachable()
+	for _, q := range d.bfsOrder(symbols)[:len(reachable)] {
+		if d.F.Has(q) {
+			return d.shortestPaths(d.Q0, symbols, reachable)[q], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,83 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestEqual compares machines component by component.
+func TestEqual(t *testing.T) {
+	a, b := buildModThree(), buildModThree()
+	if !a.Equal(b) {
+		t.Fatal("identical machines should be equal")
+	}
+
+	b.Delta[S1][Zero] = S1
+	if a.Equal(b) {
+		t.Fatal("different δ should not be equal")
+	}
+
+	b = buildModThree()
+	delete(b.F, S2)
+	if a.Equal(b) {
+		t.Fatal("different F should not be equal")
+	}
+
+	// Empty vs missing rows are the same partial δ.
+	c := literalDFA("a")
+	d := literalDFA("a")
+	d.Delta[1] = map[rune]int{}
+	if !c.Equal(d) {
+		t.Fatal("empty row should equal missing row")
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	d := buildModThree()
+	if ok, w, err := Equivalent(d, d.Minimize()); !ok || w != nil || err != nil {
+		t.Fatalf("machine vs its minimization: %v %v %v", ok, w, err)
+	}
+	div3, _ := d.PruneToFinals(NewSet(S0))
+	ok, w, err := Equivalent(div3, d)
+	if ok || err != nil || string(w) != "1" {
+		t.Fatalf("got %v %q %v, want counterexample \"1\"", ok, string(w), err)
+	}
+	// Equal languages, different partiality.
+	partial := Must(NewDFA([]int{0}, []Bit{Zero, One}, 0, nil, TransitionFn[int, Bit]{}, false))
+	empty := Must(NewDFA([]int{0}, []Bit{Zero, One}, 0, nil,
+		TransitionFn[int, Bit]{0: {Zero: 0, One: 0}}, true))
+	if ok, _, _ := Equivalent(partial, empty); !ok {
+		t.Error("two empty languages differ")
+	}
+	if _, _, err := Equivalent(literalDFA("a"), literalDFA("b")); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("alphabet mismatch: err = %v", err)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	d := buildModThree()
+	div3, _ := d.PruneToFinals(NewSet(S0))
+	if ok, w, err := Subset(div3, d); !ok || w != nil || err != nil {
+		t.Fatalf("multiples of three ⊆ all: %v %v %v", ok, w, err)
+	}
+	ok, w, err := Subset(d, div3)
+	if ok || err != nil || string(w) != "1" {
+		t.Fatalf("got %v %q %v, want witness \"1\"", ok, string(w), err)
+	}
+	if acc, _, _ := d.Accepts(w); !acc {
+		t.Error("witness not accepted by a")
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	ends1 := Must(NewDFA([]bool{false, true}, []Bit{Zero, One}, false, []bool{true},
+		TransitionFn[bool, Bit]{false: {Zero: false, One: true}, true: {Zero: false, One: true}}, true))
+	empty, w := Intersect(div3, ends1).IsEmpty()
+	if empty || string(w) != "11" {
+		t.Fatalf("multiples of three ending in 1: %v %q, want witness \"11\"", empty, string(w))
+	}
+	if empty, w := Intersect(ends1, endsInZero()).IsEmpty(); !empty || w != nil {
+		t.Errorf("ends in 1 and in 0: %v %q", empty, string(w))
+	}
+}
@@ -0,0 +1,143 @@
+package fsm
+
+import "fmt"
+
+// ---------- Typed errors ----------
+
+// The errors below let callers branch on what went wrong with errors.As
+// instead of matching messages:
+//
+//	var nt *fsm.ErrNoTransition[State, Bit]
+//	if errors.As(err, &nt) {
+//		log.Printf("stuck in %v on %v", nt.State, nt.Symbol)
+//	}
+//
+// Construction errors also match ErrInvalidInput, and ErrNoTransition
+// matches ErrUndefined, via errors.Is.
+
+// ErrUnknownState reports a state that is used but not in Q. Kind is
+// StartNotInQ, FinalNotInQ, or UnknownState for any other use, such as a δ
+// row, a default or a rule.
+type ErrUnknownState[Q comparable] struct {
+	Kind  ProblemKind
+	State Q
+}
+
+func (e *ErrUnknownState[Q]) Error() string {
+	switch e.Kind {
+	case StartNotInQ:
+		return fmt.Sprintf("q0 %v not in Q", e.State)
+	case FinalNotInQ:
+		return fmt.Sprintf("final %v not in Q", e.State)
+	}
+	return fmt.Sprintf("state %v not in Q", e.State)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true.
+func (e *ErrUnknownState[Q]) Is(target error) bool { return target == ErrInvalidInput }
+
+// ErrUnknownSymbol reports a δ entry of State on a Symbol not in Σ.
+type ErrUnknownSymbol[Q comparable, Sigma comparable] struct {
+	State  Q
+	Symbol Sigma
+}
+
+func (e *ErrUnknownSymbol[Q, Sigma]) Error() string {
+	return fmt.Sprintf("delta row %v has symbol %v not in Σ", e.State, e.Symbol)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true.
+func (e *ErrUnknownSymbol[Q, Sigma]) Is(target error) bool { return target == ErrInvalidInput }
+
+// ErrUnknownTarget reports a transition δ(State,Symbol) leading to a Target
+// not in Q. If Default or Epsilon is set, the edge is the default or an
+// ε-move of State, and Symbol is the zero value.
+type ErrUnknownTarget[Q comparable, Sigma comparable] struct {
+	State   Q
+	Symbol  Sigma
+	Target  Q
+	Default bool
+	Epsilon bool
+}
+
+func (e *ErrUnknownTarget[Q, Sigma]) Error() string {
+	if e.Default {
+		return fmt.Sprintf("default %v → %v not in Q", e.State, e.Target)
+	}
+	if e.Epsilon {
+		return fmt.Sprintf("epsilon(%v) → %v not in Q", e.State, e.Target)
+	}
+	return fmt.Sprintf("delta(%v,%v) → %v not in Q", e.State, e.Symbol, e.Target)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true.
+func (e *ErrUnknownTarget[Q, Sigma]) Is(target error) bool { return target == ErrInvalidInput }
+
+// ErrIncompleteDelta reports a missing transition δ(State,Symbol) when a
+// complete machine was required. NoRow is set if State has no row at all;
+// Symbol is then the zero value.
+type ErrIncompleteDelta[Q comparable, Sigma comparable] struct {
+	State  Q
+	Symbol Sigma
+	NoRow  bool
+}
+
+func (e *ErrIncompleteDelta[Q, Sigma]) Error() string {
+	if e.NoRow {
+		return fmt.Sprintf("delta missing row for state %v", e.State)
+	}
+	return fmt.Sprintf("delta missing (%v,%v)", e.State, e.Symbol)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true.
+func (e *ErrIncompleteDelta[Q, Sigma]) Is(target error) bool { return target == ErrInvalidInput }
+
+// ErrOptionType reports an option value of the wrong type for the machine,
+// e.g. an AutoComplete sink that is not a state.
+type ErrOptionType struct {
+	Option string
+	Value  any
+	Want   string // the expected type, as printed by %T
+}
+
+func (e *ErrOptionType) Error() string {
+	return fmt.Sprintf("%s value %v is a %T, not a %s", e.Option, e.Value, e.Value, e.Want)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true.
+func (e *ErrOptionType) Is(target error) bool { return target == ErrInvalidInput }
+
+// ErrNoTransition is returned by Step when δ(State,Symbol) is undefined.
+// NoRow is set if State has no transitions at all.
+type ErrNoTransition[Q any, Sigma any] struct {
+	State  Q
+	Symbol Sigma
+	NoRow  bool
+}
+
+func (e *ErrNoTransition[Q, Sigma]) Error() string {
+	if e.NoRow {
+		return fmt.Sprintf("no row for state %v", e.State)
+	}
+	return fmt.Sprintf("no transition for (%v,%v)", e.State, e.Symbol)
+}
+
+// Is makes errors.Is(err, ErrUndefined) true.
+func (e *ErrNoTransition[Q, Sigma]) Is(target error) bool { return target == ErrUndefined }
+
+// RunError is returned by Run when the input cannot be consumed: Symbol,
+// at zero-based Index, failed in State, the state reached so far. Err is
+// the underlying error, usually an *ErrNoTransition, and is unwrapped by
+// errors.Is and errors.As.
+type RunError[Q any, Sigma any] struct {
+	Index  int
+	Symbol Sigma
+	State  Q
+	Err    error
+}
+
+func (e *RunError[Q, Sigma]) Error() string {
+	return fmt.Sprintf("symbol %d: %v", e.Index, e.Err)
+}
+
+func (e *RunError[Q, Sigma]) Unwrap() error { return e.Err }
@@ -0,0 +1,146 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTypedErrors branches on construction and run errors with errors.As.
+func TestTypedErrors(t *testing.T) {
+	_, err := NewDFA([]State{S0}, []Bit{Zero}, S1, nil, nil, false)
+	var us *ErrUnknownState[State]
+	if !errors.As(err, &us) || us.Kind != StartNotInQ || us.State != S1 || !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("bad q0: got %v", err)
+	}
+
+	_, err = NewDFA([]State{S0}, []Bit{Zero}, S0, nil, TransitionFn[State, Bit]{S0: {Zero: S2}}, false)
+	var ut *ErrUnknownTarget[State, Bit]
+	if !errors.As(err, &ut) || ut.Target != S2 || ut.Symbol != Zero || err.Error() != "delta(0,48) → 2 not in Q" {
+		t.Errorf("bad target: got %v", err)
+	}
+
+	_, err = NewDFA([]State{S0}, []Bit{Zero, One}, S0, nil, TransitionFn[State, Bit]{S0: {Zero: S0}}, true)
+	var inc *ErrIncompleteDelta[State, Bit]
+	if !errors.As(err, &inc) || inc.State != S0 || inc.Symbol != One || inc.NoRow {
+		t.Errorf("incomplete: got %v", err)
+	}
+
+	d := buildModThree()
+	delete(d.Delta[S1], One)
+	_, err = d.Run([]Bit{One, One})
+	var nt *ErrNoTransition[State, Bit]
+	if !errors.As(err, &nt) || nt.State != S1 || nt.Symbol != One || nt.NoRow || !errors.Is(err, ErrUndefined) {
+		t.Errorf("run: got %v", err)
+	}
+	if errors.Is(err, ErrInvalidInput) {
+		t.Error("an undefined transition is not invalid input")
+	}
+	if _, err := d.Step(S2+1, Zero); !errors.As(err, &nt) || !nt.NoRow {
+		t.Errorf("no row: got %v", err)
+	}
+}
+
+// TestTypedErrors_Constructors checks the other constructors report the
+// same typed errors as NewDFA.
+func TestTypedErrors_Constructors(t *testing.T) {
+	states := []int{0, 1}
+	_, err := NewNFA(states, []rune("a"), 0, []int{2}, nil)
+	var us *ErrUnknownState[int]
+	if !errors.As(err, &us) || us.Kind != FinalNotInQ || us.State != 2 {
+		t.Errorf("NFA final: got %v", err)
+	}
+	_, err = NewNFA(states, []rune("a"), 0, nil, NFATransitionFn[int, rune]{0: {'b': NewSet(1)}})
+	var usym *ErrUnknownSymbol[int, rune]
+	if !errors.As(err, &usym) || usym.Symbol != 'b' {
+		t.Errorf("NFA symbol: got %v", err)
+	}
+	_, err = NewEpsilonNFA(states, []rune("a"), 0, nil, nil, map[int]Set[int]{0: NewSet(5)})
+	var ut *ErrUnknownTarget[int, rune]
+	if !errors.As(err, &ut) || !ut.Epsilon || ut.Target != 5 || err.Error() != "epsilon(0) → 5 not in Q" {
+		t.Errorf("ε-NFA target: got %v", err)
+	}
+
+	f := buildModThree().Freeze()
+	if _, err := f.WithTransition(S0, Zero, S2+1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("WithTransition target: got %v", err)
+	}
+	var fsym *ErrUnknownSymbol[State, Bit]
+	if _, err := f.WithTransition(S0, 'x', S1); !errors.As(err, &fsym) || fsym.Symbol != 'x' {
+		t.Errorf("WithTransition symbol: got %v", err)
+	}
+	var fs *ErrUnknownState[State]
+	if _, err := f.WithFinal(S2+1, true); !errors.As(err, &fs) || fs.Kind != FinalNotInQ {
+		t.Errorf("WithFinal: got %v", err)
+	}
+
+	_, err = NewMultiTape(1, 0, states, 0, nil, []TapeRule[int, rune]{{From: 0, On: []rune{'a'}, To: 3}})
+	if !errors.As(err, &us) || us.State != 3 {
+		t.Errorf("MultiTape rule: got %v", err)
+	}
+
+	_, err = NewIntervalDFA(states, Range[int]{0, 9}, 0, nil, []IntervalRule[int, int]{{From: 0, On: Range[int]{0, 4}, To: 7}}, false)
+	var it *ErrUnknownTarget[int, int]
+	if !errors.As(err, &it) || it.Target != 7 {
+		t.Errorf("IntervalDFA target: got %v", err)
+	}
+	_, err = NewIntervalDFA(states, Range[int]{0, 9}, 0, nil, []IntervalRule[int, int]{
+		{From: 0, On: Range[int]{0, 4}, To: 1}, {From: 1, On: Range[int]{0, 9}, To: 1},
+	}, true)
+	var inc *ErrIncompleteDelta[int, int]
+	if !errors.As(err, &inc) || inc.State != 0 || inc.Symbol != 5 {
+		t.Errorf("IntervalDFA incomplete: got %v", err)
+	}
+
+	if err := NewRunner(buildModThree(), 0).Resume(S2+1, 0); !errors.As(err, &fs) || fs.Kind != UnknownState {
+		t.Errorf("Resume: got %v", err)
+	}
+}
+
+// TestRunError locates the failing symbol of a long input.
+func TestRunError(t *testing.T) {
+	d := buildModThree()
+	delete(d.Delta[S2], Zero)
+	input := []Bit{Zero, One, Zero, Zero, One} // 0 → 0 → 1 → 2, then δ(2,0) is undefined
+	q, err := d.Run(input)
+	var re *RunError[State, Bit]
+	if !errors.As(err, &re) || re.Index != 3 || re.Symbol != Zero || re.State != S2 || q != S2 {
+		t.Fatalf("got %v, %v", q, err)
+	}
+	if err.Error() != "symbol 3: no transition for (2,48)" || !errors.Is(err, ErrUndefined) {
+		t.Fatalf("message = %q", err.Error())
+	}
+	if _, _, err := d.Accepts(input); !errors.As(err, &re) || re.Index != 3 {
+		t.Fatalf("Accepts: got %v", err)
+	}
+	if _, err := d.RunReader(strings.NewReader("0100"), BitDecoder(Zero, One)); !errors.As(err, &re) || re.Index != 3 {
+		t.Fatalf("RunReader: got %v", err)
+	}
+}
+
+// TestRunError_OtherRunners reports failures of RunDebug and HashDFA as
+// Run does.
+func TestRunError_OtherRunners(t *testing.T) {
+	d := buildModThree()
+	delete(d.Delta[S2], Zero)
+	_, err := d.RunDebug([]Bit{One, Zero, Zero}, Breakpoints[State, Bit]{}, nil)
+	var re *RunError[State, Bit]
+	if !errors.As(err, &re) || re.Index != 2 || re.State != S2 || !errors.Is(err, ErrUndefined) {
+		t.Fatalf("RunDebug: got %v", err)
+	}
+
+	m := Must(NewHashDFA(sliceHasher, [][]int{{0}, {1}}, []Bit{Zero, One}, []int{0}, nil,
+		[]Transition[[]int, Bit]{{From: []int{0}, On: One, To: []int{1}}}, false))
+	_, err = m.Run([]Bit{One, One})
+	var hre *RunError[[]int, Bit]
+	var nt *ErrNoTransition[[]int, Bit]
+	if !errors.As(err, &hre) || hre.Index != 1 || hre.State[0] != 1 || !errors.As(err, &nt) {
+		t.Fatalf("HashDFA.Run: got %v", err)
+	}
+	if _, _, err := m.Accepts([]Bit{Zero}); !errors.As(err, &hre) || hre.Index != 0 {
+		t.Fatalf("HashDFA.Accepts: got %v", err)
+	}
+	if _, err := m.Step([]int{7}, Zero); !errors.As(err, &nt) || !nt.NoRow {
+		t.Fatalf("HashDFA.Step: got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------- Rejection explanations ----------
+
+// EditOp is the kind of one Edit.
+type EditOp int
+
+const (
+	EditInsert EditOp = iota
+	EditDelete
+	EditSubstitute
+)
+
+func (o EditOp) String() string {
+	switch o {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditSubstitute:
+		return "substitute"
+	}
+	return fmt.Sprintf("EditOp(%d)", int(o))
+}
+
+// Edit is one step of a repair. Pos is an index into the original input:
+// an insertion goes before input[Pos] (Pos == len(input) appends), a
+// deletion or substitution replaces input[Pos]. Symbol is the inserted or
+// substituted symbol.
+type Edit[Sigma comparable] struct {
+	Op     EditOp
+	Pos    int
+	Symbol Sigma
+}
+
+// Rejection explains why a DFA rejected an input.
+type Rejection[Q comparable, Sigma comparable] struct {
+	// Pos is where the run left every path to acceptance: the index of the
+	// symbol that had no transition or led to a state from which F is
+	// unreachable, or len(input) if the input merely ended too early.
+	Pos int
+	// State is the state reached before input[Pos].
+	State Q
+	// Edits is a smallest repair, in input order, and Repaired the
+	// accepted input it produces. Both are nil when no input is accepted.
+	Edits    []Edit[Sigma]
+	Repaired []Sigma
+}
+
+// Error describes the rejection for end users.
+func (r *Rejection[Q, Sigma]) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "input rejected at position %d in state %v", r.Pos, r.State)
+	if r.Edits != nil {
+		parts := make([]string, len(r.Edits))
+		for i, e := range r.Edits {
+			if e.Op == EditDelete {
+				parts[i] = fmt.Sprintf("delete at %d", e.Pos)
+			} else {
+				parts[i] = fmt.Sprintf("%v %v at %d", e.Op, e.Symbol, e.Pos)
+			}
+		}
+		fmt.Fprintf(&b, "; nearest fix: %s", strings.Join(parts, ", "))
+	}
+	return b.String()
+}
+
+// editNode is a position in the input paired with a state of the machine:
+// the product of the input with the edit transducer.
+type editNode[Q comparable] struct {
+	pos int
+	q   Q
+}
+
+type editStep[Q comparable, Sigma comparable] struct {
+	from editNode[Q]
+	edit *Edit[Sigma] // nil for a match
+}
+
+// ExplainReject returns nil if d accepts input. Otherwise it reports where
+// the run went wrong and a repair with the fewest insertions, deletions and
+// substitutions (Levenshtein distance) that d accepts; ties are broken
+// towards earlier edits and sorted symbols. The search visits each pair of
+// input position and useful state once, in O(len(input)·|δ|) time.
+func (d *DFA[Q, Sigma]) ExplainReject(input []Sigma) *Rejection[Q, Sigma] {
+	co := d.coreachable()
+	r := &Rejection[Q, Sigma]{Pos: len(input), State: d.Q0}
+	q := d.Q0
+	for i, a := range input {
+		qNext, ok := d.next(q, a)
+		if !ok || !co.Has(qNext) {
+			r.Pos, r.State = i, q
+			break
+		}
+		q, r.State = qNext, qNext
+	}
+	if r.Pos == len(input) && d.F.Has(q) {
+		return nil
+	}
+	if !co.Has(d.Q0) {
+		return r
+	}
+
+	// Uniform-cost search by levels: matches cost 0 and stay in the level,
+	// edits cost 1 and go to the next one.
+	symbols := d.Sigma.sorted()
+	start := editNode[Q]{0, d.Q0}
+	parent := map[editNode[Q]]editStep[Q, Sigma]{}
+	done := make(map[editNode[Q]]bool)
+	level := []editNode[Q]{start}
+	dist := map[editNode[Q]]int{start: 0}
+	var goal *editNode[Q]
+	for c := 0; len(level) > 0 && goal == nil; c++ {
+		var next []editNode[Q]
+		visit := func(from, to editNode[Q], e *Edit[Sigma], cost int) {
+			if old, ok := dist[to]; (ok && old <= c+cost) || !co.Has(to.q) {
+				return
+			}
+			dist[to] = c + cost
+			parent[to] = editStep[Q, Sigma]{from, e}
+			if cost == 0 {
+				level = append(level, to)
+			} else {
+				next = append(next, to)
+			}
+		}
+		for k := 0; k < len(level); k++ {
+			n := level[k]
+			if done[n] {
+				continue
+			}
+			done[n] = true
+			if n.pos == len(input) && d.F.Has(n.q) {
+				goal = &n
+				break
+			}
+			if n.pos < len(input) {
+				a := input[n.pos]
+				if qNext, ok := d.next(n.q, a); ok {
+					visit(n, editNode[Q]{n.pos + 1, qNext}, nil, 0)
+				}
+				visit(n, editNode[Q]{n.pos + 1, n.q}, &Edit[Sigma]{Op: EditDelete, Pos: n.pos, Symbol: a}, 1)
+				for _, b := range symbols {
+					if qNext, ok := d.next(n.q, b); ok && b != a {
+						visit(n, editNode[Q]{n.pos + 1, qNext}, &Edit[Sigma]{Op: EditSubstitute, Pos: n.pos, Symbol: b}, 1)
+					}
+				}
+			}
+			for _, b := range symbols {
+				if qNext, ok := d.next(n.q, b); ok {
+					visit(n, editNode[Q]{n.pos, qNext}, &Edit[Sigma]{Op: EditInsert, Pos: n.pos, Symbol: b}, 1)
+				}
+			}
+		}
+		level = next
+	}
+	if goal == nil {
+		return r
+	}
+
+	// Walk back from the goal, then replay forwards to build the repair.
+	var steps []editStep[Q, Sigma]
+	for n := *goal; n != start; n = parent[n].from {
+		steps = append(steps, parent[n])
+	}
+	r.Edits = []Edit[Sigma]{}
+	r.Repaired = []Sigma{}
+	for i := len(steps) - 1; i >= 0; i-- {
+		st := steps[i]
+		switch {
+		case st.edit == nil:
+			r.Repaired = append(r.Repaired, input[st.from.pos])
+		case st.edit.Op == EditDelete:
+			r.Edits = append(r.Edits, *st.edit)
+		default:
+			r.Edits = append(r.Edits, *st.edit)
+			r.Repaired = append(r.Repaired, st.edit.Symbol)
+		}
+	}
+	return r
+}
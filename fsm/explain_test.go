@@ -0,0 +1,56 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainReject(t *testing.T) {
+	d := literalDFA("abc")
+	if r := d.ExplainReject([]rune("abc")); r != nil {
+		t.Fatalf("accepted input explained: %v", r)
+	}
+	cases := []struct {
+		input string
+		pos   int
+		edits []Edit[rune]
+	}{
+		{"abxc", 2, []Edit[rune]{{EditDelete, 2, 'x'}}},
+		{"ac", 1, []Edit[rune]{{EditInsert, 1, 'b'}}},
+		{"abd", 2, []Edit[rune]{{EditSubstitute, 2, 'c'}}},
+		{"ab", 2, []Edit[rune]{{EditInsert, 2, 'c'}}},
+		{"", 0, []Edit[rune]{{EditInsert, 0, 'a'}, {EditInsert, 0, 'b'}, {EditInsert, 0, 'c'}}},
+	}
+	for _, c := range cases {
+		r := d.ExplainReject([]rune(c.input))
+		if r == nil {
+			t.Fatalf("%q: not rejected", c.input)
+		}
+		if r.Pos != c.pos || !reflect.DeepEqual(r.Edits, c.edits) || string(r.Repaired) != "abc" {
+			t.Errorf("%q: pos %d, edits %v, repaired %q; want pos %d, edits %v", c.input, r.Pos, r.Edits, string(r.Repaired), c.pos, c.edits)
+		}
+	}
+}
+
+// TestExplainRejectDistance checks that repairs are minimal: for multiples
+// of three, one edit always suffices.
+func TestExplainRejectDistance(t *testing.T) {
+	d, _ := buildModThree().PruneToFinals(NewSet(S0))
+	r := d.ExplainReject([]Bit("1"))
+	if len(r.Edits) != 1 {
+		t.Fatalf("1: %v", r)
+	}
+	if ok, _, _ := d.Accepts(r.Repaired); !ok {
+		t.Errorf("repair %q rejected", string(r.Repaired))
+	}
+	for _, w := range allWords([]Bit{Zero, One}, 6) {
+		if r := d.ExplainReject(w); r != nil && len(r.Edits) != 1 {
+			t.Errorf("%s: %d edits, want 1", string(w), len(r.Edits))
+		}
+	}
+
+	empty := Must(NewDFA([]int{0}, []Bit{Zero, One}, 0, nil, TransitionFn[int, Bit]{}, false))
+	if r := empty.ExplainReject([]Bit("01")); r == nil || r.Edits != nil || r.Pos != 0 {
+		t.Errorf("empty language: %+v", r)
+	}
+}
@@ -0,0 +1,95 @@
+package fsm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ---------- C export ----------
+
+// WriteC emits a self-contained C header for embedded targets: the dense
+// transition table as const data plus static inline reference functions.
+// All identifiers are prefixed with prefix (e.g. "modthree" gives
+// modthree_step, MODTHREE_NUM_STATES). The table uses the smallest signed
+// integer type that fits the state count; -1 marks undefined transitions.
+func (t *Table[Q, Sigma]) WriteC(w io.Writer, prefix string) error {
+	lower := strings.ToLower(prefix)
+	upper := strings.ToUpper(prefix)
+	cell := "int32_t"
+	switch {
+	case len(t.States) <= 127:
+		cell = "int8_t"
+	case len(t.States) <= 32767:
+		cell = "int16_t"
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "/* Code generated by fsm; DO NOT EDIT.\n * Source fingerprint: %s\n *\n", t.Fingerprint)
+	fmt.Fprintf(b, " * States:\n")
+	for i, q := range t.States {
+		fmt.Fprintf(b, " *   %d = %v\n", i, q)
+	}
+	fmt.Fprintf(b, " * Symbols:\n")
+	for i, a := range t.Symbols {
+		fmt.Fprintf(b, " *   %d = %v\n", i, a)
+	}
+	fmt.Fprintf(b, " */\n\n#ifndef %s_FSM_H\n#define %s_FSM_H\n\n", upper, upper)
+	fmt.Fprintf(b, "#include <stdbool.h>\n#include <stdint.h>\n\n")
+	fmt.Fprintf(b, "#define %s_START 0\n#define %s_NUM_STATES %d\n#define %s_NUM_SYMBOLS %d\n\n",
+		upper, upper, len(t.States), upper, len(t.Symbols))
+
+	fmt.Fprintf(b, "static const bool %s_final[%s_NUM_STATES] = {", lower, upper)
+	for i, f := range t.Final {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if f {
+			b.WriteString("1")
+		} else {
+			b.WriteString("0")
+		}
+	}
+	b.WriteString("};\n\n")
+
+	fmt.Fprintf(b, "static const %s %s_next[%s_NUM_STATES][%s_NUM_SYMBOLS] = {\n", cell, lower, upper, upper)
+	n := len(t.Symbols)
+	for i := range t.States {
+		b.WriteString("    {")
+		for j := 0; j < n; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(b, "%d", t.At(int32(i), int32(j)))
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("};\n\n")
+
+	fmt.Fprintf(b, `/* Returns the state reached from state on symbol, or -1. */
+static inline int32_t %[1]s_step(int32_t state, int32_t symbol) {
+    if (state < 0 || state >= %[2]s_NUM_STATES || symbol < 0 || symbol >= %[2]s_NUM_SYMBOLS) {
+        return -1;
+    }
+    return %[1]s_next[state][symbol];
+}
+
+/* Returns true if state is accepting. */
+static inline bool %[1]s_is_final(int32_t state) {
+    return state >= 0 && state < %[2]s_NUM_STATES && %[1]s_final[state];
+}
+
+/* Runs n symbol numbers from the start state; returns the final state or -1. */
+static inline int32_t %[1]s_run(const int32_t *symbols, uint32_t n) {
+    int32_t state = %[2]s_START;
+    for (uint32_t i = 0; i < n && state >= 0; i++) {
+        state = %[1]s_step(state, symbols[i]);
+    }
+    return state;
+}
+
+#endif /* %[2]s_FSM_H */
+`, lower, upper)
+	return b.Flush()
+}
@@ -0,0 +1,179 @@
+package fsm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---------- Go / TinyGo export ----------
+
+// WriteGo emits a self-contained Go source file for package pkg containing
+// the compiled table and a tiny matcher with no dependencies, suitable for
+// building with TinyGo to WebAssembly (the entry points carry //export
+// directives) or embedding in other Go programs.
+//
+// Every identifier starts with prefix, as in WriteC, so several machines
+// can be generated into one package: "Turnstile" gives TurnstileStep and
+// the export turnstile_step, while "" gives Step and step. The package doc
+// comment is emitted only if packageDoc is set, so that generated files do
+// not clobber the one the package already has.
+//
+// The generated API works on state and symbol numbers (shown unprefixed):
+//
+//	Start, NumStates, NumSymbols
+//	Step(state, symbol int32) int32 // -1 when undefined
+//	IsFinal(state int32) bool
+//	Symbol(value int32) int32       // only for integer-valued alphabets
+//	Symbol(name string) int32       // only for string-valued alphabets
+func (t *Table[Q, Sigma]) WriteGo(w io.Writer, pkg, prefix string, packageDoc bool) error {
+	// Unexported names start with the prefix in lower case, and exports
+	// are snake case, as in WriteC.
+	unexported := prefix
+	if r, size := utf8.DecodeRuneInString(prefix); size > 0 {
+		unexported = string(unicode.ToLower(r)) + prefix[size:]
+	}
+	final, next := unexported+"Final", unexported+"Next"
+	if prefix == "" {
+		final, next = "final", "next"
+	}
+	export := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return strings.ToLower(prefix) + "_" + name
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by fsm; DO NOT EDIT.\n\n")
+	if packageDoc {
+		fmt.Fprintf(&b, "// Package %s is a compiled finite state machine.\n", pkg)
+		fmt.Fprintf(&b, "// Source fingerprint: %s\n", t.Fingerprint)
+		fmt.Fprintf(&b, "package %s\n\n", pkg)
+	} else {
+		fmt.Fprintf(&b, "package %s\n\n", pkg)
+		fmt.Fprintf(&b, "// Source fingerprint: %s\n", t.Fingerprint)
+		b.WriteString("//\n")
+	}
+
+	fmt.Fprintf(&b, "// States:\n")
+	for i, q := range t.States {
+		fmt.Fprintf(&b, "//\t%d = %v\n", i, q)
+	}
+	fmt.Fprintf(&b, "//\n// Symbols:\n")
+	for i, a := range t.Symbols {
+		fmt.Fprintf(&b, "//\t%d = %v\n", i, a)
+	}
+	fmt.Fprintf(&b, "const (\n\t%[1]sStart = 0\n\t%[1]sNumStates = %[2]d\n\t%[1]sNumSymbols = %[3]d\n)\n\n", prefix, len(t.States), len(t.Symbols))
+
+	fmt.Fprintf(&b, "var %s = [%sNumStates]bool{", final, prefix)
+	for i, f := range t.Final {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%v", f)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "var %[1]s = [%[2]sNumStates * %[2]sNumSymbols]int32{\n", next, prefix)
+	n := len(t.Symbols)
+	for i := range t.States {
+		b.WriteString("\t")
+		for j := 0; j < n; j++ {
+			fmt.Fprintf(&b, "%d, ", t.At(int32(i), int32(j)))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, `// %[1]sStep returns the state reached from state on symbol, or -1.
+//
+//export %[2]s
+func %[1]sStep(state, symbol int32) int32 {
+	if state < 0 || state >= %[1]sNumStates || symbol < 0 || symbol >= %[1]sNumSymbols {
+		return -1
+	}
+	return %[3]s[state*%[1]sNumSymbols+symbol]
+}
+
+// %[1]sIsFinal reports whether state is accepting.
+//
+//export %[4]s
+func %[1]sIsFinal(state int32) bool {
+	return state >= 0 && state < %[1]sNumStates && %[5]s[state]
+}
+`, prefix, export("step"), next, export("is_final"), final)
+
+	if values, ok := integerSymbols(t.Symbols); ok {
+		fmt.Fprintf(&b, `
+// %[1]sSymbol maps a symbol value to its number, or -1 if it is not in the alphabet.
+//
+//export %[2]s
+func %[1]sSymbol(value int32) int32 {
+	switch value {
+`, prefix, export("symbol"))
+		for i, v := range values {
+			fmt.Fprintf(&b, "\tcase %d:\n\t\treturn %d\n", v, i)
+		}
+		b.WriteString("\t}\n\treturn -1\n}\n")
+	} else if names, ok := stringSymbols(t.Symbols); ok {
+		fmt.Fprintf(&b, `
+// %sSymbol maps a symbol name to its number, or -1 if it is not in the alphabet.
+func %sSymbol(name string) int32 {
+	switch name {
+`, prefix, prefix)
+		for i, name := range names {
+			fmt.Fprintf(&b, "\tcase %q:\n\t\treturn %d\n", name, i)
+		}
+		b.WriteString("\t}\n\treturn -1\n}\n")
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// integerSymbols returns the numeric values of symbols whose underlying type
+// is an integer that fits in int32 (bytes, runes, small enums).
+func integerSymbols[Sigma comparable](symbols []Sigma) ([]int64, bool) {
+	out := make([]int64, len(symbols))
+	for i, a := range symbols {
+		v := reflect.ValueOf(a)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out[i] = v.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.Uint() > 1<<31-1 {
+				return nil, false
+			}
+			out[i] = int64(v.Uint())
+		default:
+			return nil, false
+		}
+		if out[i] < -1<<31 || out[i] > 1<<31-1 {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// stringSymbols returns the values of symbols whose underlying type is string.
+func stringSymbols[Sigma comparable](symbols []Sigma) ([]string, bool) {
+	out := make([]string, len(symbols))
+	for i, a := range symbols {
+		v := reflect.ValueOf(a)
+		if v.Kind() != reflect.String {
+			return nil, false
+		}
+		out[i] = v.String()
+	}
+	return out, true
+}
@@ -0,0 +1,134 @@
+package fsm
+
+// ---------- Immutable machines ----------
+
+// Frozen is an immutable DFA. Its sets and maps are private copies that are
+// never written after construction, so one Frozen can be shared by any
+// number of goroutines. Variants are derived with the With* methods, which
+// copy only what they change and share the rest.
+type Frozen[Q comparable, Sigma comparable] struct {
+	d *DFA[Q, Sigma]
+}
+
+// Freeze returns an immutable snapshot of d. Later changes to d do not
+// affect the snapshot.
+func (d *DFA[Q, Sigma]) Freeze() *Frozen[Q, Sigma] {
+	return &Frozen[Q, Sigma]{d: d.clone()}
+}
+
+// clone deep-copies the machine.
+func (d *DFA[Q, Sigma]) clone() *DFA[Q, Sigma] {
+	delta := make(TransitionFn[Q, Sigma], len(d.Delta))
+	for q, row := range d.Delta {
+		delta[q] = copyRow(row)
+	}
+	out := &DFA[Q, Sigma]{
+		Q:     copySet(d.Q),
+		Sigma: copySet(d.Sigma),
+		Q0:    d.Q0,
+		F:     copySet(d.F),
+		Delta: delta,
+	}
+	if d.Default != nil {
+		out.Default = make(map[Q]Q, len(d.Default))
+		for q, qNext := range d.Default {
+			out.Default[q] = qNext
+		}
+	}
+	return out
+}
+
+func copySet[T comparable](s Set[T]) Set[T] {
+	out := make(Set[T], len(s))
+	for x := range s {
+		out[x] = struct{}{}
+	}
+	return out
+}
+
+func copyRow[Q comparable, Sigma comparable](row map[Sigma]Q) map[Sigma]Q {
+	out := make(map[Sigma]Q, len(row))
+	for a, q := range row {
+		out[a] = q
+	}
+	return out
+}
+
+// Thaw returns a mutable deep copy.
+func (f *Frozen[Q, Sigma]) Thaw() *DFA[Q, Sigma] { return f.d.clone() }
+
+// Start returns q0.
+func (f *Frozen[Q, Sigma]) Start() Q { return f.d.Q0 }
+
+// IsFinal reports whether q ∈ F.
+func (f *Frozen[Q, Sigma]) IsFinal(q Q) bool { return f.d.F.Has(q) }
+
+// Step applies a single transition (see DFA.Step).
+func (f *Frozen[Q, Sigma]) Step(q Q, a Sigma) (Q, error) { return f.d.Step(q, a) }
+
+// Run consumes an input sequence (see DFA.Run).
+func (f *Frozen[Q, Sigma]) Run(input []Sigma) (Q, error) { return f.d.Run(input) }
+
+// Accepts runs the machine and checks acceptance (see DFA.Accepts).
+func (f *Frozen[Q, Sigma]) Accepts(input []Sigma) (bool, Q, error) { return f.d.Accepts(input) }
+
+// Fingerprint returns the digest of the machine definition.
+func (f *Frozen[Q, Sigma]) Fingerprint() string { return f.d.Fingerprint() }
+
+// derive returns a shallow copy whose δ outer map is private, so exactly the
+// rows that are about to change can be replaced.
+func (f *Frozen[Q, Sigma]) derive() *DFA[Q, Sigma] {
+	delta := make(TransitionFn[Q, Sigma], len(f.d.Delta))
+	for q, row := range f.d.Delta {
+		delta[q] = row
+	}
+	d := *f.d
+	d.Delta = delta
+	return &d
+}
+
+// WithTransition returns a variant in which δ(q,a) = next.
+func (f *Frozen[Q, Sigma]) WithTransition(q Q, a Sigma, next Q) (*Frozen[Q, Sigma], error) {
+	if !f.d.Q.Has(q) {
+		return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
+	}
+	if !f.d.Q.Has(next) {
+		return nil, &ErrUnknownTarget[Q, Sigma]{State: q, Symbol: a, Target: next}
+	}
+	if !f.d.Sigma.Has(a) {
+		return nil, &ErrUnknownSymbol[Q, Sigma]{State: q, Symbol: a}
+	}
+	d := f.derive()
+	row := copyRow(d.Delta[q])
+	row[a] = next
+	d.Delta[q] = row
+	return &Frozen[Q, Sigma]{d: d}, nil
+}
+
+// WithoutTransition returns a variant in which δ(q,a) has no edge; the
+// default of q, if any, then applies.
+func (f *Frozen[Q, Sigma]) WithoutTransition(q Q, a Sigma) *Frozen[Q, Sigma] {
+	if _, ok := f.d.Delta[q][a]; !ok {
+		return f
+	}
+	d := f.derive()
+	row := copyRow(d.Delta[q])
+	delete(row, a)
+	d.Delta[q] = row
+	return &Frozen[Q, Sigma]{d: d}
+}
+
+// WithFinal returns a variant in which q is (or is not) accepting.
+func (f *Frozen[Q, Sigma]) WithFinal(q Q, final bool) (*Frozen[Q, Sigma], error) {
+	if !f.d.Q.Has(q) {
+		return nil, &ErrUnknownState[Q]{Kind: FinalNotInQ, State: q}
+	}
+	d := *f.d
+	d.F = copySet(f.d.F)
+	if final {
+		d.F[q] = struct{}{}
+	} else {
+		delete(d.F, q)
+	}
+	return &Frozen[Q, Sigma]{d: &d}, nil
+}
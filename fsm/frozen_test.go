@@ -0,0 +1,66 @@
+package fsm
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestFreeze_Isolation ensures a frozen machine ignores later edits.
+func TestFreeze_Isolation(t *testing.T) {
+	d := buildModThree()
+	f := d.Freeze()
+	d.Delta[S0][One] = S2
+	if q, _ := f.Step(S0, One); q != S1 {
+		t.Fatalf("frozen δ(S0,1) = %v, want S1", q)
+	}
+}
+
+// TestFrozen_WithTransition derives a variant without touching the original.
+func TestFrozen_WithTransition(t *testing.T) {
+	f := buildModThree().Freeze()
+	g, err := f.WithTransition(S0, One, S2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := g.Step(S0, One); q != S2 {
+		t.Fatalf("variant δ(S0,1) = %v, want S2", q)
+	}
+	if q, _ := f.Step(S0, One); q != S1 {
+		t.Fatalf("original δ(S0,1) = %v, want S1", q)
+	}
+	if _, err := f.WithTransition(S0, One, State(9)); err == nil {
+		t.Fatal("expected error for target not in Q")
+	}
+
+	h := f.WithoutTransition(S1, Zero)
+	if _, err := h.Step(S1, Zero); err == nil {
+		t.Fatal("expected missing transition in variant")
+	}
+
+	k, err := f.WithFinal(S1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.IsFinal(S1) || !f.IsFinal(S1) {
+		t.Fatal("WithFinal should only affect the variant")
+	}
+}
+
+// TestFrozen_Concurrent runs one frozen machine from many goroutines (use -race).
+func TestFrozen_Concurrent(t *testing.T) {
+	f := buildModThree().Freeze()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if q, err := f.Run([]Bit{One, One}); err != nil || q != S0 {
+					t.Errorf("Run = %v, %v", q, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -15,6 +15,7 @@ package fsm
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // ---------- Helpers ----------
@@ -35,6 +36,19 @@ func NewSet[T comparable](xs ...T) Set[T] {
 // Has checks membership in the set.
 func (s Set[T]) Has(x T) bool { _, ok := s[x]; return ok }
 
+// sorted returns the members in a deterministic order (by their Go-syntax
+// representation), for output that must not depend on map iteration.
+func (s Set[T]) sorted() []T {
+	out := make([]T, 0, len(s))
+	keys := make(map[T]string, len(s))
+	for x := range s {
+		out = append(out, x)
+		keys[x] = fmt.Sprintf("%#v", x)
+	}
+	sort.Slice(out, func(i, j int) bool { return keys[out[i]] < keys[out[j]] })
+	return out
+}
+
 // TransitionFn encodes the transition function δ as nested maps.
 // Example: delta[q][symbol] = nextState
 type TransitionFn[Q comparable, Sigma comparable] map[Q]map[Sigma]Q
@@ -43,17 +57,19 @@ type TransitionFn[Q comparable, Sigma comparable] map[Q]map[Sigma]Q
 
 // DFA is a generic Deterministic Finite Automaton.
 // It stores:
-//   Q      = set of states
-//   Sigma  = alphabet
-//   Q0     = initial state
-//   F      = set of accepting/final states
-//   Delta  = transition function
+//   Q       = set of states
+//   Sigma   = alphabet
+//   Q0      = initial state
+//   F       = set of accepting/final states
+//   Delta   = transition function
+//   Default = per-state "else" target, used where Delta has no edge on a symbol of Σ
 type DFA[Q comparable, Sigma comparable] struct {
-	Q     Set[Q]
-	Sigma Set[Sigma]
-	Q0    Q
-	F     Set[Q]
-	Delta TransitionFn[Q, Sigma]
+	Q       Set[Q]
+	Sigma   Set[Sigma]
+	Q0      Q
+	F       Set[Q]
+	Delta   TransitionFn[Q, Sigma]
+	Default map[Q]Q
 }
 
 // ---------- Constructor ----------
@@ -70,6 +86,24 @@ func NewDFA[Q comparable, Sigma comparable](
 	finals []Q,
 	delta TransitionFn[Q, Sigma],
 	requireComplete bool,
+) (*DFA[Q, Sigma], error) {
+	return NewDFAWithDefaults(states, alphabet, q0, finals, delta, nil, requireComplete)
+}
+
+// NewDFAWithDefaults is NewDFA with a default ("else") target per state:
+// δ(q,a) = defaults[q] whenever delta has no edge for (q,a), so a scanner's
+// catch-all edge is one entry instead of one per symbol. Defaults apply to
+// the symbols of Σ only, so algorithms that enumerate Σ (Minimize,
+// Equivalent) see the same language Run does, and a state with a default
+// counts as complete. Every default must be a state of Q.
+func NewDFAWithDefaults[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta TransitionFn[Q, Sigma],
+	defaults map[Q]Q,
+	requireComplete bool,
 ) (*DFA[Q, Sigma], error) {
 	Qset := NewSet(states...)
 	Sset := NewSet(alphabet...)
@@ -77,77 +111,138 @@ func NewDFA[Q comparable, Sigma comparable](
 
 	// Check initial state
 	if !Qset.Has(q0) {
-		return nil, fmt.Errorf("q0 %v not in Q", q0)
+		return nil, &ErrUnknownState[Q]{Kind: StartNotInQ, State: q0}
 	}
 	// Check finals
 	for f := range Fset {
 		if !Qset.Has(f) {
-			return nil, fmt.Errorf("final %v not in Q", f)
+			return nil, &ErrUnknownState[Q]{Kind: FinalNotInQ, State: f}
 		}
 	}
 	// Validate delta transitions
 	for q, row := range delta {
 		if !Qset.Has(q) {
-			return nil, fmt.Errorf("delta references unknown state %v", q)
+			return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
 		}
 		for a, qNext := range row {
 			if !Sset.Has(a) {
-				return nil, fmt.Errorf("delta row %v has symbol %v not in Σ", q, a)
+				return nil, &ErrUnknownSymbol[Q, Sigma]{State: q, Symbol: a}
 			}
 			if !Qset.Has(qNext) {
-				return nil, fmt.Errorf("delta(%v,%v) → %v not in Q", q, a, qNext)
+				return nil, &ErrUnknownTarget[Q, Sigma]{State: q, Symbol: a, Target: qNext}
 			}
 		}
 	}
+	for q, qNext := range defaults {
+		if !Qset.Has(q) {
+			return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
+		}
+		if !Qset.Has(qNext) {
+			return nil, &ErrUnknownTarget[Q, Sigma]{State: q, Target: qNext, Default: true}
+		}
+	}
 	// If completeness required, check every (q,a)
 	if requireComplete {
 		for q := range Qset {
+			if _, ok := defaults[q]; ok {
+				continue
+			}
 			row, ok := delta[q]
 			if !ok {
-				return nil, fmt.Errorf("delta missing row for state %v", q)
+				return nil, &ErrIncompleteDelta[Q, Sigma]{State: q, NoRow: true}
 			}
 			for a := range Sset {
 				if _, ok := row[a]; !ok {
-					return nil, fmt.Errorf("delta missing (%v,%v)", q, a)
+					return nil, &ErrIncompleteDelta[Q, Sigma]{State: q, Symbol: a}
 				}
 			}
 		}
 	}
 
 	return &DFA[Q, Sigma]{
-		Q:     Qset,
-		Sigma: Sset,
-		Q0:    q0,
-		F:     Fset,
-		Delta: delta,
+		Q:       Qset,
+		Sigma:   Sset,
+		Q0:      q0,
+		F:       Fset,
+		Delta:   delta,
+		Default: defaults,
 	}, nil
 }
 
+// ErrUndefined is returned by a NewDFAFunc transition function to leave
+// δ(q,a) undefined.
+var ErrUndefined = errors.New("transition undefined")
+
+// NewDFAFunc builds a DFA whose δ is computed rather than written out, e.g.
+// remainder arithmetic: delta is called once for every (q,a) in
+// states × alphabet and the results are validated as by NewDFA. delta
+// returns ErrUndefined (possibly wrapped) for a missing transition; any
+// other error aborts construction. For state spaces too large to tabulate
+// use NewLazyDFA.
+func NewDFAFunc[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta func(Q, Sigma) (Q, error),
+) (*DFA[Q, Sigma], error) {
+	table := make(TransitionFn[Q, Sigma], len(states))
+	for _, q := range states {
+		row := make(map[Sigma]Q, len(alphabet))
+		for _, a := range alphabet {
+			qNext, err := delta(q, a)
+			if errors.Is(err, ErrUndefined) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("delta(%v,%v): %w", q, a, err)
+			}
+			row[a] = qNext
+		}
+		table[q] = row
+	}
+	return NewDFA(states, alphabet, q0, finals, table, false)
+}
+
 // ---------- Core ops ----------
 
 // Step applies a single transition: q' = δ(q,a).
-// Returns an error if the transition is undefined.
+// Returns an *ErrNoTransition if the transition is undefined.
 func (d *DFA[Q, Sigma]) Step(q Q, a Sigma) (Q, error) {
 	row, ok := d.Delta[q]
-	if !ok {
-		return q, fmt.Errorf("no row for state %v", q)
+	if qNext, ok := row[a]; ok {
+		return qNext, nil
+	}
+	if qNext, ok := d.Default[q]; ok && d.Sigma.Has(a) {
+		return qNext, nil
+	}
+	return q, &ErrNoTransition[Q, Sigma]{State: q, Symbol: a, NoRow: !ok}
+}
+
+// next looks up δ(q,a), falling back to the default of q for a ∈ Σ,
+// without building an error. ok is false when the transition is undefined.
+func (d *DFA[Q, Sigma]) next(q Q, a Sigma) (Q, bool) {
+	if qNext, ok := d.Delta[q][a]; ok {
+		return qNext, true
 	}
-	qNext, ok := row[a]
-	if !ok {
-		return q, fmt.Errorf("no transition for (%v,%v)", q, a)
+	if qNext, ok := d.Default[q]; ok && d.Sigma.Has(a) {
+		return qNext, true
 	}
-	return qNext, nil
+	var zero Q
+	return zero, false
 }
 
 // Run consumes an input sequence (slice of symbols) and returns the final state.
+// If a symbol cannot be consumed it returns the state reached so far and a
+// *RunError locating the symbol.
 func (d *DFA[Q, Sigma]) Run(input []Sigma) (Q, error) {
 	q := d.Q0
-	var err error
-	for _, a := range input {
-		q, err = d.Step(q, a)
+	for i, a := range input {
+		qNext, err := d.Step(q, a)
 		if err != nil {
-			return q, err
+			return q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
 		}
+		q = qNext
 	}
 	return q, nil
 }
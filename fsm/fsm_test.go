@@ -1,6 +1,8 @@
 package fsm
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"testing"
 )
@@ -241,6 +243,95 @@ func TestNewDFA_RequireComplete(t *testing.T) {
 	}
 }
 
+// TestNewDFAWithDefaults recognizes inputs containing "ab" with catch-all
+// edges instead of one edge per symbol.
+func TestNewDFAWithDefaults(t *testing.T) {
+	alphabet := []rune("abc")
+	delta := TransitionFn[string, rune]{
+		"seek": {'a': "a"},
+		"a":    {'a': "a", 'b': "found"},
+	}
+	defaults := map[string]string{"seek": "seek", "a": "seek", "found": "found"}
+	d, err := NewDFAWithDefaults([]string{"seek", "a", "found"}, alphabet, "seek", []string{"found"}, delta, defaults, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]bool{"cab": true, "aab": true, "acb": false, "ba": false, "abc": true} {
+		if got, _, err := d.Accepts([]rune(in)); err != nil || got != want {
+			t.Errorf("%q: got %v,%v want %v", in, got, err, want)
+		}
+	}
+	if _, err := d.Run([]rune("abx")); !errors.Is(err, ErrUndefined) {
+		t.Errorf("default applied outside Σ: got %v", err)
+	}
+	// Algorithms see the defaults as ordinary edges, and defaults cover Σ
+	// only, so derived machines accept the same inputs.
+	m := d.Minimize()
+	if len(m.Q) != 3 {
+		t.Errorf("minimal machine has %d states", len(m.Q))
+	}
+	for _, w := range allWords([]rune("abx"), 4) {
+		want, _, _ := d.Accepts(w)
+		if got, _, _ := m.Accepts(w); got != want {
+			t.Errorf("Minimize: %q got %v, want %v", string(w), got, want)
+		}
+	}
+	plain := Must(NewDFA([]string{"seek", "a", "found"}, alphabet, "seek", []string{"found"}, delta, false))
+	if d.Equal(plain) || d.Fingerprint() == plain.Fingerprint() {
+		t.Error("defaults ignored by Equal or Fingerprint")
+	}
+	if !d.Equal(d.Freeze().Thaw()) {
+		t.Error("defaults lost by Freeze")
+	}
+	// A final state reached only through a default survives pruning.
+	only := Must(NewDFAWithDefaults([]int{0, 1}, alphabet, 0, []int{1}, nil, map[int]int{0: 1}, false))
+	if pruned := Must(only.PruneToFinals(NewSet(1))); !pruned.Q.Has(1) {
+		t.Errorf("PruneToFinals kept %v", pruned.Q.sorted())
+	}
+	if only.Stats().MemoryBytes <= Must(NewDFA([]int{0, 1}, alphabet, 0, []int{1}, nil, false)).Stats().MemoryBytes {
+		t.Error("Stats ignores defaults")
+	}
+	if _, err := NewDFAWithDefaults([]string{"seek"}, alphabet, "seek", nil, nil, map[string]string{"seek": "gone"}, false); err == nil {
+		t.Fatal("expected error for a default outside Q")
+	}
+}
+
+// TestNewDFAFunc computes mod-three δ by arithmetic.
+func TestNewDFAFunc(t *testing.T) {
+	states := []State{S0, S1, S2}
+	bits := []Bit{Zero, One}
+	d, err := NewDFAFunc(states, bits, S0, states, func(q State, b Bit) (State, error) {
+		return (2*q + State(b-Zero)) % 3, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(buildModThree()) {
+		t.Fatal("computed machine differs from mod-three")
+	}
+
+	// Undefined transitions are skipped; other errors abort.
+	d, err = NewDFAFunc(states, bits, S0, states, func(q State, b Bit) (State, error) {
+		if b == One {
+			return q, fmt.Errorf("odd bit: %w", ErrUndefined)
+		}
+		return q, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Step(S0, One); err == nil {
+		t.Fatal("expected (S0,One) to be undefined")
+	}
+	boom := errors.New("boom")
+	if _, err := NewDFAFunc(states, bits, S0, states, func(q State, b Bit) (State, error) { return q, boom }); !errors.Is(err, boom) {
+		t.Fatalf("got %v, want boom", err)
+	}
+	if _, err := NewDFAFunc(states, bits, S0, states, func(q State, b Bit) (State, error) { return 7, nil }); err == nil {
+		t.Fatal("expected error for a target outside Q")
+	}
+}
+
 //
 // ---------- Property tests ----------
 //
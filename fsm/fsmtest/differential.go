@@ -0,0 +1,103 @@
+package fsmtest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"fsm/fsm"
+)
+
+// ---------- Differential testing ----------
+
+// Oracle computes an observable result for an input: acceptance, a
+// remainder, an output label. Differential compares two of them.
+type Oracle[Sigma comparable, R comparable] func(input []Sigma) R
+
+// Accepts returns an oracle reporting whether d accepts the input; a run
+// error counts as rejection.
+func Accepts[Q comparable, Sigma comparable](d *fsm.DFA[Q, Sigma]) Oracle[Sigma, bool] {
+	return func(input []Sigma) bool {
+		ok, _, err := d.Accepts(input)
+		return ok && err == nil
+	}
+}
+
+// Label returns an oracle mapping the final state of d to a result, for
+// comparing against references that compute more than acceptance. label
+// receives the run error, if any, along with the state reached.
+func Label[Q comparable, Sigma comparable, R comparable](d *fsm.DFA[Q, Sigma], label func(q Q, err error) R) Oracle[Sigma, R] {
+	return func(input []Sigma) R {
+		q, err := d.Run(input)
+		return label(q, err)
+	}
+}
+
+// RandomInputs returns a generator of inputs over alphabet whose i-th input
+// has a length drawn uniformly from [0, maxLen]; the sequence is fixed by
+// seed.
+func RandomInputs[Sigma comparable](alphabet []Sigma, maxLen int, seed int64) func(i int) []Sigma {
+	rng := rand.New(rand.NewSource(seed))
+	return func(int) []Sigma {
+		out := make([]Sigma, rng.Intn(maxLen+1))
+		for j := range out {
+			out[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return out
+	}
+}
+
+// Mismatch is an input on which two oracles disagree.
+type Mismatch[Sigma comparable, R comparable] struct {
+	Index    int     // generator index of the first disagreement
+	Original []Sigma // the generated input
+	Input    []Sigma // a minimized input that still disagrees
+	A, B     R       // the results on Input
+}
+
+func (m *Mismatch[Sigma, R]) Error() string {
+	return fmt.Sprintf("oracles disagree on %v: %v vs %v (minimized from input %d of length %d)",
+		m.Input, m.A, m.B, m.Index, len(m.Original))
+}
+
+// Differential feeds gen(0), ..., gen(n-1) to a and b and returns the first
+// disagreement, minimized by deleting symbols while the oracles still
+// disagree, or nil if they agree on every input.
+func Differential[Sigma comparable, R comparable](a, b Oracle[Sigma, R], gen func(i int) []Sigma, n int) *Mismatch[Sigma, R] {
+	for i := 0; i < n; i++ {
+		input := gen(i)
+		if a(input) == b(input) {
+			continue
+		}
+		small := shrink(input, func(in []Sigma) bool { return a(in) != b(in) })
+		return &Mismatch[Sigma, R]{
+			Index:    i,
+			Original: input,
+			Input:    small,
+			A:        a(small),
+			B:        b(small),
+		}
+	}
+	return nil
+}
+
+// shrink removes chunks of input, halving the chunk size from the whole
+// input down to single symbols, while fails keeps holding. The result is
+// 1-minimal: removing any one symbol makes fails false.
+func shrink[Sigma comparable](input []Sigma, fails func([]Sigma) bool) []Sigma {
+	cur := append([]Sigma(nil), input...)
+	for chunk := len(cur); chunk >= 1; {
+		removed := false
+		for start := 0; start+chunk <= len(cur); {
+			cand := append(append([]Sigma(nil), cur[:start]...), cur[start+chunk:]...)
+			if fails(cand) {
+				cur, removed = cand, true
+				continue
+			}
+			start += chunk
+		}
+		if !removed || chunk > 1 {
+			chunk /= 2
+		}
+	}
+	return cur
+}
@@ -0,0 +1,69 @@
+package fsmtest
+
+import (
+	"testing"
+
+	"fsm/fsm"
+)
+
+// modThree is the remainder-mod-3 machine over '0'/'1'; broken swaps one
+// edge so that it is wrong exactly on inputs that visit δ(2,'0').
+func modThree(broken bool) *fsm.DFA[int, rune] {
+	delta := fsm.TransitionFn[int, rune]{
+		0: {'0': 0, '1': 1},
+		1: {'0': 2, '1': 0},
+		2: {'0': 1, '1': 2},
+	}
+	if broken {
+		delta[2]['0'] = 0
+	}
+	return fsm.Must(fsm.NewDFA([]int{0, 1, 2}, []rune("01"), 0, []int{0}, delta, true))
+}
+
+// remainder is the arithmetic reference.
+func remainder(input []rune) int {
+	r := 0
+	for _, c := range input {
+		r = (2*r + int(c-'0')) % 3
+	}
+	return r
+}
+
+func state(q int, err error) int {
+	if err != nil {
+		return -1
+	}
+	return q
+}
+
+// TestDifferential_Agree finds nothing when the machine is correct.
+func TestDifferential_Agree(t *testing.T) {
+	gen := RandomInputs([]rune("01"), 40, 1)
+	if m := Differential(Label(modThree(false), state), remainder, gen, 500); m != nil {
+		t.Fatal(m)
+	}
+	if m := Differential(Accepts(modThree(false)), Accepts(modThree(false)), gen, 100); m != nil {
+		t.Fatal(m)
+	}
+}
+
+// TestDifferential_Minimized shrinks a long counterexample to the shortest
+// input that exercises the broken edge.
+func TestDifferential_Minimized(t *testing.T) {
+	gen := RandomInputs([]rune("01"), 60, 2)
+	m := Differential(Label(modThree(true), state), remainder, gen, 500)
+	if m == nil {
+		t.Fatal("expected a mismatch")
+	}
+	if string(m.Input) != "100" {
+		t.Fatalf("minimized input = %q (from %q), want \"100\"", string(m.Input), string(m.Original))
+	}
+	if m.A != 0 || m.B != 1 {
+		t.Fatalf("results = %d vs %d, want 0 vs 1", m.A, m.B)
+	}
+
+	acc := Differential(Accepts(modThree(true)), Accepts(modThree(false)), gen, 500)
+	if acc == nil || len(acc.Input) > len(acc.Original) {
+		t.Fatalf("acceptance mismatch = %v", acc)
+	}
+}
@@ -0,0 +1,211 @@
+// Package fsmtest provides test harnesses for machines and the systems
+// built on them.
+//
+// Stress drives an event-driven subject from many goroutines at once under
+// seeded, reproducible interleavings. Deterministic and Serializable then
+// check the trials: the former that every interleaving led to the same
+// outcome, the latter that each outcome is explained by applying the
+// events one at a time in the order the subject logged. Run the tests with
+// -race so unsynchronized state is reported too.
+//
+// Differential compares a DFA against another DFA or a reference function
+// on generated inputs and reports a minimized counterexample.
+package fsmtest
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Subject is one instance of the system under test.
+type Subject[E comparable, R comparable] interface {
+	// Handle applies one event. It is called from many goroutines at once.
+	Handle(event E) error
+	// Outcome returns the observable result once all events are handled.
+	Outcome() R
+}
+
+// Logger is implemented by subjects that record the order in which they
+// applied events; Serializable requires it.
+type Logger[E comparable] interface {
+	Log() []E
+}
+
+// Options configures Stress. Zero fields take the defaults noted.
+type Options struct {
+	Goroutines int   // concurrent senders; default 8
+	Trials     int   // number of seeds tried; default 20
+	Seed       int64 // first seed; trial i uses Seed+i
+}
+
+// Trial is the result of one seeded run.
+type Trial[E comparable, R comparable] struct {
+	Seed    int64
+	Streams [][]E // events sent by each goroutine, in order
+	Outcome R
+	Log     []E // nil unless the subject implements Logger
+	Errs    []error
+}
+
+// Stress runs opts.Trials trials. Each trial creates a fresh subject,
+// deals events out to opts.Goroutines goroutines at random (each goroutine
+// keeps the relative order of its events), releases them together, and
+// makes them yield at random points, all derived from the trial's seed.
+func Stress[E comparable, R comparable](newSubject func() Subject[E, R], events []E, opts Options) []Trial[E, R] {
+	if opts.Goroutines <= 0 {
+		opts.Goroutines = 8
+	}
+	if opts.Trials <= 0 {
+		opts.Trials = 20
+	}
+	trials := make([]Trial[E, R], opts.Trials)
+	for i := range trials {
+		trials[i] = runTrial(newSubject(), events, opts.Goroutines, opts.Seed+int64(i))
+	}
+	return trials
+}
+
+func runTrial[E comparable, R comparable](s Subject[E, R], events []E, goroutines int, seed int64) Trial[E, R] {
+	rng := rand.New(rand.NewSource(seed))
+	tr := Trial[E, R]{Seed: seed, Streams: make([][]E, goroutines)}
+	for _, e := range events {
+		g := rng.Intn(goroutines)
+		tr.Streams[g] = append(tr.Streams[g], e)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for g, stream := range tr.Streams {
+		wg.Add(1)
+		yield := rand.New(rand.NewSource(seed*int64(goroutines) + int64(g)))
+		go func(stream []E, yield *rand.Rand) {
+			defer wg.Done()
+			<-start
+			for _, e := range stream {
+				if yield.Intn(2) == 0 {
+					runtime.Gosched()
+				}
+				if err := s.Handle(e); err != nil {
+					mu.Lock()
+					tr.Errs = append(tr.Errs, err)
+					mu.Unlock()
+				}
+			}
+		}(stream, yield)
+	}
+	close(start)
+	wg.Wait()
+
+	tr.Outcome = s.Outcome()
+	if l, ok := s.(Logger[E]); ok {
+		tr.Log = l.Log()
+	}
+	return tr
+}
+
+// Deterministic returns an error describing the seeds behind each outcome
+// if the trials did not all reach the same outcome, or if any trial
+// reported handler errors.
+func Deterministic[E comparable, R comparable](trials []Trial[E, R]) error {
+	if err := handlerErrors(trials); err != nil {
+		return err
+	}
+	seeds := make(map[R][]int64)
+	var order []R
+	for _, tr := range trials {
+		if _, ok := seeds[tr.Outcome]; !ok {
+			order = append(order, tr.Outcome)
+		}
+		seeds[tr.Outcome] = append(seeds[tr.Outcome], tr.Seed)
+	}
+	if len(order) <= 1 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "nondeterministic: %d distinct outcomes", len(order))
+	for _, r := range order {
+		fmt.Fprintf(&b, "; %v from seeds %v", r, seeds[r])
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// Serializable checks that every trial's subject behaved as if it applied
+// the events one at a time: its log holds exactly the events sent, keeps
+// each goroutine's events in their sending order, and serial(log) equals
+// the observed outcome. serial is a reference implementation that applies
+// events sequentially.
+func Serializable[E comparable, R comparable](trials []Trial[E, R], serial func(order []E) R) error {
+	if err := handlerErrors(trials); err != nil {
+		return err
+	}
+	for _, tr := range trials {
+		if tr.Log == nil {
+			return fmt.Errorf("seed %d: subject does not implement Logger", tr.Seed)
+		}
+		counts := make(map[E]int)
+		for _, stream := range tr.Streams {
+			for _, e := range stream {
+				counts[e]++
+			}
+			if !subsequence(stream, tr.Log) {
+				return fmt.Errorf("seed %d: log %v reorders a sender's events %v", tr.Seed, tr.Log, stream)
+			}
+		}
+		for _, e := range tr.Log {
+			counts[e]--
+		}
+		for e, n := range counts {
+			if n != 0 {
+				return fmt.Errorf("seed %d: event %v applied %d times too %s", tr.Seed, e, abs(n), fewOrMany(n))
+			}
+		}
+		if want := serial(tr.Log); want != tr.Outcome {
+			return fmt.Errorf("seed %d: outcome %v, but applying the log serially gives %v", tr.Seed, tr.Outcome, want)
+		}
+	}
+	return nil
+}
+
+func handlerErrors[E comparable, R comparable](trials []Trial[E, R]) error {
+	for _, tr := range trials {
+		if len(tr.Errs) > 0 {
+			msgs := make([]string, len(tr.Errs))
+			for i, err := range tr.Errs {
+				msgs[i] = err.Error()
+			}
+			sort.Strings(msgs)
+			return fmt.Errorf("seed %d: %d handler errors: %s", tr.Seed, len(msgs), strings.Join(msgs, "; "))
+		}
+	}
+	return nil
+}
+
+// subsequence reports whether sub appears in seq in order.
+func subsequence[E comparable](sub, seq []E) bool {
+	i := 0
+	for _, e := range seq {
+		if i < len(sub) && sub[i] == e {
+			i++
+		}
+	}
+	return i == len(sub)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func fewOrMany(n int) string {
+	if n > 0 {
+		return "few"
+	}
+	return "many"
+}
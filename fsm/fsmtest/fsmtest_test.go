@@ -0,0 +1,95 @@
+package fsmtest
+
+import (
+	"sync"
+	"testing"
+
+	"fsm/fsm"
+)
+
+// counter is a mod-4 counter on 'a'; every order of the same events ends
+// in the same state.
+func counter() *fsm.DFA[int, rune] {
+	delta := fsm.TransitionFn[int, rune]{}
+	for i := 0; i < 4; i++ {
+		delta[i] = map[rune]int{'a': (i + 1) % 4, 'r': 0}
+	}
+	return fsm.Must(fsm.NewDFA([]int{0, 1, 2, 3}, []rune("ar"), 0, nil, delta, true))
+}
+
+// lockedRunner serializes a Runner with a mutex and logs the applied order.
+type lockedRunner struct {
+	mu  sync.Mutex
+	r   *fsm.Runner[int, rune]
+	log []rune
+}
+
+func (l *lockedRunner) Handle(e rune) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.r.Feed(e); err != nil {
+		return err
+	}
+	l.log = append(l.log, e)
+	return nil
+}
+
+func (l *lockedRunner) Outcome() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.State()
+}
+
+func (l *lockedRunner) Log() []rune {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]rune(nil), l.log...)
+}
+
+func newSubject() Subject[rune, int] {
+	return &lockedRunner{r: fsm.NewRunner(counter(), 0)}
+}
+
+func serial(order []rune) int {
+	q, _ := counter().Run(order)
+	return q
+}
+
+// TestStress_Commutative passes both checks for order-independent events.
+func TestStress_Commutative(t *testing.T) {
+	trials := Stress(newSubject, []rune("aaaaaaa"), Options{Goroutines: 4, Trials: 10})
+	if err := Deterministic(trials); err != nil {
+		t.Fatal(err)
+	}
+	if err := Serializable(trials, serial); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStress_OrderDependent detects nondeterminism, yet every trial is
+// still serializable.
+func TestStress_OrderDependent(t *testing.T) {
+	events := []rune("aaaaaaaaaaaaaaaarrrr")
+	trials := Stress(newSubject, events, Options{Goroutines: 8, Trials: 50, Seed: 1})
+	if err := Deterministic(trials); err == nil {
+		t.Fatal("expected different outcomes across seeds")
+	}
+	if err := Serializable(trials, serial); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// lossy drops the log, so its outcome cannot be explained.
+type lossy struct{ lockedRunner }
+
+func (l *lossy) Log() []rune { return nil }
+
+// TestSerializable_Violations reports a log that does not match the events.
+func TestSerializable_Violations(t *testing.T) {
+	trials := Stress(func() Subject[rune, int] { return &lossy{lockedRunner{r: fsm.NewRunner(counter(), 0)}} },
+		[]rune("aa"), Options{Trials: 1})
+	trials[0].Log = []rune("a")
+	if err := Serializable(trials, serial); err == nil {
+		t.Fatal("expected a missing event to be reported")
+	}
+}
@@ -0,0 +1,206 @@
+package fsm
+
+import "fmt"
+
+// ---------- Finite-state transducers ----------
+
+// FSTArc is one transition of a transducer: from From, read In, write Out
+// and go to To. EpsIn and EpsOut mark an ε on the input or output tape, in
+// which case In or Out is ignored.
+type FSTArc[Q comparable, A comparable, B comparable] struct {
+	From   Q
+	In     A
+	Out    B
+	To     Q
+	EpsIn  bool
+	EpsOut bool
+}
+
+// FST is a nondeterministic finite-state transducer from words over A to
+// words over B: it relates an input to the output written along any run
+// that reads all of it and ends in F. Arcs may read or write nothing, so
+// inputs and outputs need not have the same length.
+type FST[Q comparable, A comparable, B comparable] struct {
+	Q  Set[Q]
+	Q0 Q
+	F  Set[Q]
+
+	arcs map[Q][]FSTArc[Q, A, B]
+}
+
+// NewFST builds and validates a transducer. Every arc must mention only
+// states in Q. Arcs leave a state in the order given, which fixes the
+// order of Transduce's results.
+func NewFST[Q comparable, A comparable, B comparable](
+	states []Q,
+	q0 Q,
+	finals []Q,
+	arcs []FSTArc[Q, A, B],
+) (*FST[Q, A, B], error) {
+	t := &FST[Q, A, B]{
+		Q:    NewSet(states...),
+		Q0:   q0,
+		F:    NewSet(finals...),
+		arcs: make(map[Q][]FSTArc[Q, A, B]),
+	}
+	if !t.Q.Has(q0) {
+		return nil, fmt.Errorf("%w: q0 %v not in Q", ErrInvalidInput, q0)
+	}
+	for f := range t.F {
+		if !t.Q.Has(f) {
+			return nil, fmt.Errorf("%w: final %v not in Q", ErrInvalidInput, f)
+		}
+	}
+	for _, e := range arcs {
+		if !t.Q.Has(e.From) || !t.Q.Has(e.To) {
+			return nil, fmt.Errorf("%w: arc %v --%s--> %v uses a state not in Q", ErrInvalidInput, e.From, e.label(), e.To)
+		}
+		t.arcs[e.From] = append(t.arcs[e.From], e)
+	}
+	return t, nil
+}
+
+// label writes the arc as in:out, with ε for an empty side.
+func (e FSTArc[Q, A, B]) label() string {
+	in, out := "ε", "ε"
+	if !e.EpsIn {
+		in = fmt.Sprint(e.In)
+	}
+	if !e.EpsOut {
+		out = fmt.Sprint(e.Out)
+	}
+	return in + ":" + out
+}
+
+// Arcs returns the arcs leaving q, in the order given to NewFST.
+func (t *FST[Q, A, B]) Arcs(q Q) []FSTArc[Q, A, B] {
+	return append([]FSTArc[Q, A, B](nil), t.arcs[q]...)
+}
+
+// Transduce returns every distinct output t relates to input, in order of
+// discovery: breadth-first over the number of arcs taken. A nil result
+// means input is not in t's domain. A cycle that reads nothing but writes
+// something gives infinitely many outputs; budget.MaxStates bounds the
+// configurations (position, state, output) explored, and progress is
+// reported in the phase "transduce".
+func (t *FST[Q, A, B]) Transduce(input []A, budget Budget) ([][]B, error) {
+	// Outputs are nodes of a trie, so configurations compare cheaply.
+	type edge struct {
+		node int
+		b    B
+	}
+	type config struct {
+		pos  int
+		q    Q
+		node int
+	}
+	parent := []edge{{node: -1}}
+	child := make(map[edge]int)
+	write := func(node int, b B) int {
+		e := edge{node, b}
+		if n, ok := child[e]; ok {
+			return n
+		}
+		child[e] = len(parent)
+		parent = append(parent, e)
+		return len(parent) - 1
+	}
+
+	tracker := budget.track()
+	start := config{0, t.Q0, 0}
+	seen := map[config]bool{start: true}
+	queue := []config{start}
+	var outputs [][]B
+	emitted := make(map[int]bool)
+	for processed := 0; processed < len(queue); processed++ {
+		if err := tracker.step("transduce", len(seen), processed, len(queue)-processed, 0); err != nil {
+			return outputs, err
+		}
+		c := queue[processed]
+		if c.pos == len(input) && t.F.Has(c.q) && !emitted[c.node] {
+			emitted[c.node] = true
+			var out []B
+			for n := c.node; n > 0; n = parent[n].node {
+				out = append(out, parent[n].b)
+			}
+			for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+				out[i], out[j] = out[j], out[i]
+			}
+			outputs = append(outputs, out)
+		}
+		for _, e := range t.arcs[c.q] {
+			next := config{c.pos, e.To, c.node}
+			if !e.EpsIn {
+				if c.pos == len(input) || input[c.pos] != e.In {
+					continue
+				}
+				next.pos++
+			}
+			if !e.EpsOut {
+				next.node = write(c.node, e.Out)
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if err := tracker.done("transduce", len(seen), len(queue)); err != nil {
+		return outputs, err
+	}
+	return outputs, nil
+}
+
+// Compose returns the transducer relating x to z when t1 relates x to some
+// y and t2 relates y to z, as OpenFst's Compose does for unweighted
+// transducers: a tokenizer followed by a normalizer becomes one machine.
+// Only pairs reachable from (t1.Q0, t2.Q0) are built. An ε written by t1
+// or read by t2 advances that side alone, so the result may hold several
+// paths for the same pair of words; that changes nothing about the
+// relation.
+func Compose[Q1 comparable, Q2 comparable, A comparable, B comparable, C comparable](t1 *FST[Q1, A, B], t2 *FST[Q2, B, C]) *FST[Pair[Q1, Q2], A, C] {
+	type state = Pair[Q1, Q2]
+	start := state{A: t1.Q0, B: t2.Q0}
+	out := &FST[state, A, C]{
+		Q:    NewSet(start),
+		Q0:   start,
+		F:    make(Set[state]),
+		arcs: make(map[state][]FSTArc[state, A, C]),
+	}
+	queue := []state{start}
+	add := func(from, to state, in A, epsIn bool, o C, epsOut bool) {
+		out.arcs[from] = append(out.arcs[from], FSTArc[state, A, C]{
+			From: from, In: in, Out: o, To: to, EpsIn: epsIn, EpsOut: epsOut,
+		})
+		if !out.Q.Has(to) {
+			out.Q[to] = struct{}{}
+			queue = append(queue, to)
+		}
+	}
+	var noIn A
+	var noOut C
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if t1.F.Has(s.A) && t2.F.Has(s.B) {
+			out.F[s] = struct{}{}
+		}
+		for _, e1 := range t1.arcs[s.A] {
+			if e1.EpsOut {
+				add(s, state{A: e1.To, B: s.B}, e1.In, e1.EpsIn, noOut, true)
+				continue
+			}
+			for _, e2 := range t2.arcs[s.B] {
+				if !e2.EpsIn && e2.In == e1.Out {
+					add(s, state{A: e1.To, B: e2.To}, e1.In, e1.EpsIn, e2.Out, e2.EpsOut)
+				}
+			}
+		}
+		for _, e2 := range t2.arcs[s.B] {
+			if e2.EpsIn {
+				add(s, state{A: s.A, B: e2.To}, noIn, true, e2.Out, e2.EpsOut)
+			}
+		}
+	}
+	return out
+}
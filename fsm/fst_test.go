@@ -0,0 +1,126 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// lowercaser maps A and B to lower case, passes a, b and space through,
+// and drops '-'.
+func lowercaser(t *testing.T) *FST[int, rune, rune] {
+	t.Helper()
+	arcs := []FSTArc[int, rune, rune]{
+		{From: 0, In: 'A', Out: 'a', To: 0},
+		{From: 0, In: 'B', Out: 'b', To: 0},
+		{From: 0, In: 'a', Out: 'a', To: 0},
+		{From: 0, In: 'b', Out: 'b', To: 0},
+		{From: 0, In: ' ', Out: ' ', To: 0},
+		{From: 0, In: '-', To: 0, EpsOut: true},
+	}
+	f, err := NewFST([]int{0}, 0, []int{0}, arcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// squeezer writes a run of spaces as one '_' and brackets the output in
+// '<' and '>'.
+func squeezer(t *testing.T) *FST[string, rune, rune] {
+	t.Helper()
+	arcs := []FSTArc[string, rune, rune]{
+		{From: "start", Out: '<', To: "word", EpsIn: true},
+		{From: "word", In: ' ', Out: '_', To: "space"},
+		{From: "space", In: ' ', To: "space", EpsOut: true},
+		{From: "word", Out: '>', To: "end", EpsIn: true},
+		{From: "space", Out: '>', To: "end", EpsIn: true},
+	}
+	for _, r := range "ab" {
+		arcs = append(arcs,
+			FSTArc[string, rune, rune]{From: "word", In: r, Out: r, To: "word"},
+			FSTArc[string, rune, rune]{From: "space", In: r, Out: r, To: "word"})
+	}
+	f, err := NewFST([]string{"start", "word", "space", "end"}, "start", []string{"end"}, arcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func transduceStrings[Q comparable](t *testing.T, f *FST[Q, rune, rune], input string) []string {
+	t.Helper()
+	outs, err := f.Transduce([]rune(input), Budget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, o := range outs {
+		got = append(got, string(o))
+	}
+	return got
+}
+
+// TestComposeFST chains a normalizing transducer after a lowercasing one.
+func TestComposeFST(t *testing.T) {
+	lower, squeeze := lowercaser(t), squeezer(t)
+	if got := transduceStrings(t, lower, "A-b B"); !reflect.DeepEqual(got, []string{"ab b"}) {
+		t.Fatalf("lowercaser: got %q", got)
+	}
+	if got := transduceStrings(t, squeeze, "a  b"); !reflect.DeepEqual(got, []string{"<a_b>"}) {
+		t.Fatalf("squeezer: got %q", got)
+	}
+
+	both := Compose(lower, squeeze)
+	for in, want := range map[string][]string{
+		"A-B  -a": {"<ab_a>"},
+		"":        {"<>"},
+		"--":      {"<>"},
+		"Ba   ":   {"<ba_>"},
+		"aC":      nil,
+	} {
+		if got := transduceStrings(t, both, in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Compose(%q): got %q want %q", in, got, want)
+		}
+	}
+	for s := range both.Q {
+		if !lower.Q.Has(s.A) || !squeeze.Q.Has(s.B) {
+			t.Fatalf("unexpected state %v", s)
+		}
+	}
+}
+
+// TestFSTTransduce checks nondeterminism, ε cycles, and validation.
+func TestFSTTransduce(t *testing.T) {
+	// a → x or y; an ε:ε loop must not hang the search.
+	f, err := NewFST([]int{0, 1}, 0, []int{1}, []FSTArc[int, rune, rune]{
+		{From: 0, In: 'a', Out: 'x', To: 1},
+		{From: 0, In: 'a', Out: 'y', To: 1},
+		{From: 1, To: 1, EpsIn: true, EpsOut: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := transduceStrings(t, f, "a"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Fatalf("got %q", got)
+	}
+
+	// ε:z loops have infinitely many outputs; the budget stops them.
+	g, err := NewFST([]int{0}, 0, []int{0}, []FSTArc[int, rune, rune]{
+		{From: 0, Out: 'z', To: 0, EpsIn: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs, err := g.Transduce(nil, Budget{MaxStates: 10})
+	if !errors.Is(err, ErrLimitExceeded) || len(outs) == 0 || len(outs[0]) != 0 {
+		t.Fatalf("got %q, %v", outs, err)
+	}
+
+	if _, err := NewFST([]int{0}, 0, []int{0}, []FSTArc[int, rune, rune]{{From: 0, In: 'a', Out: 'b', To: 1}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("unknown state: got %v", err)
+	}
+	if _, err := NewFST[int, rune, rune]([]int{0}, 1, nil, nil); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("unknown q0: got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package fsm
+
+import "math"
+
+// ---------- Growth rate and entropy ----------
+
+// GrowthRate returns the exponential growth rate λ of the accepted language:
+// the number of accepted words of length n grows like λⁿ. It is the dominant
+// eigenvalue of the transition-count matrix of the trimmed machine, and 0 for
+// finite languages.
+//
+// Nonnegative matrices have spectral radius equal to the largest spectral
+// radius of their strongly connected components, so each component is solved
+// on its own by power iteration on (A + I), which is primitive and therefore
+// converges even for periodic components.
+func (d *DFA[Q, Sigma]) GrowthRate() float64 {
+	useful := d.useful()
+	if !useful.Has(d.Q0) {
+		return 0
+	}
+
+	// Index useful states and count parallel edges.
+	index := make(map[Q]int, len(useful))
+	var states []Q
+	for q := range useful {
+		index[q] = len(states)
+		states = append(states, q)
+	}
+	adj := make([]map[int]float64, len(states))
+	for i, q := range states {
+		adj[i] = make(map[int]float64)
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok && useful.Has(qNext) {
+				adj[i][index[qNext]]++
+			}
+		}
+	}
+
+	best := 0.0
+	for _, comp := range sccs(adj) {
+		if len(comp) == 1 && adj[comp[0]][comp[0]] == 0 {
+			continue // acyclic singleton contributes nothing
+		}
+		if r := componentRadius(adj, comp); r > best {
+			best = r
+		}
+	}
+	return best
+}
+
+// Entropy returns the per-symbol entropy log₂(λ) of the accepted language in
+// bits, i.e. the capacity of the constraint the DFA describes. Languages that
+// do not grow exponentially (λ ≤ 1) have entropy 0.
+func (d *DFA[Q, Sigma]) Entropy() float64 {
+	r := d.GrowthRate()
+	if r <= 1 {
+		return 0
+	}
+	return math.Log2(r)
+}
+
+// componentRadius estimates the spectral radius of adj restricted to comp.
+func componentRadius(adj []map[int]float64, comp []int) float64 {
+	in := make(map[int]bool, len(comp))
+	for _, i := range comp {
+		in[i] = true
+	}
+	v := make(map[int]float64, len(comp))
+	for _, i := range comp {
+		v[i] = 1
+	}
+	norm := 0.0
+	for iter := 0; iter < 10000; iter++ {
+		w := make(map[int]float64, len(comp))
+		for _, i := range comp {
+			s := v[i] // the +I term
+			for j, n := range adj[i] {
+				if in[j] {
+					s += n * v[j]
+				}
+			}
+			w[i] = s
+		}
+		next := 0.0
+		for _, x := range w {
+			if x > next {
+				next = x
+			}
+		}
+		for i := range w {
+			w[i] /= next
+		}
+		v = w
+		if math.Abs(next-norm) < 1e-12 {
+			norm = next
+			break
+		}
+		norm = next
+	}
+	return norm - 1
+}
+
+// sccs returns the strongly connected components of a graph given as
+// adjacency maps, using Tarjan's algorithm.
+func sccs(adj []map[int]float64) [][]int {
+	index := make([]int, len(adj))
+	low := make([]int, len(adj))
+	onStack := make([]bool, len(adj))
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var out [][]int
+	counter := 0
+
+	var strong func(v int)
+	strong = func(v int) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for w := range adj[v] {
+			if index[w] < 0 {
+				strong(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if low[v] == index[v] {
+			var comp []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp = append(comp, w)
+				if w == v {
+					break
+				}
+			}
+			out = append(out, comp)
+		}
+	}
+	for v := range adj {
+		if index[v] < 0 {
+			strong(v)
+		}
+	}
+	return out
+}
+
+// AcceptanceProbability returns the probability that a word of length n,
+// drawn uniformly from Σⁿ, is accepted: the accepted count divided by |Σ|ⁿ.
+// It propagates the distribution over states n times, so it takes
+// O(n·|δ|) time and stays accurate where the counts overflow. Missing
+// transitions count as rejection. It is 0 for negative n.
+func (d *DFA[Q, Sigma]) AcceptanceProbability(n int) float64 {
+	if n < 0 {
+		return 0
+	}
+	dist := map[Q]float64{d.Q0: 1}
+	share := 1 / float64(len(d.Sigma))
+	for i := 0; i < n && len(dist) > 0; i++ {
+		next := make(map[Q]float64, len(dist))
+		for q, p := range dist {
+			for a := range d.Sigma {
+				if qNext, ok := d.next(q, a); ok {
+					next[qNext] += p * share
+				}
+			}
+		}
+		dist = next
+	}
+	total := 0.0
+	for q, p := range dist {
+		if d.F.Has(q) {
+			total += p
+		}
+	}
+	return total
+}
@@ -0,0 +1,174 @@
+package fsm
+
+import "fmt"
+
+// ---------- Non-comparable states ----------
+
+// Hasher supplies hashing and equality for a state type that is not
+// comparable (slices, structs containing maps, ...). Equal states must have
+// equal hashes.
+type Hasher[Q any] interface {
+	Hash(q Q) uint64
+	Equal(a, b Q) bool
+}
+
+// HasherFuncs adapts a pair of functions to the Hasher interface.
+type HasherFuncs[Q any] struct {
+	HashFn  func(Q) uint64
+	EqualFn func(a, b Q) bool
+}
+
+func (h HasherFuncs[Q]) Hash(q Q) uint64   { return h.HashFn(q) }
+func (h HasherFuncs[Q]) Equal(a, b Q) bool { return h.EqualFn(a, b) }
+
+// Transition is one edge δ(From, On) = To.
+type Transition[Q any, Sigma comparable] struct {
+	From Q
+	On   Sigma
+	To   Q
+}
+
+// HashDFA is a DFA over states that are not comparable. States are interned
+// into dense ids through a Hasher, and the machine itself is a DFA[int, Sigma]
+// over those ids, so every algorithm in this package applies to it via
+// Machine.
+type HashDFA[Q any, Sigma comparable] struct {
+	hasher  Hasher[Q]
+	states  []Q
+	buckets map[uint64][]int
+	dfa     *DFA[int, Sigma]
+}
+
+// NewHashDFA builds and validates a HashDFA; the checks are those of NewDFA,
+// plus a check that no (q,σ) pair is given two different targets.
+func NewHashDFA[Q any, Sigma comparable](
+	h Hasher[Q],
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta []Transition[Q, Sigma],
+	requireComplete bool,
+) (*HashDFA[Q, Sigma], error) {
+	m := &HashDFA[Q, Sigma]{hasher: h, buckets: make(map[uint64][]int)}
+	ids := make([]int, 0, len(states))
+	for _, q := range states {
+		ids = append(ids, m.intern(q))
+	}
+
+	id := func(role string, q Q) (int, error) {
+		i, ok := m.ID(q)
+		if !ok {
+			return 0, fmt.Errorf("%w: %s %v not in Q", ErrInvalidInput, role, q)
+		}
+		return i, nil
+	}
+	start, err := id("q0", q0)
+	if err != nil {
+		return nil, err
+	}
+	finalIDs := make([]int, 0, len(finals))
+	for _, f := range finals {
+		i, err := id("final", f)
+		if err != nil {
+			return nil, err
+		}
+		finalIDs = append(finalIDs, i)
+	}
+	table := make(TransitionFn[int, Sigma])
+	for _, tr := range delta {
+		from, err := id("delta source", tr.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := id(fmt.Sprintf("delta(%v,%v) target", tr.From, tr.On), tr.To)
+		if err != nil {
+			return nil, err
+		}
+		if table[from] == nil {
+			table[from] = make(map[Sigma]int)
+		}
+		if prev, ok := table[from][tr.On]; ok && prev != to {
+			return nil, fmt.Errorf("%w: delta(%v,%v) has two targets: %v and %v", ErrInvalidInput, tr.From, tr.On, m.states[prev], tr.To)
+		}
+		table[from][tr.On] = to
+	}
+
+	d, err := NewDFA(ids, alphabet, start, finalIDs, table, requireComplete)
+	if err != nil {
+		return nil, err
+	}
+	m.dfa = d
+	return m, nil
+}
+
+// intern returns the id of q, assigning a new one if q is unseen.
+func (m *HashDFA[Q, Sigma]) intern(q Q) int {
+	if i, ok := m.ID(q); ok {
+		return i
+	}
+	i := len(m.states)
+	m.states = append(m.states, q)
+	hash := m.hasher.Hash(q)
+	m.buckets[hash] = append(m.buckets[hash], i)
+	return i
+}
+
+// ID returns the dense id of state q.
+func (m *HashDFA[Q, Sigma]) ID(q Q) (int, bool) {
+	for _, i := range m.buckets[m.hasher.Hash(q)] {
+		if m.hasher.Equal(m.states[i], q) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// State returns the state with the given id.
+func (m *HashDFA[Q, Sigma]) State(id int) Q { return m.states[id] }
+
+// Machine returns the underlying DFA over state ids.
+func (m *HashDFA[Q, Sigma]) Machine() *DFA[int, Sigma] { return m.dfa }
+
+// Step applies a single transition: q' = δ(q,a).
+// Returns an *ErrNoTransition if the transition is undefined.
+func (m *HashDFA[Q, Sigma]) Step(q Q, a Sigma) (Q, error) {
+	i, ok := m.ID(q)
+	if !ok {
+		return q, &ErrNoTransition[Q, Sigma]{State: q, Symbol: a, NoRow: true}
+	}
+	j, ok := m.dfa.next(i, a)
+	if !ok {
+		return q, &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}
+	}
+	return m.states[j], nil
+}
+
+// Run consumes an input sequence and returns the final state. A symbol
+// that cannot be consumed is reported as a *RunError.
+func (m *HashDFA[Q, Sigma]) Run(input []Sigma) (Q, error) {
+	i, err := m.run(input)
+	return m.states[i], err
+}
+
+func (m *HashDFA[Q, Sigma]) run(input []Sigma) (int, error) {
+	i := m.dfa.Q0
+	for k, a := range input {
+		j, ok := m.dfa.next(i, a)
+		if !ok {
+			q := m.states[i]
+			return i, &RunError[Q, Sigma]{Index: k, Symbol: a, State: q, Err: &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}}
+		}
+		i = j
+	}
+	return i, nil
+}
+
+// Accepts runs the machine and checks if the final state is in F.
+func (m *HashDFA[Q, Sigma]) Accepts(input []Sigma) (bool, Q, error) {
+	i, err := m.run(input)
+	if err != nil {
+		return false, m.states[i], err
+	}
+	return m.dfa.F.Has(i), m.states[i], nil
+}
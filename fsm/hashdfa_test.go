@@ -0,0 +1,58 @@
+package fsm
+
+import (
+	"errors"
+	"hash/fnv"
+	"reflect"
+	"testing"
+)
+
+// sliceHasher hashes []int states (e.g. subset-construction states).
+var sliceHasher Hasher[[]int] = HasherFuncs[[]int]{
+	HashFn: func(q []int) uint64 {
+		h := fnv.New64a()
+		for _, x := range q {
+			h.Write([]byte{byte(x), byte(x >> 8)})
+		}
+		return h.Sum64()
+	},
+	EqualFn: func(a, b []int) bool { return reflect.DeepEqual(a, b) },
+}
+
+// TestHashDFA runs a machine whose states are slices.
+func TestHashDFA(t *testing.T) {
+	a, b := []int{0}, []int{0, 1}
+	m, err := NewHashDFA(sliceHasher, [][]int{a, b}, []Bit{Zero, One}, []int{0}, [][]int{{0, 1}},
+		[]Transition[[]int, Bit]{
+			{From: []int{0}, On: One, To: []int{0, 1}},
+			{From: []int{0}, On: Zero, To: []int{0}},
+			{From: []int{0, 1}, On: Zero, To: []int{0}},
+			{From: []int{0, 1}, On: One, To: []int{0, 1}},
+		}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, q, err := m.Accepts([]Bit{Zero, One, One})
+	if err != nil || !ok || !reflect.DeepEqual(q, b) {
+		t.Fatalf("Accepts = %v, %v, %v", ok, q, err)
+	}
+	if len(m.Machine().Q) != 2 {
+		t.Fatalf("expected 2 interned states, got %d", len(m.Machine().Q))
+	}
+}
+
+// TestHashDFA_Conflict rejects two targets for the same (q,σ).
+func TestHashDFA_Conflict(t *testing.T) {
+	_, err := NewHashDFA(sliceHasher, [][]int{{0}, {1}}, []Bit{Zero}, []int{0}, nil,
+		[]Transition[[]int, Bit]{
+			{From: []int{0}, On: Zero, To: []int{0}},
+			{From: []int{0}, On: Zero, To: []int{1}},
+		}, false)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for two targets, got %v", err)
+	}
+	_, err = NewHashDFA(sliceHasher, [][]int{{0}}, []Bit{Zero}, []int{9}, nil, nil, false)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for q0 outside Q, got %v", err)
+	}
+}
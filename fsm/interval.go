@@ -0,0 +1,196 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ---------- Interval alphabets ----------
+
+// IntervalRule is one transition of an IntervalDFA: from From, on any value
+// in On, go to To.
+type IntervalRule[Q comparable, T Integer] struct {
+	From Q
+	On   Range[T]
+	To   Q
+}
+
+// IntervalDFA is a DFA whose symbols are integers drawn from a declared
+// Domain and whose transitions are labelled with ranges rather than single
+// values, e.g. [0,9] → S1 and [10,99] → S2. It suits machines driven by
+// sensor readings or sizes, where listing every value is impractical.
+type IntervalDFA[Q comparable, T Integer] struct {
+	Q      Set[Q]
+	Domain Range[T]
+	Q0     Q
+	F      Set[Q]
+
+	rows map[Q][]IntervalRule[Q, T] // sorted by On.Lo
+}
+
+// NewIntervalDFA builds and validates an IntervalDFA. Every range must be
+// non-empty and inside domain, and a state's ranges must not overlap. If
+// requireComplete is true, each state's ranges must also cover the whole
+// domain.
+func NewIntervalDFA[Q comparable, T Integer](
+	states []Q,
+	domain Range[T],
+	q0 Q,
+	finals []Q,
+	rules []IntervalRule[Q, T],
+	requireComplete bool,
+) (*IntervalDFA[Q, T], error) {
+	if domain.Lo > domain.Hi {
+		return nil, fmt.Errorf("%w: empty domain [%v,%v]", ErrInvalidInput, domain.Lo, domain.Hi)
+	}
+	m := &IntervalDFA[Q, T]{
+		Q:      NewSet(states...),
+		Domain: domain,
+		Q0:     q0,
+		F:      NewSet(finals...),
+		rows:   make(map[Q][]IntervalRule[Q, T]),
+	}
+	if !m.Q.Has(q0) {
+		return nil, &ErrUnknownState[Q]{Kind: StartNotInQ, State: q0}
+	}
+	for f := range m.F {
+		if !m.Q.Has(f) {
+			return nil, &ErrUnknownState[Q]{Kind: FinalNotInQ, State: f}
+		}
+	}
+	for _, r := range rules {
+		if !m.Q.Has(r.From) {
+			return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: r.From}
+		}
+		if !m.Q.Has(r.To) {
+			return nil, &ErrUnknownTarget[Q, T]{State: r.From, Symbol: r.On.Lo, Target: r.To}
+		}
+		if r.On.Lo > r.On.Hi || r.On.Lo < domain.Lo || r.On.Hi > domain.Hi {
+			return nil, fmt.Errorf("%w: range [%v,%v] of state %v is empty or outside the domain [%v,%v]",
+				ErrInvalidInput, r.On.Lo, r.On.Hi, r.From, domain.Lo, domain.Hi)
+		}
+		m.rows[r.From] = append(m.rows[r.From], r)
+	}
+
+	for q, row := range m.rows {
+		sort.Slice(row, func(i, j int) bool { return row[i].On.Lo < row[j].On.Lo })
+		for i := 1; i < len(row); i++ {
+			if row[i].On.Lo <= row[i-1].On.Hi {
+				return nil, fmt.Errorf("%w: state %v: ranges [%v,%v] and [%v,%v] overlap",
+					ErrInvalidInput, q, row[i-1].On.Lo, row[i-1].On.Hi, row[i].On.Lo, row[i].On.Hi)
+			}
+		}
+	}
+	if requireComplete {
+		for q := range m.Q {
+			if gaps := m.Gaps(q); len(gaps) > 0 {
+				return nil, &ErrIncompleteDelta[Q, T]{State: q, Symbol: gaps[0].Lo}
+			}
+		}
+	}
+	return m, nil
+}
+
+// Gaps returns the parts of the domain on which q has no transition, in
+// ascending order.
+func (m *IntervalDFA[Q, T]) Gaps(q Q) []Range[T] {
+	var gaps []Range[T]
+	next, done := m.Domain.Lo, false
+	for _, r := range m.rows[q] {
+		if r.On.Lo > next {
+			gaps = append(gaps, Range[T]{next, r.On.Lo - 1})
+		}
+		if r.On.Hi == m.Domain.Hi {
+			done = true
+			break
+		}
+		next = r.On.Hi + 1
+	}
+	if !done {
+		gaps = append(gaps, Range[T]{next, m.Domain.Hi})
+	}
+	return gaps
+}
+
+// Step applies a single transition: q' = δ(q,x).
+func (m *IntervalDFA[Q, T]) Step(q Q, x T) (Q, error) {
+	if !m.Domain.Contains(x) {
+		return q, fmt.Errorf("%w: %v outside the domain [%v,%v]", ErrInvalidInput, x, m.Domain.Lo, m.Domain.Hi)
+	}
+	row := m.rows[q]
+	i := sort.Search(len(row), func(i int) bool { return row[i].On.Hi >= x })
+	if i == len(row) || !row[i].On.Contains(x) {
+		return q, &ErrNoTransition[Q, T]{State: q, Symbol: x}
+	}
+	return row[i].To, nil
+}
+
+// Run consumes an input sequence and returns the final state. A symbol
+// that cannot be consumed is reported as a *RunError.
+func (m *IntervalDFA[Q, T]) Run(input []T) (Q, error) {
+	q := m.Q0
+	for i, x := range input {
+		qNext, err := m.Step(q, x)
+		if err != nil {
+			return q, &RunError[Q, T]{Index: i, Symbol: x, State: q, Err: err}
+		}
+		q = qNext
+	}
+	return q, nil
+}
+
+// Accepts runs the machine and checks if the final state is in F.
+func (m *IntervalDFA[Q, T]) Accepts(input []T) (bool, Q, error) {
+	q, err := m.Run(input)
+	if err != nil {
+		return false, q, err
+	}
+	return m.F.Has(q), q, nil
+}
+
+// ToIntervals converts a DFA over integer symbols into an IntervalDFA,
+// merging runs of consecutive symbols with the same target into one range.
+// The domain spans the smallest to the largest symbol of Σ; values between
+// them that are not in Σ have no transition, as in d. A machine over a
+// dense alphabet, such as one from CompileRegex, keeps a handful of ranges
+// per state instead of one map entry per symbol. Σ must not be empty.
+func ToIntervals[Q comparable, T Integer](d *DFA[Q, T]) (*IntervalDFA[Q, T], error) {
+	symbols := make([]T, 0, len(d.Sigma))
+	for a := range d.Sigma {
+		symbols = append(symbols, a)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("%w: empty alphabet", ErrInvalidInput)
+	}
+	m := &IntervalDFA[Q, T]{
+		Q:      copySet(d.Q),
+		Domain: Range[T]{symbols[0], symbols[len(symbols)-1]},
+		Q0:     d.Q0,
+		F:      copySet(d.F),
+		rows:   make(map[Q][]IntervalRule[Q, T], len(d.Q)),
+	}
+	for q := range d.Q {
+		var row []IntervalRule[Q, T]
+		for _, a := range symbols {
+			qNext, ok := d.next(q, a)
+			if !ok {
+				continue
+			}
+			if n := len(row); n > 0 && row[n-1].On.Hi+1 == a && row[n-1].To == qNext {
+				row[n-1].On.Hi = a
+				continue
+			}
+			row = append(row, IntervalRule[Q, T]{From: q, On: Range[T]{a, a}, To: qNext})
+		}
+		if len(row) > 0 {
+			m.rows[q] = row
+		}
+	}
+	return m, nil
+}
+
+// Rules returns the transitions leaving q, in ascending order of range.
+func (m *IntervalDFA[Q, T]) Rules(q Q) []IntervalRule[Q, T] {
+	return append([]IntervalRule[Q, T](nil), m.rows[q]...)
+}
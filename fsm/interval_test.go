@@ -0,0 +1,93 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestIntervalDFA models a thermostat that trips after two hot readings in
+// a row.
+func TestIntervalDFA(t *testing.T) {
+	domain := Range[int]{-40, 125}
+	cool, hot := Range[int]{-40, 79}, Range[int]{80, 125}
+	rules := []IntervalRule[string, int]{
+		{From: "ok", On: cool, To: "ok"},
+		{From: "ok", On: hot, To: "warm"},
+		{From: "warm", On: cool, To: "ok"},
+		{From: "warm", On: hot, To: "tripped"},
+		{From: "tripped", On: domain, To: "tripped"},
+	}
+	m, err := NewIntervalDFA([]string{"ok", "warm", "tripped"}, domain, "ok", []string{"tripped"}, rules, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		in   []int
+		want bool
+	}{
+		{[]int{20, 85, 30, 90}, false},
+		{[]int{20, 85, 90, -40}, true},
+		{[]int{79, 80, 125}, true},
+	} {
+		if got, _, err := m.Accepts(tc.in); err != nil || got != tc.want {
+			t.Fatalf("%v: got %v,%v want %v", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := m.Run([]int{200}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an out-of-domain value, got %v", err)
+	}
+}
+
+// TestNewIntervalDFA_Validation checks overlap and completeness errors.
+func TestNewIntervalDFA_Validation(t *testing.T) {
+	domain := Range[uint8]{0, 255}
+	overlap := []IntervalRule[int, uint8]{{0, Range[uint8]{0, 10}, 0}, {0, Range[uint8]{10, 255}, 0}}
+	if _, err := NewIntervalDFA([]int{0}, domain, 0, nil, overlap, false); err == nil {
+		t.Fatal("expected an overlap error")
+	}
+
+	gappy := []IntervalRule[int, uint8]{{0, Range[uint8]{1, 9}, 0}, {0, Range[uint8]{20, 255}, 0}}
+	if _, err := NewIntervalDFA([]int{0}, domain, 0, nil, gappy, true); err == nil {
+		t.Fatal("expected an incomplete error")
+	}
+	m, err := NewIntervalDFA([]int{0}, domain, 0, nil, gappy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Range[uint8]{{0, 0}, {10, 19}}
+	if got := m.Gaps(0); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Gaps = %v, want %v", got, want)
+	}
+}
+
+// TestToIntervals compresses a regex DFA and checks it agrees.
+func TestToIntervals(t *testing.T) {
+	d, err := CompileRegex(`[a-z][a-z0-9_]*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ToIntervals(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Domain != (Range[rune]{'0', 'z'}) {
+		t.Fatalf("domain = %v", m.Domain)
+	}
+	rules := 0
+	for q := range m.Q {
+		rules += len(m.Rules(q))
+	}
+	if want := 1 + 3; rules != want { // start: [a-z]; loop: [0-9], _, [a-z]
+		t.Fatalf("%d rules, want %d", rules, want)
+	}
+	for _, in := range []string{"x", "snake_case9", "9x", "", "Ab"} {
+		want, _, wantErr := d.Accepts([]rune(in))
+		got, _, err := m.Accepts([]rune(in))
+		if got != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("%q: got %v,%v want %v,%v", in, got, err, want, wantErr)
+		}
+	}
+	if _, err := ToIntervals(Must(NewDFA[int, rune]([]int{0}, nil, 0, nil, nil, false))); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("empty alphabet: got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package fsm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ---------- Lazy DFAs ----------
+
+// LazyOptions configures a LazyDFA. The zero value computes every
+// transition afresh and records nothing.
+type LazyOptions struct {
+	Memoize  bool // cache transitions in a MemoDelta
+	Capacity int  // cache entries when Memoize is set; 0 means unbounded
+	Track    bool // record the states runs enter, see Discovered
+}
+
+// LazyDFA is a DFA whose transitions are computed by a DeltaFunc and whose
+// states are discovered as runs reach them, for machines with a state space
+// far too large to tabulate of which runs only visit a small part. F is a
+// predicate for the same reason. It is safe for concurrent use when delta
+// and isFinal are.
+type LazyDFA[Q comparable, Sigma comparable] struct {
+	Sigma Set[Sigma] // nil accepts any symbol
+	Q0    Q
+
+	isFinal func(Q) bool
+	delta   DeltaFunc[Q, Sigma]
+	memo    *MemoDelta[Q, Sigma]
+
+	mu   sync.Mutex
+	seen Set[Q] // nil unless tracking
+}
+
+// NewLazyDFA builds a lazy machine starting in q0. A nil alphabet leaves
+// the symbols unchecked; otherwise Step rejects symbols outside it.
+func NewLazyDFA[Q comparable, Sigma comparable](
+	alphabet []Sigma,
+	q0 Q,
+	isFinal func(Q) bool,
+	delta DeltaFunc[Q, Sigma],
+	opts LazyOptions,
+) (*LazyDFA[Q, Sigma], error) {
+	if delta == nil || isFinal == nil {
+		return nil, fmt.Errorf("%w: lazy DFA needs a transition function and a final-state predicate", ErrInvalidInput)
+	}
+	m := &LazyDFA[Q, Sigma]{Q0: q0, isFinal: isFinal, delta: delta}
+	if alphabet != nil {
+		m.Sigma = NewSet(alphabet...)
+	}
+	if opts.Memoize {
+		m.memo = NewMemoDelta(delta, opts.Capacity)
+		m.delta = m.memo.Step
+	}
+	if opts.Track {
+		m.seen = NewSet(q0)
+	}
+	return m, nil
+}
+
+// IsFinal reports whether q is accepting.
+func (m *LazyDFA[Q, Sigma]) IsFinal(q Q) bool { return m.isFinal(q) }
+
+// Step computes a single transition: q' = δ(q,a).
+func (m *LazyDFA[Q, Sigma]) Step(q Q, a Sigma) (Q, error) {
+	if m.Sigma != nil && !m.Sigma.Has(a) {
+		return q, fmt.Errorf("%w: symbol %v not in Σ", ErrInvalidInput, a)
+	}
+	qNext, ok := m.delta(q, a)
+	if !ok {
+		return q, &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}
+	}
+	if m.seen != nil {
+		m.mu.Lock()
+		m.seen[qNext] = struct{}{}
+		m.mu.Unlock()
+	}
+	return qNext, nil
+}
+
+// Run consumes an input sequence and returns the final state. A symbol
+// that cannot be consumed is reported as a *RunError.
+func (m *LazyDFA[Q, Sigma]) Run(input []Sigma) (Q, error) {
+	q := m.Q0
+	for i, a := range input {
+		qNext, err := m.Step(q, a)
+		if err != nil {
+			return q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
+		}
+		q = qNext
+	}
+	return q, nil
+}
+
+// Accepts runs the machine and checks if the final state is accepting.
+func (m *LazyDFA[Q, Sigma]) Accepts(input []Sigma) (bool, Q, error) {
+	q, err := m.Run(input)
+	if err != nil {
+		return false, q, err
+	}
+	return m.isFinal(q), q, nil
+}
+
+// Discovered returns a copy of the states entered so far, q0 included. It
+// is nil unless the machine was built with Track.
+func (m *LazyDFA[Q, Sigma]) Discovered() Set[Q] {
+	if m.seen == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copySet(m.seen)
+}
+
+// MemoStats returns the statistics of the transition cache, zero unless
+// the machine was built with Memoize.
+func (m *LazyDFA[Q, Sigma]) MemoStats() MemoStats {
+	if m.memo == nil {
+		return MemoStats{}
+	}
+	return m.memo.Stats()
+}
+
+// Explore tabulates the part of the machine reachable from q0 over Σ,
+// which must be set, breadth-first. The budget bounds the number of states,
+// which may be infinite; progress is reported in the phase "explore".
+func (m *LazyDFA[Q, Sigma]) Explore(budget Budget) (*DFA[Q, Sigma], error) {
+	if m.Sigma == nil {
+		return nil, fmt.Errorf("%w: Explore needs an alphabet", ErrInvalidInput)
+	}
+	symbols := m.Sigma.sorted()
+	tracker := budget.track()
+	out := &DFA[Q, Sigma]{
+		Q:     NewSet(m.Q0),
+		Sigma: copySet(m.Sigma),
+		Q0:    m.Q0,
+		F:     make(Set[Q]),
+		Delta: make(TransitionFn[Q, Sigma]),
+	}
+	queue := []Q{m.Q0}
+	for processed := 0; processed < len(queue); processed++ {
+		if err := tracker.step("explore", len(out.Q), processed, len(queue)-processed, 0); err != nil {
+			return nil, err
+		}
+		q := queue[processed]
+		if m.isFinal(q) {
+			out.F[q] = struct{}{}
+		}
+		row := make(map[Sigma]Q, len(symbols))
+		for _, a := range symbols {
+			qNext, err := m.Step(q, a)
+			if err != nil {
+				continue
+			}
+			row[a] = qNext
+			if !out.Q.Has(qNext) {
+				out.Q[qNext] = struct{}{}
+				queue = append(queue, qNext)
+			}
+		}
+		if len(row) > 0 {
+			out.Delta[q] = row
+		}
+	}
+	if err := tracker.done("explore", len(out.Q), len(queue)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestLazyDFA runs "divisible by 1_000_003" over decimal digits: a million
+// states, of which a run visits one per digit.
+func TestLazyDFA(t *testing.T) {
+	const n = 1_000_003
+	digits := []rune("0123456789")
+	calls := 0
+	delta := func(q int, r rune) (int, bool) {
+		calls++
+		return (q*10 + int(r-'0')) % n, true
+	}
+	m, err := NewLazyDFA(digits, 0, func(q int) bool { return q == 0 }, delta,
+		LazyOptions{Memoize: true, Track: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]bool{"1000003": true, "2000006": true, "1000004": false, "": true} {
+		if got, _, err := m.Accepts([]rune(in)); err != nil || got != want {
+			t.Errorf("%q: got %v,%v want %v", in, got, err, want)
+		}
+	}
+	before := calls
+	m.Accepts([]rune("1000003"))
+	if calls != before {
+		t.Errorf("memoized run called delta %d times", calls-before)
+	}
+	if st := m.MemoStats(); st.Hits < 7 {
+		t.Errorf("stats = %+v", st)
+	}
+	if got := len(m.Discovered()); got > 30 {
+		t.Errorf("discovered %d states", got)
+	}
+	if _, err := m.Step(0, 'x'); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("symbol outside Σ: got %v", err)
+	}
+	if _, err := m.Explore(Budget{MaxStates: 1000}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Explore of a huge machine: got %v", err)
+	}
+}
+
+// TestLazyDFA_Explore tabulates a small lazy machine and compares it with
+// the hand-written one.
+func TestLazyDFA_Explore(t *testing.T) {
+	mod3 := func(q State, b Bit) (State, bool) { return (2*q + State(b-Zero)) % 3, true }
+	m, err := NewLazyDFA([]Bit{Zero, One}, S0, func(q State) bool { return true }, mod3, LazyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := m.Explore(Budget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(buildModThree()) {
+		t.Fatal("explored machine differs from mod-three")
+	}
+	if m.Discovered() != nil || m.MemoStats() != (MemoStats{}) {
+		t.Fatal("untracked machine reports state")
+	}
+	if _, err := NewLazyDFA[int, rune](nil, 0, nil, nil, LazyOptions{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("nil delta: got %v", err)
+	}
+}
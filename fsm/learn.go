@@ -0,0 +1,297 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"unsafe"
+)
+
+// ---------- Learning: blue-fringe EDSM ----------
+
+// Labels of prefix-tree nodes.
+const (
+	labelUnknown int8 = iota
+	labelAccept
+	labelReject
+)
+
+// apta is an augmented prefix tree acceptor that is folded in place while
+// learning. Every change is journaled so trial merges can be rolled back.
+type apta[Sigma comparable] struct {
+	alphabet []Sigma
+	next     []map[Sigma]int
+	label    []int8
+	journal  []aptaChange[Sigma]
+}
+
+// memory estimates the bytes held by the tree; every node but the root
+// has one incoming edge.
+func (t *apta[Sigma]) memory() int64 {
+	var a Sigma
+	n := len(t.label)
+	edge := float64(unsafe.Sizeof(a)+unsafe.Sizeof(n)+1) / mapLoadFactor
+	return int64(n*(mapHeaderBytes+1) + int(float64(n-1)*edge))
+}
+
+// blueNode is a fringe node together with the red edge that leads to it.
+type blueNode[Sigma comparable] struct {
+	node   int
+	parent int
+	via    Sigma
+}
+
+// aptaChange records one mutation for rollback.
+type aptaChange[Sigma comparable] struct {
+	node     int
+	isLabel  bool
+	oldLabel int8
+	sym      Sigma
+	old      int
+	had      bool
+}
+
+func (t *apta[Sigma]) addNode() int {
+	t.next = append(t.next, make(map[Sigma]int))
+	t.label = append(t.label, labelUnknown)
+	return len(t.label) - 1
+}
+
+// insert walks (and extends) the tree along word and labels the last node.
+func (t *apta[Sigma]) insert(word []Sigma, label int8) error {
+	n := 0
+	for _, a := range word {
+		c, ok := t.next[n][a]
+		if !ok {
+			c = t.addNode()
+			t.next[n][a] = c
+		}
+		n = c
+	}
+	if t.label[n] != labelUnknown && t.label[n] != label {
+		return fmt.Errorf("%w: sample %v is both positive and negative", ErrInvalidInput, word)
+	}
+	t.label[n] = label
+	return nil
+}
+
+func (t *apta[Sigma]) setLabel(n int, l int8) {
+	t.journal = append(t.journal, aptaChange[Sigma]{node: n, isLabel: true, oldLabel: t.label[n]})
+	t.label[n] = l
+}
+
+func (t *apta[Sigma]) setNext(n int, a Sigma, c int) {
+	old, had := t.next[n][a]
+	t.journal = append(t.journal, aptaChange[Sigma]{node: n, sym: a, old: old, had: had})
+	t.next[n][a] = c
+}
+
+// rollback undoes journaled changes back to mark.
+func (t *apta[Sigma]) rollback(mark int) {
+	for i := len(t.journal) - 1; i >= mark; i-- {
+		ch := t.journal[i]
+		switch {
+		case ch.isLabel:
+			t.label[ch.node] = ch.oldLabel
+		case ch.had:
+			t.next[ch.node][ch.sym] = ch.old
+		default:
+			delete(t.next[ch.node], ch.sym)
+		}
+	}
+	t.journal = t.journal[:mark]
+}
+
+// merge redirects blue node b into red node r and folds b's subtree into the
+// machine. It returns the EDSM evidence score (the number of labelled nodes
+// that agree) or false on a label conflict. The caller rolls back on failure.
+func (t *apta[Sigma]) merge(r int, b blueNode[Sigma]) (int, bool) {
+	t.setNext(b.parent, b.via, r)
+	return t.fold(r, b.node)
+}
+
+func (t *apta[Sigma]) fold(r, b int) (int, bool) {
+	score := 0
+	if lb := t.label[b]; lb != labelUnknown {
+		switch t.label[r] {
+		case labelUnknown:
+			t.setLabel(r, lb)
+		case lb:
+			score++
+		default:
+			return 0, false
+		}
+	}
+	for _, a := range t.alphabet {
+		c, ok := t.next[b][a]
+		if !ok {
+			continue
+		}
+		if rc, ok := t.next[r][a]; ok {
+			s, ok := t.fold(rc, c)
+			if !ok {
+				return 0, false
+			}
+			score += s
+		} else {
+			t.setNext(r, a, c)
+		}
+	}
+	return score, true
+}
+
+// LearnEDSM infers a DFA consistent with labelled samples using the
+// blue-fringe evidence-driven state-merging heuristic. It starts from the
+// prefix tree of all samples and repeatedly performs the merge of a fringe
+// ("blue") node into the core ("red") with the most agreeing labels,
+// promoting blue nodes that cannot be merged anywhere. Unlike exact
+// identification it never backtracks, so it scales to large sample sets.
+//
+// The alphabet order fixes the tie-breaking, so results are deterministic.
+// States of the result are numbered in breadth-first order from 0; missing
+// transitions mean no sample exercised them.
+func LearnEDSM[Sigma comparable](alphabet []Sigma, positive, negative [][]Sigma) (*DFA[int, Sigma], error) {
+	return LearnEDSMBudget(alphabet, positive, negative, Budget{})
+}
+
+// LearnEDSMBudget is LearnEDSM under resource limits. MaxStates and
+// MaxMemory bound the prefix tree built from the samples; Timeout bounds
+// the whole run. A *LimitError counts samples inserted (while building the
+// tree) or merges performed (while folding it) as Processed; progress is
+// reported in the phases "build" and "merge".
+func LearnEDSMBudget[Sigma comparable](alphabet []Sigma, positive, negative [][]Sigma, budget Budget) (*DFA[int, Sigma], error) {
+	tracker := budget.track()
+	t := &apta[Sigma]{alphabet: alphabet}
+	t.addNode()
+
+	sigma := NewSet(alphabet...)
+	inserted := 0
+	for _, samples := range []struct {
+		words [][]Sigma
+		label int8
+	}{{positive, labelAccept}, {negative, labelReject}} {
+		for _, w := range samples.words {
+			for _, a := range w {
+				if !sigma.Has(a) {
+					return nil, fmt.Errorf("%w: sample symbol %v not in Σ", ErrInvalidInput, a)
+				}
+			}
+			if err := t.insert(w, samples.label); err != nil {
+				return nil, err
+			}
+			inserted++
+			remaining := len(positive) + len(negative) - inserted
+			if err := tracker.step("build", len(t.label), inserted, remaining, t.memory()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	red := map[int]bool{0: true}
+	merges := 0
+	for {
+		blue := t.fringe(red)
+		if err := tracker.step("merge", len(t.label), merges, len(blue), t.memory()); err != nil {
+			return nil, err
+		}
+		if len(blue) == 0 {
+			break
+		}
+		bestScore, bestR, bestB := -1, -1, blue[0]
+		promoted := false
+		for _, b := range blue {
+			canMerge := false
+			for _, r := range sortedKeys(red) {
+				mark := len(t.journal)
+				s, ok := t.merge(r, b)
+				t.rollback(mark)
+				if !ok {
+					continue
+				}
+				canMerge = true
+				if s > bestScore {
+					bestScore, bestR, bestB = s, r, b
+				}
+			}
+			if !canMerge {
+				red[b.node] = true
+				promoted = true
+				break
+			}
+		}
+		if promoted {
+			continue
+		}
+		t.merge(bestR, bestB)
+		t.journal = t.journal[:0]
+		merges++
+	}
+	d := t.toDFA()
+	if err := tracker.done("merge", len(d.Q), merges); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// fringe returns the blue nodes: children of red nodes that are not red.
+// Blue nodes head untouched subtrees, so each has exactly one red parent.
+func (t *apta[Sigma]) fringe(red map[int]bool) []blueNode[Sigma] {
+	var blue []blueNode[Sigma]
+	for _, r := range sortedKeys(red) {
+		for _, a := range t.alphabet {
+			if c, ok := t.next[r][a]; ok && !red[c] {
+				blue = append(blue, blueNode[Sigma]{node: c, parent: r, via: a})
+			}
+		}
+	}
+	sort.Slice(blue, func(i, j int) bool { return blue[i].node < blue[j].node })
+	return blue
+}
+
+// toDFA renumbers the nodes reachable from the root in BFS order.
+func (t *apta[Sigma]) toDFA() *DFA[int, Sigma] {
+	ids := map[int]int{0: 0}
+	order := []int{0}
+	for i := 0; i < len(order); i++ {
+		for _, a := range t.alphabet {
+			if c, ok := t.next[order[i]][a]; ok {
+				if _, seen := ids[c]; !seen {
+					ids[c] = len(order)
+					order = append(order, c)
+				}
+			}
+		}
+	}
+	states := make([]int, len(order))
+	var finals []int
+	delta := make(TransitionFn[int, Sigma], len(order))
+	for i, n := range order {
+		states[i] = i
+		if t.label[n] == labelAccept {
+			finals = append(finals, i)
+		}
+		row := make(map[Sigma]int)
+		for _, a := range t.alphabet {
+			if c, ok := t.next[n][a]; ok {
+				row[a] = ids[c]
+			}
+		}
+		delta[i] = row
+	}
+	return &DFA[int, Sigma]{
+		Q:     NewSet(states...),
+		Sigma: NewSet(t.alphabet...),
+		Q0:    0,
+		F:     NewSet(finals...),
+		Delta: delta,
+	}
+}
+
+// sortedKeys returns the keys of an int-keyed map in increasing order.
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
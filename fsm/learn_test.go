@@ -0,0 +1,102 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// allWords returns every word over alphabet of length 0..n.
+func allWords[Sigma comparable](alphabet []Sigma, n int) [][]Sigma {
+	out := [][]Sigma{{}}
+	frontier := [][]Sigma{{}}
+	for l := 0; l < n; l++ {
+		var next [][]Sigma
+		for _, w := range frontier {
+			for _, a := range alphabet {
+				ext := append(append([]Sigma{}, w...), a)
+				next = append(next, ext)
+			}
+		}
+		out = append(out, next...)
+		frontier = next
+	}
+	return out
+}
+
+// TestLearnEDSM_ModThree learns divisibility by 3 from a complete sample.
+func TestLearnEDSM_ModThree(t *testing.T) {
+	ref := buildModThree()
+	var pos, neg [][]Bit
+	for _, w := range allWords([]Bit{Zero, One}, 6) {
+		if q, _ := ref.Run(w); q == S0 {
+			pos = append(pos, w)
+		} else {
+			neg = append(neg, w)
+		}
+	}
+
+	d, err := LearnEDSM([]Bit{Zero, One}, pos, neg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Q) != 3 {
+		t.Fatalf("expected 3 states, got %d", len(d.Q))
+	}
+	for _, w := range allWords([]Bit{Zero, One}, 9) {
+		got, _, _ := d.Accepts(w)
+		q, _ := ref.Run(w)
+		if got != (q == S0) {
+			t.Fatalf("%v: learned=%v, want %v", w, got, q == S0)
+		}
+	}
+}
+
+// TestLearnEDSM_Conflict rejects a word labelled both ways.
+func TestLearnEDSM_Conflict(t *testing.T) {
+	w := []Bit{One}
+	if _, err := LearnEDSM([]Bit{Zero, One}, [][]Bit{w}, [][]Bit{w}); err == nil {
+		t.Fatal("expected error for contradictory samples")
+	}
+}
+
+// TestLearnEDSMBudget stops on each kind of limit with progress details.
+func TestLearnEDSMBudget(t *testing.T) {
+	ref := buildModThree()
+	var pos, neg [][]Bit
+	for _, w := range allWords([]Bit{Zero, One}, 6) {
+		if q, _ := ref.Run(w); q == S0 {
+			pos = append(pos, w)
+		} else {
+			neg = append(neg, w)
+		}
+	}
+	alphabet := []Bit{Zero, One}
+
+	_, err := LearnEDSMBudget(alphabet, pos, neg, Budget{MaxStates: 20})
+	var le *LimitError
+	if !errors.As(err, &le) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("got %v, want a *LimitError", err)
+	}
+	if le.Limit != "states" || le.States <= 20 || le.Processed == 0 {
+		t.Fatalf("limit error = %+v", le)
+	}
+
+	if _, err := LearnEDSMBudget(alphabet, pos, neg, Budget{MaxMemory: 1000}); !errors.As(err, &le) || le.Limit != "memory" {
+		t.Fatalf("got %v, want a memory limit", err)
+	}
+	if _, err := LearnEDSMBudget(alphabet, pos, neg, Budget{Timeout: time.Nanosecond}); !errors.As(err, &le) || le.Limit != "time" {
+		t.Fatalf("got %v, want a time limit", err)
+	}
+	phases := map[string]int{}
+	progress := func(p Progress) error {
+		phases[p.Phase]++
+		return nil
+	}
+	if _, err := LearnEDSMBudget(alphabet, pos, neg, Budget{MaxStates: 1 << 10, OnProgress: progress}); err != nil {
+		t.Fatalf("generous budget: %v", err)
+	}
+	if phases["build"] != len(pos)+len(neg) || phases["merge"] == 0 {
+		t.Fatalf("progress reports per phase = %v", phases)
+	}
+}
@@ -0,0 +1,124 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ---------- Resource limits ----------
+
+// ErrLimitExceeded is matched by every *LimitError via errors.Is.
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// Budget bounds the resources of algorithms whose state space can explode
+// on adversarial input, and optionally reports their progress. Zero fields
+// are unlimited, so the zero Budget imposes no limits.
+type Budget struct {
+	MaxStates int           // states (or tree nodes) built
+	MaxMemory int64         // estimated bytes held by the construction
+	Timeout   time.Duration // wall time
+
+	// OnProgress, if set, is called as the algorithm advances and once more
+	// with Done set when it finishes. Returning an error aborts the
+	// algorithm, which returns that error; use it for custom cancellation.
+	OnProgress func(Progress) error
+	// ProgressInterval is the minimum time between OnProgress calls
+	// (the final call is always made). Zero reports every step.
+	ProgressInterval time.Duration
+}
+
+// Progress is a snapshot of a long-running algorithm.
+type Progress struct {
+	Phase     string // e.g. "build", "merge", "refine"
+	States    int    // states built so far
+	Processed int    // algorithm-specific units of work completed
+	Frontier  int    // work items waiting
+	Memory    int64  // estimated bytes held now
+	Peak      int64  // highest Memory so far; on the Done report, of the whole run
+	Elapsed   time.Duration
+	Done      bool
+}
+
+// LimitError reports which limit stopped an algorithm and how far it got.
+type LimitError struct {
+	Limit     string // "states", "memory" or "time"
+	States    int    // states built so far
+	Processed int    // algorithm-specific units of work completed
+	Memory    int64  // estimated bytes at the time
+	Peak      int64  // highest estimate seen before the limit was hit
+	Elapsed   time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded after %v: %d states, %d processed, ~%d bytes",
+		e.Limit, e.Elapsed.Round(time.Millisecond), e.States, e.Processed, e.Memory)
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) true.
+func (e *LimitError) Is(target error) bool { return target == ErrLimitExceeded }
+
+// budgetTracker measures an algorithm run against a Budget.
+type budgetTracker struct {
+	b        Budget
+	start    time.Time
+	reported time.Time
+	peak     int64 // highest memory estimate passed to check
+}
+
+func (b Budget) track() *budgetTracker {
+	return &budgetTracker{b: b, start: time.Now()}
+}
+
+// check returns a *LimitError if any limit is exceeded, and records the
+// peak memory estimate.
+func (t *budgetTracker) check(states, processed int, memory int64) error {
+	if memory > t.peak {
+		t.peak = memory
+	}
+	limit := ""
+	elapsed := time.Since(t.start)
+	switch {
+	case t.b.MaxStates > 0 && states > t.b.MaxStates:
+		limit = "states"
+	case t.b.MaxMemory > 0 && memory > t.b.MaxMemory:
+		limit = "memory"
+	case t.b.Timeout > 0 && elapsed > t.b.Timeout:
+		limit = "time"
+	default:
+		return nil
+	}
+	return &LimitError{Limit: limit, States: states, Processed: processed, Memory: memory, Peak: t.peak, Elapsed: elapsed}
+}
+
+// step checks the limits and reports progress. A nil tracker does nothing.
+func (t *budgetTracker) step(phase string, states, processed, frontier int, memory int64) error {
+	if t == nil {
+		return nil
+	}
+	if err := t.check(states, processed, memory); err != nil {
+		return err
+	}
+	return t.report(Progress{Phase: phase, States: states, Processed: processed, Frontier: frontier, Memory: memory, Peak: t.peak}, false)
+}
+
+// done makes the final progress report.
+func (t *budgetTracker) done(phase string, states, processed int) error {
+	if t == nil {
+		return nil
+	}
+	return t.report(Progress{Phase: phase, States: states, Processed: processed, Peak: t.peak, Done: true}, true)
+}
+
+func (t *budgetTracker) report(p Progress, force bool) error {
+	if t.b.OnProgress == nil {
+		return nil
+	}
+	now := time.Now()
+	if !force && !t.reported.IsZero() && now.Sub(t.reported) < t.b.ProgressInterval {
+		return nil
+	}
+	t.reported = now
+	p.Elapsed = now.Sub(t.start)
+	return t.b.OnProgress(p)
+}
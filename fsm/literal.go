@@ -0,0 +1,71 @@
+package fsm
+
+// ---------- String-literal helpers for rune/byte machines ----------
+
+// OnAny builds a δ row for a rune alphabet that sends every rune of chars
+// to next. Combine rows with MergeRows:
+//
+//	delta := fsm.TransitionFn[string, rune]{
+//		"idle": fsm.MergeRows(
+//			fsm.OnAny("0123456789", "number"),
+//			fsm.OnAny(" \t", "idle"),
+//		),
+//	}
+func OnAny[Q comparable](chars string, next Q) map[rune]Q {
+	row := make(map[rune]Q, len(chars))
+	for _, r := range chars {
+		row[r] = next
+	}
+	return row
+}
+
+// OnAnyByte is OnAny for byte alphabets; chars is taken byte by byte.
+func OnAnyByte[Q comparable](chars string, next Q) map[byte]Q {
+	row := make(map[byte]Q, len(chars))
+	for i := 0; i < len(chars); i++ {
+		row[chars[i]] = next
+	}
+	return row
+}
+
+// MergeRows combines δ rows into one; later rows win on conflicting symbols.
+func MergeRows[Q comparable, Sigma comparable](rows ...map[Sigma]Q) map[Sigma]Q {
+	out := make(map[Sigma]Q)
+	for _, row := range rows {
+		for a, q := range row {
+			out[a] = q
+		}
+	}
+	return out
+}
+
+// Runes returns the distinct runes of each string, in order of first
+// appearance, for declaring rune alphabets: fsm.Runes("0123456789", " \t").
+func Runes(sets ...string) []rune {
+	seen := make(map[rune]bool)
+	var out []rune
+	for _, s := range sets {
+		for _, r := range s {
+			if !seen[r] {
+				seen[r] = true
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// Bytes is Runes for byte alphabets.
+func Bytes(sets ...string) []byte {
+	var seen [256]bool
+	var out []byte
+	for _, s := range sets {
+		for i := 0; i < len(s); i++ {
+			if !seen[s[i]] {
+				seen[s[i]] = true
+				out = append(out, s[i])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package fsm
+
+import "testing"
+
+// TestOnAny builds a small number lexer from string literals.
+func TestOnAny(t *testing.T) {
+	const digits = "0123456789"
+	delta := TransitionFn[string, rune]{
+		"idle": MergeRows(
+			OnAny(digits, "number"),
+			OnAny(" \t", "idle"),
+		),
+		"number": MergeRows(
+			OnAny(digits, "number"),
+			OnAny(" \t", "idle"),
+		),
+	}
+	d, err := NewDFA([]string{"idle", "number"}, Runes(digits, " \t"), "idle", []string{"number"}, delta, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]bool{"  42": true, "7 ": false, "": false} {
+		if got, _, err := d.Accepts([]rune(in)); err != nil || got != want {
+			t.Fatalf("%q: got %v,%v want %v", in, got, err, want)
+		}
+	}
+}
+
+// TestMergeRows_LaterWins checks override order and byte helpers.
+func TestMergeRows_LaterWins(t *testing.T) {
+	row := MergeRows(OnAnyByte("ab", 1), OnAnyByte("b", 2))
+	if row['a'] != 1 || row['b'] != 2 || len(row) != 2 {
+		t.Fatalf("row = %v", row)
+	}
+	if got := string(Bytes("abc", "cba", "d")); got != "abcd" {
+		t.Fatalf("Bytes = %q", got)
+	}
+}
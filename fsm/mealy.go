@@ -0,0 +1,89 @@
+package fsm
+
+// ---------- Mealy machines ----------
+
+// MealyEdge is one transition of a Mealy machine: the next state and the
+// output emitted on the way.
+type MealyEdge[Q comparable, Out any] struct {
+	Next Q
+	Out  Out
+}
+
+// MealyFn encodes δ and the output function together.
+// Example: delta[q][symbol] = MealyEdge{Next: q2, Out: "x"}
+type MealyFn[Q comparable, Sigma comparable, Out any] map[Q]map[Sigma]MealyEdge[Q, Out]
+
+// Mealy is a transducer whose transitions emit outputs, for encoders and
+// protocol translators. Its state graph is an ordinary DFA (with every
+// state accepting), so the analyses of this package apply to it.
+type Mealy[Q comparable, Sigma comparable, Out any] struct {
+	DFA    *DFA[Q, Sigma]
+	Output map[Q]map[Sigma]Out
+}
+
+// NewMealy builds and validates a Mealy machine as NewDFA does.
+func NewMealy[Q comparable, Sigma comparable, Out any](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	delta MealyFn[Q, Sigma, Out],
+	requireComplete bool,
+) (*Mealy[Q, Sigma, Out], error) {
+	next := make(TransitionFn[Q, Sigma], len(delta))
+	output := make(map[Q]map[Sigma]Out, len(delta))
+	for q, row := range delta {
+		next[q] = make(map[Sigma]Q, len(row))
+		output[q] = make(map[Sigma]Out, len(row))
+		for a, e := range row {
+			next[q][a] = e.Next
+			output[q][a] = e.Out
+		}
+	}
+	d, err := NewDFA(states, alphabet, q0, states, next, requireComplete)
+	if err != nil {
+		return nil, err
+	}
+	return &Mealy[Q, Sigma, Out]{DFA: d, Output: output}, nil
+}
+
+// Step applies one transition and returns the next state and its output.
+func (m *Mealy[Q, Sigma, Out]) Step(q Q, a Sigma) (Q, Out, error) {
+	qNext, err := m.DFA.Step(q, a)
+	if err != nil {
+		var zero Out
+		return q, zero, err
+	}
+	return qNext, m.Output[q][a], nil
+}
+
+// Run translates input from q0 and returns one output per symbol and the
+// final state. On an undefined transition it returns the outputs so far,
+// the state reached, and a *RunError naming the position.
+func (m *Mealy[Q, Sigma, Out]) Run(input []Sigma) ([]Out, Q, error) {
+	out := make([]Out, 0, len(input))
+	q := m.DFA.Q0
+	for i, a := range input {
+		qNext, o, err := m.Step(q, a)
+		if err != nil {
+			return out, q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
+		}
+		q = qNext
+		out = append(out, o)
+	}
+	return out, q, nil
+}
+
+// Stage returns the machine as a pipeline stage starting at q0, emitting
+// one output per symbol. The stage keeps the current state, so use a
+// fresh one per stream.
+func (m *Mealy[Q, Sigma, Out]) Stage() Stage[Sigma, Out] {
+	q := m.DFA.Q0
+	return StageFunc[Sigma, Out](func(a Sigma, emit func(Out) error) error {
+		qNext, o, err := m.Step(q, a)
+		if err != nil {
+			return err
+		}
+		q = qNext
+		return emit(o)
+	})
+}
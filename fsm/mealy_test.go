@@ -0,0 +1,44 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// nrzi encodes bits as level changes: a 1 toggles the output level.
+func nrzi() *Mealy[Bit, Bit, Bit] {
+	return Must(NewMealy([]Bit{Zero, One}, []Bit{Zero, One}, Zero, MealyFn[Bit, Bit, Bit]{
+		Zero: {Zero: {Zero, Zero}, One: {One, One}},
+		One:  {Zero: {One, One}, One: {Zero, Zero}},
+	}, true))
+}
+
+func TestMealyRun(t *testing.T) {
+	out, q, err := nrzi().Run([]Bit("1101"))
+	if err != nil || string(out) != "1001" || q != One {
+		t.Fatalf("got %q, %v, %v", string(out), q, err)
+	}
+	staged, err := Collect(nrzi().Stage(), []Bit("1101"))
+	if err != nil || !reflect.DeepEqual(staged, out) {
+		t.Errorf("stage output %q, want %q", string(staged), string(out))
+	}
+}
+
+func TestMealyPartial(t *testing.T) {
+	m := Must(NewMealy([]int{0, 1}, []rune("ab"), 0, MealyFn[int, rune, string]{
+		0: {'a': {1, "x"}},
+		1: {'b': {0, "y"}},
+	}, false))
+	out, q, err := m.Run([]rune("abb"))
+	var re *RunError[int, rune]
+	if !errors.As(err, &re) || re.Index != 2 || q != 0 || !reflect.DeepEqual(out, []string{"x", "y"}) {
+		t.Fatalf("got %v, %v, %v", out, q, err)
+	}
+	if !errors.Is(err, ErrUndefined) {
+		t.Fatalf("%v does not match ErrUndefined", err)
+	}
+	if _, err := NewMealy([]int{0}, []rune("a"), 0, MealyFn[int, rune, string]{0: {'a': {2, ""}}}, false); err == nil {
+		t.Error("edge to an unknown state accepted")
+	}
+}
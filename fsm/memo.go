@@ -0,0 +1,100 @@
+package fsm
+
+import (
+	"container/list"
+	"sync"
+)
+
+// ---------- Memoized transition functions ----------
+
+// DeltaFunc computes a transition instead of looking it up: it returns
+// δ(q,a) and whether it is defined.
+type DeltaFunc[Q comparable, Sigma comparable] func(q Q, a Sigma) (Q, bool)
+
+// MemoStats reports the effectiveness of a MemoDelta.
+type MemoStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int // entries currently cached
+}
+
+// MemoDelta caches the results of an expensive DeltaFunc, keeping at most
+// a fixed number of (state, symbol) entries and evicting the least recently
+// used. Undefined transitions are cached too. It is safe for concurrent use;
+// fn may be called concurrently for different keys.
+type MemoDelta[Q comparable, Sigma comparable] struct {
+	fn       DeltaFunc[Q, Sigma]
+	capacity int
+
+	mu      sync.Mutex
+	entries map[Edge[Q, Sigma]]*list.Element
+	lru     *list.List // front is most recent
+	stats   MemoStats
+}
+
+type memoEntry[Q comparable, Sigma comparable] struct {
+	key  Edge[Q, Sigma]
+	next Q
+	ok   bool
+}
+
+// NewMemoDelta wraps fn with a cache of up to capacity entries. A
+// capacity of 0 or less means unbounded.
+func NewMemoDelta[Q comparable, Sigma comparable](fn DeltaFunc[Q, Sigma], capacity int) *MemoDelta[Q, Sigma] {
+	return &MemoDelta[Q, Sigma]{
+		fn:       fn,
+		capacity: capacity,
+		entries:  make(map[Edge[Q, Sigma]]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Step returns δ(q,a), computing it only on a cache miss. The method value
+// m.Step is itself a DeltaFunc.
+func (m *MemoDelta[Q, Sigma]) Step(q Q, a Sigma) (Q, bool) {
+	key := Edge[Q, Sigma]{From: q, On: a}
+	m.mu.Lock()
+	if el, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(el)
+		m.stats.Hits++
+		e := el.Value.(*memoEntry[Q, Sigma])
+		m.mu.Unlock()
+		return e.next, e.ok
+	}
+	m.stats.Misses++
+	m.mu.Unlock()
+
+	next, ok := m.fn(q, a)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, raced := m.entries[key]; !raced {
+		m.entries[key] = m.lru.PushFront(&memoEntry[Q, Sigma]{key: key, next: next, ok: ok})
+		if m.capacity > 0 && m.lru.Len() > m.capacity {
+			oldest := m.lru.Back()
+			m.lru.Remove(oldest)
+			delete(m.entries, oldest.Value.(*memoEntry[Q, Sigma]).key)
+			m.stats.Evictions++
+		}
+	}
+	return next, ok
+}
+
+// Stats returns the hit, miss and eviction counts so far.
+func (m *MemoDelta[Q, Sigma]) Stats() MemoStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats
+	s.Size = m.lru.Len()
+	return s
+}
+
+// Reset empties the cache and zeroes the statistics.
+func (m *MemoDelta[Q, Sigma]) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[Edge[Q, Sigma]]*list.Element)
+	m.lru.Init()
+	m.stats = MemoStats{}
+}
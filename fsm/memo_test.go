@@ -0,0 +1,36 @@
+package fsm
+
+import "testing"
+
+// TestMemoDelta checks hits, misses and LRU eviction.
+func TestMemoDelta(t *testing.T) {
+	calls := 0
+	mod3 := func(q int, b Bit) (int, bool) {
+		calls++
+		if b != Zero && b != One {
+			return 0, false
+		}
+		return (2*q + int(b-Zero)) % 3, true
+	}
+	m := NewMemoDelta[int, Bit](mod3, 2)
+
+	if q, ok := m.Step(1, One); !ok || q != 0 {
+		t.Fatalf("δ(1,1) = %v,%v", q, ok)
+	}
+	m.Step(1, One)
+	m.Step(2, Zero)
+	if _, ok := m.Step(0, 'x'); ok {
+		t.Fatal("undefined transition reported as defined")
+	}
+	m.Step(0, 'x')
+	m.Step(1, One) // evicted by the two newer keys
+	want := MemoStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2}
+	if got := m.Stats(); got != want || calls != 4 {
+		t.Fatalf("stats = %+v after %d calls, want %+v", got, calls, want)
+	}
+
+	m.Reset()
+	if got := m.Stats(); got != (MemoStats{}) {
+		t.Fatalf("after Reset: %+v", got)
+	}
+}
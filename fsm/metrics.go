@@ -0,0 +1,72 @@
+package fsm
+
+// ---------- Graph metrics ----------
+
+// Distances returns the length of a shortest input leading from q0 to each
+// reachable state. Unreachable states are absent.
+func (d *DFA[Q, Sigma]) Distances() map[Q]int {
+	return d.distancesFrom(d.Q0)
+}
+
+// distancesFrom runs a breadth-first search from q.
+func (d *DFA[Q, Sigma]) distancesFrom(q Q) map[Q]int {
+	dist := map[Q]int{q: 0}
+	queue := []Q{q}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for a := range d.Sigma {
+			if pNext, ok := d.next(p, a); ok {
+				if _, seen := dist[pNext]; !seen {
+					dist[pNext] = dist[p] + 1
+					queue = append(queue, pNext)
+				}
+			}
+		}
+	}
+	return dist
+}
+
+// Depth returns the largest distance from q0 to a reachable state: every
+// reachable state is reached by some input of at most this length.
+func (d *DFA[Q, Sigma]) Depth() int {
+	depth := 0
+	for _, n := range d.Distances() {
+		if n > depth {
+			depth = n
+		}
+	}
+	return depth
+}
+
+// Diameter returns the largest shortest-path distance between two reachable
+// states p and q such that q is reachable from p. It costs one breadth-first
+// search per reachable state.
+func (d *DFA[Q, Sigma]) Diameter() int {
+	diameter := 0
+	for q := range d.reachable() {
+		for _, n := range d.distancesFrom(q) {
+			if n > diameter {
+				diameter = n
+			}
+		}
+	}
+	return diameter
+}
+
+// AcceptDepth returns the largest, over the reachable accepting states, of
+// the length of a shortest input reaching that state: tests that feed every
+// input up to this length visit every reachable state in F. The second
+// result is false when no accepting state is reachable.
+func (d *DFA[Q, Sigma]) AcceptDepth() (int, bool) {
+	depth, found := 0, false
+	for q, n := range d.Distances() {
+		if d.F.Has(q) {
+			found = true
+			if n > depth {
+				depth = n
+			}
+		}
+	}
+	return depth, found
+}
@@ -0,0 +1,39 @@
+package fsm
+
+import "testing"
+
+// TestDistances measures mod-three and a chain with an unreachable state.
+func TestDistances(t *testing.T) {
+	d := buildModThree()
+	dist := d.Distances()
+	if dist[S0] != 0 || dist[S1] != 1 || dist[S2] != 2 {
+		t.Fatalf("Distances = %v", dist)
+	}
+	if d.Depth() != 2 {
+		t.Fatalf("Depth = %d, want 2", d.Depth())
+	}
+	// S1 -> S0 and S2 -> S1 -> S0 take 2 steps from S2.
+	if d.Diameter() != 2 {
+		t.Fatalf("Diameter = %d, want 2", d.Diameter())
+	}
+	if n, ok := d.AcceptDepth(); !ok || n != 2 {
+		t.Fatalf("AcceptDepth = %d,%v want 2,true", n, ok)
+	}
+
+	chain := Must(NewDFA([]int{0, 1, 2, 3, 9}, []Bit{Zero}, 0, []int{1, 9},
+		TransitionFn[int, Bit]{0: {Zero: 1}, 1: {Zero: 2}, 2: {Zero: 3}}, false))
+	if _, ok := chain.Distances()[9]; ok {
+		t.Fatal("unreachable state should have no distance")
+	}
+	if chain.Depth() != 3 || chain.Diameter() != 3 {
+		t.Fatalf("Depth, Diameter = %d, %d; want 3, 3", chain.Depth(), chain.Diameter())
+	}
+	if n, ok := chain.AcceptDepth(); !ok || n != 1 {
+		t.Fatalf("AcceptDepth = %d,%v want 1,true", n, ok)
+	}
+
+	empty := Must(NewDFA([]int{0, 1}, []Bit{Zero}, 0, []int{1}, TransitionFn[int, Bit]{}, false))
+	if _, ok := empty.AcceptDepth(); ok {
+		t.Fatal("no accepting state is reachable")
+	}
+}
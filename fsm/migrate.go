@@ -0,0 +1,137 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ---------- Session migration ----------
+
+// ErrNoMigration is returned when no chain of registered migrations leads
+// from a persisted session's revision to the target machine.
+var ErrNoMigration = errors.New("no migration path")
+
+// PersistedSession is the durable form of a session: the revision (machine
+// fingerprint) it ran on, its state there, and optionally the symbols it has
+// consumed since q0, which a Replay migration re-runs on the new machine.
+type PersistedSession[Q comparable, Sigma comparable] struct {
+	Revision string  `json:"revision"`
+	State    Q       `json:"state"`
+	Journal  []Sigma `json:"journal,omitempty"`
+}
+
+// Migration maps sessions from one machine revision to the next. With
+// Replay set the journal is re-run from the new q0 and the mappings are
+// ignored. Otherwise a state is looked up in States, then passed to Map,
+// and finally kept as is if the new machine still has it.
+type Migration[Q comparable, Sigma comparable] struct {
+	States map[Q]Q
+	Map    func(old Q) (Q, bool)
+	Replay bool
+}
+
+type migrationEdge[Q comparable, Sigma comparable] struct {
+	to   string
+	rule Migration[Q, Sigma]
+}
+
+// Migrations is a registry of migrations between machine revisions. A
+// session several revisions behind is carried forward along the shortest
+// chain of registered migrations.
+type Migrations[Q comparable, Sigma comparable] struct {
+	machines map[string]*DFA[Q, Sigma]
+	edges    map[string][]migrationEdge[Q, Sigma]
+}
+
+// NewMigrations returns an empty migration registry.
+func NewMigrations[Q comparable, Sigma comparable]() *Migrations[Q, Sigma] {
+	return &Migrations[Q, Sigma]{
+		machines: make(map[string]*DFA[Q, Sigma]),
+		edges:    make(map[string][]migrationEdge[Q, Sigma]),
+	}
+}
+
+// Add registers rule for sessions moving from machine from to machine to.
+func (m *Migrations[Q, Sigma]) Add(from, to *DFA[Q, Sigma], rule Migration[Q, Sigma]) {
+	f, t := from.Fingerprint(), to.Fingerprint()
+	m.machines[f], m.machines[t] = from, to
+	m.edges[f] = append(m.edges[f], migrationEdge[Q, Sigma]{to: t, rule: rule})
+}
+
+// Migrate carries p forward to target. A session already on target is
+// returned unchanged.
+func (m *Migrations[Q, Sigma]) Migrate(p PersistedSession[Q, Sigma], target *DFA[Q, Sigma]) (PersistedSession[Q, Sigma], error) {
+	goal := target.Fingerprint()
+	if p.Revision == goal {
+		return p, nil
+	}
+	path, ok := m.path(p.Revision, goal)
+	if !ok {
+		return p, fmt.Errorf("%w from %.12s to %.12s", ErrNoMigration, p.Revision, goal)
+	}
+	m.machines[goal] = target
+	for _, e := range path {
+		next, err := e.rule.apply(p, m.machines[e.to])
+		if err != nil {
+			return p, fmt.Errorf("migrate %.12s -> %.12s: %w", p.Revision, e.to, err)
+		}
+		p = next
+	}
+	return p, nil
+}
+
+// path returns the shortest chain of migrations from one revision to
+// another, found breadth-first.
+func (m *Migrations[Q, Sigma]) path(from, to string) ([]migrationEdge[Q, Sigma], bool) {
+	type hop struct {
+		prev string
+		edge migrationEdge[Q, Sigma]
+	}
+	seen := map[string]hop{from: {}}
+	queue := []string{from}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		if r == to {
+			var out []migrationEdge[Q, Sigma]
+			for r != from {
+				h := seen[r]
+				out = append([]migrationEdge[Q, Sigma]{h.edge}, out...)
+				r = h.prev
+			}
+			return out, true
+		}
+		for _, e := range m.edges[r] {
+			if _, ok := seen[e.to]; !ok {
+				seen[e.to] = hop{prev: r, edge: e}
+				queue = append(queue, e.to)
+			}
+		}
+	}
+	return nil, false
+}
+
+// apply migrates p onto next.
+func (rule Migration[Q, Sigma]) apply(p PersistedSession[Q, Sigma], next *DFA[Q, Sigma]) (PersistedSession[Q, Sigma], error) {
+	out := PersistedSession[Q, Sigma]{Revision: next.Fingerprint(), Journal: p.Journal}
+	if rule.Replay {
+		q, err := next.Run(p.Journal)
+		if err != nil {
+			return p, fmt.Errorf("replay journal: %w", err)
+		}
+		out.State = q
+		return out, nil
+	}
+	q, ok := rule.States[p.State]
+	if !ok && rule.Map != nil {
+		q, ok = rule.Map(p.State)
+	}
+	if !ok {
+		q = p.State
+	}
+	if !next.Q.Has(q) {
+		return p, fmt.Errorf("%w: %v", ErrStaleSession, p.State)
+	}
+	out.State = q
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// parity is a two-state revision of mod-three that drops S2.
+func parity() *DFA[State, Bit] {
+	return Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S0},
+		TransitionFn[State, Bit]{S0: {Zero: S0, One: S1}, S1: {Zero: S1, One: S0}}, true))
+}
+
+// TestMigrations_Chain carries a session across two revisions, mapping on
+// the first hop and replaying the journal on the second.
+func TestMigrations_Chain(t *testing.T) {
+	v1, v2 := buildModThree(), parity()
+	v3 := Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S1},
+		TransitionFn[State, Bit]{S0: {Zero: S0, One: S1}, S1: {Zero: S1, One: S1}}, true))
+
+	m := NewMigrations[State, Bit]()
+	m.Add(v1, v2, Migration[State, Bit]{States: map[State]State{S2: S1}})
+	m.Add(v2, v3, Migration[State, Bit]{Replay: true})
+
+	journal := []Bit{One, Zero, Zero} // v1: S2
+	p := PersistedSession[State, Bit]{Revision: v1.Fingerprint(), State: S2, Journal: journal}
+	got, err := m.Migrate(p, v2)
+	if err != nil || got.State != S1 || got.Revision != v2.Fingerprint() {
+		t.Fatalf("v1 -> v2 = %+v, %v; want S1", got, err)
+	}
+	got, err = m.Migrate(p, v3)
+	if err != nil || got.State != S1 || got.Revision != v3.Fingerprint() || len(got.Journal) != 3 {
+		t.Fatalf("v1 -> v3 = %+v, %v; want S1 by replay", got, err)
+	}
+
+	if _, err := m.Migrate(PersistedSession[State, Bit]{Revision: v3.Fingerprint()}, v1); !errors.Is(err, ErrNoMigration) {
+		t.Fatalf("expected ErrNoMigration, got %v", err)
+	}
+}
+
+// TestMigrations_Stale rejects a state that neither maps nor survives.
+func TestMigrations_Stale(t *testing.T) {
+	v1, v2 := buildModThree(), parity()
+	m := NewMigrations[State, Bit]()
+	m.Add(v1, v2, Migration[State, Bit]{Map: func(q State) (State, bool) { return q, false }})
+	p := PersistedSession[State, Bit]{Revision: v1.Fingerprint(), State: S2}
+	if _, err := m.Migrate(p, v2); !errors.Is(err, ErrStaleSession) {
+		t.Fatalf("expected ErrStaleSession, got %v", err)
+	}
+	p.State = S1
+	if got, err := m.Migrate(p, v2); err != nil || got.State != S1 {
+		t.Fatalf("surviving state = %+v, %v", got, err)
+	}
+}
+
+// TestRegistry_Restore persists a session, redeploys, and restores it.
+func TestRegistry_Restore(t *testing.T) {
+	current := buildModThree()
+	r, err := NewRegistry(func() (*DFA[State, Bit], error) { return current, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.KeepJournal = true
+	s := r.Open()
+	s.Feed(One)
+	s.Feed(Zero)
+	saved := s.Persist()
+	if saved.State != S2 || len(saved.Journal) != 2 {
+		t.Fatalf("persisted %+v", saved)
+	}
+
+	old := current
+	current = parity()
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Restore(saved, nil); !errors.Is(err, ErrNoMigration) {
+		t.Fatalf("expected ErrNoMigration, got %v", err)
+	}
+	m := NewMigrations[State, Bit]()
+	m.Add(old, current, Migration[State, Bit]{Replay: true})
+	restored, err := r.Restore(saved, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, v, _ := restored.State(); q != S1 || v != 2 {
+		t.Fatalf("restored = (%v, %d), want (S1, 2)", q, v)
+	}
+	if q, _ := restored.Feed(One); q != S0 {
+		t.Fatalf("restored session should run on the new δ, got %v", q)
+	}
+}
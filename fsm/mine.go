@@ -0,0 +1,132 @@
+package fsm
+
+import "fmt"
+
+// ---------- Process mining ----------
+
+// Event is one entry of an event log: an activity observed in a case.
+type Event[Sigma comparable] struct {
+	Case     string
+	Activity Sigma
+}
+
+// MinedModel is a DFA summarizing an event log, annotated with how often
+// each part of it was observed. State 0 is the start of a case; every other
+// state stands for "the last activity was Activity[q]".
+type MinedModel[Sigma comparable] struct {
+	*DFA[int, Sigma]
+	Activity map[int]Sigma
+	Visits   map[int]int           // number of times each state was entered
+	Freq     map[int]map[Sigma]int // number of times each edge was taken
+	Ends     map[int]int           // number of cases that ended in each state
+	Cases    int
+}
+
+// MineLog builds a directly-follows model from an event log. Events are
+// grouped by case in log order. Edges (and case ends) whose share of their
+// source state's outgoing observations is below threshold are treated as
+// noise and dropped, along with states that become unreachable.
+func MineLog[Sigma comparable](log []Event[Sigma], threshold float64) (*MinedModel[Sigma], error) {
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("%w: threshold %v not in [0,1]", ErrInvalidInput, threshold)
+	}
+
+	// Group events by case, keeping first-appearance order.
+	var caseOrder []string
+	traces := make(map[string][]Sigma)
+	for _, e := range log {
+		if _, ok := traces[e.Case]; !ok {
+			caseOrder = append(caseOrder, e.Case)
+		}
+		traces[e.Case] = append(traces[e.Case], e.Activity)
+	}
+
+	// One state per activity, numbered by first appearance.
+	ids := make(map[Sigma]int)
+	var alphabet []Sigma
+	for _, c := range caseOrder {
+		for _, a := range traces[c] {
+			if _, ok := ids[a]; !ok {
+				alphabet = append(alphabet, a)
+				ids[a] = len(alphabet)
+			}
+		}
+	}
+
+	freq := make(map[int]map[Sigma]int)
+	ends := make(map[int]int)
+	for _, c := range caseOrder {
+		q := 0
+		for _, a := range traces[c] {
+			if freq[q] == nil {
+				freq[q] = make(map[Sigma]int)
+			}
+			freq[q][a]++
+			q = ids[a]
+		}
+		ends[q]++
+	}
+
+	// Drop noisy edges and ends.
+	delta := make(TransitionFn[int, Sigma])
+	finals := NewSet[int]()
+	for q := 0; q <= len(alphabet); q++ {
+		total := ends[q]
+		for _, n := range freq[q] {
+			total += n
+		}
+		if total == 0 {
+			continue
+		}
+		row := make(map[Sigma]int)
+		for a, n := range freq[q] {
+			if float64(n)/float64(total) >= threshold {
+				row[a] = ids[a]
+			}
+		}
+		delta[q] = row
+		if ends[q] > 0 && float64(ends[q])/float64(total) >= threshold {
+			finals[q] = struct{}{}
+		}
+	}
+
+	m := &MinedModel[Sigma]{
+		Activity: make(map[int]Sigma),
+		Visits:   make(map[int]int),
+		Freq:     make(map[int]map[Sigma]int),
+		Ends:     make(map[int]int),
+		Cases:    len(caseOrder),
+	}
+	d := &DFA[int, Sigma]{
+		Q:     NewSet(0),
+		Sigma: NewSet(alphabet...),
+		Q0:    0,
+		F:     NewSet[int](),
+		Delta: delta,
+	}
+	// Keep only what survives filtering.
+	for q := range d.reachable() {
+		d.Q[q] = struct{}{}
+		if finals.Has(q) {
+			d.F[q] = struct{}{}
+			m.Ends[q] = ends[q]
+		}
+		if q > 0 {
+			m.Activity[q] = alphabet[q-1]
+		}
+		kept := make(map[Sigma]int)
+		for a, next := range delta[q] {
+			kept[a] = freq[q][a]
+			m.Visits[next] += freq[q][a]
+		}
+		m.Freq[q] = kept
+	}
+	m.Visits[0] = m.Cases
+	for q := range delta {
+		if !d.Q.Has(q) {
+			delete(delta, q)
+		}
+	}
+	m.DFA = d
+	return m, nil
+}
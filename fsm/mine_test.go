@@ -0,0 +1,52 @@
+package fsm
+
+import "testing"
+
+// eventLog flattens cases, one after another in the given order, into a log.
+func eventLog(cases map[string]string, order ...string) []Event[rune] {
+	var log []Event[rune]
+	for _, c := range order {
+		for _, a := range cases[c] {
+			log = append(log, Event[rune]{Case: c, Activity: a})
+		}
+	}
+	return log
+}
+
+// TestMineLog builds a model and checks frequencies and acceptance.
+func TestMineLog(t *testing.T) {
+	log := eventLog(map[string]string{
+		"c1": "ab",
+		"c2": "ab",
+		"c3": "abb",
+		"c4": "ax", // rare path
+	}, "c1", "c2", "c3", "c4")
+
+	m, err := MineLog(log, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Cases != 4 || m.Freq[0]['a'] != 4 {
+		t.Fatalf("cases=%d freq(start,a)=%d", m.Cases, m.Freq[0]['a'])
+	}
+	for _, w := range []string{"ab", "abb", "abbb", "ax"} {
+		if ok, _, _ := m.Accepts([]rune(w)); !ok {
+			t.Fatalf("%q should be accepted", w)
+		}
+	}
+
+	// After "a", x is 1 of 4 observations: dropped at threshold 0.3.
+	m, err = MineLog(log, 0.3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := m.Accepts([]rune("ax")); ok {
+		t.Fatal("noisy path ax should be filtered")
+	}
+	if ok, _, _ := m.Accepts([]rune("ab")); !ok {
+		t.Fatal("ab should still be accepted")
+	}
+	if len(m.Q) != 3 {
+		t.Fatalf("expected 3 states after filtering, got %d", len(m.Q))
+	}
+}
@@ -0,0 +1,307 @@
+package fsm
+
+// ---------- Minimization ----------
+
+// Minimize returns the minimal DFA accepting the same language as d, using
+// Hopcroft's partition refinement. Unreachable states are dropped and the
+// states are renumbered 0..n-1 in breadth-first order from the start state
+// 0, so equal languages give Equal results.
+//
+// Totality is preserved: if every reachable state of d has a transition on
+// every symbol, the result is complete and keeps a single dead state when
+// one is needed. Otherwise the dead states are merged into the missing
+// transitions and the result is trim.
+func (d *DFA[Q, Sigma]) Minimize() *DFA[int, Sigma] {
+	m, _ := d.MinimizeBudget(Budget{})
+	return m
+}
+
+// MinimizeBudget is Minimize under a Budget. Progress is reported in the
+// phase "refine", with the current number of blocks as States and the
+// splitters processed as Processed. MaxMemory is not tracked, since the
+// refinement never holds more than a few words per state.
+func (d *DFA[Q, Sigma]) MinimizeBudget(budget Budget) (*DFA[int, Sigma], error) {
+	symbols := d.Sigma.sorted()
+	order := d.bfsOrder(symbols)[:len(d.reachable())]
+	index := make(map[Q]int, len(order))
+	for i, q := range order {
+		index[q] = i
+	}
+
+	// Dense δ over the reachable states, with an extra sink state n when
+	// some transition is missing.
+	n := len(order)
+	next := make([][]int, n)
+	complete := true
+	for i, q := range order {
+		next[i] = make([]int, len(symbols))
+		for k, a := range symbols {
+			if qNext, ok := d.next(q, a); ok {
+				next[i][k] = index[qNext]
+			} else {
+				next[i][k] = n
+				complete = false
+			}
+		}
+	}
+	total := n
+	if !complete {
+		total++
+		sink := make([]int, len(symbols))
+		for k := range sink {
+			sink[k] = n
+		}
+		next = append(next, sink)
+	}
+	final := make([]bool, total)
+	for i, q := range order {
+		final[i] = d.F.Has(q)
+	}
+
+	tracker := budget.track()
+	block, processed, err := hopcroft(next, final, len(symbols), tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	// Renumber the blocks breadth-first from the start block, skipping the
+	// sink's block unless the input was complete.
+	dead := -1
+	if !complete {
+		dead = block[n]
+	}
+	id := map[int]int{block[0]: 0}
+	rep := []int{0}
+	for i := 0; i < len(rep); i++ {
+		for k := range symbols {
+			b := block[next[rep[i]][k]]
+			if _, ok := id[b]; !ok && b != dead {
+				id[b] = len(rep)
+				rep = append(rep, next[rep[i]][k])
+			}
+		}
+	}
+
+	out := &DFA[int, Sigma]{
+		Q:     make(Set[int], len(rep)),
+		Sigma: copySet(d.Sigma),
+		Q0:    0,
+		F:     make(Set[int]),
+		Delta: make(TransitionFn[int, Sigma], len(rep)),
+	}
+	for i, s := range rep {
+		out.Q[i] = struct{}{}
+		if final[s] {
+			out.F[i] = struct{}{}
+		}
+		row := make(map[Sigma]int, len(symbols))
+		for k, a := range symbols {
+			// The start block is the dead block when the language is empty.
+			if b := block[next[s][k]]; b != dead {
+				row[a] = id[b]
+			}
+		}
+		out.Delta[i] = row
+	}
+	if err := tracker.done("refine", len(rep), processed); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MinimizeBrzozowski returns the same machine as Minimize, computed
+// independently by Brzozowski's algorithm: reverse, determinize, reverse,
+// determinize. It exists to cross-check Minimize, e.g. in property tests
+// asserting d.MinimizeBrzozowski().Equal(d.Minimize()); the subset
+// constructions can take exponential time.
+func (d *DFA[Q, Sigma]) MinimizeBrzozowski() *DFA[int, Sigma] {
+	m := d.reverseDeterminize().reverseDeterminize()
+
+	// The subset construction drops the dead state; put it back when d is
+	// complete, as Minimize keeps it. A state of m without an accepting
+	// state ahead can only be a start state of an empty language.
+	complete := true
+	for q := range d.reachable() {
+		for a := range d.Sigma {
+			if _, ok := d.next(q, a); !ok {
+				complete = false
+			}
+		}
+	}
+	missing := false
+	for q := range m.Q {
+		if len(m.Delta[q]) < len(m.Sigma) {
+			missing = true
+		}
+	}
+	if complete && missing {
+		sink := m.Q0
+		if len(m.F) > 0 {
+			sink = len(m.Q)
+			m.Q[sink] = struct{}{}
+			m.Delta[sink] = make(map[Sigma]int, len(m.Sigma))
+		}
+		for q := range m.Q {
+			for a := range m.Sigma {
+				if _, ok := m.Delta[q][a]; !ok {
+					m.Delta[q][a] = sink
+				}
+			}
+		}
+	}
+	m, _ = m.Renumber(OrderBFS)
+	return m
+}
+
+// Reverse returns an NFA accepting the reversed words of d's language, for
+// suffix matching and the like: every edge is turned around, the old start
+// state is the only final state, and a fresh start state (Part 0) has
+// ε-transitions to the old final states (Part 1).
+func (d *DFA[Q, Sigma]) Reverse() *NFA[Tagged[Q], Sigma] {
+	n, starts := d.reverse()
+	start := Tagged[Q]{}
+	out := newTagged(start, n)
+	for _, f := range starts.sorted() {
+		out.addEpsilon(start, Tagged[Q]{1, f})
+	}
+	return out
+}
+
+// reverse is Reverse without the fresh start state: it returns the NFA
+// and its start states, the final states of d. Determinizing from the set
+// directly keeps Brzozowski's construction minimal. Its Q0 is d.Q0 only
+// to keep it well-formed; determinize it with determinizeFrom(starts).
+func (d *DFA[Q, Sigma]) reverse() (*NFA[Q, Sigma], Set[Q]) {
+	n := &NFA[Q, Sigma]{
+		Q:     copySet(d.Q),
+		Sigma: copySet(d.Sigma),
+		Q0:    d.Q0,
+		F:     NewSet(d.Q0),
+		Delta: make(NFATransitionFn[Q, Sigma]),
+	}
+	for q := range d.Q {
+		for a := range d.Sigma {
+			qNext, ok := d.next(q, a)
+			if !ok {
+				continue
+			}
+			if n.Delta[qNext] == nil {
+				n.Delta[qNext] = make(map[Sigma]Set[Q])
+			}
+			if n.Delta[qNext][a] == nil {
+				n.Delta[qNext][a] = make(Set[Q])
+			}
+			n.Delta[qNext][a][q] = struct{}{}
+		}
+	}
+	return n, copySet(d.F)
+}
+
+// reverseDeterminize determinizes the reversal of d.
+func (d *DFA[Q, Sigma]) reverseDeterminize() *DFA[int, Sigma] {
+	n, starts := d.reverse()
+	m, _, _ := n.determinizeFrom(starts, Budget{})
+	return m
+}
+
+// hopcroft partitions the states of a complete dense DFA into classes of
+// equivalent states and returns the class of each state, along with the
+// number of splitters processed.
+func hopcroft(next [][]int, final []bool, k int, tracker *budgetTracker) ([]int, int, error) {
+	n := len(next)
+	inv := make([][][]int, k) // inv[a][t] = states s with δ(s,a) = t
+	for a := range inv {
+		inv[a] = make([][]int, n)
+	}
+	for s, row := range next {
+		for a, t := range row {
+			inv[a][t] = append(inv[a][t], s)
+		}
+	}
+
+	block := make([]int, n)
+	var blocks [][]int
+	var acc, rej []int
+	for s := 0; s < n; s++ {
+		if final[s] {
+			acc = append(acc, s)
+		} else {
+			rej = append(rej, s)
+		}
+	}
+	for _, b := range [][]int{acc, rej} {
+		if len(b) > 0 {
+			for _, s := range b {
+				block[s] = len(blocks)
+			}
+			blocks = append(blocks, b)
+		}
+	}
+
+	var work []int
+	inWork := make([]bool, len(blocks), n)
+	if len(blocks) == 2 {
+		smaller := 0
+		if len(blocks[1]) < len(blocks[0]) {
+			smaller = 1
+		}
+		work = append(work, smaller)
+		inWork[smaller] = true
+	}
+
+	processed := 0
+	for ; len(work) > 0; processed++ {
+		if err := tracker.step("refine", len(blocks), processed, len(work), 0); err != nil {
+			return nil, processed, err
+		}
+		splitter := work[len(work)-1]
+		work = work[:len(work)-1]
+		inWork[splitter] = false
+		// Snapshot the splitter: blocks may be rewritten below.
+		members := append([]int(nil), blocks[splitter]...)
+
+		for a := 0; a < k; a++ {
+			hit := make(map[int][]int) // block -> its members with δ(s,a) in splitter
+			for _, t := range members {
+				for _, s := range inv[a][t] {
+					hit[block[s]] = append(hit[block[s]], s)
+				}
+			}
+			for _, y := range sortedKeys(hit) {
+				in := hit[y]
+				if len(in) == len(blocks[y]) {
+					continue
+				}
+				mark := make(map[int]bool, len(in))
+				for _, s := range in {
+					mark[s] = true
+				}
+				var out []int
+				for _, s := range blocks[y] {
+					if !mark[s] {
+						out = append(out, s)
+					}
+				}
+				blocks[y] = in
+				z := len(blocks)
+				blocks = append(blocks, out)
+				inWork = append(inWork, false)
+				for _, s := range out {
+					block[s] = z
+				}
+				switch {
+				case inWork[y]:
+					work = append(work, z)
+					inWork[z] = true
+				case len(in) <= len(out):
+					work = append(work, y)
+					inWork[y] = true
+				default:
+					work = append(work, z)
+					inWork[z] = true
+				}
+			}
+		}
+	}
+	return block, processed, nil
+}
@@ -0,0 +1,135 @@
+package fsm
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// sameLanguage compares two machines on all words up to length n.
+func sameLanguage[Q1 comparable, Q2 comparable, Sigma comparable](t *testing.T, a *DFA[Q1, Sigma], b *DFA[Q2, Sigma], alphabet []Sigma, n int) {
+	t.Helper()
+	for _, w := range allWords(alphabet, n) {
+		x, _, _ := a.Accepts(w)
+		y, _, _ := b.Accepts(w)
+		if x != y {
+			t.Fatalf("%v: %v vs %v", w, x, y)
+		}
+	}
+}
+
+// TestMinimize_Complete merges a duplicated copy of the mod-three machine.
+func TestMinimize_Complete(t *testing.T) {
+	// States 0-2 and 3-5 both compute x mod 3; 6 is unreachable.
+	delta := TransitionFn[int, Bit]{}
+	for i := 0; i < 6; i++ {
+		r := i % 3
+		base := 3 * (1 - i/3) // jump to the other copy
+		delta[i] = map[Bit]int{Zero: base + (2*r)%3, One: base + (2*r+1)%3}
+	}
+	delta[6] = map[Bit]int{Zero: 6, One: 6}
+	d := Must(NewDFA([]int{0, 1, 2, 3, 4, 5, 6}, []Bit{Zero, One}, 0, []int{0, 3}, delta, true))
+
+	m := d.Minimize()
+	if len(m.Q) != 3 || len(m.F) != 1 || !m.F.Has(0) {
+		t.Fatalf("minimal machine: Q=%v F=%v", m.Q.sorted(), m.F.sorted())
+	}
+	for q := range m.Q {
+		if len(m.Delta[q]) != 2 {
+			t.Fatalf("state %d lost completeness", q)
+		}
+	}
+	sameLanguage(t, d, m, []Bit{Zero, One}, 8)
+	if !m.Equal(m.Minimize()) {
+		t.Fatal("Minimize is not idempotent")
+	}
+}
+
+// TestMinimize_Partial drops dead states from a partial machine.
+func TestMinimize_Partial(t *testing.T) {
+	// "ab" with a dead branch on 'b' and a redundant final copy.
+	delta := TransitionFn[int, rune]{
+		0: {'a': 1, 'b': 3},
+		1: {'b': 2, 'a': 4},
+		3: {'a': 3},
+	}
+	d := Must(NewDFA([]int{0, 1, 2, 3, 4}, []rune("ab"), 0, []int{2}, delta, false))
+	m := d.Minimize()
+	if len(m.Q) != 3 {
+		t.Fatalf("got %d states, want 3", len(m.Q))
+	}
+	if !m.Equal(literalDFA("ab").Minimize()) {
+		t.Fatal("minimal machines for the same language differ")
+	}
+	sameLanguage(t, d, m, []rune("ab"), 5)
+
+	empty := Must(NewDFA([]int{0, 1}, []rune("a"), 0, []int{1}, TransitionFn[int, rune]{}, false)).Minimize()
+	if len(empty.Q) != 1 || len(empty.F) != 0 {
+		t.Fatalf("empty language: Q=%v F=%v", empty.Q.sorted(), empty.F.sorted())
+	}
+}
+
+// TestMinimizeBudget reports progress and lets the callback cancel.
+func TestMinimizeBudget(t *testing.T) {
+	d := counterDFA(64, false)
+	var reports []Progress
+	m, err := d.MinimizeBudget(Budget{OnProgress: func(p Progress) error {
+		reports = append(reports, p)
+		return nil
+	}})
+	if err != nil || len(m.Q) != 64 {
+		t.Fatalf("got %v states, %v", len(m.Q), err)
+	}
+	last := reports[len(reports)-1]
+	if len(reports) < 2 || !last.Done || last.Phase != "refine" || last.Processed == 0 {
+		t.Fatalf("reports = %+v", reports)
+	}
+
+	stop := errors.New("stop")
+	_, err = d.MinimizeBudget(Budget{OnProgress: func(p Progress) error {
+		if p.Processed == 3 {
+			return stop
+		}
+		return nil
+	}})
+	if err != stop {
+		t.Fatalf("got %v, want the callback's error", err)
+	}
+}
+
+// randomDFA builds an n-state machine over {0,1}; with holes, about one
+// transition in five is left out.
+func randomDFA(rng *rand.Rand, n int, holes bool) *DFA[int, Bit] {
+	states := make([]int, n)
+	var finals []int
+	delta := TransitionFn[int, Bit]{}
+	for q := range states {
+		states[q] = q
+		if rng.Intn(3) == 0 {
+			finals = append(finals, q)
+		}
+		delta[q] = map[Bit]int{}
+		for _, a := range []Bit{Zero, One} {
+			if !holes || rng.Intn(5) > 0 {
+				delta[q][a] = rng.Intn(n)
+			}
+		}
+	}
+	return Must(NewDFA(states, []Bit{Zero, One}, 0, finals, delta, false))
+}
+
+// TestMinimizeBrzozowski cross-checks the two minimization algorithms.
+func TestMinimizeBrzozowski(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 300; i++ {
+		d := randomDFA(rng, 1+rng.Intn(8), i%2 == 1)
+		h, b := d.Minimize(), d.MinimizeBrzozowski()
+		if !h.Equal(b) {
+			t.Fatalf("machine %d: Hopcroft %d states, Brzozowski %d states\n%v\n%v", i, len(h.Q), len(b.Q), h.Delta, b.Delta)
+		}
+	}
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	if m := div3.MinimizeBrzozowski(); len(m.Q) != 3 {
+		t.Fatalf("multiples of three: %d states, want 3", len(m.Q))
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package fsm
+
+import "os"
+
+// MmapTable reads a binary table file into memory. On this platform it
+// cannot be memory-mapped, so the data is not shared between processes.
+// The returned function is a no-op kept for API parity.
+func MmapTable(path string) (*TableView, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := LoadTable(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, func() error { return nil }, nil
+}
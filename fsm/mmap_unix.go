@@ -0,0 +1,34 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package fsm
+
+import (
+	"os"
+	"syscall"
+)
+
+// MmapTable maps a binary table file read-only and returns a view over the
+// mapping, so processes loading the same file share its pages. Call the
+// returned function to unmap once the view is no longer used.
+func MmapTable(path string) (*TableView, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	unmap := func() error { return syscall.Munmap(data) }
+	v, err := LoadTable(data)
+	if err != nil {
+		unmap()
+		return nil, nil, err
+	}
+	return v, unmap, nil
+}
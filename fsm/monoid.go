@@ -0,0 +1,193 @@
+package fsm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ---------- Transition monoid ----------
+
+// TransitionMonoid is the monoid of state mappings induced by input words:
+// each element is the function q ↦ δ*(q, w) for some word w, and the
+// product of two elements is their composition. Because the product of the
+// elements of u and v is the element of uv, a long input can be split into
+// chunks, each chunk mapped to its element independently (in parallel), and
+// the results multiplied.
+type TransitionMonoid[Q comparable, Sigma comparable] struct {
+	States  []Q     // state numbering used by Elements
+	Symbols []Sigma // generators, in the order of Generators
+
+	// Elements[i][j] is the state number reached from state j under element
+	// i, or -1 where the mapping is undefined. Element 0 is the identity.
+	Elements [][]int
+	// Words[i] is a shortest word whose mapping is element i.
+	Words [][]Sigma
+	// Mul[i][j] is the element "i then j".
+	Mul [][]int
+	// Generators[k] is the element of the one-symbol word Symbols[k].
+	Generators []int
+
+	symIndex map[Sigma]int
+}
+
+// transKey encodes a state mapping as a map key.
+func transKey(t []int) string {
+	b := make([]byte, 4*len(t))
+	for i, x := range t {
+		binary.LittleEndian.PutUint32(b[4*i:], uint32(int32(x)))
+	}
+	return string(b)
+}
+
+// TransitionMonoid computes the transition monoid of d by breadth-first
+// closure of the symbol mappings, so Words are shortest representatives.
+// The monoid can have up to (|Q|+1)^|Q| elements; computation stops with an
+// error once it exceeds limit elements (limit <= 0 means no limit).
+func (d *DFA[Q, Sigma]) TransitionMonoid(limit int) (*TransitionMonoid[Q, Sigma], error) {
+	states := d.Q.sorted()
+	index := make(map[Q]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	m := &TransitionMonoid[Q, Sigma]{States: states, Symbols: d.Sigma.sorted(), symIndex: make(map[Sigma]int)}
+
+	gens := make([][]int, len(m.Symbols))
+	for k, a := range m.Symbols {
+		m.symIndex[a] = k
+		gens[k] = make([]int, len(states))
+		for i, q := range states {
+			gens[k][i] = -1
+			if qNext, ok := d.next(q, a); ok {
+				gens[k][i] = index[qNext]
+			}
+		}
+	}
+
+	identity := make([]int, len(states))
+	for i := range identity {
+		identity[i] = i
+	}
+	ids := map[string]int{transKey(identity): 0}
+	m.Elements = [][]int{identity}
+	m.Words = [][]Sigma{{}}
+	// right[i][k] is the element i·symbol k; it is filled during closure and
+	// then used to derive the full product table.
+	var right [][]int
+	for i := 0; i < len(m.Elements); i++ {
+		row := make([]int, len(gens))
+		for k, g := range gens {
+			t := compose(m.Elements[i], g)
+			key := transKey(t)
+			id, ok := ids[key]
+			if !ok {
+				if limit > 0 && len(m.Elements) >= limit {
+					return nil, fmt.Errorf("transition monoid has more than %d elements", limit)
+				}
+				id = len(m.Elements)
+				ids[key] = id
+				m.Elements = append(m.Elements, t)
+				word := append(append([]Sigma{}, m.Words[i]...), m.Symbols[k])
+				m.Words = append(m.Words, word)
+			}
+			row[k] = id
+		}
+		right = append(right, row)
+	}
+
+	m.Generators = make([]int, len(gens))
+	for k := range gens {
+		m.Generators[k] = right[0][k]
+	}
+	// i·j is i followed by the letters of a word for j.
+	m.Mul = make([][]int, len(m.Elements))
+	for i := range m.Elements {
+		m.Mul[i] = make([]int, len(m.Elements))
+		for j, w := range m.Words {
+			e := i
+			for _, a := range w {
+				e = right[e][m.symIndex[a]]
+			}
+			m.Mul[i][j] = e
+		}
+	}
+	return m, nil
+}
+
+// compose returns "f then g" on state numbers, with -1 absorbing.
+func compose(f, g []int) []int {
+	out := make([]int, len(f))
+	for i, x := range f {
+		if x < 0 {
+			out[i] = -1
+		} else {
+			out[i] = g[x]
+		}
+	}
+	return out
+}
+
+// Element returns the element of the word input.
+func (m *TransitionMonoid[Q, Sigma]) Element(input []Sigma) (int, error) {
+	e := 0
+	for _, a := range input {
+		k, ok := m.symIndex[a]
+		if !ok {
+			return 0, fmt.Errorf("%w: symbol %v not in Σ", ErrInvalidInput, a)
+		}
+		e = m.Mul[e][m.Generators[k]]
+	}
+	return e, nil
+}
+
+// Apply returns the state element e maps q to; false if undefined.
+func (m *TransitionMonoid[Q, Sigma]) Apply(e int, q Q) (Q, bool) {
+	for i, s := range m.States {
+		if s == q {
+			if j := m.Elements[e][i]; j >= 0 {
+				return m.States[j], true
+			}
+			break
+		}
+	}
+	var zero Q
+	return zero, false
+}
+
+// ---------- Syntactic monoid ----------
+
+// SyntacticMonoid returns the syntactic monoid of the language of d: the
+// transition monoid of its minimal DFA. Two words map to the same element
+// exactly when they can be swapped in any context without changing
+// membership. limit is as for TransitionMonoid.
+func (d *DFA[Q, Sigma]) SyntacticMonoid(limit int) (*TransitionMonoid[int, Sigma], error) {
+	return d.Minimize().TransitionMonoid(limit)
+}
+
+// IsAperiodic reports whether the syntactic monoid of L(d) is aperiodic,
+// i.e. for every word w there is an n with wⁿ and wⁿ⁺¹ equivalent. By
+// Schützenberger's theorem these are exactly the star-free languages, which
+// are the languages definable in first-order logic and LTL over finite
+// words. When the answer is false, witness is a word whose powers cycle
+// with period greater than one (the language "counts" repetitions of it).
+func (d *DFA[Q, Sigma]) IsAperiodic(limit int) (aperiodic bool, witness []Sigma, err error) {
+	m, err := d.SyntacticMonoid(limit)
+	if err != nil {
+		return false, nil, err
+	}
+	for x := range m.Elements {
+		seen := map[int]bool{x: true}
+		p := x
+		for {
+			q := m.Mul[p][x]
+			if q == p {
+				break
+			}
+			if seen[q] {
+				return false, m.Words[x], nil
+			}
+			seen[q] = true
+			p = q
+		}
+	}
+	return true, nil, nil
+}
@@ -0,0 +1,65 @@
+package fsm
+
+import "testing"
+
+// TestTransitionMonoid_ModThree checks the monoid of x ↦ 2x+b mod 3, which
+// is the affine group {x ↦ ax+c : a ∈ {1,2}} of order 6.
+func TestTransitionMonoid_ModThree(t *testing.T) {
+	d := buildModThree()
+	m, err := d.TransitionMonoid(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Elements) != 6 {
+		t.Fatalf("monoid has %d elements, want 6", len(m.Elements))
+	}
+
+	// Split a run into chunks, map each chunk, and multiply.
+	u, v := []Bit{One, Zero, One}, []Bit{One, One, Zero, Zero}
+	eu, _ := m.Element(u)
+	ev, _ := m.Element(v)
+	uv, _ := m.Element(append(append([]Bit{}, u...), v...))
+	if m.Mul[eu][ev] != uv {
+		t.Fatalf("Mul[%d][%d] = %d, want %d", eu, ev, m.Mul[eu][ev], uv)
+	}
+	want, _ := d.Run(append(u, v...))
+	if got, ok := m.Apply(uv, S0); !ok || got != want {
+		t.Fatalf("Apply = %v, want %v", got, want)
+	}
+
+	for i, w := range m.Words {
+		if e, _ := m.Element(w); e != i {
+			t.Fatalf("word %v of element %d maps to %d", w, i, e)
+		}
+	}
+	if _, err := d.TransitionMonoid(3); err == nil {
+		t.Fatal("expected the limit to be enforced")
+	}
+}
+
+// TestIsAperiodic separates star-free languages from counting ones.
+func TestIsAperiodic(t *testing.T) {
+	divisible := buildModThree().clone()
+	divisible.F = NewSet(S0)
+	ok, w, err := divisible.IsAperiodic(0)
+	if err != nil || ok {
+		t.Fatalf("divisibility by 3 is not star-free: got %v,%v", ok, err)
+	}
+	if len(w) == 0 {
+		t.Fatal("expected a witness word")
+	}
+
+	if ok, _, err := literalDFA("abc").IsAperiodic(0); err != nil || !ok {
+		t.Fatalf("finite languages are star-free: got %v,%v", ok, err)
+	}
+
+	// Words over {a} of even length: counting modulo 2.
+	even := Must(NewDFA([]int{0, 1}, []rune("a"), 0, []int{0}, TransitionFn[int, rune]{0: {'a': 1}, 1: {'a': 0}}, true))
+	if ok, w, _ := even.IsAperiodic(0); ok || string(w) != "a" {
+		t.Fatalf("(aa)* is not star-free: got %v, witness %q", ok, string(w))
+	}
+
+	if m, err := divisible.SyntacticMonoid(0); err != nil || len(m.Elements) != 6 {
+		t.Fatalf("syntactic monoid: %v elements, %v", len(m.Elements), err)
+	}
+}
@@ -0,0 +1,144 @@
+package fsm
+
+import "fmt"
+
+// ---------- Multi-tape automata ----------
+
+// TapeRule is one transition of a multi-tape automaton: from From, reading
+// the column On (one symbol per tape), go to To.
+type TapeRule[Q comparable, Sigma comparable] struct {
+	From Q
+	On   []Sigma
+	To   Q
+}
+
+// MultiTape is a deterministic automaton reading K tapes in lockstep. Each
+// step consumes one column, the i-th symbols of all tapes; tapes shorter
+// than the longest are padded with Pad, so rules mentioning Pad describe
+// what happens once a tape has run out. It models relations between aligned
+// sequences, such as an output checked against its input.
+type MultiTape[Q comparable, Sigma comparable] struct {
+	K   int
+	Pad Sigma
+	Q   Set[Q]
+	Q0  Q
+	F   Set[Q]
+
+	rows map[Q][]TapeRule[Q, Sigma]
+}
+
+// NewMultiTape builds and validates a k-tape automaton. Every rule must
+// read exactly k symbols, mention only states in Q, and no column may have
+// two different targets from the same state.
+func NewMultiTape[Q comparable, Sigma comparable](
+	k int,
+	pad Sigma,
+	states []Q,
+	q0 Q,
+	finals []Q,
+	rules []TapeRule[Q, Sigma],
+) (*MultiTape[Q, Sigma], error) {
+	if k < 1 {
+		return nil, fmt.Errorf("%w: need at least one tape, got %d", ErrInvalidInput, k)
+	}
+	m := &MultiTape[Q, Sigma]{
+		K:    k,
+		Pad:  pad,
+		Q:    NewSet(states...),
+		Q0:   q0,
+		F:    NewSet(finals...),
+		rows: make(map[Q][]TapeRule[Q, Sigma]),
+	}
+	if !m.Q.Has(q0) {
+		return nil, &ErrUnknownState[Q]{Kind: StartNotInQ, State: q0}
+	}
+	for f := range m.F {
+		if !m.Q.Has(f) {
+			return nil, &ErrUnknownState[Q]{Kind: FinalNotInQ, State: f}
+		}
+	}
+	for _, r := range rules {
+		if len(r.On) != k {
+			return nil, fmt.Errorf("%w: rule %v --%v--> %v reads %d tapes, want %d", ErrInvalidInput, r.From, r.On, r.To, len(r.On), k)
+		}
+		for _, q := range []Q{r.From, r.To} {
+			if !m.Q.Has(q) {
+				return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
+			}
+		}
+		if prev, ok := m.lookup(r.From, r.On); ok && prev != r.To {
+			return nil, fmt.Errorf("%w: delta(%v,%v) has two targets: %v and %v", ErrInvalidInput, r.From, r.On, prev, r.To)
+		}
+		r.On = append([]Sigma(nil), r.On...)
+		m.rows[r.From] = append(m.rows[r.From], r)
+	}
+	return m, nil
+}
+
+// lookup finds the rule for column in state q. Rows are short in practice,
+// so a linear scan beats hashing the column.
+func (m *MultiTape[Q, Sigma]) lookup(q Q, column []Sigma) (Q, bool) {
+rules:
+	for _, r := range m.rows[q] {
+		for i, a := range r.On {
+			if column[i] != a {
+				continue rules
+			}
+		}
+		return r.To, true
+	}
+	var zero Q
+	return zero, false
+}
+
+// Step applies one transition on a column of K symbols.
+func (m *MultiTape[Q, Sigma]) Step(q Q, column []Sigma) (Q, error) {
+	if len(column) != m.K {
+		return q, fmt.Errorf("column %v has %d symbols, want %d", column, len(column), m.K)
+	}
+	qNext, ok := m.lookup(q, column)
+	if !ok {
+		return q, fmt.Errorf("no transition for (%v,%v)", q, column)
+	}
+	return qNext, nil
+}
+
+// Run reads the K tapes in lockstep, padding the shorter ones, and returns
+// the final state.
+func (m *MultiTape[Q, Sigma]) Run(tapes ...[]Sigma) (Q, error) {
+	if len(tapes) != m.K {
+		return m.Q0, fmt.Errorf("got %d tapes, want %d", len(tapes), m.K)
+	}
+	n := 0
+	for _, t := range tapes {
+		if len(t) > n {
+			n = len(t)
+		}
+	}
+	q := m.Q0
+	column := make([]Sigma, m.K)
+	for i := 0; i < n; i++ {
+		for j, t := range tapes {
+			if i < len(t) {
+				column[j] = t[i]
+			} else {
+				column[j] = m.Pad
+			}
+		}
+		qNext, ok := m.lookup(q, column)
+		if !ok {
+			return q, fmt.Errorf("no transition for (%v,%v) at %d", q, column, i)
+		}
+		q = qNext
+	}
+	return q, nil
+}
+
+// Accepts runs the automaton and checks if the final state is in F.
+func (m *MultiTape[Q, Sigma]) Accepts(tapes ...[]Sigma) (bool, Q, error) {
+	q, err := m.Run(tapes...)
+	if err != nil {
+		return false, q, err
+	}
+	return m.F.Has(q), q, nil
+}
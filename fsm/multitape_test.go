@@ -0,0 +1,62 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMultiTape checks a two-tape relation: the second tape is the first
+// with every 'a' doubled.
+func TestMultiTape(t *testing.T) {
+	rules := []TapeRule[string, rune]{
+		{From: "sync", On: []rune{'b', 'b'}, To: "sync"},
+		{From: "sync", On: []rune{'a', 'a'}, To: "owe a"},
+		{From: "owe a", On: []rune{'b', 'a'}, To: "owe b"},
+		{From: "owe a", On: []rune{'#', 'a'}, To: "sync"},
+		{From: "owe b", On: []rune{'b', 'b'}, To: "owe b"},
+		{From: "owe b", On: []rune{'a', 'b'}, To: "owe a"},
+		{From: "owe b", On: []rune{'#', 'b'}, To: "sync"},
+	}
+	// The second tape can fall arbitrarily far behind, which no finite
+	// machine tracks; this one handles a lag of at most one symbol.
+	m, err := NewMultiTape(2, '#', []string{"sync", "owe a", "owe b"}, "sync", []string{"sync"}, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		in, out string
+		want    bool
+	}{
+		{"b", "b", true},
+		{"a", "aa", true},
+		{"ab", "aab", true},
+		{"ba", "baa", true},
+		{"a", "a", false},
+	} {
+		got, _, err := m.Accepts([]rune(tc.in), []rune(tc.out))
+		if err != nil || got != tc.want {
+			t.Fatalf("(%q,%q): got %v,%v want %v", tc.in, tc.out, got, err, tc.want)
+		}
+	}
+	if _, err := m.Run([]rune("b"), []rune("a")); err == nil {
+		t.Fatal("expected an error for a misaligned column")
+	}
+	if _, err := m.Run([]rune("b")); err == nil {
+		t.Fatal("expected an error for a missing tape")
+	}
+}
+
+// TestNewMultiTape_Validation rejects malformed rules.
+func TestNewMultiTape_Validation(t *testing.T) {
+	states := []int{0, 1}
+	bad := [][]TapeRule[int, rune]{
+		{{From: 0, On: []rune{'a'}, To: 1}},
+		{{From: 0, On: []rune{'a', 'a'}, To: 2}},
+		{{From: 0, On: []rune{'a', 'a'}, To: 1}, {From: 0, On: []rune{'a', 'a'}, To: 0}},
+	}
+	for i, rules := range bad {
+		if _, err := NewMultiTape(2, 0, states, 0, nil, rules); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("case %d: expected ErrInvalidInput, got %v", i, err)
+		}
+	}
+}
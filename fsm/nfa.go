@@ -0,0 +1,526 @@
+package fsm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// ---------- NFA definition ----------
+
+// NFATransitionFn encodes the transition relation δ: Q × Σ → 2^Q as
+// nested maps. A missing entry is the empty set.
+// Example: delta[q][symbol] = NewSet(p, r)
+type NFATransitionFn[Q comparable, Sigma comparable] map[Q]map[Sigma]Set[Q]
+
+// NFA is a generic Nondeterministic Finite Automaton: like a DFA, except
+// that δ(q,σ) is a set of states. It accepts an input if some run ends in F.
+//
+// Epsilon holds the spontaneous (ε) transitions, which a run may take at
+// any point without consuming input; it is nil for a plain NFA.
+type NFA[Q comparable, Sigma comparable] struct {
+	Q       Set[Q]
+	Sigma   Set[Sigma]
+	Q0      Q
+	F       Set[Q]
+	Delta   NFATransitionFn[Q, Sigma]
+	Epsilon map[Q]Set[Q]
+}
+
+// NewNFA builds a new NFA and validates it with the checks of NewDFA:
+// q0 ∈ Q, F ⊆ Q, and every symbol and target of δ in Σ and Q.
+func NewNFA[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta NFATransitionFn[Q, Sigma],
+) (*NFA[Q, Sigma], error) {
+	Qset := NewSet(states...)
+	Sset := NewSet(alphabet...)
+	Fset := NewSet(finals...)
+
+	if !Qset.Has(q0) {
+		return nil, &ErrUnknownState[Q]{Kind: StartNotInQ, State: q0}
+	}
+	for f := range Fset {
+		if !Qset.Has(f) {
+			return nil, &ErrUnknownState[Q]{Kind: FinalNotInQ, State: f}
+		}
+	}
+	for q, row := range delta {
+		if !Qset.Has(q) {
+			return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
+		}
+		for a, targets := range row {
+			if !Sset.Has(a) {
+				return nil, &ErrUnknownSymbol[Q, Sigma]{State: q, Symbol: a}
+			}
+			for qNext := range targets {
+				if !Qset.Has(qNext) {
+					return nil, &ErrUnknownTarget[Q, Sigma]{State: q, Symbol: a, Target: qNext}
+				}
+			}
+		}
+	}
+
+	return &NFA[Q, Sigma]{
+		Q:     Qset,
+		Sigma: Sset,
+		Q0:    q0,
+		F:     Fset,
+		Delta: delta,
+	}, nil
+}
+
+// NewEpsilonNFA builds an NFA with ε-transitions, validating them along
+// with the checks of NewNFA.
+func NewEpsilonNFA[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta NFATransitionFn[Q, Sigma],
+	epsilon map[Q]Set[Q],
+) (*NFA[Q, Sigma], error) {
+	n, err := NewNFA(states, alphabet, q0, finals, delta)
+	if err != nil {
+		return nil, err
+	}
+	for q, targets := range epsilon {
+		if !n.Q.Has(q) {
+			return nil, &ErrUnknownState[Q]{Kind: UnknownState, State: q}
+		}
+		for qNext := range targets {
+			if !n.Q.Has(qNext) {
+				return nil, &ErrUnknownTarget[Q, Sigma]{State: q, Target: qNext, Epsilon: true}
+			}
+		}
+	}
+	n.Epsilon = epsilon
+	return n, nil
+}
+
+// ---------- NFA execution ----------
+
+// EpsilonClosure returns the states reachable from any state in from by
+// ε-transitions alone, including from itself.
+func (n *NFA[Q, Sigma]) EpsilonClosure(from Set[Q]) Set[Q] {
+	out := make(Set[Q], len(from))
+	var stack []Q
+	for q := range from {
+		out[q] = struct{}{}
+		stack = append(stack, q)
+	}
+	for len(stack) > 0 {
+		q := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for p := range n.Epsilon[q] {
+			if !out.Has(p) {
+				out[p] = struct{}{}
+				stack = append(stack, p)
+			}
+		}
+	}
+	return out
+}
+
+// Step returns the set of states reachable from any state in from on a,
+// followed by any ε-transitions.
+func (n *NFA[Q, Sigma]) Step(from Set[Q], a Sigma) Set[Q] {
+	out := make(Set[Q])
+	for q := range from {
+		for qNext := range n.Delta[q][a] {
+			out[qNext] = struct{}{}
+		}
+	}
+	return n.EpsilonClosure(out)
+}
+
+// Run consumes an input sequence and returns the set of states the runs
+// can end in; it is empty once every run has died.
+func (n *NFA[Q, Sigma]) Run(input []Sigma) Set[Q] {
+	cur := n.EpsilonClosure(NewSet(n.Q0))
+	for _, a := range input {
+		if len(cur) == 0 {
+			break
+		}
+		cur = n.Step(cur, a)
+	}
+	return cur
+}
+
+// Accepts reports whether some run on input ends in F.
+func (n *NFA[Q, Sigma]) Accepts(input []Sigma) bool {
+	for q := range n.Run(input) {
+		if n.F.Has(q) {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- Subset construction ----------
+
+// Determinize returns a DFA accepting the same language, built by the
+// subset construction over the subsets reachable from the ε-closure of
+// {q0}. Its states
+// are numbered 0..n-1 in discovery order with start state 0. The empty
+// subset is left out, so the result is partial wherever every run dies.
+func (n *NFA[Q, Sigma]) Determinize() *DFA[int, Sigma] {
+	d, _ := n.DeterminizeBudget(Budget{})
+	return d
+}
+
+// DeterminizeBudget is Determinize under a Budget, for machines whose
+// subset construction may blow up exponentially. Progress is reported in
+// the phase "subset", with the subsets discovered as States and those
+// expanded as Processed.
+func (n *NFA[Q, Sigma]) DeterminizeBudget(budget Budget) (*DFA[int, Sigma], error) {
+	d, _, err := n.determinize(budget)
+	return d, err
+}
+
+// DeterminizeLabeled is Determinize with each DFA state named by the NFA
+// states it stands for, so the result stays debuggable. format receives
+// the states in sorted order; nil means FormatStateSet. It fails if format
+// gives two subsets the same name.
+func (n *NFA[Q, Sigma]) DeterminizeLabeled(format func(states []Q) string) (*DFA[string, Sigma], error) {
+	if format == nil {
+		format = FormatStateSet[Q]
+	}
+	d, origins, err := n.determinize(Budget{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(origins))
+	seen := make(map[string]int, len(origins))
+	for id, qs := range origins {
+		name := format(qs)
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("label %q names both %v and %v", name, origins[prev], qs)
+		}
+		seen[name] = id
+		names[id] = name
+	}
+
+	out := &DFA[string, Sigma]{
+		Q:     make(Set[string], len(names)),
+		Sigma: d.Sigma,
+		Q0:    names[0],
+		F:     make(Set[string]),
+		Delta: make(TransitionFn[string, Sigma], len(names)),
+	}
+	for id, name := range names {
+		out.Q[name] = struct{}{}
+		if d.F.Has(id) {
+			out.F[name] = struct{}{}
+		}
+		row := make(map[Sigma]string, len(d.Delta[id]))
+		for a, j := range d.Delta[id] {
+			row[a] = names[j]
+		}
+		out.Delta[name] = row
+	}
+	return out, nil
+}
+
+// FormatStateSet formats a set of states as "{q1,q3}".
+func FormatStateSet[Q any](states []Q) string {
+	parts := make([]string, len(states))
+	for i, q := range states {
+		parts[i] = fmt.Sprint(q)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// bitset is a subset of the NFA states by their sorted index.
+type bitset []uint64
+
+func (b bitset) has(i int) bool { return b[i/64]&(1<<(i%64)) != 0 }
+
+func (b bitset) empty() bool {
+	for _, w := range b {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns the canonical encoding of b, for interning.
+func (b bitset) key() string {
+	buf := make([]byte, 8*len(b))
+	for i, w := range b {
+		binary.LittleEndian.PutUint64(buf[8*i:], w)
+	}
+	return string(buf)
+}
+
+// determinize runs the subset construction and also returns, for each DFA
+// state, the NFA states it stands for in sorted order.
+func (n *NFA[Q, Sigma]) determinize(budget Budget) (*DFA[int, Sigma], [][]Q, error) {
+	return n.determinizeFrom(NewSet(n.Q0), budget)
+}
+
+// determinizeFrom runs the subset construction from the ε-closure of a set
+// of start states.
+func (n *NFA[Q, Sigma]) determinizeFrom(starts Set[Q], budget Budget) (*DFA[int, Sigma], [][]Q, error) {
+	states := n.Q.sorted()
+	symbols := n.Sigma.sorted()
+	index := make(map[Q]int, len(states))
+	for i, q := range states {
+		index[q] = i
+	}
+	words := (len(states) + 63) / 64
+	symbolIndex := make(map[Sigma]int, len(symbols))
+	for k, a := range symbols {
+		symbolIndex[a] = k
+	}
+
+	// closure(i) is the ε-closure of states[i] as a list of indexes. It is
+	// computed on first use, so memory stays proportional to the states
+	// the subsets actually contain rather than to |Q|²·|Σ|.
+	closures := make([][]int, len(states))
+	var closureBytes int64
+	closure := func(i int) []int {
+		if c := closures[i]; c != nil {
+			return c
+		}
+		var c []int
+		for p := range n.EpsilonClosure(NewSet(states[i])) {
+			c = append(c, index[p])
+		}
+		closures[i] = c
+		closureBytes += int64(8*len(c) + 24)
+		return c
+	}
+	// members calls fn with the index of every state in s.
+	members := func(s bitset, fn func(i int)) {
+		for w, word := range s {
+			for ; word != 0; word &= word - 1 {
+				fn(64*w + bits.TrailingZeros64(word))
+			}
+		}
+	}
+
+	out := &DFA[int, Sigma]{
+		Q:     make(Set[int]),
+		Sigma: copySet(n.Sigma),
+		Q0:    0,
+		F:     make(Set[int]),
+		Delta: make(TransitionFn[int, Sigma]),
+	}
+	ids := make(map[string]int)
+	var sets []bitset
+	add := func(s bitset) int {
+		k := s.key()
+		if id, ok := ids[k]; ok {
+			return id
+		}
+		id := len(sets)
+		ids[k] = id
+		sets = append(sets, s)
+		out.Q[id] = struct{}{}
+		members(s, func(i int) {
+			if n.F.Has(states[i]) {
+				out.F[id] = struct{}{}
+			}
+		})
+		return id
+	}
+	start := make(bitset, words)
+	for q := range starts {
+		for _, j := range closure(index[q]) {
+			start[j/64] |= 1 << (j % 64)
+		}
+	}
+	add(start)
+
+	tracker := budget.track()
+	perSet := int64(16*words + 64)
+	var rowBytes int64
+	for id := 0; id < len(sets); id++ {
+		// Successor sets, by symbol index, from the δ rows of the members.
+		next := make(map[int]bitset)
+		var err error
+		members(sets[id], func(i int) {
+			for a, targets := range n.Delta[states[i]] {
+				k, ok := symbolIndex[a]
+				if !ok || len(targets) == 0 {
+					continue
+				}
+				s := next[k]
+				if s == nil {
+					s = make(bitset, words)
+					next[k] = s
+				}
+				for qNext := range targets {
+					for _, j := range closure(index[qNext]) {
+						s[j/64] |= 1 << (j % 64)
+					}
+				}
+			}
+			if err == nil {
+				err = tracker.check(len(sets), id, int64(len(sets))*perSet+int64(len(next)*words*8)+closureBytes+rowBytes)
+			}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		keys := make([]int, 0, len(next))
+		for k := range next {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		row := make(map[Sigma]int, len(keys))
+		for _, k := range keys {
+			row[symbols[k]] = add(next[k])
+		}
+		out.Delta[id] = row
+		rowBytes += int64(48 + 32*len(row))
+		if err := tracker.step("subset", len(sets), id+1, len(sets)-id-1, int64(len(sets))*perSet+closureBytes+rowBytes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tracker.done("subset", len(sets), len(sets)); err != nil {
+		return nil, nil, err
+	}
+
+	origins := make([][]Q, len(sets))
+	for id, s := range sets {
+		for i, q := range states {
+			if s.has(i) {
+				origins[id] = append(origins[id], q)
+			}
+		}
+	}
+	return out, origins, nil
+}
+
+// ---------- Composition ----------
+
+// Tagged names a state of a composed NFA: the operand it came from (1 for
+// the first, 2 for the second) and its state there. Part 0 is the fresh
+// start state some constructions add.
+type Tagged[Q comparable] struct {
+	Part  int
+	State Q
+}
+
+// NFA returns the DFA as an NFA with singleton transition sets, for use in
+// the compositions below.
+func (d *DFA[Q, Sigma]) NFA() *NFA[Q, Sigma] {
+	n := &NFA[Q, Sigma]{
+		Q:     copySet(d.Q),
+		Sigma: copySet(d.Sigma),
+		Q0:    d.Q0,
+		F:     copySet(d.F),
+		Delta: make(NFATransitionFn[Q, Sigma], len(d.Delta)),
+	}
+	for q := range d.Q {
+		row := make(map[Sigma]Set[Q], len(d.Delta[q]))
+		for a := range d.Sigma {
+			if qNext, ok := d.next(q, a); ok {
+				row[a] = NewSet(qNext)
+			}
+		}
+		if len(row) > 0 {
+			n.Delta[q] = row
+		}
+	}
+	return n
+}
+
+// UnionNFA accepts the words accepted by a or b: a fresh start state has
+// ε-transitions to both start states.
+func UnionNFA[Q comparable, Sigma comparable](a, b *NFA[Q, Sigma]) *NFA[Tagged[Q], Sigma] {
+	start := Tagged[Q]{}
+	out := newTagged(start, a, b)
+	out.addEpsilon(start, Tagged[Q]{1, a.Q0})
+	out.addEpsilon(start, Tagged[Q]{2, b.Q0})
+	return out
+}
+
+// ConcatNFA accepts the words uv with u accepted by a and v by b: every
+// final state of a has an ε-transition to the start of b.
+func ConcatNFA[Q comparable, Sigma comparable](a, b *NFA[Q, Sigma]) *NFA[Tagged[Q], Sigma] {
+	out := newTagged(Tagged[Q]{1, a.Q0}, a, b)
+	for f := range a.F {
+		out.addEpsilon(Tagged[Q]{1, f}, Tagged[Q]{2, b.Q0})
+	}
+	out.F = make(Set[Tagged[Q]], len(b.F))
+	for f := range b.F {
+		out.F[Tagged[Q]{2, f}] = struct{}{}
+	}
+	return out
+}
+
+// StarNFA accepts any concatenation of zero or more words accepted by a:
+// a fresh accepting start state enters a, and every final state of a
+// returns to it.
+func StarNFA[Q comparable, Sigma comparable](a *NFA[Q, Sigma]) *NFA[Tagged[Q], Sigma] {
+	start := Tagged[Q]{}
+	out := newTagged(start, a)
+	out.F[start] = struct{}{}
+	out.addEpsilon(start, Tagged[Q]{1, a.Q0})
+	for f := range a.F {
+		out.addEpsilon(Tagged[Q]{1, f}, start)
+	}
+	return out
+}
+
+// newTagged copies the operands into one NFA over tagged states, with the
+// union of their alphabets and final states. start is added to Q.
+func newTagged[Q comparable, Sigma comparable](start Tagged[Q], parts ...*NFA[Q, Sigma]) *NFA[Tagged[Q], Sigma] {
+	out := &NFA[Tagged[Q], Sigma]{
+		Q:       NewSet(start),
+		Sigma:   make(Set[Sigma]),
+		Q0:      start,
+		F:       make(Set[Tagged[Q]]),
+		Delta:   make(NFATransitionFn[Tagged[Q], Sigma]),
+		Epsilon: make(map[Tagged[Q]]Set[Tagged[Q]]),
+	}
+	for i, n := range parts {
+		tag := func(q Q) Tagged[Q] { return Tagged[Q]{i + 1, q} }
+		for a := range n.Sigma {
+			out.Sigma[a] = struct{}{}
+		}
+		for q := range n.Q {
+			out.Q[tag(q)] = struct{}{}
+		}
+		for f := range n.F {
+			out.F[tag(f)] = struct{}{}
+		}
+		for q, row := range n.Delta {
+			tr := make(map[Sigma]Set[Tagged[Q]], len(row))
+			for a, targets := range row {
+				ts := make(Set[Tagged[Q]], len(targets))
+				for p := range targets {
+					ts[tag(p)] = struct{}{}
+				}
+				tr[a] = ts
+			}
+			out.Delta[tag(q)] = tr
+		}
+		for q, targets := range n.Epsilon {
+			for p := range targets {
+				out.addEpsilon(tag(q), tag(p))
+			}
+		}
+	}
+	return out
+}
+
+// addEpsilon adds the ε-transition from → to.
+func (n *NFA[Q, Sigma]) addEpsilon(from, to Q) {
+	if n.Epsilon == nil {
+		n.Epsilon = make(map[Q]Set[Q])
+	}
+	if n.Epsilon[from] == nil {
+		n.Epsilon[from] = make(Set[Q])
+	}
+	n.Epsilon[from][to] = struct{}{}
+}
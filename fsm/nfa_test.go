@@ -0,0 +1,255 @@
+package fsm
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// thirdFromLast builds the NFA for "the k-th symbol from the end is 1":
+// state 0 loops and guesses, states 1..k count the remaining symbols.
+func thirdFromLast(k int) *NFA[int, Bit] {
+	states := []int{0}
+	delta := NFATransitionFn[int, Bit]{0: {Zero: NewSet(0), One: NewSet(0, 1)}}
+	for i := 1; i < k; i++ {
+		delta[i] = map[Bit]Set[int]{Zero: NewSet(i + 1), One: NewSet(i + 1)}
+	}
+	for i := 1; i <= k; i++ {
+		states = append(states, i)
+	}
+	n, err := NewNFA(states, []Bit{Zero, One}, 0, []int{k}, delta)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// TestNFA_Accepts checks the runs against the definition.
+func TestNFA_Accepts(t *testing.T) {
+	n := thirdFromLast(3)
+	for _, w := range allWords([]Bit{Zero, One}, 7) {
+		want := len(w) >= 3 && w[len(w)-3] == One
+		if got := n.Accepts(w); got != want {
+			t.Fatalf("%v: got %v want %v", w, got, want)
+		}
+	}
+	if got := n.Run([]Bit{One, One}); len(got) != 3 {
+		t.Fatalf("Run(11) = %v, want {0,1,2}", got.sorted())
+	}
+}
+
+// TestNFA_Determinize gives the classic 2^k-state DFA.
+func TestNFA_Determinize(t *testing.T) {
+	n := thirdFromLast(3)
+	d := n.Determinize()
+	if len(d.Q) != 8 {
+		t.Fatalf("determinized to %d states, want 8", len(d.Q))
+	}
+	if len(d.Minimize().Q) != 8 {
+		t.Fatal("the subset DFA for k=3 is already minimal")
+	}
+	for _, w := range allWords([]Bit{Zero, One}, 8) {
+		got, _, _ := d.Accepts(w)
+		if got != n.Accepts(w) {
+			t.Fatalf("%v: DFA %v, NFA %v", w, got, n.Accepts(w))
+		}
+	}
+}
+
+// TestNFA_Partial leaves out the empty subset.
+func TestNFA_Partial(t *testing.T) {
+	n, err := NewNFA([]int{0, 1}, []Bit{Zero, One}, 0, []int{1},
+		NFATransitionFn[int, Bit]{0: {One: NewSet(1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := n.Determinize()
+	if len(d.Q) != 2 {
+		t.Fatalf("got %d states, want 2", len(d.Q))
+	}
+	if _, err := d.Run([]Bit{Zero}); err == nil {
+		t.Fatal("expected no transition into the empty subset")
+	}
+}
+
+// TestNFA_Validation rejects targets outside Q.
+func TestNFA_Validation(t *testing.T) {
+	_, err := NewNFA([]int{0}, []Bit{Zero}, 0, nil, NFATransitionFn[int, Bit]{0: {Zero: NewSet(5)}})
+	if err == nil {
+		t.Fatal("expected an error for a target outside Q")
+	}
+}
+
+// TestNFA_DeterminizeBudget stops an exponential construction.
+func TestNFA_DeterminizeBudget(t *testing.T) {
+	_, err := thirdFromLast(12).DeterminizeBudget(Budget{MaxStates: 100})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	var calls int
+	var maxMemory int64
+	var last Progress
+	d, err := thirdFromLast(4).DeterminizeBudget(Budget{OnProgress: func(p Progress) error {
+		calls++
+		if p.Memory > maxMemory {
+			maxMemory = p.Memory
+		}
+		if p.Peak < p.Memory {
+			t.Errorf("peak %d below current %d", p.Peak, p.Memory)
+		}
+		last = p
+		return nil
+	}})
+	if err != nil || len(d.Q) != 16 || calls == 0 {
+		t.Fatalf("got %v states, %v, %d progress calls", len(d.Q), err, calls)
+	}
+	if !last.Done || last.Peak == 0 || last.Peak != maxMemory {
+		t.Errorf("final report %+v, max memory seen %d", last, maxMemory)
+	}
+
+	_, err = thirdFromLast(12).DeterminizeBudget(Budget{MaxMemory: 4096})
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "memory" || le.Peak < le.Memory || le.Peak <= 4096 {
+		t.Fatalf("memory limit: got %+v", err)
+	}
+}
+
+// TestNFA_DeterminizeSparse keeps a large sparse NFA within its memory
+// budget: nothing proportional to |Q|²·|Σ| may be built up front.
+func TestNFA_DeterminizeSparse(t *testing.T) {
+	const size = 3000
+	alphabet := make([]byte, 256)
+	for i := range alphabet {
+		alphabet[i] = byte(i)
+	}
+	states := make([]int, size)
+	delta := make(NFATransitionFn[int, byte], size)
+	for i := range states {
+		states[i] = i
+		if i+1 < size {
+			delta[i] = map[byte]Set[int]{byte(i): NewSet(i + 1)}
+		}
+	}
+	n := Must(NewNFA(states, alphabet, 0, []int{size - 1}, delta))
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := n.DeterminizeBudget(Budget{MaxMemory: 1 << 20})
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Fatalf("allocated %d bytes under a 1MB budget", alloc)
+	}
+	d, err := n.DeterminizeBudget(Budget{})
+	if err != nil || len(d.Q) != size {
+		t.Fatalf("got %d states, %v", len(d.Q), err)
+	}
+}
+
+// TestNFA_DeterminizeLabeled names states by their origin sets.
+func TestNFA_DeterminizeLabeled(t *testing.T) {
+	n := thirdFromLast(2)
+	d, err := n.DeterminizeLabeled(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Q0 != "{0}" || !d.Q.Has("{0,1,2}") || !d.F.Has("{0,2}") || d.F.Has("{0,1}") {
+		t.Fatalf("states %v, finals %v", d.Q.sorted(), d.F.sorted())
+	}
+	if q, _ := d.Run([]Bit{One, One}); q != "{0,1,2}" {
+		t.Fatalf("Run(11) = %q", q)
+	}
+
+	_, err = n.DeterminizeLabeled(func([]int) string { return "same" })
+	if err == nil {
+		t.Fatal("expected an error for colliding labels")
+	}
+}
+
+// TestEpsilonNFA follows ε-transitions when running and determinizing.
+func TestEpsilonNFA(t *testing.T) {
+	// 0 -ε-> 1 -1-> 2 -ε-> 3 (final), and 0 -0-> 0.
+	n, err := NewEpsilonNFA([]int{0, 1, 2, 3}, []Bit{Zero, One}, 0, []int{3},
+		NFATransitionFn[int, Bit]{0: {Zero: NewSet(0)}, 1: {One: NewSet(2)}},
+		map[int]Set[int]{0: NewSet(1), 2: NewSet(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := n.EpsilonClosure(NewSet(0)); len(c) != 2 || !c.Has(1) {
+		t.Fatalf("closure(0) = %v", c.sorted())
+	}
+	// The language is 0*1.
+	d := n.Determinize()
+	for _, w := range allWords([]Bit{Zero, One}, 5) {
+		want := len(w) > 0 && w[len(w)-1] == One
+		for i := 0; want && i < len(w)-1; i++ {
+			want = w[i] == Zero
+		}
+		got, _, _ := d.Accepts(w)
+		if n.Accepts(w) != want || got != want {
+			t.Fatalf("%v: NFA %v, DFA %v, want %v", w, n.Accepts(w), got, want)
+		}
+	}
+
+	if _, err := NewEpsilonNFA([]int{0}, []Bit{Zero}, 0, nil, nil, map[int]Set[int]{0: NewSet(1)}); err == nil {
+		t.Fatal("expected an error for an ε-target outside Q")
+	}
+}
+
+// TestNFA_Composition builds (mod-three-zero | "1") · "0"* and checks it.
+func TestNFA_Composition(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	one := Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S1},
+		TransitionFn[State, Bit]{S0: {One: S1}}, false))
+	zero := Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S1},
+		TransitionFn[State, Bit]{S0: {Zero: S1}}, false))
+
+	// Operands of ConcatNFA share a state type, so determinize each part.
+	union := UnionNFA(div3.NFA(), one.NFA()).Determinize().NFA()
+	zeros := StarNFA(zero.NFA()).Determinize().NFA()
+	full := ConcatNFA(union, zeros)
+	d := full.Determinize().Minimize()
+
+	value := func(w []Bit) int {
+		v := 0
+		for _, b := range w {
+			v = 2*v + int(b-Zero)
+		}
+		return v
+	}
+	for _, w := range allWords([]Bit{Zero, One}, 8) {
+		// Strip trailing zeros: the rest must be a multiple of 3 or "1".
+		i := len(w)
+		for i > 0 && w[i-1] == Zero {
+			i--
+		}
+		var want bool
+		for j := i; j <= len(w); j++ {
+			head := w[:j]
+			if value(head)%3 == 0 || (len(head) == 1 && head[0] == One) {
+				want = true
+			}
+		}
+		got, _, _ := d.Accepts(w)
+		if got != want || full.Accepts(w) != want {
+			t.Fatalf("%v: DFA %v, NFA %v, want %v", w, got, full.Accepts(w), want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	r := literalDFA("abc").Reverse()
+	for w, want := range map[string]bool{"cba": true, "abc": false, "": false, "cb": false} {
+		if got := r.Accepts([]rune(w)); got != want {
+			t.Errorf("%q: %v, want %v", w, got, want)
+		}
+	}
+	// Multiples of three read from the other end are still multiples of
+	// three: 2 ≡ -1 (mod 3), so reversal only flips the sign.
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	if ok, w, err := Equivalent(div3.Reverse().Determinize(), div3); !ok || err != nil {
+		t.Errorf("reversed multiples of three differ on %q (%v)", string(w), err)
+	}
+}
@@ -0,0 +1,111 @@
+package fsm
+
+import "fmt"
+
+// ---------- Construction options ----------
+
+// Option configures NewDFAWith. Options apply in order, so a later one
+// overrides an earlier one.
+type Option func(*dfaOptions)
+
+type dfaOptions struct {
+	requireComplete bool
+	warnUnreachable bool
+	warn            func(error)
+	sink            any
+	hasSink         bool
+}
+
+// RequireComplete makes construction fail unless every (q,σ) pair has a
+// transition, like NewDFA's requireComplete = true.
+func RequireComplete() Option { return func(o *dfaOptions) { o.requireComplete = true } }
+
+// AllowPartial accepts undefined transitions, which make Step fail. It is
+// the default.
+func AllowPartial() Option { return func(o *dfaOptions) { o.requireComplete = false } }
+
+// AutoComplete routes every undefined transition over Σ to sink, which is
+// added to Q if needed, so a new sink loops on every symbol. It is applied
+// before RequireComplete is checked. Q must be the state type of the
+// machine; spell it out for an untyped constant, as AutoComplete[State](3),
+// or NewDFAWith fails with an *ErrOptionType.
+func AutoComplete[Q comparable](sink Q) Option {
+	return func(o *dfaOptions) { o.sink, o.hasSink = sink, true }
+}
+
+// WarnUnreachable reports states that cannot be reached from q0 as a
+// warning to the OnWarning hook; construction still succeeds.
+func WarnUnreachable() Option { return func(o *dfaOptions) { o.warnUnreachable = true } }
+
+// OnWarning sends warnings to fn. Without it, warnings are dropped.
+func OnWarning(fn func(error)) Option { return func(o *dfaOptions) { o.warn = fn } }
+
+// NewDFAWith is NewDFA configured by options instead of a bare bool:
+//
+//	fsm.NewDFAWith(states, alphabet, q0, finals, delta, fsm.AutoComplete(Dead), fsm.WarnUnreachable())
+//
+// Without options it behaves as NewDFA with requireComplete = false. delta
+// is not modified.
+func NewDFAWith[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta TransitionFn[Q, Sigma],
+	opts ...Option,
+) (*DFA[Q, Sigma], error) {
+	var o dfaOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.hasSink {
+		sink, ok := o.sink.(Q)
+		if !ok {
+			var want Q
+			return nil, &ErrOptionType{Option: "AutoComplete", Value: o.sink, Want: fmt.Sprintf("%T", want)}
+		}
+		states, delta = autoComplete(states, alphabet, delta, sink)
+	}
+	d, err := NewDFA(states, alphabet, q0, finals, delta, o.requireComplete)
+	if err != nil {
+		return nil, err
+	}
+	if o.warnUnreachable && o.warn != nil {
+		reach := d.reachable()
+		var lost []Q
+		for _, q := range d.Q.sorted() {
+			if !reach.Has(q) {
+				lost = append(lost, q)
+			}
+		}
+		if len(lost) > 0 {
+			o.warn(fmt.Errorf("unreachable states %v", lost))
+		}
+	}
+	return d, nil
+}
+
+// autoComplete returns copies of states and delta in which every missing
+// transition goes to sink.
+func autoComplete[Q comparable, Sigma comparable](states []Q, alphabet []Sigma, delta TransitionFn[Q, Sigma], sink Q) ([]Q, TransitionFn[Q, Sigma]) {
+	all := append([]Q(nil), states...)
+	if !NewSet(states...).Has(sink) {
+		all = append(all, sink)
+	}
+	out := make(TransitionFn[Q, Sigma], len(all))
+	for _, q := range all {
+		row := copyRow(delta[q])
+		for _, a := range alphabet {
+			if _, ok := row[a]; !ok {
+				row[a] = sink
+			}
+		}
+		out[q] = row
+	}
+	for q, row := range delta {
+		if _, ok := out[q]; !ok {
+			out[q] = row // unknown state: NewDFA reports it
+		}
+	}
+	return all, out
+}
@@ -0,0 +1,61 @@
+package fsm
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"testing"
+)
+
+// TestNewDFAWith checks each option.
+func TestNewDFAWith(t *testing.T) {
+	states := []string{"start", "a", "orphan"}
+	alphabet := []rune("ab")
+	delta := TransitionFn[string, rune]{"start": {'a': "a"}, "a": {'b': "start"}}
+
+	if _, err := NewDFAWith(states, alphabet, "start", []string{"a"}, delta); err != nil {
+		t.Fatalf("partial by default: %v", err)
+	}
+	if _, err := NewDFAWith(states, alphabet, "start", []string{"a"}, delta, RequireComplete()); err == nil {
+		t.Fatal("RequireComplete accepted a partial δ")
+	}
+	if _, err := NewDFAWith(states, alphabet, "start", []string{"a"}, delta, RequireComplete(), AllowPartial()); err != nil {
+		t.Fatalf("AllowPartial after RequireComplete: %v", err)
+	}
+
+	var warnings []error
+	d, err := NewDFAWith(states, alphabet, "start", []string{"a"}, delta,
+		AutoComplete("dead"), RequireComplete(), WarnUnreachable(), OnWarning(func(err error) { warnings = append(warnings, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := d.Run([]rune("bba")); q != "dead" || len(d.Q) != 4 || len(delta["start"]) != 1 {
+		t.Fatalf("AutoComplete: ended in %v, Q = %v, caller's δ = %v", q, d.Q, delta)
+	}
+	if len(warnings) != 1 || warnings[0].Error() != "unreachable states [orphan]" {
+		t.Fatalf("warnings = %v", warnings)
+	}
+	// Without a hook, warnings are dropped rather than logged.
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	if _, err := NewDFAWith(states, alphabet, "start", []string{"a"}, delta, WarnUnreachable()); err != nil || logged.Len() > 0 {
+		t.Fatalf("default hook: err %v, logged %q", err, logged.String())
+	}
+	_, err = NewDFAWith(states, alphabet, "start", nil, delta, AutoComplete(7))
+	var ot *ErrOptionType
+	if !errors.As(err, &ot) || ot.Want != "string" || !errors.Is(err, ErrInvalidInput) ||
+		err.Error() != "AutoComplete value 7 is a int, not a string" {
+		t.Fatalf("mistyped sink: got %v", err)
+	}
+	// An explicit type argument moves the check to compile time.
+	if _, err := NewDFAWith([]State{S0}, []Bit{Zero}, S0, nil, nil, AutoComplete[State](2), RequireComplete()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The builder takes the same options.
+	if _, err := NewBuilder[int, rune]().Start(0).Alphabet('a').Build(AutoComplete(0), RequireComplete()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package pda implements pushdown automata: finite state machines with a
+// stack, for nested and balanced structures such as brackets, which no DFA
+// can recognize. Machines may be nondeterministic; Accepts explores every
+// run.
+package pda
+
+import (
+	"errors"
+	"fmt"
+
+	"fsm/fsm"
+)
+
+// ErrStackLimit reports that some run grew its stack past MaxStack, so a
+// rejection may be wrong.
+var ErrStackLimit = errors.New("pda: stack limit exceeded")
+
+// DefaultMaxStack is the MaxStack of a new PDA.
+const DefaultMaxStack = 1 << 16
+
+// Acceptance selects when a run that has read the whole input accepts.
+type Acceptance int
+
+const (
+	// ByFinalState accepts in a state of F, whatever is left on the stack.
+	ByFinalState Acceptance = iota
+	// ByEmptyStack accepts once the stack, bottom marker included, is
+	// empty; F is ignored.
+	ByEmptyStack
+)
+
+// Rule is one transition: in state From, reading In (nothing if Epsilon)
+// with Pop on top of the stack, replace Pop by Push and go to To. Push[0]
+// ends up on top, so Push = [x, Pop] pushes x and Push = nil pops.
+type Rule[Q comparable, Sigma comparable, Gamma comparable] struct {
+	From    Q
+	In      Sigma
+	Epsilon bool
+	Pop     Gamma
+	Push    []Gamma
+	To      Q
+}
+
+// PDA is a pushdown automaton with states Q, input alphabet Σ and stack
+// alphabet Γ. A run starts in Q0 with only Bottom on the stack and halts
+// when no rule applies, in particular once the stack is empty.
+type PDA[Q comparable, Sigma comparable, Gamma comparable] struct {
+	Q      fsm.Set[Q]
+	Sigma  fsm.Set[Sigma]
+	Gamma  fsm.Set[Gamma]
+	Q0     Q
+	Bottom Gamma
+	F      fsm.Set[Q]
+	Accept Acceptance
+
+	// MaxStack bounds the stack of every run, which ε-rules that push
+	// could otherwise grow without end.
+	MaxStack int
+
+	rules map[Q][]Rule[Q, Sigma, Gamma]
+}
+
+// New builds and validates a PDA. Γ is Bottom and every stack symbol the
+// rules mention; rules must mention only states in Q and, unless Epsilon,
+// symbols in alphabet.
+func New[Q comparable, Sigma comparable, Gamma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	bottom Gamma,
+	finals []Q,
+	accept Acceptance,
+	rules []Rule[Q, Sigma, Gamma],
+) (*PDA[Q, Sigma, Gamma], error) {
+	p := &PDA[Q, Sigma, Gamma]{
+		Q:        fsm.NewSet(states...),
+		Sigma:    fsm.NewSet(alphabet...),
+		Gamma:    fsm.NewSet(bottom),
+		Q0:       q0,
+		Bottom:   bottom,
+		F:        fsm.NewSet(finals...),
+		Accept:   accept,
+		MaxStack: DefaultMaxStack,
+		rules:    make(map[Q][]Rule[Q, Sigma, Gamma]),
+	}
+	if !p.Q.Has(q0) {
+		return nil, fmt.Errorf("%w: q0 %v not in Q", fsm.ErrInvalidInput, q0)
+	}
+	for f := range p.F {
+		if !p.Q.Has(f) {
+			return nil, fmt.Errorf("%w: final %v not in Q", fsm.ErrInvalidInput, f)
+		}
+	}
+	for _, r := range rules {
+		if !p.Q.Has(r.From) || !p.Q.Has(r.To) {
+			return nil, fmt.Errorf("%w: rule %v → %v uses a state not in Q", fsm.ErrInvalidInput, r.From, r.To)
+		}
+		if !r.Epsilon && !p.Sigma.Has(r.In) {
+			return nil, fmt.Errorf("%w: rule %v → %v reads %v, not in Σ", fsm.ErrInvalidInput, r.From, r.To, r.In)
+		}
+		p.Gamma[r.Pop] = struct{}{}
+		for _, g := range r.Push {
+			p.Gamma[g] = struct{}{}
+		}
+		r.Push = append([]Gamma(nil), r.Push...)
+		p.rules[r.From] = append(p.rules[r.From], r)
+	}
+	return p, nil
+}
+
+// MustNew is New for package-level machines; it panics on an invalid one.
+func MustNew[Q comparable, Sigma comparable, Gamma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	bottom Gamma,
+	finals []Q,
+	accept Acceptance,
+	rules []Rule[Q, Sigma, Gamma],
+) *PDA[Q, Sigma, Gamma] {
+	p, err := New(states, alphabet, q0, bottom, finals, accept, rules)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// stacks interns stacks as nodes of a tree of pushes, so configurations
+// compare in O(1). Node 0 is the empty stack.
+type stacks[Gamma comparable] struct {
+	below []int
+	top   []Gamma
+	depth []int
+	index map[push[Gamma]]int
+}
+
+type push[Gamma comparable] struct {
+	below int
+	g     Gamma
+}
+
+func newStacks[Gamma comparable]() *stacks[Gamma] {
+	var zero Gamma
+	return &stacks[Gamma]{
+		below: []int{-1},
+		top:   []Gamma{zero},
+		depth: []int{0},
+		index: make(map[push[Gamma]]int),
+	}
+}
+
+func (s *stacks[Gamma]) push(below int, g Gamma) int {
+	k := push[Gamma]{below, g}
+	if n, ok := s.index[k]; ok {
+		return n
+	}
+	s.below = append(s.below, below)
+	s.top = append(s.top, g)
+	s.depth = append(s.depth, s.depth[below]+1)
+	s.index[k] = len(s.below) - 1
+	return len(s.below) - 1
+}
+
+// config is a configuration of a run: input position, state and stack.
+type config[Q comparable] struct {
+	pos   int
+	q     Q
+	stack int
+}
+
+// Accepts reports whether some run reads all of input and accepts. Runs
+// are explored breadth-first without repeating a configuration. If none
+// accepts and some run was cut at MaxStack, it returns false and
+// ErrStackLimit.
+func (p *PDA[Q, Sigma, Gamma]) Accepts(input []Sigma) (bool, error) {
+	st := newStacks[Gamma]()
+	start := config[Q]{0, p.Q0, st.push(0, p.Bottom)}
+	seen := map[config[Q]]bool{start: true}
+	queue := []config[Q]{start}
+	overflow := false
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c.pos == len(input) && p.accepts(c) {
+			return true, nil
+		}
+		if c.stack == 0 {
+			continue
+		}
+		top := st.top[c.stack]
+		for _, r := range p.rules[c.q] {
+			if r.Pop != top {
+				continue
+			}
+			next := config[Q]{c.pos, r.To, st.below[c.stack]}
+			if !r.Epsilon {
+				if c.pos == len(input) || input[c.pos] != r.In {
+					continue
+				}
+				next.pos++
+			}
+			if st.depth[next.stack]+len(r.Push) > p.MaxStack {
+				overflow = true
+				continue
+			}
+			for i := len(r.Push) - 1; i >= 0; i-- {
+				next.stack = st.push(next.stack, r.Push[i])
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if overflow {
+		return false, ErrStackLimit
+	}
+	return false, nil
+}
+
+func (p *PDA[Q, Sigma, Gamma]) accepts(c config[Q]) bool {
+	if p.Accept == ByEmptyStack {
+		return c.stack == 0
+	}
+	return p.F.Has(c.q)
+}
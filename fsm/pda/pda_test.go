@@ -0,0 +1,103 @@
+package pda
+
+import (
+	"errors"
+	"testing"
+
+	"fsm/fsm"
+)
+
+// brackets accepts balanced () and [] nests.
+func brackets(accept Acceptance) *PDA[string, rune, rune] {
+	var rules []Rule[string, rune, rune]
+	for _, top := range []rune{'$', '(', '['} {
+		for _, open := range []rune{'(', '['} {
+			rules = append(rules, Rule[string, rune, rune]{From: "q", In: open, Pop: top, Push: []rune{open, top}, To: "q"})
+		}
+	}
+	rules = append(rules,
+		Rule[string, rune, rune]{From: "q", In: ')', Pop: '(', To: "q"},
+		Rule[string, rune, rune]{From: "q", In: ']', Pop: '[', To: "q"},
+		Rule[string, rune, rune]{From: "q", Epsilon: true, Pop: '$', To: "done"},
+	)
+	return MustNew([]string{"q", "done"}, []rune("()[]"), "q", '$', []string{"done"}, accept, rules)
+}
+
+// TestBrackets accepts exactly the balanced words, by either mode.
+func TestBrackets(t *testing.T) {
+	for _, accept := range []Acceptance{ByFinalState, ByEmptyStack} {
+		p := brackets(accept)
+		for in, want := range map[string]bool{
+			"":       true,
+			"()":     true,
+			"([])()": true,
+			"[(())]": true,
+			"(":      false,
+			"(]":     false,
+			"())(":   false,
+			"([)]":   false,
+		} {
+			got, err := p.Accepts([]rune(in))
+			if err != nil || got != want {
+				t.Errorf("mode %d, %q: got %v, %v want %v", accept, in, got, err, want)
+			}
+		}
+	}
+}
+
+// TestPalindromes needs nondeterminism: the machine guesses the middle.
+func TestPalindromes(t *testing.T) {
+	var rules []Rule[int, rune, rune]
+	for _, a := range "ab" {
+		for _, top := range "ab$" {
+			rules = append(rules,
+				Rule[int, rune, rune]{From: 0, In: a, Pop: top, Push: []rune{a, top}, To: 0}, // push the first half
+				Rule[int, rune, rune]{From: 0, In: a, Pop: top, Push: []rune{top}, To: 1},    // odd middle
+			)
+		}
+		rules = append(rules, Rule[int, rune, rune]{From: 1, In: a, Pop: a, To: 1}) // match the second half
+	}
+	for _, top := range "ab$" {
+		rules = append(rules, Rule[int, rune, rune]{From: 0, Epsilon: true, Pop: top, Push: []rune{top}, To: 1})
+	}
+	rules = append(rules, Rule[int, rune, rune]{From: 1, Epsilon: true, Pop: '$', Push: []rune{'$'}, To: 2})
+	p := MustNew([]int{0, 1, 2}, []rune("ab"), 0, '$', []int{2}, ByFinalState, rules)
+	for in, want := range map[string]bool{
+		"": true, "a": true, "abba": true, "aba": true, "abbba": true,
+		"ab": false, "abab": false, "aab": false,
+	} {
+		if got, err := p.Accepts([]rune(in)); err != nil || got != want {
+			t.Errorf("%q: got %v, %v want %v", in, got, err, want)
+		}
+	}
+	if len(p.Gamma) != 3 {
+		t.Errorf("Γ = %v", p.Gamma)
+	}
+}
+
+// TestStackLimit cuts runs that push forever on ε.
+func TestStackLimit(t *testing.T) {
+	p := MustNew([]int{0}, []rune("a"), 0, 'z', nil, ByFinalState, []Rule[int, rune, rune]{
+		{From: 0, Epsilon: true, Pop: 'z', Push: []rune{'z', 'z'}, To: 0},
+	})
+	p.MaxStack = 100
+	if ok, err := p.Accepts(nil); ok || !errors.Is(err, ErrStackLimit) {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+}
+
+// TestNew_Validation rejects unknown states and symbols.
+func TestNew_Validation(t *testing.T) {
+	bad := [][]Rule[int, rune, rune]{
+		{{From: 0, In: 'a', Pop: 'z', To: 1}},
+		{{From: 0, In: 'b', Pop: 'z', To: 0}},
+	}
+	for i, rules := range bad {
+		if _, err := New([]int{0}, []rune("a"), 0, 'z', nil, ByFinalState, rules); !errors.Is(err, fsm.ErrInvalidInput) {
+			t.Errorf("case %d: got %v", i, err)
+		}
+	}
+	if _, err := New[int, rune, rune]([]int{0}, nil, 0, 'z', []int{1}, ByFinalState, nil); !errors.Is(err, fsm.ErrInvalidInput) {
+		t.Errorf("bad final: got %v", err)
+	}
+}
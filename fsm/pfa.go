@@ -0,0 +1,209 @@
+package fsm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ---------- Probabilistic automata ----------
+
+// PFA is a probabilistic automaton over the structure of a DFA.
+// In every state q the possible outcomes are the defined transitions and,
+// when q ∈ F, stopping. P[q][a] is the probability of reading a next and
+// Stop[q] the probability of ending the run in q; together they sum to 1.
+type PFA[Q comparable, Sigma comparable] struct {
+	*DFA[Q, Sigma]
+	P    map[Q]map[Sigma]float64
+	Stop map[Q]float64
+}
+
+// FitProbabilities turns d into a usage model by maximum-likelihood
+// estimation from observed traces. Each trace must be a run of d that ends in
+// an accepting state. smoothing is an additive (Laplace) pseudo-count added to
+// every outcome; states never visited with smoothing 0 get uniform outcomes.
+func (d *DFA[Q, Sigma]) FitProbabilities(traces [][]Sigma, smoothing float64) (*PFA[Q, Sigma], error) {
+	if smoothing < 0 {
+		return nil, fmt.Errorf("%w: negative smoothing %v", ErrInvalidInput, smoothing)
+	}
+	edges := make(map[Q]map[Sigma]float64, len(d.Q))
+	stops := make(map[Q]float64, len(d.F))
+	for i, tr := range traces {
+		q := d.Q0
+		for _, a := range tr {
+			qNext, err := d.Step(q, a)
+			if err != nil {
+				return nil, fmt.Errorf("trace %d: %w", i, err)
+			}
+			if edges[q] == nil {
+				edges[q] = make(map[Sigma]float64)
+			}
+			edges[q][a]++
+			q = qNext
+		}
+		if !d.F.Has(q) {
+			return nil, fmt.Errorf("trace %d ends in non-accepting state %v", i, q)
+		}
+		stops[q]++
+	}
+
+	p := &PFA[Q, Sigma]{
+		DFA:  d,
+		P:    make(map[Q]map[Sigma]float64, len(d.Q)),
+		Stop: make(map[Q]float64, len(d.F)),
+	}
+	for q := range d.Q {
+		var outcomes []Sigma
+		for a := range d.Sigma {
+			if _, ok := d.next(q, a); ok {
+				outcomes = append(outcomes, a)
+			}
+		}
+		canStop := d.F.Has(q)
+		n := float64(len(outcomes))
+		if canStop {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+
+		total := stops[q]
+		for _, a := range outcomes {
+			total += edges[q][a]
+		}
+		total += smoothing * n
+		weight := func(count float64) float64 {
+			if total == 0 {
+				return 1 / n
+			}
+			return (count + smoothing) / total
+		}
+
+		row := make(map[Sigma]float64, len(outcomes))
+		for _, a := range outcomes {
+			row[a] = weight(edges[q][a])
+		}
+		p.P[q] = row
+		if canStop {
+			p.Stop[q] = weight(stops[q])
+		}
+	}
+	return p, nil
+}
+
+// NewPFA attaches the probabilities p and stop to d and validates them.
+func NewPFA[Q comparable, Sigma comparable](d *DFA[Q, Sigma], p map[Q]map[Sigma]float64, stop map[Q]float64) (*PFA[Q, Sigma], error) {
+	m := &PFA[Q, Sigma]{DFA: d, P: p, Stop: stop}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// probabilityTolerance is how far a row may sum from 1.
+const probabilityTolerance = 1e-9
+
+// Validate checks that every probability lies in [0,1] and belongs to a
+// defined transition or, for Stop, a final state, and that in every state
+// with an outcome the probabilities sum to 1 (within 1e-9). A state whose
+// row and Stop are all missing is never entered by a sampled run.
+func (m *PFA[Q, Sigma]) Validate() error {
+	for q, row := range m.P {
+		if !m.Q.Has(q) {
+			return fmt.Errorf("%w: P has state %v not in Q", ErrInvalidInput, q)
+		}
+		for a, x := range row {
+			if _, ok := m.next(q, a); !ok {
+				return fmt.Errorf("%w: P[%v][%v] has no transition", ErrInvalidInput, q, a)
+			}
+			if !(x >= 0 && x <= 1) {
+				return fmt.Errorf("%w: P[%v][%v] = %v is not a probability", ErrInvalidInput, q, a, x)
+			}
+		}
+	}
+	for q, x := range m.Stop {
+		if !m.F.Has(q) {
+			return fmt.Errorf("%w: Stop[%v] for a non-final state", ErrInvalidInput, q)
+		}
+		if !(x >= 0 && x <= 1) {
+			return fmt.Errorf("%w: Stop[%v] = %v is not a probability", ErrInvalidInput, q, x)
+		}
+	}
+	for q := range m.Q {
+		_, stops := m.Stop[q]
+		if len(m.P[q]) == 0 && !stops {
+			continue
+		}
+		total := m.Stop[q]
+		for _, x := range m.P[q] {
+			total += x
+		}
+		if math.Abs(total-1) > probabilityTolerance {
+			return fmt.Errorf("%w: outcomes of %v sum to %v, want 1", ErrInvalidInput, q, total)
+		}
+	}
+	return nil
+}
+
+// Probability returns the probability that a run generates exactly input
+// and then stops: the product of P along its run and Stop in its last
+// state. It is 0 when input has no run or the run ends outside F.
+func (m *PFA[Q, Sigma]) Probability(input []Sigma) float64 {
+	q, p := m.Q0, 1.0
+	for _, a := range input {
+		qNext, ok := m.next(q, a)
+		if !ok {
+			return 0
+		}
+		p *= m.P[q][a]
+		q = qNext
+	}
+	return p * m.Stop[q]
+}
+
+// SampleRun draws a random walk of at most n steps from q0: in each state
+// it picks the next symbol or stopping with their probabilities, drawing
+// from rng so equal seeds give equal walks. The second result is true if
+// the walk ended by stopping, and false if it was cut at n steps or
+// reached a state without outcomes.
+func (m *PFA[Q, Sigma]) SampleRun(rng *rand.Rand, n int) (Walk[Q, Sigma], bool) {
+	symbols := m.Sigma.sorted()
+	var w Walk[Q, Sigma]
+	q := m.Q0
+	stopped := false
+	for i := 0; i <= n; i++ {
+		x := rng.Float64()
+		if x < m.Stop[q] {
+			stopped = true
+			break
+		}
+		x -= m.Stop[q]
+		if i == n {
+			break
+		}
+		picked := false
+		var last WalkStep[Q, Sigma]
+		for _, a := range symbols {
+			p, ok := m.P[q][a]
+			if !ok || p == 0 {
+				continue
+			}
+			qNext, _ := m.next(q, a)
+			last = WalkStep[Q, Sigma]{Symbol: a, State: qNext}
+			picked = true
+			if x < p {
+				break
+			}
+			x -= p
+		}
+		if !picked {
+			break
+		}
+		// Rounding may leave x just above the last outcome; take it.
+		w.Steps = append(w.Steps, last)
+		q = last.State
+	}
+	w.Accepted = m.F.Has(q)
+	return w, stopped
+}
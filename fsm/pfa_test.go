@@ -0,0 +1,122 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestFitProbabilities checks maximum-likelihood and smoothed estimates.
+func TestFitProbabilities(t *testing.T) {
+	d := buildModThree()
+	// From S0: "1" twice, "0" once; the "0" run stops in S0.
+	traces := [][]Bit{{Zero}, {One, One}, {One, One}}
+
+	p, err := d.FitProbabilities(traces, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// S0 outcomes: 0 (1x), 1 (2x), stop (3x) -> 1/6, 2/6, 3/6
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-12 }
+	if !near(p.P[S0][Zero], 1.0/6) || !near(p.P[S0][One], 2.0/6) || !near(p.Stop[S0], 3.0/6) {
+		t.Fatalf("S0 row = %v stop=%v", p.P[S0], p.Stop[S0])
+	}
+	// S2 is never visited: uniform over {0, 1, stop}.
+	if !near(p.P[S2][Zero], 1.0/3) || !near(p.Stop[S2], 1.0/3) {
+		t.Fatalf("S2 row = %v stop=%v", p.P[S2], p.Stop[S2])
+	}
+
+	p, err = d.FitProbabilities(traces, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// S1 saw "1" twice, nothing else: (2+1)/(2+3)
+	if !near(p.P[S1][One], 3.0/5) || !near(p.P[S1][Zero], 1.0/5) {
+		t.Fatalf("smoothed S1 row = %v", p.P[S1])
+	}
+}
+
+// TestFitProbabilities_BadTrace rejects traces that are not accepted runs.
+func TestFitProbabilities_BadTrace(t *testing.T) {
+	d := literalDFA("ab")
+	if _, err := d.FitProbabilities([][]rune{[]rune("a")}, 0); err == nil {
+		t.Fatal("expected error for trace ending in non-accepting state")
+	}
+	if _, err := d.FitProbabilities([][]rune{[]rune("b")}, 0); err == nil {
+		t.Fatal("expected error for trace with undefined transition")
+	}
+}
+
+// TestPFA_Validate rejects rows that are not distributions.
+func TestPFA_Validate(t *testing.T) {
+	d := buildModThree()
+	good := map[State]map[Bit]float64{
+		S0: {Zero: 0.25, One: 0.25},
+		S1: {Zero: 0.5, One: 0.5},
+		S2: {Zero: 0.5, One: 0.5},
+	}
+	if _, err := NewPFA(d, good, map[State]float64{S0: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	for name, stop := range map[string]map[State]float64{
+		"short row": {S0: 0.25},
+		"bad value": {S0: 0.5, S1: -0.5},
+	} {
+		if _, err := NewPFA(d, good, stop); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+	div3, _ := d.PruneToFinals(NewSet(S0))
+	if _, err := NewPFA(div3, good, map[State]float64{S0: 0.5, S1: 0}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("stop in non-final state: got %v", err)
+	}
+}
+
+// TestPFA_Sample checks that sampled runs follow Probability.
+func TestPFA_Sample(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	p, err := NewPFA(div3, map[State]map[Bit]float64{
+		S0: {Zero: 0.25, One: 0.25},
+		S1: {Zero: 0.5, One: 0.5},
+		S2: {Zero: 0.5, One: 0.5},
+	}, map[State]float64{S0: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-12 }
+	if got := p.Probability(nil); !near(got, 0.5) {
+		t.Fatalf("P(ε) = %v", got)
+	}
+	// 11: 0.25 · 0.5 · 0.5
+	if got := p.Probability([]Bit{One, One}); !near(got, 0.0625) {
+		t.Fatalf("P(11) = %v", got)
+	}
+	if got := p.Probability([]Bit{One}); got != 0 {
+		t.Fatalf("P(1) = %v, want 0 for a rejected word", got)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	const runs = 20000
+	counts := map[string]int{}
+	for i := 0; i < runs; i++ {
+		w, stopped := p.SampleRun(rng, 50)
+		if !stopped {
+			continue
+		}
+		if !w.Accepted {
+			t.Fatalf("stopped walk %v not accepted", w.Input())
+		}
+		counts[fmt.Sprint(w.Input())]++
+	}
+	for _, word := range [][]Bit{nil, {Zero}, {One, One}} {
+		got := float64(counts[fmt.Sprint(word)]) / runs
+		if want := p.Probability(word); math.Abs(got-want) > 0.02 {
+			t.Errorf("%v sampled %.3f, want %.3f", word, got, want)
+		}
+	}
+	if w, stopped := p.SampleRun(rng, 0); len(w.Steps) != 0 || (stopped && !w.Accepted) {
+		t.Fatalf("n=0: %v, %v", w, stopped)
+	}
+}
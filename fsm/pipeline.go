@@ -0,0 +1,83 @@
+package fsm
+
+import "context"
+
+// ---------- Pipelines ----------
+
+// Stage is one step of a processing pipeline. Feed consumes one input and
+// passes each resulting output to emit before returning, so a chain of
+// stages processes every symbol in a single pass and a slow downstream
+// stage holds back the ones before it.
+type Stage[In any, Out any] interface {
+	Feed(in In, emit func(Out) error) error
+}
+
+// StageFunc adapts a function to the Stage interface.
+type StageFunc[In any, Out any] func(in In, emit func(Out) error) error
+
+func (f StageFunc[In, Out]) Feed(in In, emit func(Out) error) error { return f(in, emit) }
+
+// MooreStage runs d as a Moore machine: on each input symbol it steps and
+// emits output(q') for the state entered. The stage keeps the current state,
+// so use a fresh one per stream.
+func MooreStage[Q comparable, Sigma comparable, Out any](d *DFA[Q, Sigma], output func(Q) Out) Stage[Sigma, Out] {
+	q := d.Q0
+	return StageFunc[Sigma, Out](func(a Sigma, emit func(Out) error) error {
+		qNext, err := d.Step(q, a)
+		if err != nil {
+			return err
+		}
+		q = qNext
+		return emit(output(q))
+	})
+}
+
+// Pipeline chains two stages: every output of first is fed to second. Longer
+// cascades nest: Pipeline(a, Pipeline(b, c)).
+func Pipeline[A any, B any, C any](first Stage[A, B], second Stage[B, C]) Stage[A, C] {
+	return StageFunc[A, C](func(in A, emit func(C) error) error {
+		return first.Feed(in, func(mid B) error { return second.Feed(mid, emit) })
+	})
+}
+
+// Collect feeds input through s and returns all outputs.
+func Collect[In any, Out any](s Stage[In, Out], input []In) ([]Out, error) {
+	var out []Out
+	emit := func(o Out) error {
+		out = append(out, o)
+		return nil
+	}
+	for _, in := range input {
+		if err := s.Feed(in, emit); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// Stream feeds values from in through s and sends the outputs to out until
+// in is closed, ctx is done, or a stage fails. Sends block, so a slow reader
+// of out slows the whole pipeline instead of buffering. out is not closed.
+func Stream[In any, Out any](ctx context.Context, s Stage[In, Out], in <-chan In, out chan<- Out) error {
+	emit := func(o Out) error {
+		select {
+		case out <- o:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := s.Feed(v, emit); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
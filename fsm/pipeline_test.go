@@ -0,0 +1,64 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+)
+
+// divisibleStage reports, per bit, whether the prefix read so far is
+// divisible by three.
+func divisibleStage() Stage[Bit, bool] {
+	return MooreStage(buildModThree(), func(q State) bool { return q == S0 })
+}
+
+// countTrue emits a running count of true inputs, skipping false ones.
+func countTrue() Stage[bool, int] {
+	n := 0
+	return StageFunc[bool, int](func(b bool, emit func(int) error) error {
+		if !b {
+			return nil
+		}
+		n++
+		return emit(n)
+	})
+}
+
+// TestPipeline_Collect chains a Moore stage with a filtering stage.
+func TestPipeline_Collect(t *testing.T) {
+	// Prefixes of 1,1,0,0,1,1: 1, 3, 6, 12, 25, 51.
+	got, err := Collect(Pipeline(divisibleStage(), countTrue()), []Bit{One, One, Zero, Zero, One, One})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[3] != 4 {
+		t.Fatalf("got %v, want [1 2 3 4]", got)
+	}
+	if _, err := Collect(divisibleStage(), []Bit{One, 9}); err == nil {
+		t.Fatal("expected the stage error to propagate")
+	}
+}
+
+// TestPipeline_Stream runs a pipeline over channels.
+func TestPipeline_Stream(t *testing.T) {
+	in := make(chan Bit)
+	out := make(chan int)
+	done := make(chan error, 1)
+	go func() { done <- Stream(context.Background(), Pipeline(divisibleStage(), countTrue()), in, out) }()
+
+	go func() {
+		for _, b := range []Bit{One, One, Zero} {
+			in <- b
+		}
+		close(in)
+	}()
+	var got []int
+	for len(got) < 2 {
+		got = append(got, <-out)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != 1 || got[1] != 2 {
+		t.Fatalf("got %v, want [1 2]", got)
+	}
+}
@@ -0,0 +1,223 @@
+// Package presets provides parameterized machines for common control-flow
+// patterns: debounce, circuit breaker and retry with exponential backoff.
+//
+// Time is discrete: callers feed a Tick event at a fixed interval (for
+// example from a time.Ticker) alongside the domain events, so the machines
+// stay ordinary complete DFAs that can be analysed, exported and driven by
+// fsm.Runner or fsm.Registry sessions like any other.
+package presets
+
+import (
+	"fmt"
+
+	"fsm/fsm"
+)
+
+// Event is an input symbol of the preset machines.
+type Event int
+
+const (
+	Tick    Event = iota // one unit of time has passed
+	Signal               // a raw input edge (debounce)
+	Success              // the guarded operation succeeded
+	Failure              // the guarded operation failed
+)
+
+func (e Event) String() string {
+	switch e {
+	case Tick:
+		return "Tick"
+	case Signal:
+		return "Signal"
+	case Success:
+		return "Success"
+	case Failure:
+		return "Failure"
+	}
+	return fmt.Sprintf("Event(%d)", int(e))
+}
+
+// build enumerates the states reachable from start under step and returns
+// the complete DFA over alphabet, with F given by final.
+func build[Q comparable](start Q, alphabet []Event, step func(Q, Event) Q, final func(Q) bool) (*fsm.DFA[Q, Event], error) {
+	seen := fsm.NewSet(start)
+	order := []Q{start}
+	delta := make(fsm.TransitionFn[Q, Event])
+	var finals []Q
+	for i := 0; i < len(order); i++ {
+		q := order[i]
+		if final(q) {
+			finals = append(finals, q)
+		}
+		delta[q] = make(map[Event]Q, len(alphabet))
+		for _, e := range alphabet {
+			next := step(q, e)
+			delta[q][e] = next
+			if !seen.Has(next) {
+				seen[next] = struct{}{}
+				order = append(order, next)
+			}
+		}
+	}
+	return fsm.NewDFA(order, alphabet, start, finals, delta, true)
+}
+
+// ---------- Debounce ----------
+
+// DebounceState is a state of the debounce machine. Quiet counts the ticks
+// since the last Signal while a signal is pending.
+type DebounceState struct {
+	Pending bool
+	Quiet   int
+	Fired   bool // the debounced signal fires on entering this state
+}
+
+// Debounce returns a machine that fires once a Signal has been followed by
+// quiet ticks without another Signal. F is the set of firing states: after
+// each Feed, a final state means "act now".
+func Debounce(quiet int) (*fsm.DFA[DebounceState, Event], error) {
+	if quiet < 1 {
+		return nil, fmt.Errorf("%w: debounce needs quiet >= 1, got %d", fsm.ErrInvalidInput, quiet)
+	}
+	step := func(s DebounceState, e Event) DebounceState {
+		switch {
+		case e == Signal:
+			return DebounceState{Pending: true}
+		case s.Pending && s.Quiet+1 == quiet:
+			return DebounceState{Fired: true}
+		case s.Pending:
+			return DebounceState{Pending: true, Quiet: s.Quiet + 1}
+		}
+		return DebounceState{}
+	}
+	return build(DebounceState{}, []Event{Tick, Signal}, step, func(s DebounceState) bool { return s.Fired })
+}
+
+// ---------- Circuit breaker ----------
+
+// BreakerPhase is the coarse state of a circuit breaker.
+type BreakerPhase int
+
+const (
+	Closed   BreakerPhase = iota // calls flow; failures are counted
+	Open                         // calls are rejected until the cooldown ends
+	HalfOpen                     // one probe call is allowed
+)
+
+func (p BreakerPhase) String() string {
+	switch p {
+	case Closed:
+		return "Closed"
+	case Open:
+		return "Open"
+	case HalfOpen:
+		return "HalfOpen"
+	}
+	return fmt.Sprintf("BreakerPhase(%d)", int(p))
+}
+
+// BreakerState is a state of the circuit breaker: its phase plus the
+// consecutive failures (Closed) or elapsed cooldown ticks (Open).
+type BreakerState struct {
+	Phase BreakerPhase
+	N     int
+}
+
+// CircuitBreaker returns a breaker that opens after threshold consecutive
+// failures, stays open for cooldown ticks, then lets one probe through:
+// its Success closes the breaker and its Failure reopens it. F is the set
+// of states in which calls are allowed (Closed and HalfOpen).
+func CircuitBreaker(threshold, cooldown int) (*fsm.DFA[BreakerState, Event], error) {
+	if threshold < 1 || cooldown < 1 {
+		return nil, fmt.Errorf("%w: circuit breaker needs threshold and cooldown >= 1, got %d, %d",
+			fsm.ErrInvalidInput, threshold, cooldown)
+	}
+	step := func(s BreakerState, e Event) BreakerState {
+		switch s.Phase {
+		case Closed:
+			switch e {
+			case Success:
+				return BreakerState{Closed, 0}
+			case Failure:
+				if s.N+1 == threshold {
+					return BreakerState{Open, 0}
+				}
+				return BreakerState{Closed, s.N + 1}
+			}
+		case Open:
+			if e == Tick {
+				if s.N+1 == cooldown {
+					return BreakerState{HalfOpen, 0}
+				}
+				return BreakerState{Open, s.N + 1}
+			}
+		case HalfOpen:
+			switch e {
+			case Success:
+				return BreakerState{Closed, 0}
+			case Failure:
+				return BreakerState{Open, 0}
+			}
+		}
+		return s
+	}
+	allowed := func(s BreakerState) bool { return s.Phase != Open }
+	return build(BreakerState{Closed, 0}, []Event{Tick, Success, Failure}, step, allowed)
+}
+
+// ---------- Retry with exponential backoff ----------
+
+// RetryState is a state of the retry machine. Attempt counts attempts made
+// (from 1); Wait is the number of ticks left before the next attempt.
+type RetryState struct {
+	Attempt int
+	Wait    int
+	Done    bool // the operation succeeded
+	GaveUp  bool // maxAttempts attempts all failed
+}
+
+// Retry returns a machine for up to maxAttempts attempts. After failed
+// attempt k it waits min(base·2^(k-1), maxWait) ticks, then the next
+// attempt is due: a state with Wait == 0 and neither Done nor GaveUp means
+// "attempt now". F is {Done}.
+func Retry(maxAttempts, base, maxWait int) (*fsm.DFA[RetryState, Event], error) {
+	if maxAttempts < 1 || base < 1 || maxWait < base {
+		return nil, fmt.Errorf("%w: retry needs maxAttempts, base >= 1 and maxWait >= base, got %d, %d, %d",
+			fsm.ErrInvalidInput, maxAttempts, base, maxWait)
+	}
+	backoff := func(k int) int {
+		d := base
+		for i := 1; i < k && d < maxWait; i++ {
+			d *= 2
+		}
+		if d > maxWait {
+			d = maxWait
+		}
+		return d
+	}
+	step := func(s RetryState, e Event) RetryState {
+		if s.Done || s.GaveUp {
+			return s
+		}
+		if s.Wait > 0 {
+			if e == Tick {
+				s.Wait--
+				if s.Wait == 0 {
+					s.Attempt++
+				}
+			}
+			return s
+		}
+		switch e {
+		case Success:
+			return RetryState{Attempt: s.Attempt, Done: true}
+		case Failure:
+			if s.Attempt == maxAttempts {
+				return RetryState{Attempt: s.Attempt, GaveUp: true}
+			}
+			return RetryState{Attempt: s.Attempt, Wait: backoff(s.Attempt)}
+		}
+		return s
+	}
+	return build(RetryState{Attempt: 1}, []Event{Tick, Success, Failure}, step, func(s RetryState) bool { return s.Done })
+}
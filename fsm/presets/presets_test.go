@@ -0,0 +1,79 @@
+package presets
+
+import (
+	"testing"
+
+	"fsm/fsm"
+)
+
+// TestDebounce fires only after a quiet period.
+func TestDebounce(t *testing.T) {
+	d, err := Debounce(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := fsm.NewRunner(d, 0)
+	var fired []int
+	events := []Event{Signal, Tick, Signal, Tick, Tick, Tick, Tick}
+	for i, e := range events {
+		q, err := r.Feed(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.F.Has(q) {
+			fired = append(fired, i)
+		}
+	}
+	if len(fired) != 1 || fired[0] != 4 {
+		t.Fatalf("fired at %v, want [4]", fired)
+	}
+}
+
+// TestCircuitBreaker walks Closed → Open → HalfOpen → Closed/Open.
+func TestCircuitBreaker(t *testing.T) {
+	d, err := CircuitBreaker(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _ := d.Run([]Event{Failure, Failure, Success, Failure, Failure})
+	if q != (BreakerState{Closed, 2}) {
+		t.Fatalf("got %v, want two counted failures", q)
+	}
+	q, _ = d.Step(q, Failure)
+	if q.Phase != Open || d.F.Has(q) {
+		t.Fatalf("got %v, want Open and not allowed", q)
+	}
+	q, _ = d.Run([]Event{Failure, Failure, Failure, Tick, Tick})
+	if q.Phase != HalfOpen || !d.F.Has(q) {
+		t.Fatalf("got %v, want HalfOpen", q)
+	}
+	if next, _ := d.Step(q, Failure); next.Phase != Open {
+		t.Fatalf("failed probe: got %v, want Open", next)
+	}
+	if next, _ := d.Step(q, Success); next.Phase != Closed {
+		t.Fatalf("successful probe: got %v, want Closed", next)
+	}
+}
+
+// TestRetry checks the backoff schedule and both outcomes.
+func TestRetry(t *testing.T) {
+	d, err := Retry(4, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Waits after attempts 1, 2, 3 are 1, 2, 3 (capped from 4) ticks.
+	in := []Event{Failure, Tick, Failure, Tick, Tick, Failure, Tick, Tick, Tick}
+	q, err := d.Run(in)
+	if err != nil || q != (RetryState{Attempt: 4}) {
+		t.Fatalf("got %+v,%v want attempt 4 due", q, err)
+	}
+	if ok, _, _ := d.Accepts(append(in, Success)); !ok {
+		t.Fatal("success on the last attempt should be accepted")
+	}
+	if q, _ := d.Step(q, Failure); !q.GaveUp {
+		t.Fatalf("got %+v, want GaveUp", q)
+	}
+	if _, err := Retry(0, 1, 1); err == nil {
+		t.Fatal("expected a parameter error")
+	}
+}
@@ -0,0 +1,210 @@
+package fsm
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ---------- Product constructions ----------
+
+// Pair is a state of a product machine: the current states of both
+// operands. In the constructions that keep running after one operand has
+// rejected (Union and the differences), DeadA or DeadB is set once that
+// operand has hit an undefined transition, and its state is then the zero
+// value.
+type Pair[Q1 comparable, Q2 comparable] struct {
+	A     Q1
+	B     Q2
+	DeadA bool
+	DeadB bool
+}
+
+// Intersect returns the product automaton accepting the inputs accepted by
+// both a and b, e.g. "divisible by 3 and ends in 0" from the two machines.
+// Only pairs reachable from (a.Q0, b.Q0) are built. Σ is the union of the
+// two alphabets; a symbol outside one operand's alphabet has no
+// transitions, as in Harmonize with RejectUnknown.
+func Intersect[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) *DFA[Pair[Q1, Q2], Sigma] {
+	d, _ := IntersectBudget(a, b, Budget{})
+	return d
+}
+
+// IntersectBudget is Intersect under a Budget: a product may have
+// |Q1|·|Q2| states. Progress is reported in the phase "product", with the
+// pairs discovered as States and those expanded as Processed.
+func IntersectBudget[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma], budget Budget) (*DFA[Pair[Q1, Q2], Sigma], error) {
+	return product(a, b, false, func(inA, inB bool) bool { return inA && inB }, budget)
+}
+
+// Union returns the product automaton accepting the inputs accepted by a or
+// b. A run continues while either operand has a transition, tracking the
+// other as dead (see Pair). Σ is the union of the two alphabets.
+func Union[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) *DFA[Pair[Q1, Q2], Sigma] {
+	d, _ := UnionBudget(a, b, Budget{})
+	return d
+}
+
+// UnionBudget is Union under a Budget; see IntersectBudget.
+func UnionBudget[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma], budget Budget) (*DFA[Pair[Q1, Q2], Sigma], error) {
+	return product(a, b, true, func(inA, inB bool) bool { return inA || inB }, budget)
+}
+
+// Difference returns the product automaton accepting the inputs accepted
+// by a but not by b. Σ is the union of the two alphabets.
+func Difference[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) *DFA[Pair[Q1, Q2], Sigma] {
+	d, _ := DifferenceBudget(a, b, Budget{})
+	return d
+}
+
+// DifferenceBudget is Difference under a Budget; see IntersectBudget.
+func DifferenceBudget[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma], budget Budget) (*DFA[Pair[Q1, Q2], Sigma], error) {
+	return product(a, b, true, func(inA, inB bool) bool { return inA && !inB }, budget)
+}
+
+// SymmetricDifference returns the product automaton accepting the inputs
+// accepted by exactly one of a and b. Its language is empty, i.e.
+// AcceptDepth reports false, exactly when a and b accept the same inputs,
+// whatever their states look like.
+func SymmetricDifference[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma]) *DFA[Pair[Q1, Q2], Sigma] {
+	d, _ := SymmetricDifferenceBudget(a, b, Budget{})
+	return d
+}
+
+// SymmetricDifferenceBudget is SymmetricDifference under a Budget; see
+// IntersectBudget.
+func SymmetricDifferenceBudget[Q1 comparable, Q2 comparable, Sigma comparable](a *DFA[Q1, Sigma], b *DFA[Q2, Sigma], budget Budget) (*DFA[Pair[Q1, Q2], Sigma], error) {
+	return product(a, b, true, func(inA, inB bool) bool { return inA != inB }, budget)
+}
+
+// product builds the pairs reachable from (a.Q0, b.Q0). With keepDead, a
+// pair survives an undefined transition of one operand as long as the
+// other has one; otherwise both must have one. accept decides F from
+// whether each operand accepts.
+func product[Q1 comparable, Q2 comparable, Sigma comparable](
+	a *DFA[Q1, Sigma],
+	b *DFA[Q2, Sigma],
+	keepDead bool,
+	accept func(inA, inB bool) bool,
+	budget Budget,
+) (*DFA[Pair[Q1, Q2], Sigma], error) {
+	sigma := copySet(a.Sigma)
+	for s := range b.Sigma {
+		sigma[s] = struct{}{}
+	}
+	symbols := sigma.sorted()
+
+	start := Pair[Q1, Q2]{A: a.Q0, B: b.Q0}
+	out := &DFA[Pair[Q1, Q2], Sigma]{
+		Q:     NewSet(start),
+		Sigma: sigma,
+		Q0:    start,
+		F:     make(Set[Pair[Q1, Q2]]),
+		Delta: make(TransitionFn[Pair[Q1, Q2], Sigma]),
+	}
+	var sym Sigma
+	pairSize := int64(unsafe.Sizeof(start))
+	perPair, perEdge := 2*pairSize+64, int64(unsafe.Sizeof(sym))+pairSize+16
+	var memory int64
+	tracker := budget.track()
+	queue := []Pair[Q1, Q2]{start}
+	for processed := 1; len(queue) > 0; processed++ {
+		p := queue[0]
+		queue = queue[1:]
+		if accept(!p.DeadA && a.F.Has(p.A), !p.DeadB && b.F.Has(p.B)) {
+			out.F[p] = struct{}{}
+		}
+		row := make(map[Sigma]Pair[Q1, Q2])
+		for _, s := range symbols {
+			var n Pair[Q1, Q2]
+			okA, okB := false, false
+			if !p.DeadA {
+				n.A, okA = a.next(p.A, s)
+			}
+			if !p.DeadB {
+				n.B, okB = b.next(p.B, s)
+			}
+			if !(okA && okB) && !(keepDead && (okA || okB)) {
+				continue
+			}
+			n.DeadA, n.DeadB = !okA, !okB
+			row[s] = n
+			if !out.Q.Has(n) {
+				out.Q[n] = struct{}{}
+				queue = append(queue, n)
+			}
+		}
+		out.Delta[p] = row
+		memory += perPair + int64(len(row))*perEdge
+		if err := tracker.step("product", len(out.Q), processed, len(queue), memory+int64(len(queue))*perPair); err != nil {
+			return nil, err
+		}
+	}
+	if err := tracker.done("product", len(out.Q), len(out.Q)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ---------- Completion and complement ----------
+
+// Complete returns a copy of d in which every undefined transition leads to
+// sink, a new non-accepting state looping on every symbol, so algorithms
+// that assume a total δ can run on it. If δ is already total, the copy has
+// no sink. sink must not already be a state of d.
+func (d *DFA[Q, Sigma]) Complete(sink Q) (*DFA[Q, Sigma], error) {
+	if d.Q.Has(sink) {
+		return nil, fmt.Errorf("%w: sink %v already in Q", ErrInvalidInput, sink)
+	}
+	out := d.clone()
+	for q := range d.Q {
+		for a := range d.Sigma {
+			if _, ok := d.next(q, a); ok {
+				continue
+			}
+			if out.Delta[q] == nil {
+				out.Delta[q] = make(map[Sigma]Q, len(d.Sigma))
+			}
+			out.Delta[q][a] = sink
+			out.Q[sink] = struct{}{}
+		}
+	}
+	if out.Q.Has(sink) {
+		out.Delta[sink] = make(map[Sigma]Q, len(d.Sigma))
+		for a := range d.Sigma {
+			out.Delta[sink][a] = sink
+		}
+	}
+	return out, nil
+}
+
+// Complement returns a DFA accepting exactly the inputs over Σ that d
+// rejects. Flipping F is only correct on a complete machine, so the states
+// are renumbered as by Renumber(OrderBFS) and, when a transition is
+// missing, a new non-accepting sink state n routes them all, which then
+// becomes accepting. d is not modified.
+func (d *DFA[Q, Sigma]) Complement() *DFA[int, Sigma] {
+	out, _ := d.Renumber(OrderBFS)
+	sink := len(out.Q)
+	for q := 0; q < sink; q++ {
+		for a := range out.Sigma {
+			if _, ok := out.Delta[q][a]; !ok {
+				out.Delta[q][a] = sink
+				out.Q[sink] = struct{}{}
+			}
+		}
+	}
+	if out.Q.Has(sink) {
+		out.Delta[sink] = make(map[Sigma]int, len(out.Sigma))
+		for a := range out.Sigma {
+			out.Delta[sink][a] = sink
+		}
+	}
+	flipped := make(Set[int], len(out.Q)-len(out.F))
+	for q := range out.Q {
+		if !out.F.Has(q) {
+			flipped[q] = struct{}{}
+		}
+	}
+	out.F = flipped
+	return out
+}
@@ -0,0 +1,192 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// endsInZero accepts binary words whose last bit is 0.
+func endsInZero() *DFA[bool, Bit] {
+	return Must(NewDFA([]bool{false, true}, []Bit{Zero, One}, false, []bool{true},
+		TransitionFn[bool, Bit]{
+			false: {Zero: true, One: false},
+			true:  {Zero: true, One: false},
+		}, true))
+}
+
+// TestIntersect checks "divisible by 3 and ends in 0", i.e. divisible by 6.
+func TestIntersect(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	p := Intersect(div3, endsInZero())
+	for _, w := range allWords([]Bit{Zero, One}, 8) {
+		n := 0
+		for _, b := range w {
+			n = 2*n + int(b-Zero)
+		}
+		ok, _, err := p.Accepts(w)
+		if want := len(w) > 0 && n%6 == 0; err != nil || ok != want {
+			t.Fatalf("%s: accepted %v (err %v), want %v", string(w), ok, err, want)
+		}
+	}
+	if len(p.Q) != 6 {
+		t.Errorf("product has %d states, want 6 reachable pairs", len(p.Q))
+	}
+}
+
+// TestIntersectAlphabets rejects symbols only one operand knows.
+func TestIntersectAlphabets(t *testing.T) {
+	p := Intersect(literalDFA("ab"), literalDFA("ac"))
+	if !p.Sigma.Has('b') || !p.Sigma.Has('c') {
+		t.Fatalf("Σ = %v, want the union", p.Sigma)
+	}
+	for _, w := range []string{"ab", "ac", "a"} {
+		if ok, _, _ := p.Accepts([]rune(w)); ok {
+			t.Errorf("%q accepted", w)
+		}
+	}
+	q := Intersect(literalDFA("ab"), literalDFA("ab"))
+	if ok, _, err := q.Accepts([]rune("ab")); !ok || err != nil {
+		t.Errorf("ab rejected by ab ∩ ab: %v", err)
+	}
+}
+
+// TestUnion mixes state types and keeps running after one operand rejects.
+func TestUnion(t *testing.T) {
+	u := Union(literalDFA("ab"), Must(NewDFA([]bool{false, true}, []rune{'a', 'c'}, false, []bool{true},
+		TransitionFn[bool, rune]{false: {'a': false, 'c': true}}, false)))
+	for w, want := range map[string]bool{
+		"ab": true, "c": true, "aac": true, "aaac": true,
+		"": false, "a": false, "abc": false, "ac": true, "b": false, "cc": false,
+	} {
+		if ok, _, _ := u.Accepts([]rune(w)); ok != want {
+			t.Errorf("%q: accepted %v, want %v", w, ok, want)
+		}
+	}
+	for p := range u.Q {
+		if p.DeadA && p.DeadB {
+			t.Errorf("pair %+v dead on both sides", p)
+		}
+	}
+}
+
+func TestDifference(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	d := Difference(div3, endsInZero())
+	for _, w := range allWords([]Bit{Zero, One}, 8) {
+		n := 0
+		for _, b := range w {
+			n = 2*n + int(b-Zero)
+		}
+		ok, _, _ := d.Accepts(w)
+		endsZero := len(w) > 0 && w[len(w)-1] == Zero
+		if want := n%3 == 0 && !endsZero; ok != want {
+			t.Fatalf("%s: accepted %v, want %v", string(w), ok, want)
+		}
+	}
+	// Inputs the subtrahend cannot read stay in the difference.
+	if ok, _, _ := Difference(literalDFA("abc"), literalDFA("ab")).Accepts([]rune("abc")); !ok {
+		t.Error("abc ∉ {abc} \\ {ab}")
+	}
+}
+
+// TestSymmetricDifference decides equivalence of differently built machines.
+func TestSymmetricDifference(t *testing.T) {
+	d := buildModThree()
+	if _, ok := SymmetricDifference(d, d.Minimize()).AcceptDepth(); ok {
+		t.Error("machine differs from its minimization")
+	}
+	div3, _ := d.PruneToFinals(NewSet(S0))
+	x := SymmetricDifference(div3, d)
+	if _, ok := x.AcceptDepth(); !ok {
+		t.Fatal("multiples of three equal to all words")
+	}
+	if ok, _, _ := x.Accepts([]Bit("1")); !ok {
+		t.Error("1 is accepted by exactly one operand")
+	}
+	if ok, _, _ := x.Accepts([]Bit("11")); ok {
+		t.Error("11 is accepted by both")
+	}
+}
+
+func TestComplement(t *testing.T) {
+	c := literalDFA("ab").Complement()
+	if len(c.Q) != 4 {
+		t.Fatalf("%d states, want 3 and a sink", len(c.Q))
+	}
+	for _, w := range allWords([]rune{'a', 'b'}, 4) {
+		ok, _, err := c.Accepts(w)
+		if want := string(w) != "ab"; err != nil || ok != want {
+			t.Fatalf("%q: accepted %v (err %v), want %v", string(w), ok, err, want)
+		}
+	}
+
+	// A complete machine gets no sink.
+	d, _ := buildModThree().PruneToFinals(NewSet(S0))
+	if c := d.Complement(); len(c.Q) != 3 {
+		t.Errorf("complete machine: %d states, want 3", len(c.Q))
+	}
+	if _, ok := SymmetricDifference(d, d.Complement().Complement()).AcceptDepth(); ok {
+		t.Error("double complement changed the language")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	d := literalDFA("ab")
+	c, err := d.Complete(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Q) != 4 || c.F.Has(-1) {
+		t.Fatalf("Q = %v, F = %v", c.Q, c.F)
+	}
+	for q := range c.Q {
+		if len(c.Delta[q]) != len(c.Sigma) {
+			t.Errorf("state %d still partial: %v", q, c.Delta[q])
+		}
+	}
+	sameLanguage(t, d, c, []rune{'a', 'b'}, 4)
+	if len(d.Q) != 3 {
+		t.Error("Complete modified its receiver")
+	}
+
+	if _, err := d.Complete(1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("existing sink: err = %v", err)
+	}
+	m := buildModThree()
+	if c, _ := m.Complete(State(9)); c.Q.Has(State(9)) {
+		t.Error("sink added to a complete machine")
+	}
+}
+
+// counter counts symbols modulo n and accepts at 0.
+func counter(n int) *DFA[int, rune] {
+	states := make([]int, n)
+	delta := make(TransitionFn[int, rune], n)
+	for i := range states {
+		states[i] = i
+		delta[i] = map[rune]int{'a': (i + 1) % n}
+	}
+	return Must(NewDFA(states, []rune("a"), 0, []int{0}, delta, true))
+}
+
+// TestProductBudget stops a product of two coprime counters, which has
+// every one of its 97·101 pairs.
+func TestProductBudget(t *testing.T) {
+	a, b := counter(97), counter(101)
+	_, err := IntersectBudget(a, b, Budget{MaxStates: 1000})
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "states" {
+		t.Fatalf("MaxStates: got %v", err)
+	}
+	if _, err := UnionBudget(a, b, Budget{MaxMemory: 64 << 10}); !errors.As(err, &le) || le.Limit != "memory" {
+		t.Fatalf("MaxMemory: got %v", err)
+	}
+	var last Progress
+	d, err := SymmetricDifferenceBudget(a, b, Budget{OnProgress: func(p Progress) error { last = p; return nil }})
+	if err != nil || len(d.Q) != 97*101 || !last.Done || last.Phase != "product" {
+		t.Fatalf("unlimited: %d states, %v, last progress %+v", len(d.Q), err, last)
+	}
+	if _, err := DifferenceBudget(a, b, Budget{MaxStates: 97 * 101}); err != nil {
+		t.Fatalf("exact limit: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package protocol enforces method-call orderings with a finite state
+// machine. A library describes the allowed lifecycle of an object, e.g.
+// Open → Write* → Close, and embeds a Guard whose Check calls reject
+// out-of-order use with a descriptive error.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"fsm/fsm"
+)
+
+// ErrViolation is matched by every *Violation via errors.Is.
+var ErrViolation = errors.New("protocol violation")
+
+// Spec describes a protocol: the lifecycle states, the state an object
+// starts in, the states in which it may be abandoned, and for each state
+// the methods allowed there and the state each one leads to.
+type Spec struct {
+	Start       string
+	Final       []string
+	Transitions map[string]map[string]string // state → method → next state
+}
+
+// Machine validates the spec and builds its DFA. States and methods are
+// collected from Start, Final and Transitions.
+func (s Spec) Machine() (*fsm.DFA[string, string], error) {
+	states := fsm.NewSet(s.Start)
+	methods := fsm.NewSet[string]()
+	for _, f := range s.Final {
+		states[f] = struct{}{}
+	}
+	delta := make(fsm.TransitionFn[string, string], len(s.Transitions))
+	for from, row := range s.Transitions {
+		states[from] = struct{}{}
+		delta[from] = make(map[string]string, len(row))
+		for method, to := range row {
+			states[to] = struct{}{}
+			methods[method] = struct{}{}
+			delta[from][method] = to
+		}
+	}
+	return fsm.NewDFA(keys(states), keys(methods), s.Start, s.Final, delta, false)
+}
+
+func keys(s fsm.Set[string]) []string {
+	out := make([]string, 0, len(s))
+	for k := range s {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HistoryLimit is the number of accepted calls a Guard remembers for its
+// violation reports, so a long-lived guard uses constant memory.
+const HistoryLimit = 32
+
+// Guard tracks one object's position in a protocol. It is safe for
+// concurrent use.
+type Guard struct {
+	mu      sync.Mutex
+	d       *fsm.DFA[string, string]
+	state   string
+	history []string // the last HistoryLimit accepted calls, oldest first
+	calls   int      // all accepted calls
+}
+
+// New returns a guard in the start state of d.
+func New(d *fsm.DFA[string, string]) *Guard {
+	return &Guard{d: d, state: d.Q0}
+}
+
+// MustNew builds a guard from a spec and panics if the spec is invalid; it
+// is meant for package-level protocol definitions.
+func MustNew(s Spec) *Guard {
+	return New(fsm.Must(s.Machine()))
+}
+
+// Clone returns a guard for the same protocol in the start state, for
+// guarding another object.
+func (g *Guard) Clone() *Guard { return New(g.d) }
+
+// Check records a call to method. If the protocol does not allow it in the
+// current state it returns a *Violation and the state is unchanged.
+func (g *Guard) Check(method string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	next, err := g.d.Step(g.state, method)
+	if err != nil {
+		return g.violation(method)
+	}
+	g.state = next
+	if len(g.history) == HistoryLimit {
+		copy(g.history, g.history[1:])
+		g.history = g.history[:HistoryLimit-1]
+	}
+	g.history = append(g.history, method)
+	g.calls++
+	return nil
+}
+
+// Done reports whether the object may be abandoned now, i.e. the current
+// state is final. It returns a *Violation with an empty Method otherwise.
+func (g *Guard) Done() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.d.F.Has(g.state) {
+		return nil
+	}
+	return g.violation("")
+}
+
+// State returns the current protocol state.
+func (g *Guard) State() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.state
+}
+
+func (g *Guard) violation(method string) *Violation {
+	var allowed []string
+	for m := range g.d.Delta[g.state] {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return &Violation{
+		Method:  method,
+		State:   g.state,
+		Allowed: allowed,
+		History: append([]string(nil), g.history...),
+		Calls:   g.calls,
+	}
+}
+
+// Violation describes a call the protocol does not allow.
+type Violation struct {
+	Method  string   // the rejected call; empty when reported by Done
+	State   string   // protocol state at the time
+	Allowed []string // methods allowed in State
+	History []string // the last HistoryLimit calls accepted, oldest first
+	Calls   int      // calls accepted in all; more than len(History) if some were dropped
+}
+
+func (v *Violation) Error() string {
+	var b strings.Builder
+	if v.Method == "" {
+		fmt.Fprintf(&b, "protocol: object abandoned in state %q", v.State)
+	} else {
+		fmt.Fprintf(&b, "protocol: %s not allowed in state %q", v.Method, v.State)
+	}
+	if len(v.Allowed) > 0 {
+		fmt.Fprintf(&b, " (allowed: %s)", strings.Join(v.Allowed, ", "))
+	}
+	if len(v.History) > 0 {
+		more := ""
+		if v.Calls > len(v.History) {
+			more = "..., "
+		}
+		fmt.Fprintf(&b, "; after %s%s", more, strings.Join(v.History, ", "))
+	}
+	return b.String()
+}
+
+// Is makes errors.Is(err, ErrViolation) true.
+func (v *Violation) Is(target error) bool { return target == ErrViolation }
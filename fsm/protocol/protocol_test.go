@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var fileSpec = Spec{
+	Start: "new",
+	Final: []string{"new", "closed"},
+	Transitions: map[string]map[string]string{
+		"new":  {"Open": "open"},
+		"open": {"Write": "open", "Close": "closed"},
+	},
+}
+
+// TestGuard_Lifecycle follows Open → Write* → Close.
+func TestGuard_Lifecycle(t *testing.T) {
+	g := MustNew(fileSpec)
+	for _, m := range []string{"Open", "Write", "Write", "Close"} {
+		if err := g.Check(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Done(); err != nil || g.State() != "closed" {
+		t.Fatalf("Done = %v in state %q", err, g.State())
+	}
+}
+
+// TestGuard_Violation checks the error details and that state is kept.
+func TestGuard_Violation(t *testing.T) {
+	g := MustNew(fileSpec)
+	if err := g.Check("Open"); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Check("Open")
+	var v *Violation
+	if !errors.As(err, &v) || !errors.Is(err, ErrViolation) {
+		t.Fatalf("got %v, want a *Violation", err)
+	}
+	if v.State != "open" || len(v.Allowed) != 2 || v.Allowed[0] != "Close" || len(v.History) != 1 {
+		t.Fatalf("violation = %+v", v)
+	}
+	want := `protocol: Open not allowed in state "open" (allowed: Close, Write); after Open`
+	if err.Error() != want {
+		t.Fatalf("message = %q", err.Error())
+	}
+	if g.State() != "open" {
+		t.Fatalf("state changed to %q", g.State())
+	}
+	if err := g.Done(); !errors.Is(err, ErrViolation) {
+		t.Fatalf("Done = %v, want a violation", err)
+	}
+	if g.Clone().State() != "new" {
+		t.Fatal("Clone should start fresh")
+	}
+}
+
+// TestGuard_HistoryBound keeps only the last HistoryLimit calls of a
+// long-lived guard.
+func TestGuard_HistoryBound(t *testing.T) {
+	g := MustNew(Spec{
+		Start: "closed",
+		Final: []string{"closed"},
+		Transitions: map[string]map[string]string{
+			"closed": {"Open": "open"},
+			"open":   {"Write": "open", "Close": "closed"},
+		},
+	})
+	for i := 0; i < 1000; i++ {
+		for _, m := range []string{"Open", "Write", "Close"} {
+			if err := g.Check(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(g.history) != HistoryLimit || cap(g.history) > 2*HistoryLimit {
+		t.Fatalf("history holds %d calls, capacity %d", len(g.history), cap(g.history))
+	}
+	err := g.Check("Close")
+	var v *Violation
+	if !errors.As(err, &v) || v.Calls != 3000 || len(v.History) != HistoryLimit || v.History[HistoryLimit-1] != "Close" {
+		t.Fatalf("violation = %+v", v)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "; after ..., ") {
+		t.Fatalf("message = %q", msg)
+	}
+}
@@ -0,0 +1,148 @@
+package fsm
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ---------- Fingerprints ----------
+
+// Fingerprint returns a stable SHA-256 digest of the machine definition
+// (Q, Σ, q0, F and δ), independent of map iteration order. It hashes the
+// edges and each default once, not every symbol a default covers, so it
+// costs time in the size of the definition rather than |Q|·|Σ|.
+func (d *DFA[Q, Sigma]) Fingerprint() string {
+	h := sha256.New()
+	states := d.Q.sorted()
+	fmt.Fprintf(h, "Q=%#v\nΣ=%#v\nq0=%#v\nF=%#v\n", states, d.Sigma.sorted(), d.Q0, d.F.sorted())
+	for _, q := range states {
+		row := make(Set[Sigma], len(d.Delta[q]))
+		for a := range d.Delta[q] {
+			if d.Sigma.Has(a) {
+				row[a] = struct{}{}
+			}
+		}
+		for _, a := range row.sorted() {
+			fmt.Fprintf(h, "δ(%#v,%#v)=%#v\n", q, a, d.Delta[q][a])
+		}
+		if qNext, ok := d.Default[q]; ok && len(row) < len(d.Sigma) {
+			fmt.Fprintf(h, "δ(%#v,*)=%#v\n", q, qNext)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ---------- Record and replay ----------
+
+// recordHeader is the first line of a run file.
+type recordHeader[Q comparable] struct {
+	Fingerprint string    `json:"fingerprint"`
+	Start       Q         `json:"start"`
+	Time        time.Time `json:"time"`
+}
+
+// recordStep is one consumed symbol in a run file.
+type recordStep[Q comparable, Sigma comparable] struct {
+	Time   time.Time `json:"time"`
+	Symbol Sigma     `json:"symbol"`
+	State  Q         `json:"state"`
+	Err    string    `json:"error,omitempty"`
+}
+
+// Recorder steps a DFA and writes every step to a run file as JSON lines:
+// a header with the machine fingerprint, then one line per symbol with its
+// timestamp and resulting state. Q and Sigma must be JSON-encodable.
+type Recorder[Q comparable, Sigma comparable] struct {
+	dfa   *DFA[Q, Sigma]
+	enc   *json.Encoder
+	state Q
+}
+
+// NewRecorder writes the run-file header for d and starts a run at q0.
+func NewRecorder[Q comparable, Sigma comparable](w io.Writer, d *DFA[Q, Sigma]) (*Recorder[Q, Sigma], error) {
+	r := &Recorder[Q, Sigma]{dfa: d, enc: json.NewEncoder(w), state: d.Q0}
+	hdr := recordHeader[Q]{Fingerprint: d.Fingerprint(), Start: d.Q0, Time: time.Now()}
+	if err := r.enc.Encode(hdr); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// State returns the current state of the recorded run.
+func (r *Recorder[Q, Sigma]) State() Q { return r.state }
+
+// Step applies one symbol and records it. A transition error is recorded
+// (the state is left unchanged) and also returned.
+func (r *Recorder[Q, Sigma]) Step(a Sigma) (Q, error) {
+	q, err := r.dfa.Step(r.state, a)
+	rec := recordStep[Q, Sigma]{Time: time.Now(), Symbol: a, State: q}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+	if werr := r.enc.Encode(rec); werr != nil {
+		return r.state, werr
+	}
+	r.state = q
+	return q, err
+}
+
+// Divergence is a recorded step whose outcome differs on replay.
+type Divergence[Q comparable, Sigma comparable] struct {
+	Index    int
+	Symbol   Sigma
+	Recorded Q
+	Got      Q
+	RecErr   string
+	GotErr   string
+}
+
+// ReplayReport summarizes a replay.
+type ReplayReport[Q comparable, Sigma comparable] struct {
+	SameMachine bool // the run file fingerprint matches the replay machine
+	Steps       int
+	Divergences []Divergence[Q, Sigma]
+}
+
+// Replay re-executes a run file against d (possibly a newer version of the
+// recorded machine), starting from d.Q0, and reports every step whose
+// resulting state or error differs from the recording.
+func Replay[Q comparable, Sigma comparable](r io.Reader, d *DFA[Q, Sigma]) (*ReplayReport[Q, Sigma], error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var hdr recordHeader[Q]
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, fmt.Errorf("run file header: %w", err)
+	}
+	rep := &ReplayReport[Q, Sigma]{SameMachine: hdr.Fingerprint == d.Fingerprint()}
+
+	q := d.Q0
+	for {
+		var rec recordStep[Q, Sigma]
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return rep, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("run file step %d: %w", rep.Steps, err)
+		}
+		qNext, err := d.Step(q, rec.Symbol)
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+		if qNext != rec.State || gotErr != rec.Err {
+			rep.Divergences = append(rep.Divergences, Divergence[Q, Sigma]{
+				Index: rep.Steps, Symbol: rec.Symbol,
+				Recorded: rec.State, Got: qNext,
+				RecErr: rec.Err, GotErr: gotErr,
+			})
+		}
+		q = qNext
+		rep.Steps++
+	}
+}
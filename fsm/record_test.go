@@ -0,0 +1,51 @@
+package fsm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestFingerprint is stable across rebuilds and sensitive to δ.
+func TestFingerprint(t *testing.T) {
+	a, b := buildModThree(), buildModThree()
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Fatal("identical machines should have equal fingerprints")
+	}
+	b.Delta[S2][One] = S0
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Fatal("changing δ should change the fingerprint")
+	}
+}
+
+// TestRecordReplay records a run and replays it against an edited machine.
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf, buildModThree())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []Bit{One, Zero, One, One} {
+		if _, err := rec.Step(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := buf.Bytes()
+
+	rep, err := Replay(bytes.NewReader(data), buildModThree())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.SameMachine || rep.Steps != 4 || len(rep.Divergences) != 0 {
+		t.Fatalf("unexpected report for same machine: %+v", rep)
+	}
+
+	edited := buildModThree()
+	edited.Delta[S2][One] = S0 // the third step (index 2) is S2 --1--> S2
+	rep, err = Replay(bytes.NewReader(data), edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.SameMachine || len(rep.Divergences) == 0 || rep.Divergences[0].Index != 2 {
+		t.Fatalf("expected divergence at index 2, got %+v", rep)
+	}
+}
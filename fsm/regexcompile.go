@@ -0,0 +1,296 @@
+package fsm
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+)
+
+// ---------- Regular expressions → DFA ----------
+
+// MaxRegexAlphabet bounds the alphabet CompileRegex derives from a pattern.
+// Classes like `.` or `\pL` exceed it; use CompileRegexAlphabet for them.
+const MaxRegexAlphabet = 4096
+
+// CompileRegex compiles a pattern in Go regexp syntax (RE2: no
+// backreferences) into a minimal DFA over runes. The pattern describes
+// whole inputs, so `^`/`\A` at the start and `$`/`\z` at the end are
+// redundant; elsewhere they hold only at the ends of the input, so `a^b`
+// matches nothing. Other empty-width assertions (`\b`, multi-line
+// anchors) are rejected. Σ is every rune the pattern mentions, so a class may not
+// cover more than MaxRegexAlphabet runes in total; for `.` or `\pL` use
+// CompileRegexIntervals.
+func CompileRegex(pattern string) (*DFA[int, rune], error) {
+	re, err := parseRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	alphabet, err := regexAlphabet(re)
+	if err != nil {
+		return nil, err
+	}
+	return compileRegex(re, alphabet)
+}
+
+// CompileRegexAlphabet is CompileRegex over a given alphabet: classes are
+// restricted to it, so `.` means "any symbol of alphabet".
+func CompileRegexAlphabet(pattern string, alphabet []rune) (*DFA[int, rune], error) {
+	re, err := parseRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return compileRegex(re, alphabet)
+}
+
+// CompileRegexIntervals is CompileRegex for patterns with large classes,
+// such as `\p{L}+` or `.`: it returns a machine over the whole rune domain
+// whose transitions are rune ranges, so its size depends on the number of
+// class boundaries rather than on the number of runes.
+func CompileRegexIntervals(pattern string) (*IntervalDFA[int, rune], error) {
+	re, err := parseRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// Runes between two consecutive cuts behave alike in every instruction,
+	// so each interval is represented by its first rune.
+	cuts := regexCuts(re)
+	d, err := compileRegex(re, cuts)
+	if err != nil {
+		return nil, err
+	}
+	var rules []IntervalRule[int, rune]
+	for _, q := range d.Q.sorted() {
+		for i, lo := range cuts {
+			qNext, ok := d.next(q, lo)
+			if !ok {
+				continue
+			}
+			hi := rune(unicode.MaxRune)
+			if i+1 < len(cuts) {
+				hi = cuts[i+1] - 1
+			}
+			if n := len(rules); n > 0 && rules[n-1].From == q && rules[n-1].To == qNext && rules[n-1].On.Hi+1 == lo {
+				rules[n-1].On.Hi = hi
+				continue
+			}
+			rules = append(rules, IntervalRule[int, rune]{From: q, On: Range[rune]{lo, hi}, To: qNext})
+		}
+	}
+	return NewIntervalDFA(d.Q.sorted(), Range[rune]{0, unicode.MaxRune}, d.Q0, d.F.sorted(), rules, false)
+}
+
+func parseRegex(pattern string) (*syntax.Regexp, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return re.Simplify(), nil
+}
+
+// regexAlphabet collects the runes the pattern can match.
+func regexAlphabet(re *syntax.Regexp) ([]rune, error) {
+	seen := make(Set[rune])
+	var walk func(re *syntax.Regexp) error
+	walk = func(re *syntax.Regexp) error {
+		switch re.Op {
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			return fmt.Errorf("%w: %v matches any rune; use CompileRegexAlphabet", ErrInvalidInput, re)
+		case syntax.OpLiteral:
+			for _, r := range re.Rune {
+				seen[r] = struct{}{}
+				if re.Flags&syntax.FoldCase != 0 {
+					for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+						seen[f] = struct{}{}
+					}
+				}
+			}
+		case syntax.OpCharClass:
+			for i := 0; i+1 < len(re.Rune); i += 2 {
+				lo, hi := re.Rune[i], re.Rune[i+1]
+				if int(hi-lo)+1+len(seen) > MaxRegexAlphabet {
+					return fmt.Errorf("%w: class %v has more than %d runes; use CompileRegexAlphabet", ErrInvalidInput, re, MaxRegexAlphabet)
+				}
+				for r := lo; r <= hi; r++ {
+					seen[r] = struct{}{}
+				}
+			}
+		}
+		for _, sub := range re.Sub {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(re); err != nil {
+		return nil, err
+	}
+	return seen.sorted(), nil
+}
+
+// regexCuts returns, in order, the first rune of every interval of the
+// rune domain on which all instructions of the pattern agree.
+func regexCuts(re *syntax.Regexp) []rune {
+	cuts := NewSet[rune](0)
+	add := func(lo, hi rune) {
+		cuts[lo] = struct{}{}
+		if hi < unicode.MaxRune {
+			cuts[hi+1] = struct{}{}
+		}
+	}
+	var walk func(re *syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		switch re.Op {
+		case syntax.OpAnyCharNotNL:
+			add('\n', '\n')
+		case syntax.OpLiteral:
+			for _, r := range re.Rune {
+				add(r, r)
+				if re.Flags&syntax.FoldCase != 0 {
+					for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+						add(f, f)
+					}
+				}
+			}
+		case syntax.OpCharClass:
+			for i := 0; i+1 < len(re.Rune); i += 2 {
+				add(re.Rune[i], re.Rune[i+1])
+			}
+		}
+		for _, sub := range re.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+	out := make([]rune, 0, len(cuts))
+	for r := range cuts {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// compileRegex turns the compiled program into an ε-NFA over alphabet,
+// then determinizes and minimizes it. Each instruction pc has two states:
+// pc before any symbol is read, where `^` holds, and pc+n after, where it
+// does not. An instruction asserting `$` is accepting if the program can
+// finish from there without reading another symbol, and has no other way
+// out, so `a^b` and `a$b` denote the empty language.
+func compileRegex(re *syntax.Regexp, alphabet []rune) (*DFA[int, rune], error) {
+	prog, err := regexProg(re)
+	if err != nil {
+		return nil, err
+	}
+	n := len(prog.Inst)
+
+	states := make([]int, 2*n)
+	delta := make(NFATransitionFn[int, rune])
+	epsilon := make(map[int]Set[int])
+	var finals []int
+	for pc := range prog.Inst {
+		inst := &prog.Inst[pc]
+		for _, atStart := range []bool{true, false} {
+			q, shift := pc, 0
+			if !atStart {
+				q, shift = pc+n, n
+			}
+			states[q] = q
+			switch inst.Op {
+			case syntax.InstAlt, syntax.InstAltMatch:
+				epsilon[q] = NewSet(int(inst.Out)+shift, int(inst.Arg)+shift)
+			case syntax.InstNop, syntax.InstCapture:
+				epsilon[q] = NewSet(int(inst.Out) + shift)
+			case syntax.InstEmptyWidth:
+				op := syntax.EmptyOp(inst.Arg)
+				switch {
+				case op&syntax.EmptyBeginText != 0 && !atStart:
+					// `^` after a symbol: no way out.
+				case op&syntax.EmptyEndText != 0:
+					if regexEnds(prog, int(inst.Out), atStart, make(Set[int])) {
+						finals = append(finals, q)
+					}
+				default:
+					epsilon[q] = NewSet(int(inst.Out) + shift)
+				}
+			case syntax.InstMatch:
+				finals = append(finals, q)
+			case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+				row := make(map[rune]Set[int])
+				for _, a := range alphabet {
+					if inst.MatchRune(a) {
+						row[a] = NewSet(int(inst.Out) + n)
+					}
+				}
+				delta[q] = row
+			}
+		}
+	}
+	nfa, err := NewEpsilonNFA(states, alphabet, prog.Start, finals, delta, epsilon)
+	if err != nil {
+		return nil, err
+	}
+	return nfa.Determinize().Minimize(), nil
+}
+
+// regexProg compiles re, rejecting the assertions other than `^` and `$`.
+func regexProg(re *syntax.Regexp) (*syntax.Prog, error) {
+	prog, err := syntax.Compile(re)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	for pc := range prog.Inst {
+		inst := &prog.Inst[pc]
+		if inst.Op == syntax.InstEmptyWidth && syntax.EmptyOp(inst.Arg)&^(syntax.EmptyBeginText|syntax.EmptyEndText) != 0 {
+			return nil, fmt.Errorf("%w: assertion in %v is not supported", ErrInvalidInput, re)
+		}
+	}
+	return prog, nil
+}
+
+// regexEnds reports whether the program can match from pc without reading
+// a symbol; atStart tells whether `^` holds there.
+func regexEnds(prog *syntax.Prog, pc int, atStart bool, seen Set[int]) bool {
+	if seen.Has(pc) {
+		return false
+	}
+	seen[pc] = struct{}{}
+	inst := &prog.Inst[pc]
+	switch inst.Op {
+	case syntax.InstMatch:
+		return true
+	case syntax.InstAlt, syntax.InstAltMatch:
+		return regexEnds(prog, int(inst.Out), atStart, seen) || regexEnds(prog, int(inst.Arg), atStart, seen)
+	case syntax.InstNop, syntax.InstCapture:
+		return regexEnds(prog, int(inst.Out), atStart, seen)
+	case syntax.InstEmptyWidth:
+		if syntax.EmptyOp(inst.Arg)&syntax.EmptyBeginText != 0 && !atStart {
+			return false
+		}
+		return regexEnds(prog, int(inst.Out), atStart, seen)
+	}
+	return false
+}
+
+// ---------- Leftmost-first matching ----------
+
+// CompileRegexFirst compiles a pattern for leftmost-first matching, the
+// semantics of the regexp package and Perl: alternatives are tried in the
+// order written and repetitions are greedy unless marked lazy, so `a|ab`
+// matches "a" in "ab" where leftmost-longest (POSIX) matching gives "ab".
+//
+// A DFA carries no priorities, so they are compiled into the machine: as
+// in RE2, a state stands for the threads of the program still running, in
+// priority order, and a thread that reaches a match cuts off the threads
+// below it. Scanning the result for the longest match from each start, as
+// FindAll does by default, then yields the leftmost-first matches. The
+// machine accepts only the matches a leftmost-first matcher can report, a
+// subset of the language of CompileRegex: use it for scanning, and
+// CompileRegex for membership.
+func CompileRegexFirst(pattern string) (*DFA[int, rune], error) {
+	t, err := compileTagged(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	return t.scan, nil
+}
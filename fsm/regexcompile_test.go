@@ -0,0 +1,139 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// TestCompileRegex compares with the regexp package on every word.
+func TestCompileRegex(t *testing.T) {
+	for _, pattern := range []string{
+		`(0|1(01*0)*1)*`,
+		`ab+c?`,
+		`^[a-c]{2,3}$`,
+		`(?i)Ab|c*`,
+		`(a|b)*abb`,
+		``,
+		`[^\x00-\x{10FFFF}]`,
+		`a^b`,
+		`a$b`,
+		`(^a|b)c`,
+		`a(b$|c)`,
+		`(^a)*b`,
+		`^$|a`,
+	} {
+		d, err := CompileRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for _, w := range allWords([]rune("abcAB01"), 4) {
+			got, _, _ := d.Accepts(w) // runes outside Σ are rejected
+			if want := re.MatchString(string(w)); got != want {
+				t.Fatalf("%s on %q: %v, want %v", pattern, string(w), got, want)
+			}
+		}
+	}
+	d, _ := CompileRegex(`(0|1(01*0)*1)*`)
+	if len(d.Q) != 3 {
+		t.Errorf("multiples of three: %d states, want 3", len(d.Q))
+	}
+	for _, pattern := range []string{`a^b`, `a$b`} {
+		d := Must(CompileRegex(pattern))
+		if ok, _, _ := d.Accepts([]rune("ab")); ok || len(d.F) != 0 {
+			t.Errorf("%s: accepts \"ab\" or has finals %v", pattern, d.F)
+		}
+	}
+}
+
+func TestCompileRegexAlphabet(t *testing.T) {
+	if _, err := CompileRegex(`a.c`); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("any-char without alphabet: err = %v", err)
+	}
+	d, err := CompileRegexAlphabet(`a.c`, []rune("abcx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for w, want := range map[string]bool{"axc": true, "abc": true, "ac": false} {
+		if ok, _, _ := d.Accepts([]rune(w)); ok != want {
+			t.Errorf("%q: %v, want %v", w, ok, want)
+		}
+	}
+	for _, bad := range []string{`a(`, `\bword\b`, `\pL+`} {
+		if _, err := CompileRegex(bad); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: err = %v", bad, err)
+		}
+	}
+}
+
+// TestCompileRegexFirst compares leftmost-first scans with the regexp
+// package, including patterns where priorities pick a shorter match.
+func TestCompileRegexFirst(t *testing.T) {
+	for _, pattern := range []string{
+		`a|ab`,
+		`ab|a`,
+		`a+?`,
+		`a*?b`,
+		`(a|ab)(c|bcd)`,
+		`(a+|b+)+?c?`,
+		`x*`,
+		`ab??`,
+		`(?i)A|ab`,
+	} {
+		d, err := CompileRegexFirst(pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		std := regexp.MustCompile(pattern)
+		for _, w := range allWords([]rune("abcd"), 5) {
+			var want []Match
+			for _, loc := range std.FindAllStringIndex(string(w), -1) {
+				if loc[0] < loc[1] {
+					want = append(want, Match{loc[0], loc[1]})
+				}
+			}
+			got := d.FindAll(w, ScanOptions{})
+			if len(got) != len(want) || (len(got) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Fatalf("%s on %q: %v, regexp gives %v", pattern, string(w), got, want)
+			}
+		}
+	}
+	// The POSIX machine for the same pattern prefers the longer match.
+	in := []rune("ab")
+	if got := Must(CompileRegex(`a|ab`)).FindAll(in, ScanOptions{}); !reflect.DeepEqual(got, []Match{{0, 2}}) {
+		t.Errorf("leftmost-longest: %v", got)
+	}
+	if got := Must(CompileRegexFirst(`a|ab`)).FindAll(in, ScanOptions{}); !reflect.DeepEqual(got, []Match{{0, 1}}) {
+		t.Errorf("leftmost-first: %v", got)
+	}
+}
+
+// TestCompileRegexIntervals compares range machines for large classes with
+// the regexp package.
+func TestCompileRegexIntervals(t *testing.T) {
+	for _, pattern := range []string{
+		`\p{L}+\d*`,
+		`.b`,
+		`[^a-c]x?`,
+		`\p{Greek}|[0-9]{2}`,
+		`(?i)ß|a`,
+		`a^b`,
+	} {
+		m, err := CompileRegexIntervals(pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for _, w := range allWords([]rune("ab1é\nΩẞ"), 3) {
+			got, _, _ := m.Accepts(w)
+			if want := re.MatchString(string(w)); got != want {
+				t.Fatalf("%s on %q: %v, want %v", pattern, string(w), got, want)
+			}
+		}
+	}
+	if m := Must(CompileRegexIntervals(`\p{L}+`)); len(m.Q) != 2 {
+		t.Errorf(`\p{L}+: %d states, want 2`, len(m.Q))
+	}
+}
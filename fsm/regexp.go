@@ -0,0 +1,306 @@
+package fsm
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ---------- regexp-style facade ----------
+
+// Regexp wraps a rune DFA in the string-matching API of regexp.Regexp, so
+// code using the standard package can switch to DFA-backed machines with
+// few changes. Searches take time linear in the input; see DFA.FindAll.
+// Indices are byte offsets into the string, as in regexp; invalid UTF-8
+// decodes to U+FFFD one byte at a time. A Regexp is safe for concurrent
+// use, except for Longest.
+//
+// Unlike regexp, empty matches are never reported by the Find methods,
+// and replacement strings are literal. Capture groups are reported by the
+// Submatch methods of matchers compiled from a pattern.
+type Regexp[Q comparable] struct {
+	d    *DFA[Q, rune]
+	tags *TaggedDFA // capture groups of d, if compiled from a pattern
+	opts ScanOptions
+	pool *sync.Pool // of *scanner[Q, rune] for d
+
+	// The leftmost-longest machine Longest switches to, if any.
+	longest     *DFA[Q, rune]
+	longestTags *TaggedDFA
+}
+
+// NewRegexp returns a matcher for the language of d. Each match extends
+// greedily to the last position where d accepts, so `[0-9]+` finds "123"
+// in "123 45" as regexp.Compile does. The matches are leftmost-longest:
+// they differ from regexp's leftmost-first ones where an earlier
+// alternative matches less text, as `a|ab` on "ab", unless d was built by
+// CompileRegexFirst.
+func NewRegexp[Q comparable](d *DFA[Q, rune]) *Regexp[Q] {
+	re := &Regexp[Q]{}
+	re.use(d)
+	return re
+}
+
+// CompileRegexp compiles a pattern in Go regexp syntax into a matcher with
+// the leftmost-first semantics of regexp.Compile; see CompileRegexFirst
+// for the patterns it accepts.
+func CompileRegexp(pattern string) (*Regexp[int], error) {
+	first, err := compileTagged(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	longest, err := compileTagged(pattern, false)
+	if err != nil {
+		return nil, err
+	}
+	re := NewRegexp(first.scan)
+	re.tags = first
+	re.longest, re.longestTags = longest.scan, longest
+	return re, nil
+}
+
+// CompileRegexpPOSIX is like CompileRegexp but with leftmost-longest
+// semantics, like regexp.CompilePOSIX.
+func CompileRegexpPOSIX(pattern string) (*Regexp[int], error) {
+	longest, err := compileTagged(pattern, false)
+	if err != nil {
+		return nil, err
+	}
+	re := NewRegexp(longest.scan)
+	re.tags = longest
+	return re, nil
+}
+
+// Longest makes future searches prefer leftmost-longest matches, like
+// regexp.Regexp.Longest: a matcher from CompileRegexp switches to the
+// leftmost-longest machine for its pattern. Matchers from NewRegexp or
+// CompileRegexpPOSIX are leftmost-longest already and are unchanged. It
+// must not be called concurrently with other methods.
+func (re *Regexp[Q]) Longest() {
+	if re.longest != nil {
+		re.use(re.longest)
+		re.tags = re.longestTags
+		re.longest, re.longestTags = nil, nil
+	}
+}
+
+// use makes d the machine searches run on.
+func (re *Regexp[Q]) use(d *DFA[Q, rune]) {
+	re.d = d
+	re.pool = &sync.Pool{New: func() any { return newScanner(d) }}
+}
+
+// NumSubexp returns the number of capture groups, which is zero for a
+// matcher from NewRegexp.
+func (re *Regexp[Q]) NumSubexp() int {
+	if re.tags == nil {
+		return 0
+	}
+	return re.tags.NumSubexp()
+}
+
+// SubexpNames returns the names of the capture groups, as
+// regexp.Regexp.SubexpNames does.
+func (re *Regexp[Q]) SubexpNames() []string {
+	if re.tags == nil {
+		return []string{""}
+	}
+	return re.tags.SubexpNames()
+}
+
+// decode returns the runes of s and the byte offset of each rune, with a
+// final entry for len(s).
+func decode(s string) ([]rune, []int) {
+	runes := make([]rune, 0, len(s))
+	offsets := make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		runes = append(runes, r)
+		offsets = append(offsets, i)
+		i += size
+	}
+	return runes, append(offsets, len(s))
+}
+
+// matches returns the matches in runes.
+func (re *Regexp[Q]) matches(runes []rune) []Match {
+	sc := re.pool.Get().(*scanner[Q, rune])
+	defer re.pool.Put(sc)
+	return sc.findAll(runes, re.opts)
+}
+
+// find returns up to n matches as byte-offset pairs; n < 0 means all.
+func (re *Regexp[Q]) find(s string, n int) [][]int {
+	if n == 0 {
+		return nil
+	}
+	runes, offsets := decode(s)
+	var out [][]int
+	for _, m := range re.matches(runes) {
+		out = append(out, []int{offsets[m.Start], offsets[m.End]})
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
+
+// MatchString reports whether s contains a match. An empty match counts, so
+// a machine accepting the empty word matches every string.
+func (re *Regexp[Q]) MatchString(s string) bool {
+	return re.d.F.Has(re.d.Q0) || re.find(s, 1) != nil
+}
+
+// Match reports whether b contains a match.
+func (re *Regexp[Q]) Match(b []byte) bool { return re.MatchString(string(b)) }
+
+// FindStringIndex returns the byte offsets of the leftmost match in s, or
+// nil if there is none.
+func (re *Regexp[Q]) FindStringIndex(s string) []int {
+	if m := re.find(s, 1); m != nil {
+		return m[0]
+	}
+	return nil
+}
+
+// FindString returns the text of the leftmost match in s, or "".
+func (re *Regexp[Q]) FindString(s string) string {
+	if loc := re.FindStringIndex(s); loc != nil {
+		return s[loc[0]:loc[1]]
+	}
+	return ""
+}
+
+// FindAllStringIndex returns the byte offsets of successive non-overlapping
+// matches; n < 0 returns all of them.
+func (re *Regexp[Q]) FindAllStringIndex(s string, n int) [][]int { return re.find(s, n) }
+
+// FindAllString returns the text of successive non-overlapping matches;
+// n < 0 returns all of them.
+func (re *Regexp[Q]) FindAllString(s string, n int) []string {
+	locs := re.find(s, n)
+	if locs == nil {
+		return nil
+	}
+	out := make([]string, len(locs))
+	for i, loc := range locs {
+		out[i] = s[loc[0]:loc[1]]
+	}
+	return out
+}
+
+// ReplaceAllStringFunc returns a copy of src in which every match has been
+// replaced by repl applied to it.
+func (re *Regexp[Q]) ReplaceAllStringFunc(src string, repl func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.find(src, -1) {
+		b.WriteString(src[last:loc[0]])
+		b.WriteString(repl(src[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// ReplaceAllString returns a copy of src in which every match has been
+// replaced by repl. There are no groups, so repl is used literally, as in
+// regexp.Regexp.ReplaceAllLiteralString.
+func (re *Regexp[Q]) ReplaceAllString(src, repl string) string {
+	return re.ReplaceAllStringFunc(src, func(string) string { return repl })
+}
+
+// Split slices s into the substrings between matches, like
+// regexp.Regexp.Split; n < 0 returns all of them.
+func (re *Regexp[Q]) Split(s string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	var out []string
+	last := 0
+	for _, loc := range re.find(s, -1) {
+		if n > 0 && len(out) == n-1 {
+			break
+		}
+		out = append(out, s[last:loc[0]])
+		last = loc[1]
+	}
+	return append(out, s[last:])
+}
+
+// findSubmatch returns up to n matches with their capture groups as
+// byte-offset pairs; n < 0 means all.
+func (re *Regexp[Q]) findSubmatch(s string, n int) [][]int {
+	if n == 0 {
+		return nil
+	}
+	runes, offsets := decode(s)
+	var out [][]int
+	for _, m := range re.matches(runes) {
+		caps := []int{m.Start, m.End}
+		if re.tags != nil {
+			caps = re.tags.submatch(runes, m.Start, m.End)
+		}
+		for i, c := range caps {
+			if c >= 0 {
+				caps[i] = offsets[c]
+			}
+		}
+		out = append(out, caps)
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
+
+// submatches returns the text of each group located by caps, or "" for a
+// group that did not take part.
+func submatches(s string, caps []int) []string {
+	out := make([]string, len(caps)/2)
+	for i := range out {
+		if caps[2*i] >= 0 {
+			out[i] = s[caps[2*i]:caps[2*i+1]]
+		}
+	}
+	return out
+}
+
+// FindStringSubmatchIndex returns the byte offsets of the leftmost match in
+// s and of its capture groups, pair i locating group i, or nil if there is
+// no match. A group that did not take part is at -1, -1.
+func (re *Regexp[Q]) FindStringSubmatchIndex(s string) []int {
+	if m := re.findSubmatch(s, 1); m != nil {
+		return m[0]
+	}
+	return nil
+}
+
+// FindStringSubmatch returns the text of the leftmost match in s and of its
+// capture groups, or nil if there is no match.
+func (re *Regexp[Q]) FindStringSubmatch(s string) []string {
+	if caps := re.FindStringSubmatchIndex(s); caps != nil {
+		return submatches(s, caps)
+	}
+	return nil
+}
+
+// FindAllStringSubmatchIndex is the 'All' version of
+// FindStringSubmatchIndex; n < 0 returns all matches.
+func (re *Regexp[Q]) FindAllStringSubmatchIndex(s string, n int) [][]int {
+	return re.findSubmatch(s, n)
+}
+
+// FindAllStringSubmatch is the 'All' version of FindStringSubmatch; n < 0
+// returns all matches.
+func (re *Regexp[Q]) FindAllStringSubmatch(s string, n int) [][]string {
+	all := re.findSubmatch(s, n)
+	if all == nil {
+		return nil
+	}
+	out := make([][]string, len(all))
+	for i, caps := range all {
+		out[i] = submatches(s, caps)
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package fsm
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// digitsDFA accepts one or more ASCII digits.
+func digitsDFA() *DFA[int, rune] {
+	const digits = "0123456789"
+	delta := TransitionFn[int, rune]{0: OnAny(digits, 1), 1: OnAny(digits, 1)}
+	return Must(NewDFA([]int{0, 1}, Runes(digits), 0, []int{1}, delta, false))
+}
+
+// TestRegexp_Find checks byte offsets and greedy matches.
+func TestRegexp_Find(t *testing.T) {
+	re := NewRegexp(digitsDFA())
+	s := "héllo 42, wörld 7"
+	if !re.MatchString(s) || re.MatchString("none") {
+		t.Fatal("MatchString")
+	}
+	if got := re.FindStringIndex(s); !reflect.DeepEqual(got, []int{7, 9}) {
+		t.Fatalf("FindStringIndex = %v, want [7 9]", got)
+	}
+	if got := re.FindAllString(s, -1); !reflect.DeepEqual(got, []string{"42", "7"}) {
+		t.Fatalf("FindAllString = %q", got)
+	}
+	re.Longest()
+	if got := re.FindString(s); got != "42" {
+		t.Fatalf("FindString = %q", got)
+	}
+	if got := re.FindAllStringIndex(s, 1); !reflect.DeepEqual(got, [][]int{{7, 9}}) {
+		t.Fatalf("FindAllStringIndex(1) = %v", got)
+	}
+}
+
+// TestRegexp_Stdlib compares the facade with regexp on patterns without
+// alternation priorities, where the two must agree.
+func TestRegexp_Stdlib(t *testing.T) {
+	cases := []struct {
+		pattern string
+		inputs  []string
+	}{
+		{`[0-9]+`, []string{"123 45", "a1b22c333", "", "x"}},
+		{`ab*`, []string{"abbb a ab", "bbb", "aab"}},
+		{`foo|bar`, []string{"foobar barfoo", "fobar", "foofoo"}},
+		{`(ab)+`, []string{"ababab aba", "xabx"}},
+		{`[a-c]+x?`, []string{"abcx cba xx", "ccxx"}},
+		{`x*y`, []string{"xxy y xyy", "xxx"}},
+	}
+	for _, c := range cases {
+		std := regexp.MustCompile(c.pattern)
+		re := NewRegexp(Must(CompileRegex(c.pattern)))
+		for _, in := range c.inputs {
+			if got, want := re.FindAllStringIndex(in, -1), std.FindAllStringIndex(in, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %q: FindAllStringIndex = %v, regexp gives %v", c.pattern, in, got, want)
+			}
+			if got, want := re.ReplaceAllString(in, "#"), std.ReplaceAllLiteralString(in, "#"); got != want {
+				t.Errorf("%s on %q: ReplaceAllString = %q, regexp gives %q", c.pattern, in, got, want)
+			}
+			if got, want := re.MatchString(in), std.MatchString(in); got != want {
+				t.Errorf("%s on %q: MatchString = %v, regexp gives %v", c.pattern, in, got, want)
+			}
+		}
+	}
+}
+
+// TestRegexp_ReplaceSplit checks the rewriting helpers.
+func TestRegexp_ReplaceSplit(t *testing.T) {
+	re := NewRegexp(digitsDFA())
+	re.Longest()
+	if got := re.ReplaceAllString("a1b22c", "#"); got != "a#b#c" {
+		t.Fatalf("ReplaceAllString = %q", got)
+	}
+	if got := re.Split("a1b22c", -1); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("Split = %q", got)
+	}
+	if got := re.Split("a1b22c", 2); !reflect.DeepEqual(got, []string{"a", "b22c"}) {
+		t.Fatalf("Split(2) = %q", got)
+	}
+}
+
+// TestCompileRegexp checks leftmost-first matching against regexp, and
+// Longest against regexp's Longest.
+func TestCompileRegexp(t *testing.T) {
+	cases := []struct {
+		pattern string
+		inputs  []string
+	}{
+		{`a|ab`, []string{"ab abab", "aab"}},
+		{`[0-9]+?x|[0-9]`, []string{"12x 3", "x1"}},
+		{`(foo|foobar)baz?`, []string{"foobarbaz foobazz", "foobar"}},
+		{`b+?c*`, []string{"bbbccc bc", "cb"}},
+	}
+	for _, c := range cases {
+		re := Must(CompileRegexp(c.pattern))
+		posix := Must(CompileRegexpPOSIX(c.pattern))
+		std := regexp.MustCompile(c.pattern)
+		stdLongest := regexp.MustCompile(c.pattern)
+		stdLongest.Longest()
+		for _, in := range c.inputs {
+			if got, want := re.FindAllStringIndex(in, -1), std.FindAllStringIndex(in, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %q: leftmost-first %v, regexp gives %v", c.pattern, in, got, want)
+			}
+			if got, want := posix.FindAllStringIndex(in, -1), stdLongest.FindAllStringIndex(in, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %q: leftmost-longest %v, regexp gives %v", c.pattern, in, got, want)
+			}
+		}
+		re.Longest()
+		for _, in := range c.inputs {
+			if got, want := re.FindAllStringIndex(in, -1), stdLongest.FindAllStringIndex(in, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %q after Longest: %v, regexp gives %v", c.pattern, in, got, want)
+			}
+		}
+	}
+	if _, err := CompileRegexp(`a(`); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
+
+// TestRegexp_Submatch compares capture groups with regexp, byte offsets
+// included, before and after Longest.
+func TestRegexp_Submatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		inputs  []string
+	}{
+		{`(?P<key>[a-zé]+)=(?P<val>[0-9]*)`, []string{"clé=12 a= b=3", "=1"}},
+		{`(a|ab)(c|bcd)(d*)`, []string{"abcd abcdd", "acd"}},
+		{`(x)|(y)+`, []string{"xyy yx"}},
+	}
+	for _, c := range cases {
+		re := Must(CompileRegexp(c.pattern))
+		std := regexp.MustCompile(c.pattern)
+		if re.NumSubexp() != std.NumSubexp() || !reflect.DeepEqual(re.SubexpNames(), std.SubexpNames()) {
+			t.Fatalf("%s: groups %q, regexp has %q", c.pattern, re.SubexpNames(), std.SubexpNames())
+		}
+		for _, longest := range []bool{false, true} {
+			if longest {
+				re.Longest()
+				std.Longest()
+			}
+			for _, in := range c.inputs {
+				if got, want := re.FindAllStringSubmatchIndex(in, -1), std.FindAllStringSubmatchIndex(in, -1); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s on %q (longest %v): %v, regexp gives %v", c.pattern, in, longest, got, want)
+				}
+				if got, want := re.FindStringSubmatch(in), std.FindStringSubmatch(in); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s on %q (longest %v): %q, regexp gives %q", c.pattern, in, longest, got, want)
+				}
+			}
+		}
+	}
+	re := NewRegexp(digitsDFA())
+	if got := re.FindAllStringSubmatch("a1b22", -1); !reflect.DeepEqual(got, [][]string{{"1"}, {"22"}}) || re.NumSubexp() != 0 {
+		t.Errorf("no groups: %q", got)
+	}
+}
@@ -0,0 +1,237 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ---------- Hot reload ----------
+
+// ErrStaleSession is returned by a session whose state vanished in a reload
+// and could not be migrated.
+var ErrStaleSession = errors.New("session state no longer exists in machine")
+
+// Loader produces the current version of a machine definition.
+type Loader[Q comparable, Sigma comparable] func() (*DFA[Q, Sigma], error)
+
+// FileLoader returns a Loader that parses the file at path with parse.
+func FileLoader[Q comparable, Sigma comparable](path string, parse func([]byte) (*DFA[Q, Sigma], error)) Loader[Q, Sigma] {
+	return func() (*DFA[Q, Sigma], error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parse(data)
+	}
+}
+
+// Registry holds the live version of a machine and the sessions running on
+// it. Reload swaps in a new version atomically: sessions whose state still
+// exists continue on the new version, the rest are passed to Migrate and, if
+// that fails, flagged stale.
+type Registry[Q comparable, Sigma comparable] struct {
+	// Migrate maps a state that no longer exists to one in the new machine.
+	// It may be nil, in which case such sessions are flagged stale.
+	Migrate func(old Q, next *Frozen[Q, Sigma]) (Q, bool)
+	// KeepJournal makes sessions record the symbols they consume, so that
+	// Persist includes a journal for Replay migrations.
+	KeepJournal bool
+
+	load     Loader[Q, Sigma]
+	now      func() time.Time
+	mu       sync.Mutex
+	machine  *Frozen[Q, Sigma]
+	version  int
+	sessions map[*Session[Q, Sigma]]struct{}
+}
+
+// NewRegistry loads the first version of the machine.
+func NewRegistry[Q comparable, Sigma comparable](load Loader[Q, Sigma]) (*Registry[Q, Sigma], error) {
+	d, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &Registry[Q, Sigma]{
+		load:     load,
+		now:      time.Now,
+		machine:  d.Freeze(),
+		version:  1,
+		sessions: make(map[*Session[Q, Sigma]]struct{}),
+	}, nil
+}
+
+// Current returns the live machine and its version number.
+func (r *Registry[Q, Sigma]) Current() (*Frozen[Q, Sigma], int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.machine, r.version
+}
+
+// Reload calls the loader and, if the definition changed, swaps it in and
+// rebinds every open session. It returns the sessions flagged stale.
+// On a load error the live version is kept.
+func (r *Registry[Q, Sigma]) Reload() ([]*Session[Q, Sigma], error) {
+	d, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("reload: %w", err)
+	}
+	next := d.Freeze()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if next.Fingerprint() == r.machine.Fingerprint() {
+		return nil, nil
+	}
+	r.machine = next
+	r.version++
+
+	var stale []*Session[Q, Sigma]
+	for s := range r.sessions {
+		s.mu.Lock()
+		if !s.stale && !next.d.Q.Has(s.state) {
+			var q Q
+			ok := false
+			if r.Migrate != nil {
+				q, ok = r.Migrate(s.state, next)
+				ok = ok && next.d.Q.Has(q)
+			}
+			if ok {
+				s.state, s.since = q, r.now()
+			} else {
+				s.stale = true
+				stale = append(stale, s)
+			}
+		}
+		s.machine, s.version = next, r.version
+		s.mu.Unlock()
+	}
+	return stale, nil
+}
+
+// Watch calls Reload every interval until ctx is done. Load errors and
+// stale sessions are reported to the callbacks, which may be nil.
+func (r *Registry[Q, Sigma]) Watch(ctx context.Context, interval time.Duration,
+	onError func(error), onStale func([]*Session[Q, Sigma])) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			stale, err := r.Reload()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+			if len(stale) > 0 && onStale != nil {
+				onStale(stale)
+			}
+		}
+	}
+}
+
+// Session is a run bound to a Registry that follows reloads.
+type Session[Q comparable, Sigma comparable] struct {
+	reg     *Registry[Q, Sigma]
+	mu      sync.Mutex
+	machine *Frozen[Q, Sigma]
+	version int
+	state   Q
+	since   time.Time // when state was entered
+	stale   bool
+	journal []Sigma
+}
+
+// Open starts a session at q0 of the live machine.
+func (r *Registry[Q, Sigma]) Open() *Session[Q, Sigma] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := &Session[Q, Sigma]{reg: r, machine: r.machine, version: r.version, state: r.machine.Start(), since: r.now()}
+	r.sessions[s] = struct{}{}
+	return s
+}
+
+// Close detaches the session from reloads.
+func (s *Session[Q, Sigma]) Close() {
+	s.reg.mu.Lock()
+	defer s.reg.mu.Unlock()
+	delete(s.reg.sessions, s)
+}
+
+// State returns the current state, the machine version it refers to, and
+// whether the session is stale.
+func (s *Session[Q, Sigma]) State() (Q, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.version, s.stale
+}
+
+// Since returns when the session entered its current state. Symbols that
+// leave the state unchanged do not reset it.
+func (s *Session[Q, Sigma]) Since() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.since
+}
+
+// Feed applies one symbol on the session's current machine version.
+func (s *Session[Q, Sigma]) Feed(a Sigma) (Q, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stale {
+		return s.state, fmt.Errorf("%w: %v", ErrStaleSession, s.state)
+	}
+	q, err := s.machine.Step(s.state, a)
+	if err != nil {
+		return s.state, err
+	}
+	if q != s.state {
+		s.state, s.since = q, s.reg.now()
+	}
+	if s.reg.KeepJournal {
+		s.journal = append(s.journal, a)
+	}
+	return q, nil
+}
+
+// Persist returns the durable form of the session for storage across
+// deployments; Restore brings it back.
+func (s *Session[Q, Sigma]) Persist() PersistedSession[Q, Sigma] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return PersistedSession[Q, Sigma]{
+		Revision: s.machine.Fingerprint(),
+		State:    s.state,
+		Journal:  append([]Sigma(nil), s.journal...),
+	}
+}
+
+// Restore opens a session from its persisted form, carrying it forward to
+// the live machine with m when it was saved on an older revision. m may be
+// nil if p is known to be current.
+func (r *Registry[Q, Sigma]) Restore(p PersistedSession[Q, Sigma], m *Migrations[Q, Sigma]) (*Session[Q, Sigma], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p.Revision != r.machine.Fingerprint() {
+		if m == nil {
+			return nil, fmt.Errorf("%w from %.12s", ErrNoMigration, p.Revision)
+		}
+		var err error
+		if p, err = m.Migrate(p, r.machine.d); err != nil {
+			return nil, err
+		}
+	}
+	if !r.machine.d.Q.Has(p.State) {
+		return nil, fmt.Errorf("%w: %v", ErrStaleSession, p.State)
+	}
+	s := &Session[Q, Sigma]{
+		reg: r, machine: r.machine, version: r.version,
+		state: p.State, since: r.now(), journal: append([]Sigma(nil), p.Journal...),
+	}
+	r.sessions[s] = struct{}{}
+	return s, nil
+}
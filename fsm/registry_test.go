@@ -0,0 +1,71 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRegistry_Reload swaps versions, keeps valid sessions and flags stale ones.
+func TestRegistry_Reload(t *testing.T) {
+	current := buildModThree()
+	r, err := NewRegistry(func() (*DFA[State, Bit], error) { return current, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	onS1 := r.Open()
+	onS2 := r.Open()
+	onS1.Feed(One)
+	onS2.Feed(One)
+	onS2.Feed(Zero)
+
+	// Unchanged definition: no new version.
+	if _, err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, v := r.Current(); v != 1 {
+		t.Fatalf("version = %d, want 1", v)
+	}
+
+	// New version drops S2.
+	current = Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S0},
+		TransitionFn[State, Bit]{S0: {Zero: S0, One: S1}, S1: {Zero: S1, One: S0}}, true))
+	stale, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0] != onS2 {
+		t.Fatalf("stale = %v, want [onS2]", stale)
+	}
+	if q, v, isStale := onS1.State(); q != S1 || v != 2 || isStale {
+		t.Fatalf("onS1 = (%v, %d, %v), want (S1, 2, false)", q, v, isStale)
+	}
+	if q, _ := onS1.Feed(Zero); q != S1 {
+		t.Fatalf("onS1 should follow the new δ, got %v", q)
+	}
+	if _, err := onS2.Feed(Zero); !errors.Is(err, ErrStaleSession) {
+		t.Fatalf("expected ErrStaleSession, got %v", err)
+	}
+}
+
+// TestRegistry_Migrate maps vanished states instead of flagging them.
+func TestRegistry_Migrate(t *testing.T) {
+	current := buildModThree()
+	r, err := NewRegistry(func() (*DFA[State, Bit], error) { return current, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Migrate = func(old State, _ *Frozen[State, Bit]) (State, bool) { return S0, true }
+
+	s := r.Open()
+	s.Feed(One)
+	s.Feed(Zero) // S2
+	current = Must(NewDFA([]State{S0, S1}, []Bit{Zero, One}, S0, []State{S0},
+		TransitionFn[State, Bit]{S0: {Zero: S0, One: S1}, S1: {Zero: S1, One: S0}}, true))
+	if stale, _ := r.Reload(); len(stale) != 0 {
+		t.Fatalf("unexpected stale sessions %v", stale)
+	}
+	if q, _, _ := s.State(); q != S0 {
+		t.Fatalf("migrated state = %v, want S0", q)
+	}
+}
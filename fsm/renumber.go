@@ -0,0 +1,83 @@
+package fsm
+
+import "fmt"
+
+// ---------- Renumbering ----------
+
+// StateOrder says how Renumber numbers states.
+type StateOrder int
+
+const (
+	// OrderBFS numbers q0 as 0, then the reachable states in breadth-first
+	// order following the symbols in sorted order, then the unreachable
+	// states sorted. It is the numbering of Minimize and Compile, so it is
+	// stable for a given machine.
+	OrderBFS StateOrder = iota
+	// OrderSorted numbers the states in their sorted order, whatever q0 is.
+	OrderSorted
+)
+
+func (o StateOrder) String() string {
+	switch o {
+	case OrderBFS:
+		return "OrderBFS"
+	case OrderSorted:
+		return "OrderSorted"
+	}
+	return fmt.Sprintf("StateOrder(%d)", int(o))
+}
+
+// Renumber returns d with its states mapped to 0..n-1 in the given order,
+// and the mapping from the old states. The language and the shape of δ are
+// unchanged; use Compact to also drop unreachable states. An unknown order
+// is treated as OrderBFS.
+func (d *DFA[Q, Sigma]) Renumber(order StateOrder) (*DFA[int, Sigma], map[Q]int) {
+	symbols := d.Sigma.sorted()
+	var states []Q
+	if order == OrderSorted {
+		states = d.Q.sorted()
+	} else {
+		states = d.bfsOrder(symbols)
+	}
+	return d.renumber(states, symbols)
+}
+
+// Compact is Renumber(OrderBFS) without the states unreachable from q0,
+// giving the smallest numbering that preserves the language; the mapping
+// has no entries for the dropped states.
+func (d *DFA[Q, Sigma]) Compact() (*DFA[int, Sigma], map[Q]int) {
+	symbols := d.Sigma.sorted()
+	return d.renumber(d.bfsOrder(symbols)[:len(d.reachable())], symbols)
+}
+
+// renumber builds the machine over states, numbered by their position.
+// Transitions to states outside the list are dropped.
+func (d *DFA[Q, Sigma]) renumber(states []Q, symbols []Sigma) (*DFA[int, Sigma], map[Q]int) {
+	id := make(map[Q]int, len(states))
+	for i, q := range states {
+		id[q] = i
+	}
+	out := &DFA[int, Sigma]{
+		Q:     make(Set[int], len(states)),
+		Sigma: copySet(d.Sigma),
+		Q0:    id[d.Q0],
+		F:     make(Set[int], len(d.F)),
+		Delta: make(TransitionFn[int, Sigma], len(states)),
+	}
+	for i, q := range states {
+		out.Q[i] = struct{}{}
+		if d.F.Has(q) {
+			out.F[i] = struct{}{}
+		}
+		row := make(map[Sigma]int, len(symbols))
+		for _, a := range symbols {
+			if qNext, ok := d.next(q, a); ok {
+				if j, ok := id[qNext]; ok {
+					row[a] = j
+				}
+			}
+		}
+		out.Delta[i] = row
+	}
+	return out, id
+}
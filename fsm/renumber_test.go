@@ -0,0 +1,39 @@
+package fsm
+
+import "testing"
+
+func TestRenumber(t *testing.T) {
+	d := buildModThree()
+	d.Q[State(7)] = struct{}{} // unreachable
+	d.Delta[State(7)] = map[Bit]State{Zero: S0, One: S0}
+
+	for _, order := range []StateOrder{OrderBFS, OrderSorted} {
+		r, id := d.Renumber(order)
+		if len(r.Q) != 4 || len(id) != 4 {
+			t.Fatalf("%v: %d states, mapping %v", order, len(r.Q), id)
+		}
+		if r.Q0 != id[S0] {
+			t.Errorf("%v: q0 = %d, want %d", order, r.Q0, id[S0])
+		}
+		for q, row := range d.Delta {
+			for a, next := range row {
+				if r.Delta[id[q]][a] != id[next] {
+					t.Errorf("%v: δ(%v,%c) not mapped", order, q, a)
+				}
+			}
+		}
+		sameLanguage(t, d, r, []Bit{Zero, One}, 6)
+	}
+	if _, id := d.Renumber(OrderSorted); id[State(7)] != 3 {
+		t.Errorf("sorted order put 7 at %d", id[State(7)])
+	}
+
+	c, id := d.Compact()
+	if len(c.Q) != 3 || len(id) != 3 || c.Q0 != 0 {
+		t.Fatalf("Compact: %d states, mapping %v", len(c.Q), id)
+	}
+	if _, ok := id[State(7)]; ok {
+		t.Error("Compact kept the unreachable state")
+	}
+	sameLanguage(t, d, c, []Bit{Zero, One}, 6)
+}
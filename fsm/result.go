@@ -0,0 +1,111 @@
+package fsm
+
+// ---------- Detailed runs ----------
+
+// RunResult describes a whole run in one value, so new metadata can be
+// added without growing the return values of Run and Accepts.
+type RunResult[Q comparable, Sigma comparable] struct {
+	Final    Q     // state reached; on failure, the state before the failing symbol
+	Accepted bool  // the whole input was consumed and Final is in F
+	Consumed int   // symbols applied successfully
+	FailedAt int   // index of the symbol with no transition, or -1
+	Err      error // the Step error at FailedAt, or nil
+	Trace    []Q   // q0 and every state reached, with RunOptions.Trace only
+}
+
+// RunOptions selects the optional parts of a RunResult and how the input
+// is read.
+type RunOptions[Sigma comparable] struct {
+	// Trace records the visited states: Trace[i] is the state after i
+	// symbols, so len(Trace) == Consumed+1.
+	Trace bool
+	// Ignore lists separator symbols (spaces, underscores) that every state
+	// skips as if it had a self-loop on them, whether or not they are in Σ.
+	Ignore Set[Sigma]
+}
+
+// WithIgnoredSymbols returns options that skip the given symbols.
+func WithIgnoredSymbols[Sigma comparable](symbols ...Sigma) RunOptions[Sigma] {
+	return RunOptions[Sigma]{Ignore: NewSet(symbols...)}
+}
+
+// RunDetailed runs d on input and reports the outcome as a RunResult,
+// without a trace.
+func (d *DFA[Q, Sigma]) RunDetailed(input []Sigma) RunResult[Q, Sigma] {
+	return d.RunDetailedWith(input, RunOptions[Sigma]{})
+}
+
+// RunDetailedWith is RunDetailed with options.
+func (d *DFA[Q, Sigma]) RunDetailedWith(input []Sigma, opts RunOptions[Sigma]) RunResult[Q, Sigma] {
+	res := RunResult[Q, Sigma]{Final: d.Q0, FailedAt: -1}
+	if opts.Trace {
+		res.Trace = make([]Q, 1, len(input)+1)
+		res.Trace[0] = d.Q0
+	}
+	for i, a := range input {
+		if !opts.Ignore.Has(a) {
+			q, err := d.Step(res.Final, a)
+			if err != nil {
+				res.FailedAt, res.Err = i, err
+				return res
+			}
+			res.Final = q
+		}
+		res.Consumed++
+		if opts.Trace {
+			res.Trace = append(res.Trace, res.Final)
+		}
+	}
+	res.Accepted = d.F.Has(res.Final)
+	return res
+}
+
+// RunTrace runs d on input and returns every visited state: trace[i] is
+// the state after i symbols, starting with q0. On failure the trace ends
+// with the state reached so far and the error is a *RunError, as from Run.
+func (d *DFA[Q, Sigma]) RunTrace(input []Sigma) ([]Q, error) {
+	trace := make([]Q, 1, len(input)+1)
+	trace[0] = d.Q0
+	for i, a := range input {
+		q := trace[len(trace)-1]
+		qNext, err := d.Step(q, a)
+		if err != nil {
+			return trace, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
+		}
+		trace = append(trace, qNext)
+	}
+	return trace, nil
+}
+
+// ---------- Shared-pass classification ----------
+
+// MultiAccepts runs every machine over input in a single pass and reports
+// which accept it, so classifying one large buffer against several
+// languages reads it once. A machine stops at its first undefined
+// transition and rejects; the pass ends early once all have stopped.
+func MultiAccepts[Q comparable, Sigma comparable](machines []*DFA[Q, Sigma], input []Sigma) []bool {
+	states := make([]Q, len(machines))
+	live := make([]int, len(machines)) // indexes of the machines still running
+	for i, d := range machines {
+		states[i] = d.Q0
+		live[i] = i
+	}
+	for _, a := range input {
+		if len(live) == 0 {
+			break
+		}
+		kept := live[:0]
+		for _, i := range live {
+			if q, ok := machines[i].next(states[i], a); ok {
+				states[i] = q
+				kept = append(kept, i)
+			}
+		}
+		live = kept
+	}
+	out := make([]bool, len(machines))
+	for _, i := range live {
+		out[i] = machines[i].F.Has(states[i])
+	}
+	return out
+}
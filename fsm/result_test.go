@@ -0,0 +1,75 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunDetailed(t *testing.T) {
+	d, _ := buildModThree().PruneToFinals(NewSet(S0))
+	res := d.RunDetailedWith([]Bit("110"), RunOptions[Bit]{Trace: true})
+	if res.Final != S0 || !res.Accepted || res.Consumed != 3 || res.FailedAt != -1 || res.Err != nil {
+		t.Fatalf("110: %+v", res)
+	}
+	if want := []State{S0, S1, S0, S0}; !reflect.DeepEqual(res.Trace, want) {
+		t.Errorf("trace %v, want %v", res.Trace, want)
+	}
+
+	res = d.RunDetailed([]Bit("10"))
+	if res.Final != S2 || res.Accepted || res.Consumed != 2 || res.Trace != nil {
+		t.Errorf("10: %+v", res)
+	}
+}
+
+func TestRunDetailedFailure(t *testing.T) {
+	res := literalDFA("abc").RunDetailedWith([]rune("abx c"), RunOptions[rune]{Trace: true})
+	if res.FailedAt != 2 || res.Consumed != 2 || res.Final != 2 || res.Accepted || res.Err == nil {
+		t.Fatalf("%+v", res)
+	}
+	if len(res.Trace) != res.Consumed+1 {
+		t.Errorf("trace %v for %d symbols", res.Trace, res.Consumed)
+	}
+}
+
+func TestRunDetailedIgnore(t *testing.T) {
+	d := buildModThree()
+	res := d.RunDetailedWith([]Bit("11 0_0"), WithIgnoredSymbols[Bit](' ', '_'))
+	if res.Err != nil || res.Final != S0 || res.Consumed != 6 {
+		t.Fatalf("%+v", res)
+	}
+	if res := d.RunDetailed([]Bit("11 0")); res.FailedAt != 2 {
+		t.Errorf("without Ignore: %+v", res)
+	}
+}
+
+func TestRunTrace(t *testing.T) {
+	trace, err := buildModThree().RunTrace([]Bit("1101"))
+	if want := []State{S0, S1, S0, S0, S1}; err != nil || !reflect.DeepEqual(trace, want) {
+		t.Fatalf("got %v, %v; want %v", trace, err, want)
+	}
+	partial, err := literalDFA("abc").RunTrace([]rune("abx"))
+	var re *RunError[int, rune]
+	if !errors.As(err, &re) || re.Index != 2 || !reflect.DeepEqual(partial, []int{0, 1, 2}) {
+		t.Fatalf("failure: got %v, %v", partial, err)
+	}
+}
+
+func TestMultiAccepts(t *testing.T) {
+	d := buildModThree()
+	div3, _ := d.PruneToFinals(NewSet(S0))
+	rem1, _ := d.PruneToFinals(NewSet(S1))
+	partial := Must(NewDFA([]State{S0}, []Bit{Zero, One}, S0, []State{S0},
+		TransitionFn[State, Bit]{S0: {Zero: S0}}, false))
+	machines := []*DFA[State, Bit]{div3, rem1, partial}
+	for input, want := range map[string][]bool{
+		"110": {true, false, false},
+		"111": {false, true, false},
+		"000": {true, false, true},
+		"":    {true, false, true},
+	} {
+		if got := MultiAccepts(machines, []Bit(input)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Package router dispatches HTTP requests by path with a single compiled
+// DFA: every route pattern becomes a byte-level NFA, the union is
+// determinized once, and a lookup is one table step per byte of the path,
+// however many routes there are.
+//
+// Patterns are slash-separated segments:
+//
+//	/users/new         literal
+//	/users/:id         ":name" matches one non-empty segment
+//	/static/*path      "*name" as the last segment matches the rest
+//
+// When several patterns match a path, the one with fewer "*" segments
+// wins, then the one with fewer ":" segments, then the one added first.
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fsm/fsm"
+)
+
+// ErrBadPattern is matched by every pattern error via errors.Is.
+var ErrBadPattern = errors.New("bad route pattern")
+
+// Route binds a pattern to a handler.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Params holds the values of the named segments of a matched route.
+type Params map[string]string
+
+type paramsKey struct{}
+
+// PathParams returns the parameters of the route that matched r, or nil.
+func PathParams(r *http.Request) Params {
+	p, _ := r.Context().Value(paramsKey{}).(Params)
+	return p
+}
+
+// segment is one parsed pattern segment.
+type segment struct {
+	kind byte // 0 literal, ':' param, '*' wildcard
+	text string
+}
+
+type route struct {
+	Route
+	segs     []segment
+	params   int
+	wildcard bool
+}
+
+// Router is an immutable, compiled set of routes; it is safe for
+// concurrent use.
+type Router struct {
+	// NotFound handles paths no route matches; nil means http.NotFound.
+	NotFound http.Handler
+
+	routes []route
+	next   [][256]int32 // DFA over bytes; -1 is dead
+	best   []int32      // route index accepted in each state, or -1
+}
+
+// New parses the patterns and compiles the router.
+func New(routes ...Route) (*Router, error) {
+	r := &Router{}
+	seen := make(map[string]string)
+	for _, rt := range routes {
+		segs, err := parse(rt.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		shape := shapeOf(segs)
+		if prev, dup := seen[shape]; dup {
+			return nil, fmt.Errorf("%w: %q and %q match the same paths", ErrBadPattern, prev, rt.Pattern)
+		}
+		seen[shape] = rt.Pattern
+		cr := route{Route: rt, segs: segs}
+		for _, s := range segs {
+			switch s.kind {
+			case ':':
+				cr.params++
+			case '*':
+				cr.wildcard = true
+			}
+		}
+		r.routes = append(r.routes, cr)
+	}
+	if err := r.compile(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MustNew is New for package-level routers; it panics on a bad pattern.
+func MustNew(routes ...Route) *Router {
+	r, err := New(routes...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func parse(pattern string) ([]segment, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("%w: %q does not start with /", ErrBadPattern, pattern)
+	}
+	parts := strings.Split(pattern[1:], "/")
+	segs := make([]segment, len(parts))
+	names := make(map[string]bool)
+	for i, p := range parts {
+		if p != "" && (p[0] == ':' || p[0] == '*') {
+			name := p[1:]
+			if name == "" {
+				return nil, fmt.Errorf("%w: %q has an unnamed parameter", ErrBadPattern, pattern)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("%w: %q repeats parameter %q", ErrBadPattern, pattern, name)
+			}
+			if p[0] == '*' && i != len(parts)-1 {
+				return nil, fmt.Errorf("%w: %q has %s before the last segment", ErrBadPattern, pattern, p)
+			}
+			names[name] = true
+			segs[i] = segment{kind: p[0], text: name}
+			continue
+		}
+		segs[i] = segment{text: p}
+	}
+	return segs, nil
+}
+
+// shapeOf describes the paths a pattern matches, ignoring parameter names.
+func shapeOf(segs []segment) string {
+	var b strings.Builder
+	for _, s := range segs {
+		b.WriteByte('/')
+		if s.kind != 0 {
+			b.WriteByte(s.kind)
+		} else {
+			b.WriteString(strconv.Quote(s.text))
+		}
+	}
+	return b.String()
+}
+
+// compile builds one ε-NFA for all routes, determinizes it and tabulates
+// the result over bytes.
+func (r *Router) compile() error {
+	alphabet := make([]byte, 256)
+	for i := range alphabet {
+		alphabet[i] = byte(i)
+	}
+	delta := fsm.NFATransitionFn[int, byte]{}
+	eps := map[int]fsm.Set[int]{}
+	accept := map[int]int{} // NFA final state → route index
+	states := []int{0}
+	newState := func() int {
+		states = append(states, len(states))
+		return len(states) - 1
+	}
+	edge := func(from int, a byte, to int) {
+		if delta[from] == nil {
+			delta[from] = map[byte]fsm.Set[int]{}
+		}
+		if delta[from][a] == nil {
+			delta[from][a] = fsm.NewSet[int]()
+		}
+		delta[from][a][to] = struct{}{}
+	}
+	for i, rt := range r.routes {
+		s := newState()
+		eps[0] = addTo(eps[0], s)
+		for _, seg := range rt.segs {
+			t := newState()
+			edge(s, '/', t)
+			s = t
+			switch seg.kind {
+			case ':':
+				t := newState()
+				for _, a := range alphabet {
+					if a != '/' {
+						edge(s, a, t)
+						edge(t, a, t)
+					}
+				}
+				s = t
+			case '*':
+				for _, a := range alphabet {
+					edge(s, a, s)
+				}
+			default:
+				for j := 0; j < len(seg.text); j++ {
+					t := newState()
+					edge(s, seg.text[j], t)
+					s = t
+				}
+			}
+		}
+		accept[s] = i
+	}
+	finals := make([]int, 0, len(accept))
+	for f := range accept {
+		finals = append(finals, f)
+	}
+	n, err := fsm.NewEpsilonNFA(states, alphabet, 0, finals, delta, eps)
+	if err != nil {
+		return err
+	}
+
+	// Name each subset by its states, then keep the best route it accepts.
+	d, err := n.DeterminizeLabeled(nil)
+	if err != nil {
+		return err
+	}
+	origins := make(map[string]int32)
+	for label := range d.Q {
+		best := int32(-1)
+		for _, f := range strings.Split(strings.Trim(label, "{}"), ",") {
+			q, _ := strconv.Atoi(f)
+			if i, ok := accept[q]; ok && (best < 0 || r.better(i, int(best))) {
+				best = int32(i)
+			}
+		}
+		origins[label] = best
+	}
+
+	t := d.Compile()
+	r.next = make([][256]int32, len(t.States))
+	r.best = make([]int32, len(t.States))
+	for s, label := range t.States {
+		r.best[s] = origins[label]
+		for a := 0; a < 256; a++ {
+			r.next[s][a] = -1
+			if sym, ok := t.Symbol(byte(a)); ok {
+				r.next[s][a] = t.At(int32(s), sym)
+			}
+		}
+	}
+	return nil
+}
+
+func addTo(s fsm.Set[int], q int) fsm.Set[int] {
+	if s == nil {
+		s = fsm.NewSet[int]()
+	}
+	s[q] = struct{}{}
+	return s
+}
+
+// better reports whether route i takes precedence over route j.
+func (r *Router) better(i, j int) bool {
+	a, b := r.routes[i], r.routes[j]
+	if a.wildcard != b.wildcard {
+		return !a.wildcard
+	}
+	if a.params != b.params {
+		return a.params < b.params
+	}
+	return i < j
+}
+
+// Match returns the handler and parameters of the route matching path.
+func (r *Router) Match(path string) (http.Handler, Params, bool) {
+	s := int32(0)
+	for i := 0; i < len(path) && s >= 0; i++ {
+		s = r.next[s][path[i]]
+	}
+	if s < 0 || r.best[s] < 0 {
+		return nil, nil, false
+	}
+	rt := &r.routes[r.best[s]]
+	return rt.Handler, rt.extract(path), true
+}
+
+// extract reads the parameter values of a path the route is known to match.
+func (rt *route) extract(path string) Params {
+	if rt.params == 0 && !rt.wildcard {
+		return nil
+	}
+	p := make(Params, rt.params+1)
+	parts := strings.SplitN(path[1:], "/", len(rt.segs))
+	for i, seg := range rt.segs {
+		if seg.kind != 0 {
+			p[seg.text] = parts[i]
+		}
+	}
+	return p
+}
+
+// ServeHTTP dispatches on r.URL.Path, adding the route parameters to the
+// request context (see PathParams).
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, params, ok := r.Match(req.URL.Path)
+	if !ok {
+		if r.NotFound != nil {
+			r.NotFound.ServeHTTP(w, req)
+		} else {
+			http.NotFound(w, req)
+		}
+		return
+	}
+	if params != nil {
+		req = req.WithContext(context.WithValue(req.Context(), paramsKey{}, params))
+	}
+	h.ServeHTTP(w, req)
+}
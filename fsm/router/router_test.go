@@ -0,0 +1,86 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// named returns a handler that writes its name and the sorted params.
+func named(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := PathParams(r)
+		fmt.Fprint(w, name)
+		for _, k := range []string{"id", "file", "path", "rest"} {
+			if v, ok := p[k]; ok {
+				fmt.Fprintf(w, " %s=%s", k, v)
+			}
+		}
+	})
+}
+
+func TestRouterDispatch(t *testing.T) {
+	r := MustNew(
+		Route{"/", named("root")},
+		Route{"/users/:id", named("user")},
+		Route{"/users/new", named("new")},
+		Route{"/users/:id/files/:file", named("file")},
+		Route{"/static/*path", named("static")},
+		Route{"/*rest", named("fallback")},
+	)
+	cases := map[string]string{
+		"/":                  "root",
+		"/users/42":          "user id=42",
+		"/users/new":         "new",
+		"/users/newer":       "user id=newer",
+		"/users/7/files/a.b": "file id=7 file=a.b",
+		"/static/css/x.css":  "static path=css/x.css",
+		"/static/":           "static path=",
+		"/users/":            "fallback rest=users/",
+		"/other":             "fallback rest=other",
+	}
+	for path, want := range cases {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if got, _ := io.ReadAll(rec.Body); string(got) != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRouterNotFound(t *testing.T) {
+	r := MustNew(Route{"/a/:x", named("a")})
+	for _, path := range []string{"", "/a", "/a/", "/a/b/c", "/b"} {
+		if _, _, ok := r.Match(path); ok {
+			t.Errorf("%q matched", path)
+		}
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/b", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+	r.NotFound = named("custom")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/b", nil))
+	if got := rec.Body.String(); got != "custom" {
+		t.Errorf("NotFound handler wrote %q", got)
+	}
+}
+
+func TestRouterBadPatterns(t *testing.T) {
+	for _, routes := range [][]Route{
+		{{Pattern: "users"}},
+		{{Pattern: "/users/:"}},
+		{{Pattern: "/:a/:a"}},
+		{{Pattern: "/*rest/more"}},
+		{{Pattern: "/u/:id"}, {Pattern: "/u/:name"}},
+	} {
+		if _, err := New(routes...); !errors.Is(err, ErrBadPattern) {
+			t.Errorf("%v: err = %v, want ErrBadPattern", routes, err)
+		}
+	}
+}
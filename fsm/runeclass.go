@@ -0,0 +1,138 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ---------- Symbol ranges ----------
+
+// Integer is the set of types usable as range-based symbols (runes, bytes, ints).
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Range is an inclusive interval [Lo, Hi] of symbols.
+type Range[T Integer] struct {
+	Lo T
+	Hi T
+}
+
+// Contains reports whether x lies in the range.
+func (r Range[T]) Contains(x T) bool { return r.Lo <= x && x <= r.Hi }
+
+// InRanges reports whether x lies in one of rs, which must be sorted and
+// non-overlapping (as returned by UnicodeClass).
+func InRanges[T Integer](rs []Range[T], x T) bool {
+	i := sort.Search(len(rs), func(i int) bool { return rs[i].Hi >= x })
+	return i < len(rs) && rs[i].Contains(x)
+}
+
+// ---------- Unicode classes ----------
+
+// UnicodeClass resolves a Unicode property class into sorted, non-overlapping
+// rune ranges. It accepts the regex spellings `\p{L}`, `\pL` and the negated
+// `\P{L}`, as well as a bare name ("L", "Lu", "Greek", "White_Space").
+// Names are looked up as general categories, then scripts, then properties.
+func UnicodeClass(class string) ([]Range[rune], error) {
+	name, negate := class, false
+	switch {
+	case strings.HasPrefix(name, `\p`):
+		name = name[2:]
+	case strings.HasPrefix(name, `\P`):
+		name, negate = name[2:], true
+	}
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		name = name[1 : len(name)-1]
+	}
+
+	table := unicode.Categories[name]
+	if table == nil {
+		table = unicode.Scripts[name]
+	}
+	if table == nil {
+		table = unicode.Properties[name]
+	}
+	if table == nil {
+		return nil, fmt.Errorf("%w: unknown Unicode class %q", ErrInvalidInput, class)
+	}
+
+	rs := tableRanges(table)
+	if negate {
+		rs = complementRanges(rs, 0, unicode.MaxRune)
+	}
+	return rs, nil
+}
+
+// ClassRules returns the rules of an IntervalDFA that go from from to to on
+// every rune of a Unicode class, spelled as for UnicodeClass, so a rune
+// machine can use `\p{L}` without listing its ranges. Build the machine
+// over the domain Range[rune]{0, unicode.MaxRune}:
+//
+//	letters, _ := fsm.ClassRules("start", `\p{L}`, "word")
+//	more, _ := fsm.ClassRules("word", `\p{L}`, "word")
+//	m, err := fsm.NewIntervalDFA(states, fsm.Range[rune]{0, unicode.MaxRune}, "start", finals, append(letters, more...), false)
+func ClassRules[Q comparable](from Q, class string, to Q) ([]IntervalRule[Q, rune], error) {
+	rs, err := UnicodeClass(class)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]IntervalRule[Q, rune], len(rs))
+	for i, r := range rs {
+		rules[i] = IntervalRule[Q, rune]{From: from, On: r, To: to}
+	}
+	return rules, nil
+}
+
+// tableRanges flattens a unicode.RangeTable (which may use strides) into
+// sorted, merged ranges.
+func tableRanges(t *unicode.RangeTable) []Range[rune] {
+	var rs []Range[rune]
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			rs = appendRange(rs, Range[rune]{lo, hi})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			rs = appendRange(rs, Range[rune]{r, r})
+		}
+	}
+	for _, r := range t.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range t.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return rs
+}
+
+// appendRange appends r to sorted ranges, merging it with the last one when
+// they touch.
+func appendRange[T Integer](rs []Range[T], r Range[T]) []Range[T] {
+	if n := len(rs); n > 0 && rs[n-1].Hi+1 >= r.Lo {
+		if r.Hi > rs[n-1].Hi {
+			rs[n-1].Hi = r.Hi
+		}
+		return rs
+	}
+	return append(rs, r)
+}
+
+// complementRanges returns the gaps of sorted ranges rs within [lo, hi].
+func complementRanges[T Integer](rs []Range[T], lo, hi T) []Range[T] {
+	var out []Range[T]
+	next := lo
+	for _, r := range rs {
+		if r.Lo > next {
+			out = append(out, Range[T]{next, r.Lo - 1})
+		}
+		if r.Hi >= hi {
+			return out
+		}
+		next = r.Hi + 1
+	}
+	return append(out, Range[T]{next, hi})
+}
@@ -0,0 +1,73 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+)
+
+// TestUnicodeClass checks membership for categories, scripts and negation.
+func TestUnicodeClass(t *testing.T) {
+	cases := []struct {
+		class string
+		in    []rune
+		out   []rune
+	}{
+		{`\p{L}`, []rune{'a', 'Z', 'é', 'Ж', '語'}, []rune{'1', ' ', '_'}},
+		{`\pN`, []rune{'0', '9', '٣'}, []rune{'a'}},
+		{`Greek`, []rune{'α', 'Ω'}, []rune{'a'}},
+		{`\P{L}`, []rune{'1', ' ', 0x10FFFF}, []rune{'a', 'é'}},
+	}
+	for _, c := range cases {
+		rs, err := UnicodeClass(c.class)
+		if err != nil {
+			t.Fatalf("%s: %v", c.class, err)
+		}
+		for _, r := range c.in {
+			if !InRanges(rs, r) {
+				t.Fatalf("%s: expected %q to be in class", c.class, r)
+			}
+		}
+		for _, r := range c.out {
+			if InRanges(rs, r) {
+				t.Fatalf("%s: expected %q not to be in class", c.class, r)
+			}
+		}
+	}
+}
+
+// TestUnicodeClass_Unknown ensures unknown names are rejected.
+func TestUnicodeClass_Unknown(t *testing.T) {
+	if _, err := UnicodeClass(`\p{Nope}`); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestClassRules builds an internationalized identifier machine, a letter
+// then letters or decimal digits, from class rules.
+func TestClassRules(t *testing.T) {
+	var rules []IntervalRule[string, rune]
+	for _, r := range []struct{ from, class, to string }{
+		{"start", `\p{L}`, "ident"},
+		{"ident", `\p{L}`, "ident"},
+		{"ident", `\p{Nd}`, "ident"},
+	} {
+		rs, err := ClassRules(r.from, r.class, r.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rs...)
+	}
+	m, err := NewIntervalDFA([]string{"start", "ident"}, Range[rune]{0, unicode.MaxRune}, "start", []string{"ident"}, rules, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]bool{"héllo": true, "Ωμέγα2": true, "x٣": true, "2x": false, "a-b": false, "": false} {
+		if got, _, _ := m.Accepts([]rune(in)); got != want {
+			t.Errorf("%q: got %v, want %v", in, got, want)
+		}
+	}
+	if _, err := ClassRules("start", `\p{Nope}`, "ident"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown class: got %v", err)
+	}
+}
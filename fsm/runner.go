@@ -0,0 +1,163 @@
+package fsm
+
+import (
+	"fmt"
+	"time"
+)
+
+// ---------- Incremental execution ----------
+
+// Runner executes a DFA incrementally, one symbol at a time, for callers
+// that receive input piecemeal (event streams, interactive tools).
+// It keeps a bounded history of previous states so the run can be rewound.
+type Runner[Q comparable, Sigma comparable] struct {
+	dfa   *DFA[Q, Sigma]
+	state Q
+	pos   int
+
+	// history is a ring buffer of the states before the last n steps.
+	history []Q
+	head    int // index of the oldest entry
+	n       int
+
+	cp     checkpointer[Q]
+	ignore Set[Sigma]
+}
+
+// Checkpoint is a resumable position in a run: feeding the rest of the
+// input after Offset symbols to a runner resumed at State continues the
+// run as if it had never stopped.
+type Checkpoint[Q comparable] struct {
+	State  Q
+	Offset int
+	Time   time.Time
+}
+
+type checkpointer[Q comparable] struct {
+	every    int
+	interval time.Duration
+	fn       func(Checkpoint[Q]) error
+	lastPos  int
+	lastTime time.Time
+	now      func() time.Time
+}
+
+// NewRunner starts a run of d at q0 that can step back up to historyLimit
+// symbols. A limit of 0 disables Back.
+func NewRunner[Q comparable, Sigma comparable](d *DFA[Q, Sigma], historyLimit int) *Runner[Q, Sigma] {
+	if historyLimit < 0 {
+		historyLimit = 0
+	}
+	return &Runner[Q, Sigma]{dfa: d, state: d.Q0, history: make([]Q, historyLimit)}
+}
+
+// State returns the current state.
+func (r *Runner[Q, Sigma]) State() Q { return r.state }
+
+// Pos returns the number of symbols consumed so far (net of Back).
+func (r *Runner[Q, Sigma]) Pos() int { return r.pos }
+
+// Ignore makes Feed skip the given separator symbols as if every state
+// had a self-loop on them; they still count towards Pos and the history.
+// It replaces any earlier set.
+func (r *Runner[Q, Sigma]) Ignore(symbols ...Sigma) {
+	r.ignore = NewSet(symbols...)
+}
+
+// Feed applies one symbol. On error the state is unchanged.
+func (r *Runner[Q, Sigma]) Feed(a Sigma) (Q, error) {
+	qNext := r.state
+	if !r.ignore.Has(a) {
+		var err error
+		if qNext, err = r.dfa.Step(r.state, a); err != nil {
+			return r.state, err
+		}
+	}
+	r.remember(r.state)
+	r.state = qNext
+	r.pos++
+	if err := r.checkpoint(); err != nil {
+		return qNext, err
+	}
+	return qNext, nil
+}
+
+// SetCheckpoint makes Feed call fn with the current state and offset every
+// time at least every symbols (if every > 0) or interval (if interval > 0)
+// have passed since the last checkpoint. A long streaming run can persist
+// these and, after a crash, Resume from the last one instead of from the
+// start. An error from fn is returned by the Feed that triggered it; the
+// step itself is kept. Passing a nil fn disables checkpointing.
+func (r *Runner[Q, Sigma]) SetCheckpoint(every int, interval time.Duration, fn func(Checkpoint[Q]) error) {
+	r.cp = checkpointer[Q]{every: every, interval: interval, fn: fn, lastPos: r.pos, now: time.Now}
+	r.cp.lastTime = r.cp.now()
+}
+
+func (r *Runner[Q, Sigma]) checkpoint() error {
+	cp := &r.cp
+	if cp.fn == nil {
+		return nil
+	}
+	now := cp.now()
+	due := (cp.every > 0 && r.pos-cp.lastPos >= cp.every) ||
+		(cp.interval > 0 && now.Sub(cp.lastTime) >= cp.interval)
+	if !due {
+		return nil
+	}
+	cp.lastPos, cp.lastTime = r.pos, now
+	if err := cp.fn(Checkpoint[Q]{State: r.state, Offset: r.pos, Time: now}); err != nil {
+		return fmt.Errorf("checkpoint at %d: %w", r.pos, err)
+	}
+	return nil
+}
+
+// Resume moves the run to a saved checkpoint: state q after offset symbols.
+// The history is cleared. It fails if q is not a state of the machine.
+func (r *Runner[Q, Sigma]) Resume(q Q, offset int) error {
+	if !r.dfa.Q.Has(q) {
+		return fmt.Errorf("resume: %w", &ErrUnknownState[Q]{Kind: UnknownState, State: q})
+	}
+	r.state = q
+	r.pos = offset
+	r.head, r.n = 0, 0
+	r.cp.lastPos = offset
+	return nil
+}
+
+// Back rewinds the last n steps and returns the state reached. It fails
+// without changing anything if fewer than n steps are in the history.
+func (r *Runner[Q, Sigma]) Back(n int) (Q, error) {
+	if n < 0 || n > r.n {
+		return r.state, fmt.Errorf("cannot step back %d: %d steps in history", n, r.n)
+	}
+	for i := 0; i < n; i++ {
+		last := (r.head + r.n - 1) % len(r.history)
+		r.state = r.history[last]
+		r.n--
+		r.pos--
+	}
+	return r.state, nil
+}
+
+// Reset returns the run to q0 and clears the history.
+func (r *Runner[Q, Sigma]) Reset() {
+	r.state = r.dfa.Q0
+	r.pos = 0
+	r.head, r.n = 0, 0
+	r.cp.lastPos = 0
+}
+
+// remember pushes q, evicting the oldest entry when the history is full.
+func (r *Runner[Q, Sigma]) remember(q Q) {
+	size := len(r.history)
+	if size == 0 {
+		return
+	}
+	if r.n < size {
+		r.history[(r.head+r.n)%size] = q
+		r.n++
+		return
+	}
+	r.history[r.head] = q
+	r.head = (r.head + 1) % size
+}
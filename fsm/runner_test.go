@@ -0,0 +1,112 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRunner_Back feeds symbols, rewinds, and checks the history bound.
+func TestRunner_Back(t *testing.T) {
+	r := NewRunner(buildModThree(), 2)
+	for _, a := range []Bit{One, Zero, One} { // S1, S2, S2
+		if _, err := r.Feed(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.State() != S2 || r.Pos() != 3 {
+		t.Fatalf("state=%v pos=%d, want S2 3", r.State(), r.Pos())
+	}
+
+	// Only two steps are remembered.
+	if _, err := r.Back(3); err == nil {
+		t.Fatal("expected error stepping back past the history")
+	}
+	if q, err := r.Back(2); err != nil || q != S1 || r.Pos() != 1 {
+		t.Fatalf("Back(2) = %v, %v (pos %d), want S1 at pos 1", q, err, r.Pos())
+	}
+	if _, err := r.Back(1); err == nil {
+		t.Fatal("history should be exhausted")
+	}
+
+	// Rewound runs continue normally.
+	if q, _ := r.Feed(One); q != S0 {
+		t.Fatalf("after rewind: got %v, want S0", q)
+	}
+	if q, err := r.Back(1); err != nil || q != S1 {
+		t.Fatalf("Back(1) = %v, %v, want S1", q, err)
+	}
+}
+
+// TestRunner_Checkpoint resumes a run from its last checkpoint.
+func TestRunner_Checkpoint(t *testing.T) {
+	d := buildModThree()
+	input := []Bit{One, Zero, One, One, Zero, One, Zero}
+	want, _ := d.Run(input)
+
+	r := NewRunner(d, 0)
+	var saved []Checkpoint[State]
+	r.SetCheckpoint(3, 0, func(c Checkpoint[State]) error {
+		saved = append(saved, c)
+		return nil
+	})
+	for _, b := range input[:5] { // crash after 5 symbols
+		if _, err := r.Feed(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(saved) != 1 || saved[0].Offset != 3 {
+		t.Fatalf("checkpoints = %+v, want one at offset 3", saved)
+	}
+
+	resumed := NewRunner(d, 0)
+	last := saved[len(saved)-1]
+	if err := resumed.Resume(last.State, last.Offset); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range input[last.Offset:] {
+		resumed.Feed(b)
+	}
+	if resumed.State() != want || resumed.Pos() != len(input) {
+		t.Fatalf("resumed at %v/%d, want %v/%d", resumed.State(), resumed.Pos(), want, len(input))
+	}
+	if err := resumed.Resume(State(9), 0); err == nil {
+		t.Fatal("expected an error for an unknown state")
+	}
+}
+
+// TestRunner_CheckpointInterval uses the time trigger and error reporting.
+func TestRunner_CheckpointInterval(t *testing.T) {
+	r := NewRunner(buildModThree(), 0)
+	clock := time.Unix(0, 0)
+	r.SetCheckpoint(0, time.Minute, func(Checkpoint[State]) error { return errors.New("disk full") })
+	r.cp.now = func() time.Time { return clock }
+	r.cp.lastTime = clock
+
+	if _, err := r.Feed(One); err != nil {
+		t.Fatalf("no checkpoint due yet, got %v", err)
+	}
+	clock = clock.Add(time.Minute)
+	if q, err := r.Feed(One); err == nil || q != S0 {
+		t.Fatalf("got %v,%v want S0 and the checkpoint error", q, err)
+	}
+}
+
+func TestRunner_Ignore(t *testing.T) {
+	r := NewRunner(buildModThree(), 4)
+	r.Ignore('_')
+	for _, a := range []Bit("1_0") {
+		if _, err := r.Feed(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.State() != S2 || r.Pos() != 3 {
+		t.Fatalf("state %v at %d, want S2 at 3", r.State(), r.Pos())
+	}
+	if q, _ := r.Back(2); q != S1 {
+		t.Errorf("Back(2) = %v, want S1", q)
+	}
+	if _, err := r.Feed(' '); err == nil {
+		t.Error("unignored separator accepted")
+	}
+}
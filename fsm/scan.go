@@ -0,0 +1,350 @@
+package fsm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ---------- Scanning ----------
+
+// MatchMode selects how FindAll treats matches that share input positions.
+type MatchMode int
+
+const (
+	// NonOverlapping reports the leftmost match, then resumes scanning after
+	// its end. This is what tokenizers want.
+	NonOverlapping MatchMode = iota
+	// Overlapping reports a match for every start position that has one,
+	// so a region may be covered by several matches. This is what security
+	// scanners want.
+	Overlapping
+)
+
+// Anchor restricts where a match may begin and end.
+type Anchor int
+
+const (
+	// Unanchored lets a match start and end anywhere in the input.
+	Unanchored Anchor = iota
+	// AnchorStart requires a match to begin at the start of the input.
+	AnchorStart
+	// AnchorEnd requires a match to finish at the end of the input.
+	AnchorEnd
+	// AnchorBoth requires a match to span the whole input.
+	AnchorBoth
+)
+
+// Semantics decides which end is chosen when several matches share a start.
+//
+// Leftmost-first matching, as in regexp and Perl, depends on the order of
+// alternatives, which a DFA does not keep; it is selected per machine
+// instead. CompileRegexFirst compiles a pattern so that LeftmostLongest
+// scans of its machine report the leftmost-first matches, while
+// CompileRegex gives the POSIX leftmost-longest ones.
+type Semantics int
+
+const (
+	// LeftmostLongest ends a match at the last position where the DFA
+	// accepts, as POSIX requires. It is the default: a DFA has no
+	// alternation priorities, and on patterns without them (`[0-9]+`,
+	// `foo|bar`) greedy leftmost-first matching as in regexp gives the same
+	// matches.
+	LeftmostLongest Semantics = iota
+	// LeftmostShortest ends a match at the first position where the DFA
+	// accepts, so `[0-9]+` matches one digit at a time.
+	LeftmostShortest
+)
+
+// Match is a half-open region input[Start:End] accepted by the DFA.
+type Match struct {
+	Start int
+	End   int
+}
+
+// ScanOptions configures the scanning APIs. The zero value scans for
+// unanchored, non-overlapping, leftmost-longest matches.
+type ScanOptions struct {
+	Mode      MatchMode
+	Anchor    Anchor
+	Semantics Semantics
+}
+
+// scanner finds the matches of a DFA in time linear in the input. A
+// backward pass records, for each position i, the states from which some
+// prefix of input[i:] leads into F; a run then starts only where a match
+// begins and stops as soon as no later end is possible. Sets of states are
+// interned and their transitions memoized, so each distinct (set, symbol)
+// pair costs O(|Q|) once.
+type scanner[Q comparable, Sigma comparable] struct {
+	d      *DFA[Q, Sigma]
+	states []Q
+	index  map[Q]int
+	words  int
+	sets   []bitset
+	ids    map[string]int
+	final  int
+	back   map[setStep[Sigma]]int
+	steps  int // transitions taken by forward runs
+
+	// For streaming: the states from which a non-empty word leads into F,
+	// and the memoized moves of the sets of open matches.
+	ext  bitset
+	open map[setStep[Sigma]]int
+}
+
+// setStep keys the memoized transitions of the scanner.
+type setStep[Sigma comparable] struct {
+	set   int
+	a     Sigma
+	withF bool
+}
+
+func newScanner[Q comparable, Sigma comparable](d *DFA[Q, Sigma]) *scanner[Q, Sigma] {
+	s := &scanner[Q, Sigma]{
+		d:      d,
+		states: d.Q.sorted(),
+		index:  make(map[Q]int, len(d.Q)),
+		words:  (len(d.Q) + 63) / 64,
+		ids:    make(map[string]int),
+		back:   make(map[setStep[Sigma]]int),
+	}
+	final := make(bitset, s.words)
+	for i, q := range s.states {
+		s.index[q] = i
+		if d.F.Has(q) {
+			final[i/64] |= 1 << (i % 64)
+		}
+	}
+	s.final = s.intern(final)
+	return s
+}
+
+func (s *scanner[Q, Sigma]) intern(b bitset) int {
+	k := b.key()
+	if id, ok := s.ids[k]; ok {
+		return id
+	}
+	s.ids[k] = len(s.sets)
+	s.sets = append(s.sets, b)
+	return len(s.sets) - 1
+}
+
+func (s *scanner[Q, Sigma]) has(set int, q Q) bool {
+	i, ok := s.index[q]
+	return ok && s.sets[set].has(i)
+}
+
+// pre returns the states that a move on a takes into set, together with
+// F if withF is set.
+func (s *scanner[Q, Sigma]) pre(set int, a Sigma, withF bool) int {
+	key := setStep[Sigma]{set, a, withF}
+	if id, ok := s.back[key]; ok {
+		return id
+	}
+	b := make(bitset, s.words)
+	if withF {
+		copy(b, s.sets[s.final])
+	}
+	for i, q := range s.states {
+		if qNext, ok := s.d.next(q, a); ok && s.has(set, qNext) {
+			b[i/64] |= 1 << (i % 64)
+		}
+	}
+	id := s.intern(b)
+	s.back[key] = id
+	return id
+}
+
+// extendable fills in s.ext.
+func (s *scanner[Q, Sigma]) extendable() {
+	co := s.d.coreachable()
+	s.ext = make(bitset, s.words)
+	s.open = make(map[setStep[Sigma]]int)
+	for i, q := range s.states {
+		for a := range s.d.Sigma {
+			if qNext, ok := s.d.next(q, a); ok && co.Has(qNext) {
+				s.ext[i/64] |= 1 << (i % 64)
+				break
+			}
+		}
+	}
+}
+
+// advance returns the states of the matches still open after a: those
+// open before it, and one starting at it, moved on a and kept only if they
+// can still reach F.
+func (s *scanner[Q, Sigma]) advance(set int, a Sigma) int {
+	key := setStep[Sigma]{set: set, a: a}
+	if id, ok := s.open[key]; ok {
+		return id
+	}
+	b := make(bitset, s.words)
+	move := func(q Q) {
+		if qNext, ok := s.d.next(q, a); ok {
+			if i, ok := s.index[qNext]; ok && s.ext.has(i) {
+				b[i/64] |= 1 << (i % 64)
+			}
+		}
+	}
+	move(s.d.Q0)
+	for i, q := range s.states {
+		if s.sets[set].has(i) {
+			move(q)
+		}
+	}
+	id := s.intern(b)
+	s.open[key] = id
+	return id
+}
+
+// findAll implements FindAll.
+func (s *scanner[Q, Sigma]) findAll(input []Sigma, opts ScanOptions) []Match {
+	n := len(input)
+	toEnd := opts.Anchor == AnchorEnd || opts.Anchor == AnchorBoth
+	// live[i] holds the states from which a prefix of input[i:] leads into
+	// F, or with an end anchor, those from which input[i:] itself does.
+	live := make([]int, n+1)
+	live[n] = s.final
+	for i := n - 1; i >= 0; i-- {
+		live[i] = s.pre(live[i+1], input[i], !toEnd)
+	}
+	var out []Match
+	for start := 0; start < n; {
+		if start > 0 && (opts.Anchor == AnchorStart || opts.Anchor == AnchorBoth) {
+			break
+		}
+		end, ok := n, toEnd && s.has(live[start], s.d.Q0)
+		if !toEnd {
+			end, ok = s.matchAt(input, start, live, opts.Semantics)
+		}
+		if !ok {
+			start++
+			continue
+		}
+		out = append(out, Match{Start: start, End: end})
+		if opts.Mode == Overlapping {
+			start++
+		} else {
+			start = end
+		}
+	}
+	return out
+}
+
+// matchAt runs the DFA from Q0 over input[start:] and returns the end of a
+// non-empty match. Leftmost-shortest stops at the first position where the
+// run is in F; leftmost-longest goes on while live shows that a later end
+// is still possible, so the run never reads past the end it returns.
+func (s *scanner[Q, Sigma]) matchAt(input []Sigma, start int, live []int, sem Semantics) (int, bool) {
+	end, found := 0, false
+	q := s.d.Q0
+	for i := start; i < len(input); i++ {
+		qNext, ok := s.d.next(q, input[i])
+		if !ok || !s.has(live[i+1], qNext) {
+			break
+		}
+		s.steps++
+		q = qNext
+		if s.d.F.Has(q) {
+			end, found = i+1, true
+			if sem == LeftmostShortest {
+				break
+			}
+		}
+	}
+	return end, found
+}
+
+// FindAll scans input for substrings accepted by the DFA and returns them in
+// order of their start position. Empty matches are never reported.
+//
+// It takes time linear in the input: one backward pass marks where a match
+// can still end, and each run from Q0 reads only up to the end it reports.
+// Non-overlapping scans read each symbol at most twice; overlapping scans
+// add the total length of the matches. The whole input must be in memory.
+func (d *DFA[Q, Sigma]) FindAll(input []Sigma, opts ScanOptions) []Match {
+	return newScanner(d).findAll(input, opts)
+}
+
+// ReplaceAll returns a copy of input in which every non-overlapping,
+// leftmost-longest match is replaced by repl(match). Symbols outside matches
+// are copied through. Matches are found as by FindAll, in linear time.
+func (d *DFA[Q, Sigma]) ReplaceAll(input []Sigma, repl func(match []Sigma) []Sigma) []Sigma {
+	out := make([]Sigma, 0, len(input))
+	last := 0
+	for _, m := range d.FindAll(input, ScanOptions{}) {
+		out = append(out, input[last:m.Start]...)
+		out = append(out, repl(input[m.Start:m.End])...)
+		last = m.End
+	}
+	return append(out, input[last:]...)
+}
+
+// streamChunk is how many symbols ReplaceReader buffers, at least, before
+// it settles the matches among them.
+const streamChunk = 4096
+
+// ReplaceReader is ReplaceAll over a stream: it decodes symbols from r with
+// dec, replaces every non-overlapping, leftmost-longest match by
+// repl(match) and encodes the result to w with enc, in one forward pass.
+// The buffer behind match is reused, so repl must not keep it.
+//
+// Whether a match ends or goes on depends on the input still to come, so
+// the symbols read since the last point where no match could be open are
+// buffered; past streamChunk symbols, the buffer is settled at the next
+// such point. Memory is therefore bounded by the longest stretch in which
+// some match stays open, which is about the length of a match for most
+// patterns but is the whole input for a+b on a long run of a's.
+func (d *DFA[Q, Sigma]) ReplaceReader(w io.Writer, enc SymbolEncoder[Sigma], r io.Reader, dec SymbolDecoder[Sigma], repl func(match []Sigma) []Sigma) error {
+	s := newScanner(d)
+	s.extendable()
+	none := s.intern(make(bitset, s.words))
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	write := func(syms []Sigma) error {
+		for _, a := range syms {
+			if err := enc.Encode(bw, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var buf []Sigma
+	settle := func() error {
+		last := 0
+		for _, m := range s.findAll(buf, ScanOptions{}) {
+			if err := write(buf[last:m.Start]); err != nil {
+				return err
+			}
+			if err := write(repl(buf[m.Start:m.End])); err != nil {
+				return err
+			}
+			last = m.End
+		}
+		err := write(buf[last:])
+		buf = buf[:0]
+		return err
+	}
+	open := none
+	for i := 0; ; i++ {
+		a, err := dec.Decode(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("symbol %d: %w", i, err)
+		}
+		buf = append(buf, a)
+		if open = s.advance(open, a); open == none && len(buf) >= streamChunk {
+			if err := settle(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := settle(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
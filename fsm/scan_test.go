@@ -0,0 +1,255 @@
+package fsm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// literalDFA builds a partial DFA over runes that accepts exactly word.
+// State i means "the first i runes of word have been read".
+func literalDFA(word string) *DFA[int, rune] {
+	runes := []rune(word)
+	states := make([]int, len(runes)+1)
+	delta := TransitionFn[int, rune]{}
+	for i, r := range runes {
+		states[i+1] = i + 1
+		delta[i] = map[rune]int{r: i + 1}
+	}
+	return Must(NewDFA(states, runes, 0, []int{len(runes)}, delta, false))
+}
+
+// TestFindAll_Modes checks overlapping vs non-overlapping reporting.
+func TestFindAll_Modes(t *testing.T) {
+	d := literalDFA("aa")
+	in := []rune("aaaa")
+
+	got := d.FindAll(in, ScanOptions{Mode: NonOverlapping})
+	want := []Match{{0, 2}, {2, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("non-overlapping: got %v, want %v", got, want)
+	}
+
+	got = d.FindAll(in, ScanOptions{Mode: Overlapping})
+	want = []Match{{0, 2}, {1, 3}, {2, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("overlapping: got %v, want %v", got, want)
+	}
+}
+
+// TestFindAll_NoMatch ensures unknown symbols and partial runs yield no matches.
+func TestFindAll_NoMatch(t *testing.T) {
+	d := literalDFA("ab")
+	if got := d.FindAll([]rune("xaxbx"), ScanOptions{}); len(got) != 0 {
+		t.Fatalf("expected no matches, got %v", got)
+	}
+}
+
+// TestReplaceAll rewrites every match and keeps the rest of the input.
+func TestReplaceAll(t *testing.T) {
+	d := literalDFA("ab")
+	got := d.ReplaceAll([]rune("xabyabab"), func(m []rune) []rune { return []rune("<" + string(m) + ">") })
+	if want := "x<ab>y<ab><ab>"; string(got) != want {
+		t.Fatalf("got %q, want %q", string(got), want)
+	}
+}
+
+// TestFindAll_Anchors checks each anchoring mode against the same input.
+func TestFindAll_Anchors(t *testing.T) {
+	d := literalDFA("ab")
+	cases := []struct {
+		in     string
+		anchor Anchor
+		want   []Match
+	}{
+		{"abxab", Unanchored, []Match{{0, 2}, {3, 5}}},
+		{"abxab", AnchorStart, []Match{{0, 2}}},
+		{"xab", AnchorStart, nil},
+		{"abxab", AnchorEnd, []Match{{3, 5}}},
+		{"abx", AnchorEnd, nil},
+		{"ab", AnchorBoth, []Match{{0, 2}}},
+		{"abab", AnchorBoth, nil},
+	}
+	for _, c := range cases {
+		got := d.FindAll([]rune(c.in), ScanOptions{Anchor: c.anchor})
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%q anchor=%d: got %v, want %v", c.in, c.anchor, got, c.want)
+		}
+	}
+}
+
+// TestFindAll_Semantics compares leftmost-shortest and leftmost-longest on a+.
+func TestFindAll_Semantics(t *testing.T) {
+	// 0 --a--> 1 (final) --a--> 1
+	d := Must(NewDFA([]int{0, 1}, []rune{'a', 'b'}, 0, []int{1},
+		TransitionFn[int, rune]{0: {'a': 1}, 1: {'a': 1}}, false))
+	in := []rune("aaba")
+
+	got := d.FindAll(in, ScanOptions{Semantics: LeftmostShortest})
+	want := []Match{{0, 1}, {1, 2}, {3, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("leftmost-shortest: got %v, want %v", got, want)
+	}
+
+	got = d.FindAll(in, ScanOptions{})
+	want = []Match{{0, 2}, {3, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("leftmost-longest: got %v, want %v", got, want)
+	}
+}
+
+// naiveFindAll is the quadratic reference for FindAll: it tries every end
+// from every start.
+func naiveFindAll(d *DFA[int, rune], in []rune, opts ScanOptions) []Match {
+	var out []Match
+	for start := 0; start < len(in); {
+		if start > 0 && (opts.Anchor == AnchorStart || opts.Anchor == AnchorBoth) {
+			break
+		}
+		end := -1
+		for e := start + 1; e <= len(in); e++ {
+			if opts.Anchor == AnchorEnd || opts.Anchor == AnchorBoth {
+				e = len(in)
+			}
+			if ok, _, _ := d.Accepts(in[start:e]); ok {
+				end = e
+				if opts.Semantics == LeftmostShortest {
+					break
+				}
+			}
+		}
+		if end < 0 {
+			start++
+			continue
+		}
+		out = append(out, Match{start, end})
+		if opts.Mode == Overlapping {
+			start++
+		} else {
+			start = end
+		}
+	}
+	return out
+}
+
+// TestFindAll_Reference compares every option combination with the
+// quadratic reference.
+func TestFindAll_Reference(t *testing.T) {
+	for _, pattern := range []string{`a*b|a`, `(ab)+`, `a+b+`, `b(a|b)*b`, `aa|aab?`} {
+		d := Must(CompileRegex(pattern))
+		for _, w := range allWords([]rune("abc"), 6) {
+			for _, opts := range []ScanOptions{
+				{}, {Mode: Overlapping}, {Semantics: LeftmostShortest},
+				{Anchor: AnchorStart}, {Anchor: AnchorEnd}, {Anchor: AnchorBoth},
+				{Anchor: AnchorEnd, Mode: Overlapping},
+			} {
+				got, want := d.FindAll(w, opts), naiveFindAll(d, w, opts)
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s on %q %+v: got %v, want %v", pattern, string(w), opts, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestFindAll_Linear counts the transitions of the forward runs on inputs
+// that made per-start rescans quadratic.
+func TestFindAll_Linear(t *testing.T) {
+	for _, pattern := range []string{`a+b`, `a*b|a`} {
+		d := Must(CompileRegex(pattern))
+		in := []rune(strings.Repeat("a", 10000))
+		s := newScanner(d)
+		s.findAll(in, ScanOptions{})
+		if s.steps > len(in) {
+			t.Errorf("%s: %d steps on %d symbols", pattern, s.steps, len(in))
+		}
+	}
+}
+
+// BenchmarkFindAll scans a long run of a's for a+b, which never matches;
+// the time per op grows linearly with the input.
+func BenchmarkFindAll(b *testing.B) {
+	d := Must(CompileRegex(`a+b`))
+	for _, n := range []int{1000, 10000, 100000} {
+		in := []rune(strings.Repeat("a", n))
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				d.FindAll(in, ScanOptions{})
+			}
+		})
+	}
+}
+
+var (
+	utf8Decoder = SymbolDecoderFunc[rune](func(r *bufio.Reader) (rune, error) {
+		c, _, err := r.ReadRune()
+		return c, err
+	})
+	utf8Encoder = SymbolEncoderFunc[rune](func(w *bufio.Writer, a rune) error {
+		_, err := w.WriteRune(a)
+		return err
+	})
+)
+
+// TestReplaceReader compares the streaming rewrite with ReplaceAll on
+// inputs long enough to be settled in several pieces.
+func TestReplaceReader(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	repl := func(m []rune) []rune { return []rune("<" + string(m) + ">") }
+	for _, pattern := range []string{`ab`, `a*b|a`, `(ab)+c?`, `a+b`} {
+		d := Must(CompileRegex(pattern))
+		for _, n := range []int{0, 10, 3 * streamChunk} {
+			in := make([]rune, n)
+			for i := range in {
+				in[i] = rune("abcx"[r.Intn(4)])
+			}
+			var out strings.Builder
+			if err := d.ReplaceReader(&out, utf8Encoder, strings.NewReader(string(in)), utf8Decoder, repl); err != nil {
+				t.Fatal(err)
+			}
+			if want := string(d.ReplaceAll(in, repl)); out.String() != want {
+				t.Fatalf("%s on %d symbols: streamed output differs", pattern, n)
+			}
+		}
+	}
+	d := literalDFA("ab")
+	err := d.ReplaceReader(io.Discard, RuneEncoder(map[rune]rune{'a': 'a'}), strings.NewReader("ab"), utf8Decoder, repl)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unencodable symbol: err = %v", err)
+	}
+}
+
+// TestReplaceReader_Streams checks that output is written before the input
+// ends.
+func TestReplaceReader_Streams(t *testing.T) {
+	d := literalDFA("ab")
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go func() {
+		err := d.ReplaceReader(outW, utf8Encoder, inR, utf8Decoder, func([]rune) []rune { return []rune("#") })
+		outW.CloseWithError(err)
+	}()
+	go inW.Write([]byte(strings.Repeat("xab", 3*streamChunk)))
+	got := make(chan string)
+	go func() {
+		buf := make([]byte, 4)
+		n, _ := io.ReadFull(outR, buf)
+		got <- string(buf[:n])
+	}()
+	select {
+	case s := <-got:
+		if s != "x#x#" {
+			t.Errorf("got %q", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no output before the end of input")
+	}
+	inW.Close()
+	io.Copy(io.Discard, outR)
+}
@@ -0,0 +1,222 @@
+package fsm
+
+import (
+	"errors"
+	"sync"
+)
+
+// ---------- Session scheduling ----------
+
+// ErrSchedulerClosed is returned by Send after Close.
+var ErrSchedulerClosed = errors.New("scheduler closed")
+
+// Delivery reports one event processed by a Scheduler.
+type Delivery[Q comparable, Sigma comparable] struct {
+	Session string
+	From    Q
+	On      Sigma
+	To      Q     // equal to From when Err is set
+	Err     error // the transition was undefined; the state is unchanged
+}
+
+// Scheduler hosts many lightweight sessions of one machine on a bounded
+// pool of worker goroutines. Each session has its own inbox: its events
+// are applied in the order they were sent, by one worker at a time. Ready
+// sessions wait in a FIFO run queue and give up their worker after Quantum
+// events, so a busy session cannot starve the others.
+type Scheduler[Q comparable, Sigma comparable] struct {
+	// Quantum is the maximum number of events a session processes before
+	// yielding its worker. Values below 1 mean 1. Set it before Send.
+	Quantum int
+
+	machine  *Frozen[Q, Sigma]
+	deliver  func(Delivery[Q, Sigma])
+	mu       sync.Mutex
+	cond     *sync.Cond
+	sessions map[string]*actor[Q, Sigma]
+	draining map[string]*actor[Q, Sigma] // removed sessions with events left
+	ready    []*actor[Q, Sigma]
+	pending  int // events sent but not yet processed
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+type actor[Q comparable, Sigma comparable] struct {
+	id      string
+	state   Q
+	inbox   []Sigma
+	queued  bool // in the run queue or being processed
+	removed bool
+	blocked bool             // waiting for a removed session of the same id to drain
+	next    *actor[Q, Sigma] // the session created for id after this one was removed
+}
+
+// busy reports whether the actor still has events to process.
+func (act *actor[Q, Sigma]) busy() bool { return act.queued || act.blocked }
+
+// NewScheduler starts workers goroutines running sessions of m. deliver,
+// if not nil, is called from the workers after every event; calls for one
+// session are sequential, calls for different sessions may be concurrent.
+func NewScheduler[Q comparable, Sigma comparable](m *Frozen[Q, Sigma], workers int, deliver func(Delivery[Q, Sigma])) *Scheduler[Q, Sigma] {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &Scheduler[Q, Sigma]{
+		Quantum:  1,
+		machine:  m,
+		deliver:  deliver,
+		sessions: make(map[string]*actor[Q, Sigma]),
+		draining: make(map[string]*actor[Q, Sigma]),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// Send queues event a for session id, creating the session at q0 if it
+// does not exist. It never blocks on processing. A session created after
+// Remove starts only once the events queued for the removed one are
+// processed, so the events of an id never run concurrently.
+func (s *Scheduler[Q, Sigma]) Send(id string, a Sigma) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSchedulerClosed
+	}
+	act := s.sessions[id]
+	if act == nil {
+		act = &actor[Q, Sigma]{id: id, state: s.machine.Start()}
+		if prev := s.draining[id]; prev != nil {
+			prev.next, act.blocked = act, true
+		}
+		s.sessions[id] = act
+	}
+	act.inbox = append(act.inbox, a)
+	s.pending++
+	if !act.queued && !act.blocked {
+		act.queued = true
+		s.ready = append(s.ready, act)
+		s.cond.Broadcast()
+	}
+	return nil
+}
+
+// State returns the current state of session id, reflecting every event
+// processed so far.
+func (s *Scheduler[Q, Sigma]) State(id string) (Q, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	act, ok := s.sessions[id]
+	if !ok {
+		var zero Q
+		return zero, false
+	}
+	return act.state, true
+}
+
+// Remove forgets session id. Events already queued for it are still
+// processed, before those of any new session with the same id.
+func (s *Scheduler[Q, Sigma]) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	act := s.sessions[id]
+	if act == nil {
+		return
+	}
+	delete(s.sessions, id)
+	if act.busy() {
+		act.removed = true
+		s.draining[id] = act
+	}
+}
+
+// Len returns the number of sessions.
+func (s *Scheduler[Q, Sigma]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Flush blocks until every event sent so far has been processed.
+func (s *Scheduler[Q, Sigma]) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.pending > 0 {
+		s.cond.Wait()
+	}
+}
+
+// Close stops accepting events, waits for the queued ones to be processed
+// and stops the workers.
+func (s *Scheduler[Q, Sigma]) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// drained releases the session waiting for act, a session that has just
+// processed its last queued event. s.mu must be held.
+func (s *Scheduler[Q, Sigma]) drained(act *actor[Q, Sigma]) {
+	if !act.removed {
+		return
+	}
+	if s.draining[act.id] == act {
+		delete(s.draining, act.id)
+	}
+	if next := act.next; next != nil {
+		act.next, next.blocked = nil, false
+		next.queued = true
+		s.ready = append(s.ready, next)
+	}
+}
+
+func (s *Scheduler[Q, Sigma]) work() {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		for len(s.ready) == 0 && !(s.closed && s.pending == 0) {
+			s.cond.Wait()
+		}
+		if len(s.ready) == 0 {
+			return
+		}
+		act := s.ready[0]
+		s.ready = s.ready[1:]
+		n := s.Quantum
+		if n < 1 {
+			n = 1
+		}
+		if n > len(act.inbox) {
+			n = len(act.inbox)
+		}
+		batch := act.inbox[:n:n]
+		act.inbox = act.inbox[n:]
+		state := act.state
+
+		s.mu.Unlock()
+		for _, a := range batch {
+			next, err := s.machine.Step(state, a)
+			if s.deliver != nil {
+				s.deliver(Delivery[Q, Sigma]{Session: act.id, From: state, On: a, To: next, Err: err})
+			}
+			state = next
+		}
+		s.mu.Lock()
+
+		act.state = state
+		s.pending -= n
+		if len(act.inbox) > 0 {
+			s.ready = append(s.ready, act)
+		} else {
+			act.queued = false
+			s.drained(act)
+		}
+		s.cond.Broadcast()
+	}
+}
@@ -0,0 +1,149 @@
+package fsm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestScheduler_Ordering runs many sessions on a few workers and checks
+// every session saw its events in order.
+func TestScheduler_Ordering(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string][]Bit)
+	s := NewScheduler(buildModThree().Freeze(), 4, func(d Delivery[State, Bit]) {
+		if d.Err != nil {
+			t.Error(d.Err)
+		}
+		mu.Lock()
+		seen[d.Session] = append(seen[d.Session], d.On)
+		mu.Unlock()
+	})
+	s.Quantum = 3
+
+	inputs := make(map[string][]Bit)
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("s%d", i)
+		for j := 0; j < 10; j++ {
+			b := Zero
+			if (i>>(j%8))&1 == 1 {
+				b = One
+			}
+			inputs[id] = append(inputs[id], b)
+			if err := s.Send(id, b); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	s.Flush()
+
+	d := buildModThree()
+	for id, in := range inputs {
+		want, _ := d.Run(in)
+		if got, ok := s.State(id); !ok || got != want {
+			t.Fatalf("%s: state %v, want %v", id, got, want)
+		}
+		for j, b := range seen[id] {
+			if b != in[j] {
+				t.Fatalf("%s: event %d out of order", id, j)
+			}
+		}
+	}
+	if s.Len() != 200 {
+		t.Fatalf("Len = %d", s.Len())
+	}
+	s.Close()
+	if err := s.Send("s0", One); err != ErrSchedulerClosed {
+		t.Fatalf("Send after Close = %v", err)
+	}
+}
+
+// TestScheduler_Fairness checks a flooded session does not hold the only
+// worker until its inbox is empty.
+func TestScheduler_Fairness(t *testing.T) {
+	var order []string
+	block := make(chan struct{})
+	s := NewScheduler(buildModThree().Freeze(), 1, func(d Delivery[State, Bit]) {
+		if d.Session == "gate" {
+			<-block
+			return
+		}
+		order = append(order, d.Session)
+	})
+	// Hold the worker so the queue fills up deterministically.
+	if err := s.Send("gate", Zero); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		s.Send("busy", One)
+	}
+	s.Send("quiet", One)
+	close(block)
+	s.Close()
+
+	if len(order) != 6 || order[1] != "quiet" {
+		t.Fatalf("order = %v, want quiet served after one busy event", order)
+	}
+}
+
+// TestScheduler_RemoveResend removes sessions with events still queued and
+// immediately reuses their ids: the new sessions must start from q0 and
+// must not run alongside the removed ones.
+func TestScheduler_RemoveResend(t *testing.T) {
+	var mu sync.Mutex
+	running := make(map[string]int)
+	seen := make(map[string][]Delivery[State, Bit])
+	s := NewScheduler(buildModThree().Freeze(), 8, func(d Delivery[State, Bit]) {
+		mu.Lock()
+		running[d.Session]++
+		if running[d.Session] > 1 {
+			t.Errorf("%s: two deliveries at once", d.Session)
+		}
+		seen[d.Session] = append(seen[d.Session], d)
+		mu.Unlock()
+		time.Sleep(50 * time.Microsecond)
+		mu.Lock()
+		running[d.Session]--
+		mu.Unlock()
+	})
+
+	old := []Bit{One, One, Zero, One} // ends in S1
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("s%d", i)
+		for _, b := range old {
+			if err := s.Send(id, b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Remove(id)
+		for _, b := range []Bit{One, Zero} {
+			if err := s.Send(id, b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Remove(id)
+		if err := s.Send(id, One); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Flush()
+	defer s.Close()
+
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("s%d", i)
+		got := seen[id]
+		if len(got) != len(old)+3 {
+			t.Fatalf("%s: %d deliveries", id, len(got))
+		}
+		if got[len(old)].From != S0 || got[len(old)+2].From != S0 {
+			t.Fatalf("%s: new session did not start at q0: %+v", id, got)
+		}
+		if q, ok := s.State(id); !ok || q != S1 {
+			t.Fatalf("%s: state %v, %v", id, q, ok)
+		}
+	}
+	if s.Len() != 20 {
+		t.Fatalf("Len = %d", s.Len())
+	}
+}
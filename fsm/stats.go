@@ -0,0 +1,72 @@
+package fsm
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ---------- Statistics ----------
+
+// Stats summarizes the size and shape of a machine, for capacity planning
+// and budget checks on generated machines.
+type Stats struct {
+	States    int     // |Q|
+	Symbols   int     // |Σ|
+	Edges     int     // defined transitions
+	Final     int     // |F|
+	Density   float64 // Edges / (States·Symbols); 1 for a complete DFA
+	Reachable float64 // fraction of Q reachable from q0
+
+	// MemoryBytes estimates the heap held by the map-based representation.
+	// It counts fixed-size state and symbol values only (not the contents
+	// behind strings or pointers), so treat it as a lower bound.
+	MemoryBytes int
+	// TableBytes is the size of the dense int32 table built by Compile.
+	TableBytes int
+}
+
+// Go maps keep buckets of 8 slots (plus one tophash byte each) at an average
+// load of 6.5 entries, and a header of about 48 bytes.
+const (
+	mapHeaderBytes = 48
+	mapLoadFactor  = 6.5 / 8
+)
+
+func mapBytes(entries int, entrySize uintptr) int {
+	return mapHeaderBytes + int(float64(entries)*float64(entrySize+1)/mapLoadFactor)
+}
+
+// Stats reports the size and shape of d.
+func (d *DFA[Q, Sigma]) Stats() Stats {
+	var q Q
+	var a Sigma
+	qSize, aSize := unsafe.Sizeof(q), unsafe.Sizeof(a)
+
+	s := Stats{States: len(d.Q), Symbols: len(d.Sigma), Final: len(d.F)}
+	s.MemoryBytes = mapBytes(len(d.Q), qSize) + mapBytes(len(d.Sigma), aSize) + mapBytes(len(d.F), qSize)
+	s.MemoryBytes += mapBytes(len(d.Delta), qSize+unsafe.Sizeof(uintptr(0))) + mapBytes(len(d.Default), 2*qSize)
+	for q := range d.Q {
+		for a := range d.Sigma {
+			if _, ok := d.next(q, a); ok {
+				s.Edges++
+			}
+		}
+	}
+	for _, row := range d.Delta {
+		s.MemoryBytes += mapBytes(len(row), aSize+qSize)
+	}
+	if cells := s.States * s.Symbols; cells > 0 {
+		s.Density = float64(s.Edges) / float64(cells)
+	}
+	if s.States > 0 {
+		s.Reachable = float64(len(d.reachable())) / float64(s.States)
+	}
+	s.TableBytes = 4*s.States*s.Symbols + s.States
+	return s
+}
+
+// String formats the statistics on one line.
+func (s Stats) String() string {
+	return fmt.Sprintf("states=%d symbols=%d edges=%d final=%d density=%.3f reachable=%.3f mem≈%dB table=%dB",
+		s.States, s.Symbols, s.Edges, s.Final, s.Density, s.Reachable, s.MemoryBytes, s.TableBytes)
+}
@@ -0,0 +1,22 @@
+package fsm
+
+import "testing"
+
+// TestStats checks the counts on mod-three and on a partial machine with an
+// unreachable state.
+func TestStats(t *testing.T) {
+	s := buildModThree().Stats()
+	if s.States != 3 || s.Symbols != 2 || s.Edges != 6 || s.Final != 3 || s.Density != 1 || s.Reachable != 1 {
+		t.Fatalf("mod-three stats = %+v", s)
+	}
+	if s.MemoryBytes <= 0 || s.TableBytes != 4*3*2+3 {
+		t.Fatalf("memory estimates = %d, %d", s.MemoryBytes, s.TableBytes)
+	}
+
+	delta := TransitionFn[int, rune]{0: {'a': 1}, 2: {'a': 0}}
+	d := Must(NewDFA([]int{0, 1, 2, 3}, []rune{'a', 'b'}, 0, []int{1}, delta, false))
+	s = d.Stats()
+	if s.Edges != 2 || s.Density != 0.25 || s.Reachable != 0.5 {
+		t.Fatalf("partial stats = %+v", s)
+	}
+}
@@ -0,0 +1,179 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ---------- Symbolic automata ----------
+
+// ErrOverlap reports two guards of one state that accept the same symbol.
+var ErrOverlap = errors.New("overlapping transition guards")
+
+// Predicate is a transition guard: the set of symbols it accepts.
+type Predicate[Sigma any] func(Sigma) bool
+
+// Is accepts exactly the given symbols.
+func Is[Sigma comparable](symbols ...Sigma) Predicate[Sigma] {
+	s := NewSet(symbols...)
+	return func(a Sigma) bool { return s.Has(a) }
+}
+
+// Not accepts the symbols p rejects.
+func Not[Sigma any](p Predicate[Sigma]) Predicate[Sigma] {
+	return func(a Sigma) bool { return !p(a) }
+}
+
+// SymbolicRule is one transition of a SymbolicDFA: from From, on any
+// symbol Guard accepts, go to To. Label names the guard in errors.
+type SymbolicRule[Q comparable, Sigma any] struct {
+	From  Q
+	Label string
+	Guard Predicate[Sigma]
+	To    Q
+}
+
+// SymbolicDFA is a DFA whose transitions are guarded by predicates rather
+// than listed per symbol, e.g. unicode.IsLetter → S1 and unicode.IsDigit →
+// S2, so it handles alphabets too large to enumerate, such as all runes.
+// Predicates are opaque, so determinism (no symbol accepted by two guards
+// of a state) is checked on a probe set of symbols when building the
+// machine, and on every step at run time.
+type SymbolicDFA[Q comparable, Sigma any] struct {
+	Q  Set[Q]
+	Q0 Q
+	F  Set[Q]
+
+	rows map[Q][]SymbolicRule[Q, Sigma]
+}
+
+// SymbolOverlap is a symbol accepted by two guards of State.
+type SymbolOverlap[Q comparable, Sigma any] struct {
+	State   Q
+	A, B    string // labels of the two rules
+	Witness Sigma
+}
+
+func (o SymbolOverlap[Q, Sigma]) Error() string {
+	return fmt.Sprintf("%v: state %v, %q and %q both accept %v", ErrOverlap, o.State, o.A, o.B, o.Witness)
+}
+
+// Is makes errors.Is(err, ErrOverlap) true.
+func (o SymbolOverlap[Q, Sigma]) Is(target error) bool { return target == ErrOverlap }
+
+// NewSymbolicDFA builds and validates a SymbolicDFA. Every rule must have
+// a guard and mention only states in Q, and no symbol of probe may be
+// accepted by two guards of one state; the first such overlap is returned
+// as a SymbolOverlap. Probing every rune costs about a million calls per
+// guard, which is affordable once at startup.
+func NewSymbolicDFA[Q comparable, Sigma any](
+	states []Q,
+	q0 Q,
+	finals []Q,
+	rules []SymbolicRule[Q, Sigma],
+	probe []Sigma,
+) (*SymbolicDFA[Q, Sigma], error) {
+	m := &SymbolicDFA[Q, Sigma]{
+		Q:    NewSet(states...),
+		Q0:   q0,
+		F:    NewSet(finals...),
+		rows: make(map[Q][]SymbolicRule[Q, Sigma]),
+	}
+	if !m.Q.Has(q0) {
+		return nil, fmt.Errorf("%w: q0 %v not in Q", ErrInvalidInput, q0)
+	}
+	for f := range m.F {
+		if !m.Q.Has(f) {
+			return nil, fmt.Errorf("%w: final %v not in Q", ErrInvalidInput, f)
+		}
+	}
+	for _, r := range rules {
+		if !m.Q.Has(r.From) || !m.Q.Has(r.To) {
+			return nil, fmt.Errorf("%w: rule %v --%s--> %v uses a state not in Q", ErrInvalidInput, r.From, r.Label, r.To)
+		}
+		if r.Guard == nil {
+			return nil, fmt.Errorf("%w: rule %v --%s--> %v has no guard", ErrInvalidInput, r.From, r.Label, r.To)
+		}
+		m.rows[r.From] = append(m.rows[r.From], r)
+	}
+	if overlaps := m.Overlaps(probe); len(overlaps) > 0 {
+		return nil, overlaps[0]
+	}
+	return m, nil
+}
+
+// Overlaps returns, for every pair of guards of a state that accept a
+// common symbol of probe, the first such symbol, ordered by state and by
+// the order of probe.
+func (m *SymbolicDFA[Q, Sigma]) Overlaps(probe []Sigma) []SymbolOverlap[Q, Sigma] {
+	type pair struct{ i, j int }
+	var out []SymbolOverlap[Q, Sigma]
+	for _, q := range m.Q.sorted() {
+		row := m.rows[q]
+		if len(row) < 2 {
+			continue
+		}
+		found := make(map[pair]bool)
+		var match []int
+		for _, a := range probe {
+			match = match[:0]
+			for j, r := range row {
+				if r.Guard(a) {
+					match = append(match, j)
+				}
+			}
+			for x, i := range match {
+				for _, j := range match[x+1:] {
+					if p := (pair{i, j}); !found[p] {
+						found[p] = true
+						out = append(out, SymbolOverlap[Q, Sigma]{State: q, A: row[i].Label, B: row[j].Label, Witness: a})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Step applies a single transition: q' = δ(q,a). It fails if no guard of
+// q accepts a, or if two do.
+func (m *SymbolicDFA[Q, Sigma]) Step(q Q, a Sigma) (Q, error) {
+	match := -1
+	row := m.rows[q]
+	for i, r := range row {
+		if !r.Guard(a) {
+			continue
+		}
+		if match >= 0 {
+			return q, SymbolOverlap[Q, Sigma]{State: q, A: row[match].Label, B: r.Label, Witness: a}
+		}
+		match = i
+	}
+	if match < 0 {
+		return q, &ErrNoTransition[Q, Sigma]{State: q, Symbol: a}
+	}
+	return row[match].To, nil
+}
+
+// Run consumes an input sequence and returns the final state. A symbol
+// that cannot be consumed is reported as a *RunError.
+func (m *SymbolicDFA[Q, Sigma]) Run(input []Sigma) (Q, error) {
+	q := m.Q0
+	for i, a := range input {
+		qNext, err := m.Step(q, a)
+		if err != nil {
+			return q, &RunError[Q, Sigma]{Index: i, Symbol: a, State: q, Err: err}
+		}
+		q = qNext
+	}
+	return q, nil
+}
+
+// Accepts runs the machine and checks if the final state is in F.
+func (m *SymbolicDFA[Q, Sigma]) Accepts(input []Sigma) (bool, Q, error) {
+	q, err := m.Run(input)
+	if err != nil {
+		return false, q, err
+	}
+	return m.F.Has(q), q, nil
+}
@@ -0,0 +1,79 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+)
+
+// identifier accepts a letter or underscore followed by letters, digits
+// and underscores, over all of Unicode.
+func identifier(probe []rune) (*SymbolicDFA[string, rune], error) {
+	start := Predicate[rune](func(r rune) bool { return unicode.IsLetter(r) || r == '_' })
+	return NewSymbolicDFA([]string{"start", "ident"}, "start", []string{"ident"},
+		[]SymbolicRule[string, rune]{
+			{From: "start", Label: "letter or _", Guard: start, To: "ident"},
+			{From: "ident", Label: "letter or _", Guard: start, To: "ident"},
+			{From: "ident", Label: "digit", Guard: unicode.IsDigit, To: "ident"},
+		}, probe)
+}
+
+// TestSymbolicDFA runs a Unicode identifier machine.
+func TestSymbolicDFA(t *testing.T) {
+	all := make([]rune, 0, unicode.MaxRune+1)
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		all = append(all, r)
+	}
+	m, err := identifier(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]bool{
+		"x1":     true,
+		"_":      true,
+		"Σύνολο": true,
+		"名前٣":    true,
+		"1x":     false,
+		"":       false,
+	} {
+		if got, _, _ := m.Accepts([]rune(in)); got != want {
+			t.Errorf("%q: got %v want %v", in, got, want)
+		}
+	}
+	if _, _, err := m.Accepts([]rune("a-b")); err == nil {
+		t.Error("expected an error for an unguarded symbol")
+	}
+}
+
+// TestSymbolicDFA_Overlap detects guards that share symbols.
+func TestSymbolicDFA_Overlap(t *testing.T) {
+	rules := []SymbolicRule[int, rune]{
+		{From: 0, Label: "vowel", Guard: Is('a', 'e', 'i', 'o', 'u'), To: 0},
+		{From: 0, Label: "not b", Guard: Not(Is('b')), To: 1},
+		{From: 0, Label: "b", Guard: Is('b'), To: 1},
+	}
+	_, err := NewSymbolicDFA([]int{0, 1}, 0, nil, rules, []rune("xbe"))
+	var o SymbolOverlap[int, rune]
+	if !errors.As(err, &o) || !errors.Is(err, ErrOverlap) || o.A != "vowel" || o.B != "not b" || o.Witness != 'e' {
+		t.Fatalf("got %v", err)
+	}
+
+	// Without a probe the overlap surfaces when a run reaches it.
+	m, err := NewSymbolicDFA([]int{0, 1}, 0, nil, rules, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Overlaps([]rune("abcde")); len(got) != 1 || got[0].Witness != 'a' {
+		t.Fatalf("Overlaps = %v", got)
+	}
+	if _, err := m.Step(0, 'x'); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Step(0, 'o'); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Step: got %v", err)
+	}
+
+	if _, err := NewSymbolicDFA([]int{0}, 0, nil, []SymbolicRule[int, rune]{{From: 0, To: 0}}, nil); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("missing guard: got %v", err)
+	}
+}
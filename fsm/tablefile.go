@@ -0,0 +1,279 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ---------- Binary table files ----------
+
+// Binary layout (little endian, every field 4-byte aligned):
+//
+//	magic "FSMT", version, numStates, numSymbols, layout (0 dense, 1 sparse)
+//	fingerprint: 64 bytes of hex
+//	final: numStates bytes, zero-padded to a multiple of 4
+//	dense:  next [numStates*numSymbols]int32
+//	sparse: start [numStates+1]uint32, def [numStates]int32,
+//	        syms [start[numStates]]int32, next [start[numStates]]int32
+const (
+	tableMagic     = "FSMT"
+	tableVersion   = 1
+	tableHeaderLen = 4 * 5
+	fingerprintLen = 64
+)
+
+// ErrBadTableFile reports a malformed binary table.
+var ErrBadTableFile = errors.New("malformed table file")
+
+// MarshalBinary encodes the table in the binary format read by LoadTable.
+// Only numbers are stored: map symbols to their numbers with SymbolNumbers.
+func (t *Table[Q, Sigma]) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if err := t.WriteBinary(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// WriteBinary writes the binary encoding of the table to w.
+func (t *Table[Q, Sigma]) WriteBinary(w io.Writer) error {
+	le := binary.LittleEndian
+	u32 := func(v uint32) []byte { var x [4]byte; le.PutUint32(x[:], v); return x[:] }
+	var b bytes.Buffer
+	layout := uint32(0)
+	if t.Sparse() {
+		layout = 1
+	}
+	b.WriteString(tableMagic)
+	for _, v := range []uint32{tableVersion, uint32(len(t.States)), uint32(len(t.Symbols)), layout} {
+		b.Write(u32(v))
+	}
+	fp := make([]byte, fingerprintLen)
+	copy(fp, t.Fingerprint)
+	b.Write(fp)
+	for _, f := range t.Final {
+		if f {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+	}
+	for b.Len()%4 != 0 {
+		b.WriteByte(0)
+	}
+
+	if !t.Sparse() {
+		for _, v := range t.Next {
+			b.Write(u32(uint32(v)))
+		}
+	} else {
+		start := uint32(0)
+		for _, r := range t.sparse {
+			b.Write(u32(start))
+			start += uint32(len(r.syms))
+		}
+		b.Write(u32(start))
+		for _, r := range t.sparse {
+			b.Write(u32(uint32(r.def)))
+		}
+		for _, r := range t.sparse {
+			for _, s := range r.syms {
+				b.Write(u32(uint32(s)))
+			}
+		}
+		for _, r := range t.sparse {
+			for _, n := range r.next {
+				b.Write(u32(uint32(n)))
+			}
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// SymbolNumbers returns the numbering Compile assigns to an alphabet, for
+// driving a TableView with real symbols.
+func SymbolNumbers[Sigma comparable](alphabet []Sigma) map[Sigma]int32 {
+	sorted := NewSet(alphabet...).sorted()
+	out := make(map[Sigma]int32, len(sorted))
+	for i, a := range sorted {
+		out[a] = int32(i)
+	}
+	return out
+}
+
+// TableView executes a binary table in place: it reads δ straight from the
+// byte slice, which may be memory-mapped (see MmapTable) so that many
+// processes share one copy of a large automaton.
+type TableView struct {
+	data       []byte
+	numStates  int
+	numSymbols int
+	sparse     bool
+	final      int // offsets of the sections in data
+	next       int
+	start      int
+	def        int
+	syms       int
+	snext      int
+}
+
+// LoadTable validates a binary table and returns a view backed by data:
+// besides the sizes, it checks that the sparse row offsets are in order,
+// that each row's symbols are ascending and in range, and that every
+// target is a state or -1. data must not be modified while the view is in
+// use.
+func LoadTable(data []byte) (*TableView, error) {
+	le := binary.LittleEndian
+	if len(data) < tableHeaderLen+fingerprintLen || string(data[:4]) != tableMagic {
+		return nil, fmt.Errorf("%w: bad header", ErrBadTableFile)
+	}
+	if v := le.Uint32(data[4:]); v != tableVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadTableFile, v)
+	}
+	v := &TableView{
+		data:       data,
+		numStates:  int(le.Uint32(data[8:])),
+		numSymbols: int(le.Uint32(data[12:])),
+		sparse:     le.Uint32(data[16:]) == 1,
+		final:      tableHeaderLen + fingerprintLen,
+	}
+	if v.numStates < 1 {
+		return nil, fmt.Errorf("%w: no start state", ErrBadTableFile)
+	}
+	// Bound the section sizes by the data before computing offsets, so
+	// that a corrupt header cannot overflow them.
+	if uint64(v.numStates) > uint64(len(data)) || v.numSymbols < 0 ||
+		!v.sparse && uint64(v.numStates)*uint64(v.numSymbols) > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: truncated", ErrBadTableFile)
+	}
+	off := v.final + (v.numStates+3)/4*4
+	size := off
+	if !v.sparse {
+		v.next = off
+		size += 4 * v.numStates * v.numSymbols
+	} else {
+		v.start = off
+		v.def = v.start + 4*(v.numStates+1)
+		v.syms = v.def + 4*v.numStates
+		if len(data) < v.syms {
+			return nil, fmt.Errorf("%w: truncated", ErrBadTableFile)
+		}
+		total := int(le.Uint32(data[v.start+4*v.numStates:]))
+		if total > len(data) {
+			return nil, fmt.Errorf("%w: truncated", ErrBadTableFile)
+		}
+		v.snext = v.syms + 4*total
+		size = v.snext + 4*total
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("%w: size %d, want %d", ErrBadTableFile, len(data), size)
+	}
+	if err := v.check(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// check validates the transition sections of a view whose sizes match.
+func (v *TableView) check() error {
+	target := func(off int) error {
+		if q := v.i32(off); q < -1 || int(q) >= v.numStates {
+			return fmt.Errorf("%w: target %d out of range", ErrBadTableFile, q)
+		}
+		return nil
+	}
+	if !v.sparse {
+		for i := 0; i < v.numStates*v.numSymbols; i++ {
+			if err := target(v.next + 4*i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	total := uint32(v.i32(v.start + 4*v.numStates))
+	prev := uint32(0)
+	for q := 0; q <= v.numStates; q++ {
+		lo := uint32(v.i32(v.start + 4*q))
+		if lo < prev || lo > total || (q == 0 && lo != 0) {
+			return fmt.Errorf("%w: row offset %d of state %d out of order", ErrBadTableFile, lo, q)
+		}
+		if q < v.numStates {
+			if err := target(v.def + 4*q); err != nil {
+				return err
+			}
+		}
+		if q > 0 {
+			for i := prev; i < lo; i++ {
+				a := v.i32(v.syms + 4*int(i))
+				if a < 0 || int(a) >= v.numSymbols || (i > prev && a <= v.i32(v.syms+4*int(i-1))) {
+					return fmt.Errorf("%w: symbol %d of state %d out of order or range", ErrBadTableFile, a, q-1)
+				}
+				if err := target(v.snext + 4*int(i)); err != nil {
+					return err
+				}
+			}
+		}
+		prev = lo
+	}
+	return nil
+}
+
+func (v *TableView) i32(off int) int32 { return int32(binary.LittleEndian.Uint32(v.data[off:])) }
+
+// NumStates returns the number of states; state 0 is the start state.
+func (v *TableView) NumStates() int { return v.numStates }
+
+// NumSymbols returns the alphabet size.
+func (v *TableView) NumSymbols() int { return v.numSymbols }
+
+// Fingerprint returns the fingerprint of the source DFA.
+func (v *TableView) Fingerprint() string {
+	return string(bytes.TrimRight(v.data[tableHeaderLen:tableHeaderLen+fingerprintLen], "\x00"))
+}
+
+// IsFinal reports whether state is accepting.
+func (v *TableView) IsFinal(state int32) bool {
+	return state >= 0 && int(state) < v.numStates && v.data[v.final+int(state)] == 1
+}
+
+// Step returns δ(state, symbol), or -1 when undefined.
+func (v *TableView) Step(state, symbol int32) int32 {
+	if state < 0 || int(state) >= v.numStates || symbol < 0 || int(symbol) >= v.numSymbols {
+		return -1
+	}
+	if !v.sparse {
+		return v.i32(v.next + 4*(int(state)*v.numSymbols+int(symbol)))
+	}
+	lo := int(v.i32(v.start + 4*int(state)))
+	hi := int(v.i32(v.start + 4*int(state) + 4))
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch s := v.i32(v.syms + 4*mid); {
+		case s == symbol:
+			return v.i32(v.snext + 4*mid)
+		case s < symbol:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return v.i32(v.def + 4*int(state))
+}
+
+// Run consumes symbol numbers from state 0 and returns the final state. A
+// symbol that cannot be consumed is reported as a *RunError[int32, int32].
+func (v *TableView) Run(symbols []int32) (int32, error) {
+	s := int32(0)
+	for i, a := range symbols {
+		next := v.Step(s, a)
+		if next < 0 {
+			return s, &RunError[int32, int32]{Index: i, Symbol: a, State: s, Err: &ErrNoTransition[int32, int32]{State: s, Symbol: a}}
+		}
+		s = next
+	}
+	return s, nil
+}
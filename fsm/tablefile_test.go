@@ -0,0 +1,135 @@
+package fsm
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// checkBinary encodes tab, loads it back and compares the view with tab.
+func checkBinary[Q comparable, Sigma comparable](t *testing.T, tab *Table[Q, Sigma]) {
+	t.Helper()
+	data, err := tab.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := LoadTable(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.NumStates() != len(tab.States) || v.NumSymbols() != len(tab.Symbols) || v.Fingerprint() != tab.Fingerprint {
+		t.Fatalf("header mismatch: %d/%d %q", v.NumStates(), v.NumSymbols(), v.Fingerprint())
+	}
+	for s := int32(0); int(s) < len(tab.States); s++ {
+		if v.IsFinal(s) != tab.Final[s] {
+			t.Fatalf("final(%d) mismatch", s)
+		}
+		for a := int32(0); int(a) < len(tab.Symbols); a++ {
+			if got, want := v.Step(s, a), tab.At(s, a); got != want {
+				t.Fatalf("δ(%d,%d) = %d, want %d", s, a, got, want)
+			}
+		}
+	}
+}
+
+// TestTableBinary_RoundTrip encodes dense and sparse tables and reads them back.
+func TestTableBinary_RoundTrip(t *testing.T) {
+	alphabet := make([]int, 1000)
+	row := make(map[int]int, len(alphabet))
+	for i := range alphabet {
+		alphabet[i] = i
+		row[i] = 0
+	}
+	row[7] = 1
+	sparse := Must(NewDFA([]int{0, 1}, alphabet, 0, []int{1}, TransitionFn[int, int]{0: row}, false)).Compile()
+	if !sparse.Sparse() {
+		t.Fatal("expected sparse table")
+	}
+
+	checkBinary(t, buildModThree().Compile())
+	checkBinary(t, sparse)
+
+	if _, err := LoadTable([]byte("nope")); !errors.Is(err, ErrBadTableFile) {
+		t.Fatalf("expected ErrBadTableFile, got %v", err)
+	}
+}
+
+// TestLoadTable_Corrupt damages the offsets, targets and length of valid
+// tables and checks LoadTable rejects each one.
+func TestLoadTable_Corrupt(t *testing.T) {
+	alphabet := make([]int, 100)
+	row := make(map[int]int, len(alphabet))
+	for i := range alphabet {
+		alphabet[i] = i
+		row[i] = 0
+	}
+	row[7], row[9] = 1, 1
+	sparse, err := Must(NewDFA([]int{0, 1}, alphabet, 0, []int{1}, TransitionFn[int, int]{0: row}, false)).Compile().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, err := buildModThree().Compile().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sparse sections start after the header, fingerprint and the two
+	// final flags padded to 4 bytes.
+	start := tableHeaderLen + fingerprintLen + 4
+	put := func(data []byte, off int, v uint32) []byte {
+		out := append([]byte(nil), data...)
+		binary.LittleEndian.PutUint32(out[off:], v)
+		return out
+	}
+	for name, data := range map[string][]byte{
+		"truncated":       sparse[:len(sparse)-4],
+		"offset too big":  put(sparse, start, 2000108),
+		"offsets reverse": put(sparse, start+4, 3),
+		"symbol range":    put(sparse, start+4*3+4*2, 500),
+		"sparse target":   put(sparse, start+4*3+4*2+4*2, 7),
+		"default target":  put(sparse, start+4*3, 0xfffffff0),
+		"dense target":    put(dense, len(dense)-4, 3),
+		"no states":       put(dense, 8, 0),
+		"huge header":     put(dense, 12, 0xffffffff),
+	} {
+		if _, err := LoadTable(data); !errors.Is(err, ErrBadTableFile) {
+			t.Errorf("%s: expected ErrBadTableFile, got %v", name, err)
+		}
+	}
+}
+
+// TestMmapTable maps a table file and runs symbols through it.
+func TestMmapTable(t *testing.T) {
+	tab := buildModThree().Compile()
+	data, err := tab.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "modthree.fsmt")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, unmap, err := MmapTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unmap()
+
+	num := SymbolNumbers([]Bit{Zero, One})
+	var in []int32
+	for _, a := range []Bit{One, Zero, One, One} {
+		in = append(in, num[a])
+	}
+	s, err := v.Run(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab.States[s] != S2 {
+		t.Fatalf("final = %v, want S2", tab.States[s])
+	}
+	var re *RunError[int32, int32]
+	if _, err := v.Run([]int32{1, 5}); !errors.As(err, &re) || re.Index != 1 || re.State != 1 || !errors.Is(err, ErrUndefined) {
+		t.Fatalf("expected a RunError for an unknown symbol, got %v", err)
+	}
+}
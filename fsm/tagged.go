@@ -0,0 +1,257 @@
+package fsm
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// ---------- Tagged DFA (capture groups) ----------
+
+// TaggedDFA is a rune DFA whose transitions also copy input positions
+// between registers, a TDFA after Laurikari, so that a match reports where
+// each capture group of its pattern matched and not only where the match
+// lies. Each state stands for the threads of the program still running, in
+// priority order, and keeps one register per thread and capture slot; a
+// transition rewrites the registers with a fixed list of copies, so the
+// groups cost a constant amount of work per symbol.
+//
+// Submatches follow the leftmost-first rules of the regexp package. Like
+// the rest of the scanning layer, `^` and `$` refer to the ends of the
+// match, and empty matches are never reported.
+type TaggedDFA struct {
+	scan  *DFA[int, rune] // minimal machine with the same matches
+	names []string
+	slots int
+	start taggedEdge
+	delta []map[rune]taggedEdge
+}
+
+// Sources of a register in taggedEdge: the current position, no position
+// (a group that did not take part), or else an old register.
+const (
+	tagPos   = -1
+	tagUnset = -2
+)
+
+// taggedEdge is a move of a TaggedDFA. Register r of the target is set
+// from ops[r]; if the move reaches a match, its capture slots are set from
+// match in the same way.
+type taggedEdge struct {
+	to    int
+	ops   []int
+	match []int
+}
+
+// CompileRegexTagged compiles a pattern in Go regexp syntax, as
+// CompileRegexFirst does, into a TaggedDFA that reports capture groups.
+func CompileRegexTagged(pattern string) (*TaggedDFA, error) {
+	return compileTagged(pattern, true)
+}
+
+// compileTagged builds the TaggedDFA for pattern. With cut, a thread that
+// reaches a match cuts off the threads below it, which gives the
+// leftmost-first matches; without it, every thread runs on, which gives
+// the leftmost-longest matches of regexp.Regexp.Longest with the groups of
+// the first thread, by priority, to end there.
+func compileTagged(pattern string, cut bool) (*TaggedDFA, error) {
+	re, err := parseRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	alphabet, err := regexAlphabet(re)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := regexProg(re)
+	if err != nil {
+		return nil, err
+	}
+	slots := prog.NumCap
+	n := len(prog.Inst)
+
+	// A closure collects the rune instructions reachable by ε-moves, in
+	// priority order, with the sources of their registers.
+	type closure struct {
+		threads []int
+		ops     []int
+		match   []int
+		seen    Set[int]
+	}
+	// follow walks from pc with the register sources tags. It reports
+	// whether a match was reached and cuts off the rest. After a `$`, the
+	// walk may only finish: it marks a match without cutting, as `$` holds
+	// only if the input ends here, and otherwise the other threads go on.
+	var follow func(c *closure, pc int, atStart, atEnd bool, tags []int) bool
+	follow = func(c *closure, pc int, atStart, atEnd bool, tags []int) bool {
+		key := pc
+		if atEnd {
+			key += n
+		}
+		if c.seen.Has(key) {
+			return false
+		}
+		c.seen[key] = struct{}{}
+		inst := &prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstMatch:
+			if c.match == nil {
+				c.match = append([]int(nil), tags...)
+			}
+			return cut && !atEnd
+		case syntax.InstAlt, syntax.InstAltMatch:
+			return follow(c, int(inst.Out), atStart, atEnd, tags) || follow(c, int(inst.Arg), atStart, atEnd, tags)
+		case syntax.InstNop:
+			return follow(c, int(inst.Out), atStart, atEnd, tags)
+		case syntax.InstCapture:
+			if int(inst.Arg) < slots {
+				tags = append([]int(nil), tags...)
+				tags[inst.Arg] = tagPos
+			}
+			return follow(c, int(inst.Out), atStart, atEnd, tags)
+		case syntax.InstEmptyWidth:
+			op := syntax.EmptyOp(inst.Arg)
+			if op&syntax.EmptyBeginText != 0 && !atStart {
+				return false
+			}
+			return follow(c, int(inst.Out), atStart, atEnd || op&syntax.EmptyEndText != 0, tags)
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			if !atEnd {
+				c.threads = append(c.threads, pc)
+				c.ops = append(c.ops, tags...)
+			}
+		}
+		return false
+	}
+
+	t := &TaggedDFA{names: re.CapNames(), slots: slots}
+	var states [][]int
+	ids := make(map[string]int)
+	scan := &DFA[int, rune]{
+		Q:     make(Set[int]),
+		Sigma: NewSet(alphabet...),
+		F:     make(Set[int]),
+		Delta: make(TransitionFn[int, rune]),
+	}
+	edge := func(c *closure) taggedEdge {
+		key := fmt.Sprint(c.match != nil, c.threads)
+		id, ok := ids[key]
+		if !ok {
+			id = len(states)
+			ids[key] = id
+			states = append(states, c.threads)
+			scan.Q[id] = struct{}{}
+			if c.match != nil {
+				scan.F[id] = struct{}{}
+			}
+		}
+		return taggedEdge{to: id, ops: c.ops, match: c.match}
+	}
+
+	unset := make([]int, slots)
+	for i := range unset {
+		unset[i] = tagUnset
+	}
+	start := &closure{seen: make(Set[int])}
+	follow(start, prog.Start, true, false, unset)
+	t.start = edge(start)
+	for id := 0; id < len(states); id++ {
+		row := make(map[rune]taggedEdge)
+		for _, a := range alphabet {
+			c := &closure{seen: make(Set[int])}
+			for i, pc := range states[id] {
+				inst := &prog.Inst[pc]
+				if !inst.MatchRune(a) {
+					continue
+				}
+				tags := make([]int, slots)
+				for s := range tags {
+					tags[s] = i*slots + s
+				}
+				if follow(c, int(inst.Out), false, false, tags) {
+					break
+				}
+			}
+			if c.match != nil || len(c.threads) > 0 {
+				row[a] = edge(c)
+			}
+		}
+		t.delta = append(t.delta, row)
+		scan.Delta[id] = make(map[rune]int, len(row))
+		for a, e := range row {
+			scan.Delta[id][a] = e.to
+		}
+	}
+	t.scan = scan.Minimize()
+	return t, nil
+}
+
+// NumSubexp returns the number of capture groups in the pattern.
+func (t *TaggedDFA) NumSubexp() int { return t.slots/2 - 1 }
+
+// SubexpNames returns the names of the capture groups, as
+// regexp.Regexp.SubexpNames does: the first entry, for the whole match, is
+// always empty, and so are the names of unnamed groups.
+func (t *TaggedDFA) SubexpNames() []string { return append([]string(nil), t.names...) }
+
+// DFA returns the minimal machine accepting the same matches, without
+// the registers. It is the machine of CompileRegexFirst.
+func (t *TaggedDFA) DFA() *DFA[int, rune] { return t.scan }
+
+// submatch runs the registers over the match input[start:end] and returns
+// its capture slots, as offsets into input; -1 marks a group that did not
+// take part.
+func (t *TaggedDFA) submatch(input []rune, start, end int) []int {
+	apply := func(dst, ops, regs []int, pos int) []int {
+		dst = dst[:0]
+		for _, src := range ops {
+			switch src {
+			case tagPos:
+				dst = append(dst, pos)
+			case tagUnset:
+				dst = append(dst, -1)
+			default:
+				dst = append(dst, regs[src])
+			}
+		}
+		return dst
+	}
+	caps := make([]int, 0, t.slots)
+	if t.start.match != nil {
+		caps = apply(caps, t.start.match, nil, start)
+	}
+	regs := apply(nil, t.start.ops, nil, start)
+	var next []int
+	q := t.start.to
+	for i := start; i < end; i++ {
+		e, ok := t.delta[q][input[i]]
+		if !ok {
+			break
+		}
+		if e.match != nil {
+			caps = apply(caps, e.match, regs, i+1)
+		}
+		next = apply(next, e.ops, regs, i+1)
+		regs, next = next, regs
+		q = e.to
+	}
+	// The program has no instructions for group 0, the whole match.
+	if len(caps) >= 2 {
+		caps[0], caps[1] = start, end
+	}
+	return caps
+}
+
+// FindAllSubmatch returns the leftmost-first, non-overlapping matches in
+// input as regexp.Regexp.FindAllSubmatchIndex does, with offsets into
+// input: pair i of each entry locates group i, and group 0 is the whole
+// match. n < 0 returns all of them. It takes time linear in the input.
+func (t *TaggedDFA) FindAllSubmatch(input []rune, n int) [][]int {
+	var out [][]int
+	for _, m := range t.scan.FindAll(input, ScanOptions{}) {
+		if len(out) == n {
+			break
+		}
+		out = append(out, t.submatch(input, m.Start, m.End))
+	}
+	return out
+}
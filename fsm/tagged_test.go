@@ -0,0 +1,54 @@
+package fsm
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// TestCompileRegexTagged compares capture groups with the regexp package
+// on every word.
+func TestCompileRegexTagged(t *testing.T) {
+	for _, pattern := range []string{
+		`(a+)(b*)`,
+		`(a|ab)(c|bcd)(d*)`,
+		`(a*)*b`,
+		`(?:(a)|(b))+`,
+		`(a+?)(a*)`,
+		`((a)|b)+c?`,
+		`(?P<first>a)(?P<rest>[bc]+)?`,
+		`x(a|(b))*`,
+	} {
+		tagged, err := CompileRegexTagged(pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		std := regexp.MustCompile(pattern)
+		if tagged.NumSubexp() != std.NumSubexp() || !reflect.DeepEqual(tagged.SubexpNames(), std.SubexpNames()) {
+			t.Fatalf("%s: groups %d %q, regexp has %d %q", pattern, tagged.NumSubexp(), tagged.SubexpNames(), std.NumSubexp(), std.SubexpNames())
+		}
+		// Without cutting, the groups are those of regexp's Longest.
+		longest := Must(compileTagged(pattern, false))
+		stdLongest := regexp.MustCompile(pattern)
+		stdLongest.Longest()
+		for _, w := range allWords([]rune("abcdx"), 5) {
+			for _, c := range []struct {
+				tagged *TaggedDFA
+				std    *regexp.Regexp
+			}{{tagged, std}, {longest, stdLongest}} {
+				var want [][]int
+				for _, m := range c.std.FindAllStringSubmatchIndex(string(w), -1) {
+					if m[0] < m[1] {
+						want = append(want, m)
+					}
+				}
+				if got := c.tagged.FindAllSubmatch(w, -1); !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s on %q: %v, regexp gives %v", c.std, string(w), got, want)
+				}
+			}
+		}
+	}
+	if got := Must(CompileRegexTagged(`(a)`)).FindAllSubmatch([]rune("aaa"), 2); len(got) != 2 {
+		t.Errorf("n=2: %v", got)
+	}
+}
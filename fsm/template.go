@@ -0,0 +1,212 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// ---------- Machine templates ----------
+
+// ParamKind is the type of a template parameter.
+type ParamKind int
+
+const (
+	ParamString ParamKind = iota // a string, e.g. a symbol name
+	ParamInt                     // an int, e.g. a threshold
+	ParamList                    // a []string, e.g. a subset of symbols
+)
+
+func (k ParamKind) String() string {
+	switch k {
+	case ParamString:
+		return "string"
+	case ParamInt:
+		return "int"
+	case ParamList:
+		return "list"
+	}
+	return fmt.Sprintf("ParamKind(%d)", int(k))
+}
+
+// TemplateParam declares a parameter of a MachineTemplate and how its
+// values are validated.
+type TemplateParam struct {
+	Name string
+	Kind ParamKind
+	// Min and Max bound an int, or the length of a list; Max 0 means
+	// unbounded.
+	Min, Max int
+	// OneOf, if set, lists the allowed values of a string, or the allowed
+	// elements of a list.
+	OneOf []string
+	// Default is used when no value is given; nil makes the parameter
+	// required.
+	Default interface{}
+}
+
+// MachineTemplate is a Definition in JSON with text/template actions, so
+// one vetted definition can be instantiated into many variants: thresholds
+// become counted states with {{range}}, symbol subsets become lists. Besides
+// the standard actions, templates can call
+//
+//	seq n    the ints 0..n-1
+//	add a b  a+b
+//	sub a b  a-b
+//	json v   v encoded as JSON, e.g. a quoted string or a list
+type MachineTemplate struct {
+	name   string
+	tmpl   *template.Template
+	params map[string]TemplateParam
+}
+
+var templateFuncs = template.FuncMap{
+	"seq": func(n int) []int {
+		out := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			out = append(out, i)
+		}
+		return out
+	},
+	"add": func(a, b int) int { return a + b },
+	"sub": func(a, b int) int { return a - b },
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewMachineTemplate parses a template and its parameter declarations.
+func NewMachineTemplate(name, text string, params ...TemplateParam) (*MachineTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidInput, name, err)
+	}
+	t := &MachineTemplate{name: name, tmpl: tmpl, params: make(map[string]TemplateParam, len(params))}
+	for _, p := range params {
+		if _, dup := t.params[p.Name]; dup {
+			return nil, fmt.Errorf("%w: template %s: duplicate parameter %q", ErrInvalidInput, name, p.Name)
+		}
+		if p.Default != nil {
+			if _, err := p.check(p.Default); err != nil {
+				return nil, fmt.Errorf("%w: template %s: default: %v", ErrInvalidInput, name, err)
+			}
+		}
+		t.params[p.Name] = p
+	}
+	return t, nil
+}
+
+// Instantiate validates values against the parameter declarations, renders
+// the template and builds the machine. Unknown and missing parameters are
+// errors, as is a rendered definition that NewDFA rejects.
+func (t *MachineTemplate) Instantiate(values map[string]interface{}) (*DFA[string, string], error) {
+	def, err := t.Render(values)
+	if err != nil {
+		return nil, err
+	}
+	d, err := def.DFA()
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", t.name, err)
+	}
+	return d, nil
+}
+
+// Render is Instantiate without building the machine, for inspecting or
+// storing the concrete definition.
+func (t *MachineTemplate) Render(values map[string]interface{}) (*Definition, error) {
+	data := make(map[string]interface{}, len(t.params))
+	for name := range values {
+		if _, ok := t.params[name]; !ok {
+			return nil, fmt.Errorf("%w: template %s: unknown parameter %q", ErrInvalidInput, t.name, name)
+		}
+	}
+	names := make([]string, 0, len(t.params))
+	for name := range t.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := t.params[name]
+		v, ok := values[name]
+		if !ok {
+			if p.Default == nil {
+				return nil, fmt.Errorf("%w: template %s: missing parameter %q", ErrInvalidInput, t.name, name)
+			}
+			v = p.Default
+		}
+		v, err := p.check(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidInput, t.name, err)
+		}
+		data[name] = v
+	}
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidInput, t.name, err)
+	}
+	def, err := ParseDefinition(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", t.name, err)
+	}
+	return def, nil
+}
+
+// check validates v and returns it in its canonical Go type.
+func (p TemplateParam) check(v interface{}) (interface{}, error) {
+	switch p.Kind {
+	case ParamString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q: %v is not a string", p.Name, v)
+		}
+		if err := p.allowed(s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case ParamInt:
+		n, ok := v.(int)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q: %v is not an int", p.Name, v)
+		}
+		if n < p.Min || (p.Max > 0 && n > p.Max) {
+			return nil, fmt.Errorf("parameter %q: %d outside [%d,%d]", p.Name, n, p.Min, p.Max)
+		}
+		return n, nil
+	case ParamList:
+		list, ok := v.([]string)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q: %v is not a []string", p.Name, v)
+		}
+		if len(list) < p.Min || (p.Max > 0 && len(list) > p.Max) {
+			return nil, fmt.Errorf("parameter %q: %d elements outside [%d,%d]", p.Name, len(list), p.Min, p.Max)
+		}
+		seen := make(map[string]bool, len(list))
+		for _, s := range list {
+			if seen[s] {
+				return nil, fmt.Errorf("parameter %q: duplicate element %q", p.Name, s)
+			}
+			seen[s] = true
+			if err := p.allowed(s); err != nil {
+				return nil, err
+			}
+		}
+		return append([]string(nil), list...), nil
+	}
+	return nil, fmt.Errorf("parameter %q: unknown kind %v", p.Name, p.Kind)
+}
+
+func (p TemplateParam) allowed(s string) error {
+	if len(p.OneOf) == 0 {
+		return nil
+	}
+	for _, ok := range p.OneOf {
+		if s == ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("parameter %q: %q not one of %v", p.Name, s, p.OneOf)
+}
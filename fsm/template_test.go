@@ -0,0 +1,79 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// lockoutTemplate locks an account after a per-tenant number of
+// consecutive failures; ignored symbols loop in place.
+const lockoutTemplate = `{
+  "states": [{{range $i := seq .attempts}}"fail{{$i}}", {{end}}"locked"],
+  "alphabet": [{{range .ignored}}{{json .}}, {{end}}"good", "bad"],
+  "start": "fail0",
+  "final": ["locked"],
+  "complete": true,
+  "delta": {
+    {{- range $i := seq .attempts}}
+    "fail{{$i}}": { {{range $.ignored}}{{json .}}: "fail{{$i}}", {{end -}}
+      "good": "fail0",
+      "bad": "{{if eq (add $i 1) $.attempts}}locked{{else}}fail{{add $i 1}}{{end}}"},
+    {{- end}}
+    "locked": { {{range .ignored}}{{json .}}: "locked", {{end}}"good": "locked", "bad": "locked"}
+  }
+}`
+
+func lockout(t *testing.T) *MachineTemplate {
+	t.Helper()
+	tmpl, err := NewMachineTemplate("lockout", lockoutTemplate,
+		TemplateParam{Name: "attempts", Kind: ParamInt, Min: 1, Max: 10, Default: 3},
+		TemplateParam{Name: "ignored", Kind: ParamList, OneOf: []string{"ping", "noop"}, Default: []string{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+// TestMachineTemplate instantiates two tenant variants.
+func TestMachineTemplate(t *testing.T) {
+	tmpl := lockout(t)
+
+	d, err := tmpl.Instantiate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Q) != 4 || len(d.Sigma) != 2 {
+		t.Fatalf("default variant: %d states, %d symbols", len(d.Q), len(d.Sigma))
+	}
+	if ok, _, _ := d.Accepts([]string{"bad", "bad", "bad"}); !ok {
+		t.Fatal("three failures should lock")
+	}
+
+	d, err = tmpl.Instantiate(map[string]interface{}{"attempts": 5, "ignored": []string{"ping"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, q, _ := d.Accepts([]string{"bad", "ping", "bad", "good", "bad", "bad", "bad", "ping", "bad"}); ok || q != "fail4" {
+		t.Fatalf("got %v in %q, want fail4", ok, q)
+	}
+}
+
+// TestMachineTemplate_Validation rejects bad parameter values.
+func TestMachineTemplate_Validation(t *testing.T) {
+	tmpl := lockout(t)
+	for _, values := range []map[string]interface{}{
+		{"attempts": 0},
+		{"attempts": "3"},
+		{"ignored": []string{"ping", "ping"}},
+		{"ignored": []string{"bad"}},
+		{"tenant": "acme"},
+	} {
+		if _, err := tmpl.Instantiate(values); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%v: expected ErrInvalidInput, got %v", values, err)
+		}
+	}
+	if _, err := NewMachineTemplate("x", "{{", TemplateParam{Name: "a"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ---------- NFA tie-breaking ----------
+
+// TieBreak orders the states of an NFA for the APIs that must return a
+// single run when nondeterminism allows several: less(a, b) reports
+// whether a is preferred over b. It must be a strict weak order; states it
+// does not order fall back to the order of ByStateOrder.
+type TieBreak[Q comparable] func(a, b Q) bool
+
+// ByStateOrder prefers states in the deterministic order used throughout
+// the package, by their Go-syntax representation ("S0" before "S1", but
+// 10 before 9). It is also what a nil TieBreak means.
+func ByStateOrder[Q comparable]() TieBreak[Q] {
+	return func(a, b Q) bool { return fmt.Sprintf("%#v", a) < fmt.Sprintf("%#v", b) }
+}
+
+// ByDeclaration prefers states in the order given, as lexer generators
+// prefer the rule declared first. States not listed come after those
+// listed.
+func ByDeclaration[Q comparable](order ...Q) TieBreak[Q] {
+	rank := make(map[Q]int, len(order))
+	for i, q := range order {
+		if _, ok := rank[q]; !ok {
+			rank[q] = i
+		}
+	}
+	return func(a, b Q) bool {
+		ra, okA := rank[a]
+		rb, okB := rank[b]
+		return okA && (!okB || ra < rb)
+	}
+}
+
+// ranks numbers the states of n from most to least preferred under tb.
+func (n *NFA[Q, Sigma]) ranks(tb TieBreak[Q]) map[Q]int {
+	states := n.Q.sorted()
+	if tb != nil {
+		sort.SliceStable(states, func(i, j int) bool { return tb(states[i], states[j]) })
+	}
+	rank := make(map[Q]int, len(states))
+	for i, q := range states {
+		rank[q] = i
+	}
+	return rank
+}
+
+// best returns the most preferred state of s.
+func best[Q comparable](s Set[Q], rank map[Q]int) (Q, bool) {
+	var out Q
+	found := false
+	for q := range s {
+		if !found || rank[q] < rank[out] {
+			out, found = q, true
+		}
+	}
+	return out, found
+}
+
+// AcceptingRun returns one accepting run of n on input, or false if there
+// is none. run[i] is the state after input[:i], ε-moves included, so
+// run[0] is in the ε-closure of q0 and run[len(input)] is in F. Of all
+// accepting runs it returns the one that prefers, under tb, the earliest
+// state where runs differ, so the answer does not depend on map order.
+func (n *NFA[Q, Sigma]) AcceptingRun(input []Sigma, tb TieBreak[Q]) ([]Q, bool) {
+	reach := make([]Set[Q], len(input)+1)
+	reach[0] = n.EpsilonClosure(NewSet(n.Q0))
+	for i, a := range input {
+		reach[i+1] = n.Step(reach[i], a)
+	}
+	// live[i] holds the states of reach[i] from which the rest of the
+	// input can still be accepted.
+	live := make([]Set[Q], len(input)+1)
+	live[len(input)] = make(Set[Q])
+	for q := range reach[len(input)] {
+		if n.F.Has(q) {
+			live[len(input)][q] = struct{}{}
+		}
+	}
+	for i := len(input) - 1; i >= 0; i-- {
+		live[i] = make(Set[Q])
+		for q := range reach[i] {
+			for p := range n.EpsilonClosure(n.Delta[q][input[i]]) {
+				if live[i+1].Has(p) {
+					live[i][q] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+
+	rank := n.ranks(tb)
+	q, ok := best(live[0], rank)
+	if !ok {
+		return nil, false
+	}
+	run := append(make([]Q, 0, len(input)+1), q)
+	for i, a := range input {
+		next := make(Set[Q])
+		for p := range n.EpsilonClosure(n.Delta[q][a]) {
+			if live[i+1].Has(p) {
+				next[p] = struct{}{}
+			}
+		}
+		q, _ = best(next, rank)
+		run = append(run, q)
+	}
+	return run, true
+}
+
+// LongestPrefix returns the length of the longest prefix of input that n
+// accepts and the final state it ends in, the most preferred under tb when
+// several are reachable. This is the rule lexers use: the longest lexeme
+// wins, and among rules matching it the declared priority decides.
+func (n *NFA[Q, Sigma]) LongestPrefix(input []Sigma, tb TieBreak[Q]) (int, Q, bool) {
+	end, found := 0, false
+	var finals Set[Q]
+	cur := n.EpsilonClosure(NewSet(n.Q0))
+	for i := 0; ; i++ {
+		var f Set[Q]
+		for q := range cur {
+			if n.F.Has(q) {
+				if f == nil {
+					f = make(Set[Q])
+				}
+				f[q] = struct{}{}
+			}
+		}
+		if f != nil {
+			end, finals, found = i, f, true
+		}
+		if i == len(input) || len(cur) == 0 {
+			break
+		}
+		cur = n.Step(cur, input[i])
+	}
+	if !found {
+		var zero Q
+		return 0, zero, false
+	}
+	q, _ := best(finals, n.ranks(tb))
+	return end, q, true
+}
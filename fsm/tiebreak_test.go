@@ -0,0 +1,88 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lexerNFA recognizes the keyword "if" (ending in "IF") and identifiers of
+// letters (ending in "ID"), which tie on "if".
+func lexerNFA() *NFA[string, rune] {
+	letters := []rune("fix")
+	delta := NFATransitionFn[string, rune]{
+		"start": {'i': NewSet("i", "ID")},
+		"i":     {'f': NewSet("IF")},
+		"ID":    {},
+	}
+	for _, r := range letters {
+		delta["ID"][r] = NewSet("ID")
+		if r != 'i' {
+			delta["start"][r] = NewSet("ID")
+		}
+	}
+	return Must(NewNFA([]string{"start", "i", "IF", "ID"}, letters, "start", []string{"IF", "ID"}, delta))
+}
+
+// TestLongestPrefix resolves the keyword/identifier tie by declaration.
+func TestLongestPrefix(t *testing.T) {
+	n := lexerNFA()
+	for _, c := range []struct {
+		in   string
+		tb   TieBreak[string]
+		end  int
+		want string
+	}{
+		{"if", ByDeclaration("IF", "ID"), 2, "IF"},
+		{"if", ByDeclaration("ID", "IF"), 2, "ID"},
+		{"if", nil, 2, "ID"},
+		{"ifx", ByDeclaration("IF", "ID"), 3, "ID"},
+		{"i", ByDeclaration("IF"), 1, "ID"},
+	} {
+		end, q, ok := n.LongestPrefix([]rune(c.in), c.tb)
+		if !ok || end != c.end || q != c.want {
+			t.Errorf("%q: got %d,%v,%v want %d,%v", c.in, end, q, ok, c.end, c.want)
+		}
+	}
+	if _, _, ok := n.LongestPrefix([]rune("x"), nil); !ok {
+		t.Error("expected a match")
+	}
+	if end, _, ok := n.LongestPrefix(nil, nil); ok {
+		t.Errorf("empty input matched up to %d", end)
+	}
+}
+
+// TestAcceptingRun picks between two accepting runs by policy, and the
+// same run every time.
+func TestAcceptingRun(t *testing.T) {
+	// Two paths accept "ab": 0 -a-> 1 -b-> 3 and 0 -a-> 2 -b-> 3.
+	n := Must(NewNFA([]int{0, 1, 2, 3}, []rune("ab"), 0, []int{3}, NFATransitionFn[int, rune]{
+		0: {'a': NewSet(1, 2)},
+		1: {'b': NewSet(3)},
+		2: {'b': NewSet(3)},
+	}))
+	for i := 0; i < 20; i++ {
+		run, ok := n.AcceptingRun([]rune("ab"), nil)
+		if !ok || !reflect.DeepEqual(run, []int{0, 1, 3}) {
+			t.Fatalf("state order: got %v, %v", run, ok)
+		}
+		run, ok = n.AcceptingRun([]rune("ab"), ByDeclaration(2))
+		if !ok || !reflect.DeepEqual(run, []int{0, 2, 3}) {
+			t.Fatalf("declaration: got %v, %v", run, ok)
+		}
+	}
+	if _, ok := n.AcceptingRun([]rune("a"), nil); ok {
+		t.Error("rejected input has a run")
+	}
+
+	// ε-moves: the run through the preferred branch of a union.
+	u := UnionNFA(literalDFA("ab").NFA(), literalDFA("ab").NFA())
+	run, ok := u.AcceptingRun([]rune("ab"), ByStateOrder[Tagged[int]]())
+	if !ok || len(run) != 3 || !u.F.Has(run[2]) {
+		t.Fatalf("union: got %v, %v", run, ok)
+	}
+	for i, a := range []rune("ab") {
+		if !u.EpsilonClosure(u.Delta[run[i]][a]).Has(run[i+1]) {
+			t.Fatalf("union: %v is not a run", run)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Package tm simulates deterministic single-tape Turing machines, for
+// teaching alongside the finite automata of package fsm: a machine is
+// built and validated like a DFA, runs under a step limit, and can print
+// every configuration it passes through.
+package tm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"fsm/fsm"
+)
+
+// ErrStepLimit reports that a machine had not halted after the step limit.
+var ErrStepLimit = errors.New("tm: step limit exceeded")
+
+// Move is the head movement of a rule.
+type Move int
+
+const (
+	Left  Move = -1
+	Stay  Move = 0
+	Right Move = 1
+)
+
+func (m Move) String() string {
+	switch m {
+	case Left:
+		return "L"
+	case Stay:
+		return "S"
+	case Right:
+		return "R"
+	}
+	return fmt.Sprintf("Move(%d)", int(m))
+}
+
+// Rule is one transition: in state From reading Read, write Write, move
+// the head and go to To.
+type Rule[Q comparable, Sym comparable] struct {
+	From  Q
+	Read  Sym
+	Write Sym
+	Move  Move
+	To    Q
+}
+
+// Machine is a deterministic Turing machine with states Q and tape
+// alphabet Gamma, which includes Blank. It halts on entering a state of
+// Accept or Reject, and rejects when no rule applies.
+type Machine[Q comparable, Sym comparable] struct {
+	Q      fsm.Set[Q]
+	Gamma  fsm.Set[Sym]
+	Blank  Sym
+	Q0     Q
+	Accept fsm.Set[Q]
+	Reject fsm.Set[Q]
+
+	delta map[Q]map[Sym]Rule[Q, Sym]
+}
+
+// New builds and validates a machine. Every rule must mention only states
+// in Q and symbols in gamma, move L, S or R, leave no halting state, and
+// no two rules may share From and Read. Blank is added to gamma.
+func New[Q comparable, Sym comparable](
+	states []Q,
+	gamma []Sym,
+	blank Sym,
+	q0 Q,
+	accept, reject []Q,
+	rules []Rule[Q, Sym],
+) (*Machine[Q, Sym], error) {
+	m := &Machine[Q, Sym]{
+		Q:      fsm.NewSet(states...),
+		Gamma:  fsm.NewSet(append([]Sym{blank}, gamma...)...),
+		Blank:  blank,
+		Q0:     q0,
+		Accept: fsm.NewSet(accept...),
+		Reject: fsm.NewSet(reject...),
+		delta:  make(map[Q]map[Sym]Rule[Q, Sym]),
+	}
+	if !m.Q.Has(q0) {
+		return nil, fmt.Errorf("%w: q0 %v not in Q", fsm.ErrInvalidInput, q0)
+	}
+	for _, halt := range []fsm.Set[Q]{m.Accept, m.Reject} {
+		for q := range halt {
+			if !m.Q.Has(q) {
+				return nil, fmt.Errorf("%w: halting state %v not in Q", fsm.ErrInvalidInput, q)
+			}
+			if m.Accept.Has(q) && m.Reject.Has(q) {
+				return nil, fmt.Errorf("%w: %v both accepts and rejects", fsm.ErrInvalidInput, q)
+			}
+		}
+	}
+	for _, r := range rules {
+		switch {
+		case !m.Q.Has(r.From) || !m.Q.Has(r.To):
+			return nil, fmt.Errorf("%w: rule %v uses a state not in Q", fsm.ErrInvalidInput, r)
+		case !m.Gamma.Has(r.Read) || !m.Gamma.Has(r.Write):
+			return nil, fmt.Errorf("%w: rule %v uses a symbol not in Γ", fsm.ErrInvalidInput, r)
+		case r.Move < Left || r.Move > Right:
+			return nil, fmt.Errorf("%w: rule %v has bad move %v", fsm.ErrInvalidInput, r, r.Move)
+		case m.halts(r.From):
+			return nil, fmt.Errorf("%w: rule %v leaves a halting state", fsm.ErrInvalidInput, r)
+		}
+		row := m.delta[r.From]
+		if row == nil {
+			row = make(map[Sym]Rule[Q, Sym])
+			m.delta[r.From] = row
+		}
+		if prev, ok := row[r.Read]; ok {
+			return nil, fmt.Errorf("%w: delta(%v,%v) has two rules: %v and %v", fsm.ErrInvalidInput, r.From, r.Read, prev, r)
+		}
+		row[r.Read] = r
+	}
+	return m, nil
+}
+
+func (m *Machine[Q, Sym]) halts(q Q) bool { return m.Accept.Has(q) || m.Reject.Has(q) }
+
+// Tape is an unbounded tape of cells, blank where never written.
+type Tape[Sym comparable] struct {
+	Blank  Sym
+	cells  []Sym
+	origin int // index in cells of position 0
+}
+
+// NewTape returns a tape holding input from position 0.
+func NewTape[Sym comparable](blank Sym, input []Sym) *Tape[Sym] {
+	return &Tape[Sym]{Blank: blank, cells: append([]Sym(nil), input...)}
+}
+
+// Read returns the symbol at pos.
+func (t *Tape[Sym]) Read(pos int) Sym {
+	if i := pos + t.origin; i >= 0 && i < len(t.cells) {
+		return t.cells[i]
+	}
+	return t.Blank
+}
+
+// Write sets the symbol at pos, growing the tape as needed.
+func (t *Tape[Sym]) Write(pos int, s Sym) {
+	for pos+t.origin < 0 {
+		t.cells = append([]Sym{t.Blank}, t.cells...)
+		t.origin++
+	}
+	for pos+t.origin >= len(t.cells) {
+		t.cells = append(t.cells, t.Blank)
+	}
+	t.cells[pos+t.origin] = s
+}
+
+// Bounds returns the positions of the first and last non-blank cells;
+// lo > hi when the tape is blank.
+func (t *Tape[Sym]) Bounds() (lo, hi int) {
+	lo, hi = 0, -1
+	first := true
+	for i, s := range t.cells {
+		if s == t.Blank {
+			continue
+		}
+		if first {
+			lo, first = i-t.origin, false
+		}
+		hi = i - t.origin
+	}
+	return lo, hi
+}
+
+// Contents returns the cells from the first to the last non-blank one.
+func (t *Tape[Sym]) Contents() []Sym {
+	lo, hi := t.Bounds()
+	out := make([]Sym, 0, hi-lo+1)
+	for p := lo; p <= hi; p++ {
+		out = append(out, t.Read(p))
+	}
+	return out
+}
+
+// Config is a configuration of a run: after Step steps the machine is in
+// State with its head at Head.
+type Config[Q comparable, Sym comparable] struct {
+	Step  int
+	State Q
+	Head  int
+	Tape  *Tape[Sym]
+}
+
+// String writes the configuration as the step, the state and the
+// non-blank part of the tape, widened to the head, with the scanned cell
+// in brackets: "3 q1: 1 0 [1] 0". Rune and byte symbols are written as
+// characters.
+func (c Config[Q, Sym]) String() string {
+	lo, hi := c.Tape.Bounds()
+	if lo > c.Head {
+		lo = c.Head
+	}
+	if hi < c.Head {
+		hi = c.Head
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %v:", c.Step, c.State)
+	for p := lo; p <= hi; p++ {
+		if p == c.Head {
+			fmt.Fprintf(&b, " [%s]", symbol(c.Tape.Read(p)))
+		} else {
+			fmt.Fprintf(&b, " %s", symbol(c.Tape.Read(p)))
+		}
+	}
+	return b.String()
+}
+
+// symbol writes runes and bytes as characters, other symbols with %v.
+func symbol[Sym comparable](s Sym) string {
+	switch v := any(s).(type) {
+	case rune:
+		return string(v)
+	case byte:
+		return string(rune(v))
+	}
+	return fmt.Sprint(s)
+}
+
+// Result is the configuration a run ended in.
+type Result[Q comparable, Sym comparable] struct {
+	State    Q
+	Accepted bool
+	Steps    int
+	Head     int
+	Tape     []Sym // the non-blank part of the tape
+}
+
+// Run runs the machine on input, placed from position 0 with the head on
+// it, for at most limit steps. If it has not halted by then, Run returns
+// the configuration reached and ErrStepLimit.
+func (m *Machine[Q, Sym]) Run(input []Sym, limit int) (Result[Q, Sym], error) {
+	return m.run(input, limit, nil)
+}
+
+// RunTrace is Run that writes every configuration, the initial one
+// included, to w, one per line.
+func (m *Machine[Q, Sym]) RunTrace(input []Sym, limit int, w io.Writer) (Result[Q, Sym], error) {
+	return m.run(input, limit, func(c Config[Q, Sym]) error {
+		_, err := fmt.Fprintln(w, c)
+		return err
+	})
+}
+
+func (m *Machine[Q, Sym]) run(input []Sym, limit int, trace func(Config[Q, Sym]) error) (Result[Q, Sym], error) {
+	for _, s := range input {
+		if !m.Gamma.Has(s) {
+			return Result[Q, Sym]{State: m.Q0}, fmt.Errorf("%w: input symbol %v not in Γ", fsm.ErrInvalidInput, s)
+		}
+	}
+	c := Config[Q, Sym]{State: m.Q0, Tape: NewTape(m.Blank, input)}
+	result := func() Result[Q, Sym] {
+		return Result[Q, Sym]{
+			State:    c.State,
+			Accepted: m.Accept.Has(c.State),
+			Steps:    c.Step,
+			Head:     c.Head,
+			Tape:     c.Tape.Contents(),
+		}
+	}
+	for {
+		if trace != nil {
+			if err := trace(c); err != nil {
+				return result(), err
+			}
+		}
+		if m.halts(c.State) {
+			return result(), nil
+		}
+		r, ok := m.delta[c.State][c.Tape.Read(c.Head)]
+		if !ok {
+			return result(), nil
+		}
+		if c.Step >= limit {
+			return result(), ErrStepLimit
+		}
+		c.Tape.Write(c.Head, r.Write)
+		c.Head += int(r.Move)
+		c.State = r.To
+		c.Step++
+	}
+}
@@ -0,0 +1,92 @@
+package tm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fsm/fsm"
+)
+
+// increment adds one to a binary number.
+func increment(t *testing.T) *Machine[string, rune] {
+	t.Helper()
+	m, err := New([]string{"right", "carry", "done"}, []rune("01"), '_', "right", []string{"done"}, nil,
+		[]Rule[string, rune]{
+			{From: "right", Read: '0', Write: '0', Move: Right, To: "right"},
+			{From: "right", Read: '1', Write: '1', Move: Right, To: "right"},
+			{From: "right", Read: '_', Write: '_', Move: Left, To: "carry"},
+			{From: "carry", Read: '1', Write: '0', Move: Left, To: "carry"},
+			{From: "carry", Read: '0', Write: '1', Move: Stay, To: "done"},
+			{From: "carry", Read: '_', Write: '1', Move: Stay, To: "done"},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// TestIncrement runs the machine, including growth to the left.
+func TestIncrement(t *testing.T) {
+	m := increment(t)
+	for in, want := range map[string]string{
+		"1011": "1100",
+		"111":  "1000",
+		"0":    "1",
+		"":     "1",
+	} {
+		res, err := m.Run([]rune(in), 100)
+		if err != nil || !res.Accepted || string(res.Tape) != want {
+			t.Errorf("%q: got %+v, %v want %q", in, res, err, want)
+		}
+	}
+	if res, _ := m.Run([]rune("1011"), 100); res.Steps != 8 || res.Head != 1 {
+		t.Errorf("steps %d, head %d", res.Steps, res.Head)
+	}
+	if _, err := m.Run([]rune("12"), 100); !errors.Is(err, fsm.ErrInvalidInput) {
+		t.Errorf("bad input: got %v", err)
+	}
+	if res, err := m.Run([]rune("1011"), 3); !errors.Is(err, ErrStepLimit) || res.Steps != 3 || res.State != "right" {
+		t.Errorf("limit: got %+v, %v", res, err)
+	}
+}
+
+// TestRunTrace prints one configuration per line.
+func TestRunTrace(t *testing.T) {
+	var b strings.Builder
+	if _, err := increment(t).RunTrace([]rune("1"), 10, &b); err != nil {
+		t.Fatal(err)
+	}
+	want := `0 right: [1]
+1 right: 1 [_]
+2 carry: [1]
+3 carry: [_] 0
+4 done: [1] 0
+`
+	if b.String() != want {
+		t.Fatalf("trace:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// TestNew_Validation rejects malformed machines.
+func TestNew_Validation(t *testing.T) {
+	states := []int{0, 1}
+	bad := [][]Rule[int, rune]{
+		{{From: 0, Read: 'a', Write: 'a', Move: Right, To: 2}},
+		{{From: 0, Read: 'b', Write: 'a', Move: Right, To: 1}},
+		{{From: 0, Read: 'a', Write: 'a', Move: 2, To: 1}},
+		{{From: 1, Read: 'a', Write: 'a', Move: Right, To: 0}},
+		{
+			{From: 0, Read: 'a', Write: 'a', Move: Right, To: 0},
+			{From: 0, Read: 'a', Write: 'a', Move: Left, To: 1},
+		},
+	}
+	for i, rules := range bad {
+		if _, err := New(states, []rune("a"), '_', 0, []int{1}, nil, rules); !errors.Is(err, fsm.ErrInvalidInput) {
+			t.Errorf("case %d: got %v", i, err)
+		}
+	}
+	if _, err := New[int, rune](states, nil, '_', 0, []int{1}, []int{1}, nil); !errors.Is(err, fsm.ErrInvalidInput) {
+		t.Errorf("accept and reject: got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package fsm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------- DFA → regular expression ----------
+
+// ToRegex returns a regular expression in Go regexp syntax for the
+// language of d, computed by state elimination on the generalized NFA of
+// the minimal DFA, which keeps the expression short.
+// Rune and byte symbols are written as characters, other symbols as their
+// fmt.Sprint form in a non-capturing group; see ToRegexFunc. The
+// expression describes whole inputs: anchor it as ^(?:…)$ to match with
+// the regexp package. The empty language is written [^\x00-\x{10FFFF}].
+func (d *DFA[Q, Sigma]) ToRegex() string {
+	return d.ToRegexFunc(regexSymbol[Sigma])
+}
+
+// ToRegexFunc is ToRegex with each symbol written by symbol, whose result
+// is used as an atom. A result of exactly one rune is escaped with
+// regexp.QuoteMeta and may be merged into a character class.
+func (d *DFA[Q, Sigma]) ToRegexFunc(symbol func(Sigma) string) string {
+	return d.Minimize().eliminate(symbol).render(0)
+}
+
+// regexSymbol writes rune-like and byte-like symbols as characters.
+func regexSymbol[Sigma comparable](a Sigma) string {
+	v := reflect.ValueOf(a)
+	switch v.Kind() {
+	case reflect.Int32:
+		return string(rune(v.Int()))
+	case reflect.Uint8:
+		return string(rune(v.Uint()))
+	case reflect.String:
+		if s := v.String(); utf8.RuneCountInString(s) != 1 {
+			return "(?:" + regexp.QuoteMeta(s) + ")"
+		}
+		return v.String()
+	}
+	return "(?:" + regexp.QuoteMeta(fmt.Sprint(a)) + ")"
+}
+
+// eliminate runs the state elimination. Only useful states take part; a
+// fresh start and a fresh final state are numbered n and n+1.
+func (d *DFA[Q, Sigma]) eliminate(symbol func(Sigma) string) *rx {
+	useful := d.useful()
+	if !useful.Has(d.Q0) {
+		return nil
+	}
+	states := d.Q.sorted()
+	index := make(map[Q]int, len(states))
+	var order []Q
+	for _, q := range states {
+		if useful.Has(q) {
+			index[q] = len(order)
+			order = append(order, q)
+		}
+	}
+	n := len(order)
+	start, final := n, n+1
+	edges := make([]map[int]*rx, n+2) // edges[i][j] labels i → j
+	for i := range edges {
+		edges[i] = make(map[int]*rx)
+	}
+	add := func(i, j int, r *rx) { edges[i][j] = rxAlt(edges[i][j], r) }
+	add(start, index[d.Q0], rxEpsilon())
+	for _, a := range d.Sigma.sorted() {
+		atom := rxAtom(symbol(a))
+		for i, q := range order {
+			if qNext, ok := d.next(q, a); ok && useful.Has(qNext) {
+				add(i, index[qNext], atom)
+			}
+		}
+	}
+	for i, q := range order {
+		if d.F.Has(q) {
+			add(i, final, rxEpsilon())
+		}
+	}
+
+	// Eliminate the cheapest state first: fewest paths through it.
+	alive := make([]bool, n)
+	for i := range alive {
+		alive[i] = true
+	}
+	for left := n; left > 0; left-- {
+		best, cost := -1, 0
+		for k := 0; k < n; k++ {
+			if !alive[k] {
+				continue
+			}
+			in := 0
+			for i := range edges {
+				if i != k && (i >= n || alive[i]) && edges[i][k] != nil {
+					in++
+				}
+			}
+			out := len(edges[k])
+			if edges[k][k] != nil {
+				out--
+			}
+			if c := in * out; best < 0 || c < cost {
+				best, cost = k, c
+			}
+		}
+		k := best
+		loop := rxStar(edges[k][k])
+		for i := range edges {
+			if i == k || (i < n && !alive[i]) || edges[i][k] == nil {
+				continue
+			}
+			for j, out := range edges[k] {
+				if j != k {
+					add(i, j, rxCat(edges[i][k], loop, out))
+				}
+			}
+			delete(edges[i], k)
+		}
+		alive[k] = false
+		edges[k] = nil
+	}
+	return edges[start][final]
+}
+
+// rx is a regular expression tree. A nil *rx denotes the empty language.
+type rx struct {
+	op   rxOp
+	atom string // rxLit: an atom in regexp syntax
+	char rune   // rxLit: the character, when the atom is one
+	subs []*rx
+}
+
+type rxOp int
+
+const (
+	rxEps rxOp = iota
+	rxLit
+	rxAlts
+	rxCats
+	rxStars
+)
+
+func rxEpsilon() *rx { return &rx{op: rxEps} }
+
+func rxAtom(s string) *rx {
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return &rx{op: rxLit, atom: regexp.QuoteMeta(s), char: r}
+	}
+	return &rx{op: rxLit, atom: s, char: -1}
+}
+
+// rxAlt returns a|b, flattened and without duplicates.
+func rxAlt(a, b *rx) *rx {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	var subs []*rx
+	seen := make(map[string]bool)
+	for _, r := range []*rx{a, b} {
+		parts := []*rx{r}
+		if r.op == rxAlts {
+			parts = r.subs
+		}
+		for _, p := range parts {
+			if key := p.render(0); !seen[key] {
+				seen[key] = true
+				subs = append(subs, p)
+			}
+		}
+	}
+	if len(subs) == 1 {
+		return subs[0]
+	}
+	return &rx{op: rxAlts, subs: subs}
+}
+
+// rxCat returns the concatenation, dropping ε factors.
+func rxCat(rs ...*rx) *rx {
+	var subs []*rx
+	for _, r := range rs {
+		switch {
+		case r == nil:
+			return nil
+		case r.op == rxEps:
+		case r.op == rxCats:
+			subs = append(subs, r.subs...)
+		default:
+			subs = append(subs, r)
+		}
+	}
+	switch len(subs) {
+	case 0:
+		return rxEpsilon()
+	case 1:
+		return subs[0]
+	}
+	return &rx{op: rxCats, subs: subs}
+}
+
+// rxStar returns r*; the star of nothing is ε.
+func rxStar(r *rx) *rx {
+	if r == nil || r.op == rxEps {
+		return rxEpsilon()
+	}
+	if r.op == rxStars {
+		return r
+	}
+	if r.op == rxAlts {
+		// (ε|x)* = x*
+		var rest *rx
+		for _, s := range r.subs {
+			if s.op != rxEps {
+				rest = rxAlt(rest, s)
+			}
+		}
+		if rest == nil {
+			return rxEpsilon()
+		}
+		if rest.op == rxStars {
+			return rest
+		}
+		r = rest
+	}
+	return &rx{op: rxStars, subs: []*rx{r}}
+}
+
+// render writes r in regexp syntax. prec is the binding strength of the
+// context: 0 alternation, 1 concatenation, 2 repetition.
+func (r *rx) render(prec int) string {
+	if r == nil {
+		return `[^\x00-\x{10FFFF}]`
+	}
+	group := func(s string, need bool) string {
+		if need {
+			return "(?:" + s + ")"
+		}
+		return s
+	}
+	switch r.op {
+	case rxEps:
+		return group("", prec > 0)
+	case rxLit:
+		return r.atom
+	case rxStars:
+		return r.subs[0].render(2) + "*"
+	case rxCats:
+		parts := make([]string, len(r.subs))
+		for i, s := range r.subs {
+			parts[i] = s.render(1)
+		}
+		return group(strings.Join(parts, ""), prec > 1)
+	}
+
+	// Alternation: ε becomes "?", single characters a class.
+	var rest *rx
+	optional := false
+	for _, s := range r.subs {
+		if s.op == rxEps {
+			optional = true
+		} else {
+			rest = rxAlt(rest, s)
+		}
+	}
+	if optional {
+		if rest.op == rxStars {
+			return rest.render(prec) // ε|x* = x*
+		}
+		return rest.render(2) + "?"
+	}
+	var chars []rune
+	var parts []string
+	for _, s := range r.subs {
+		if s.op == rxLit && s.char >= 0 {
+			chars = append(chars, s.char)
+		} else {
+			parts = append(parts, s.render(0))
+		}
+	}
+	if len(chars) == 1 {
+		parts = append(parts, regexp.QuoteMeta(string(chars[0])))
+	} else if len(chars) > 1 {
+		sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+		var b strings.Builder
+		b.WriteByte('[')
+		for _, c := range chars {
+			if strings.ContainsRune(`\]-^[`, c) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(c)
+		}
+		b.WriteByte(']')
+		parts = append(parts, b.String())
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	sort.Strings(parts)
+	return group(strings.Join(parts, "|"), prec > 0)
+}
@@ -0,0 +1,54 @@
+package fsm
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+)
+
+// matchesRegex checks the expression against d on every word up to n.
+func matchesRegex[Q comparable, Sigma comparable](t *testing.T, d *DFA[Q, Sigma], alphabet []Sigma, n int, str func([]Sigma) string) string {
+	t.Helper()
+	expr := d.ToRegex()
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	for _, w := range allWords(alphabet, n) {
+		want, _, _ := d.Accepts(w)
+		if got := re.MatchString(str(w)); got != want {
+			t.Fatalf("%s on %q: %v, want %v", expr, str(w), got, want)
+		}
+	}
+	return expr
+}
+
+func TestToRegex(t *testing.T) {
+	bits := func(w []Bit) string { return string(w) }
+	runes := func(w []rune) string { return string(w) }
+
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	matchesRegex(t, div3, []Bit{Zero, One}, 10, bits)
+	if got := matchesRegex(t, buildModThree(), []Bit{Zero, One}, 6, bits); got != "[01]*" {
+		t.Errorf("all words: %s, want [01]*", got)
+	}
+	if got := matchesRegex(t, literalDFA("a.c"), []rune("a.c"), 4, runes); got != `a\.c` {
+		t.Errorf("literal: %s", got)
+	}
+	empty := Must(NewDFA([]int{0}, []rune{'a'}, 0, nil, TransitionFn[int, rune]{}, false))
+	matchesRegex(t, empty, []rune{'a'}, 3, runes)
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 40; i++ {
+		d := randomDFA(rng, 5, i%2 == 0)
+		matchesRegex(t, d, []Bit{Zero, One}, 7, bits)
+	}
+}
+
+func TestToRegexFunc(t *testing.T) {
+	d := Must(NewDFA([]int{0, 1}, []string{"GET", "PUT"}, 0, []int{1},
+		TransitionFn[int, string]{0: {"GET": 1, "PUT": 0}}, false))
+	if got := d.ToRegex(); got != "(?:PUT)*(?:GET)" {
+		t.Errorf("ToRegex = %s", got)
+	}
+}
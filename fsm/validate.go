@@ -0,0 +1,178 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------- Validation reports ----------
+
+// ProblemKind classifies a Problem.
+type ProblemKind int
+
+const (
+	StartNotInQ       ProblemKind = iota // q0 ∉ Q
+	FinalNotInQ                          // a state of F ∉ Q
+	UnknownState                         // a δ row or default for a state ∉ Q
+	UnknownSymbol                        // a δ entry for a symbol ∉ Σ
+	UnknownTarget                        // a δ entry or default leading to a state ∉ Q
+	MissingTransition                    // no δ(q,σ) for q ∈ Q, σ ∈ Σ
+)
+
+var problemKinds = [...]string{"start not in Q", "final not in Q", "unknown state", "unknown symbol", "unknown target", "missing transition"}
+
+func (k ProblemKind) String() string {
+	if k >= 0 && int(k) < len(problemKinds) {
+		return problemKinds[k]
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+// Problem is one defect of a machine definition. State is the state
+// concerned; Symbol and Target are set for the kinds about δ entries.
+type Problem[Q comparable, Sigma comparable] struct {
+	Kind   ProblemKind
+	State  Q
+	Symbol Sigma
+	Target Q
+}
+
+func (p Problem[Q, Sigma]) String() string {
+	switch p.Kind {
+	case UnknownSymbol:
+		return fmt.Sprintf("%v: delta row %v has symbol %v", p.Kind, p.State, p.Symbol)
+	case UnknownTarget:
+		return fmt.Sprintf("%v: %v → %v", p.Kind, p.State, p.Target)
+	case MissingTransition:
+		return fmt.Sprintf("%v: (%v,%v)", p.Kind, p.State, p.Symbol)
+	}
+	return fmt.Sprintf("%v: %v", p.Kind, p.State)
+}
+
+// ValidationReport lists every problem of a machine definition, in a
+// stable order: start, finals, then state by state.
+type ValidationReport[Q comparable, Sigma comparable] struct {
+	Problems []Problem[Q, Sigma]
+}
+
+// Count returns the number of problems of the given kind.
+func (r ValidationReport[Q, Sigma]) Count(kind ProblemKind) int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns nil if the definition is valid, or one error listing every
+// problem, with ErrInvalidInput. Missing transitions are problems only if
+// requireComplete is set.
+func (r ValidationReport[Q, Sigma]) Err(requireComplete bool) error {
+	var lines []string
+	for _, p := range r.Problems {
+		if p.Kind != MissingTransition || requireComplete {
+			lines = append(lines, p.String())
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d problems:\n\t%s", ErrInvalidInput, len(lines), strings.Join(lines, "\n\t"))
+}
+
+// Validate checks the machine as NewDFA does, but reports every problem
+// instead of the first, so a large hand-written table can be fixed in one
+// pass. It also works on a DFA literal NewDFA never saw. A state with a
+// default has no missing transitions.
+func (d *DFA[Q, Sigma]) Validate() ValidationReport[Q, Sigma] {
+	var r ValidationReport[Q, Sigma]
+	add := func(p Problem[Q, Sigma]) { r.Problems = append(r.Problems, p) }
+	if !d.Q.Has(d.Q0) {
+		add(Problem[Q, Sigma]{Kind: StartNotInQ, State: d.Q0})
+	}
+	for _, f := range d.F.sorted() {
+		if !d.Q.Has(f) {
+			add(Problem[Q, Sigma]{Kind: FinalNotInQ, State: f})
+		}
+	}
+	rows := make(Set[Q], len(d.Delta)+len(d.Q))
+	for q := range d.Delta {
+		rows[q] = struct{}{}
+	}
+	for q := range d.Default {
+		rows[q] = struct{}{}
+	}
+	for q := range d.Q {
+		rows[q] = struct{}{}
+	}
+	symbols := d.Sigma.sorted()
+	for _, q := range rows.sorted() {
+		if !d.Q.Has(q) {
+			add(Problem[Q, Sigma]{Kind: UnknownState, State: q})
+		}
+		row := d.Delta[q]
+		extra := make(Set[Sigma])
+		for a := range row {
+			if !d.Sigma.Has(a) {
+				extra[a] = struct{}{}
+			}
+		}
+		for _, a := range extra.sorted() {
+			add(Problem[Q, Sigma]{Kind: UnknownSymbol, State: q, Symbol: a})
+		}
+		for _, as := range [][]Sigma{symbols, extra.sorted()} {
+			for _, a := range as {
+				if qNext, ok := row[a]; ok && !d.Q.Has(qNext) {
+					add(Problem[Q, Sigma]{Kind: UnknownTarget, State: q, Symbol: a, Target: qNext})
+				}
+			}
+		}
+		qDefault, hasDefault := d.Default[q]
+		if hasDefault && !d.Q.Has(qDefault) {
+			add(Problem[Q, Sigma]{Kind: UnknownTarget, State: q, Target: qDefault})
+		}
+		if d.Q.Has(q) && !hasDefault {
+			for _, a := range symbols {
+				if _, ok := row[a]; !ok {
+					add(Problem[Q, Sigma]{Kind: MissingTransition, State: q, Symbol: a})
+				}
+			}
+		}
+	}
+	return r
+}
+
+// ValidateDFA validates the arguments of NewDFA without building the
+// machine; see Validate.
+func ValidateDFA[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta TransitionFn[Q, Sigma],
+) ValidationReport[Q, Sigma] {
+	return ValidateDFAWithDefaults(states, alphabet, q0, finals, delta, nil)
+}
+
+// ValidateDFAWithDefaults validates the arguments of NewDFAWithDefaults
+// without building the machine; see Validate.
+func ValidateDFAWithDefaults[Q comparable, Sigma comparable](
+	states []Q,
+	alphabet []Sigma,
+	q0 Q,
+	finals []Q,
+	delta TransitionFn[Q, Sigma],
+	defaults map[Q]Q,
+) ValidationReport[Q, Sigma] {
+	d := &DFA[Q, Sigma]{
+		Q:       NewSet(states...),
+		Sigma:   NewSet(alphabet...),
+		Q0:      q0,
+		F:       NewSet(finals...),
+		Delta:   delta,
+		Default: defaults,
+	}
+	return d.Validate()
+}
@@ -0,0 +1,63 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidate lists every problem of a broken table at once.
+func TestValidate(t *testing.T) {
+	r := ValidateDFA([]State{S0, S1}, []Bit{Zero, One}, S2, []State{S1, S2},
+		TransitionFn[State, Bit]{
+			S0: {Zero: S0, One: S2, 'x': S1},
+			S2: {Zero: S0},
+		})
+	want := []Problem[State, Bit]{
+		{Kind: StartNotInQ, State: S2},
+		{Kind: FinalNotInQ, State: S2},
+		{Kind: UnknownSymbol, State: S0, Symbol: 'x'},
+		{Kind: UnknownTarget, State: S0, Symbol: One, Target: S2},
+		{Kind: MissingTransition, State: S1, Symbol: Zero},
+		{Kind: MissingTransition, State: S1, Symbol: One},
+		{Kind: UnknownState, State: S2},
+	}
+	if len(r.Problems) != len(want) {
+		t.Fatalf("got %d problems: %v", len(r.Problems), r.Problems)
+	}
+	for i, p := range want {
+		if r.Problems[i] != p {
+			t.Errorf("problem %d = %v, want %v", i, r.Problems[i], p)
+		}
+	}
+	if n := r.Count(MissingTransition); n != 2 {
+		t.Errorf("Count(MissingTransition) = %d", n)
+	}
+	err := r.Err(false)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "5 problems") || strings.Contains(err.Error(), "missing") {
+		t.Errorf("Err(false) = %v", err)
+	}
+	if err := r.Err(true); !strings.Contains(err.Error(), "7 problems") {
+		t.Errorf("Err(true) = %v", err)
+	}
+}
+
+// TestValidate_Valid reports nothing for a good machine, and agrees with
+// NewDFA.
+func TestValidate_Valid(t *testing.T) {
+	d := buildModThree()
+	if r := d.Validate(); len(r.Problems) != 0 || r.Err(true) != nil {
+		t.Fatalf("mod-three: %v", r.Problems)
+	}
+	if r := ValidateDFAWithDefaults([]State{S0}, []Bit{Zero}, S0, nil, nil, map[State]State{S0: S2}); r.Count(UnknownTarget) != 1 || r.Count(MissingTransition) != 0 {
+		t.Fatalf("defaults: %v", r.Problems)
+	}
+	d.Default = map[State]State{S0: S1}
+	delete(d.Delta[S0], One)
+	if r := d.Validate(); len(r.Problems) != 0 {
+		t.Fatalf("default: %v", r.Problems)
+	}
+	if s := (Problem[State, Bit]{Kind: MissingTransition, State: S1, Symbol: Zero}).String(); s != "missing transition: (1,48)" {
+		t.Fatalf("String = %q", s)
+	}
+}
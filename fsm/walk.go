@@ -0,0 +1,97 @@
+package fsm
+
+import "math/rand"
+
+// ---------- Random walks ----------
+
+// WalkBias selects how RandomWalk picks the next edge.
+type WalkBias int
+
+const (
+	// UniformEdges picks uniformly among the defined transitions.
+	UniformEdges WalkBias = iota
+	// TowardAccepting picks uniformly among the transitions after which an
+	// accepting state can still be reached in exactly the remaining number
+	// of steps, so the walk ends accepted whenever that is possible. It
+	// falls back to UniformEdges where no such transition exists.
+	TowardAccepting
+)
+
+// WalkStep is one transition of a walk.
+type WalkStep[Q comparable, Sigma comparable] struct {
+	Symbol Sigma
+	State  Q // state entered
+}
+
+// Walk is a run of the machine from q0.
+type Walk[Q comparable, Sigma comparable] struct {
+	Steps    []WalkStep[Q, Sigma]
+	Accepted bool // the last state (q0 if there are no steps) is in F
+}
+
+// Input returns the symbols of the walk.
+func (w Walk[Q, Sigma]) Input() []Sigma {
+	out := make([]Sigma, len(w.Steps))
+	for i, s := range w.Steps {
+		out[i] = s.Symbol
+	}
+	return out
+}
+
+// RandomWalk takes up to steps random transitions from q0, drawing from
+// rng, so equal seeds give equal walks. The walk stops early in a state
+// with no outgoing transitions.
+func (d *DFA[Q, Sigma]) RandomWalk(rng *rand.Rand, steps int, bias WalkBias) Walk[Q, Sigma] {
+	symbols := d.Sigma.sorted()
+	var canAccept []Set[Q] // canAccept[k]: states that reach F in exactly k steps
+	if bias == TowardAccepting {
+		canAccept = d.acceptsIn(symbols, steps)
+	}
+
+	var w Walk[Q, Sigma]
+	q := d.Q0
+	var edges, good []WalkStep[Q, Sigma]
+	for i := 0; i < steps; i++ {
+		edges, good = edges[:0], good[:0]
+		for _, a := range symbols {
+			if qNext, ok := d.next(q, a); ok {
+				e := WalkStep[Q, Sigma]{Symbol: a, State: qNext}
+				edges = append(edges, e)
+				if canAccept != nil && canAccept[steps-i-1].Has(qNext) {
+					good = append(good, e)
+				}
+			}
+		}
+		if len(edges) == 0 {
+			break
+		}
+		pick := edges
+		if len(good) > 0 {
+			pick = good
+		}
+		e := pick[rng.Intn(len(pick))]
+		w.Steps = append(w.Steps, e)
+		q = e.State
+	}
+	w.Accepted = d.F.Has(q)
+	return w
+}
+
+// acceptsIn returns, for k = 0..n, the states from which some word of
+// length exactly k leads into F.
+func (d *DFA[Q, Sigma]) acceptsIn(symbols []Sigma, n int) []Set[Q] {
+	out := make([]Set[Q], n+1)
+	out[0] = copySet(d.F)
+	for k := 1; k <= n; k++ {
+		out[k] = make(Set[Q])
+		for q := range d.Q {
+			for _, a := range symbols {
+				if qNext, ok := d.next(q, a); ok && out[k-1].Has(qNext) {
+					out[k][q] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+	return out
+}
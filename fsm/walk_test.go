@@ -0,0 +1,41 @@
+package fsm
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestRandomWalk checks determinism, consistency with Run and the
+// accepting bias.
+func TestRandomWalk(t *testing.T) {
+	d := buildModThree().clone()
+	d.F = NewSet(S0)
+
+	a := d.RandomWalk(rand.New(rand.NewSource(7)), 20, UniformEdges)
+	b := d.RandomWalk(rand.New(rand.NewSource(7)), 20, UniformEdges)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("equal seeds gave different walks")
+	}
+	if len(a.Steps) != 20 {
+		t.Fatalf("walk has %d steps, want 20", len(a.Steps))
+	}
+	if q, _ := d.Run(a.Input()); q != a.Steps[19].State || a.Accepted != (q == S0) {
+		t.Fatalf("walk ends in %v, Run gives %v", a.Steps[19].State, q)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		if w := d.RandomWalk(rng, 1+i%9, TowardAccepting); !w.Accepted {
+			t.Fatalf("biased walk %v was rejected", w.Input())
+		}
+	}
+}
+
+// TestRandomWalk_StopsAtDeadEnd ends early when no transition is defined.
+func TestRandomWalk_StopsAtDeadEnd(t *testing.T) {
+	w := literalDFA("abc").RandomWalk(rand.New(rand.NewSource(1)), 10, UniformEdges)
+	if string(w.Input()) != "abc" || !w.Accepted {
+		t.Fatalf("got %q,%v want \"abc\",true", string(w.Input()), w.Accepted)
+	}
+}
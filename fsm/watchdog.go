@@ -0,0 +1,143 @@
+package fsm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ---------- Stalled-session watchdog ----------
+
+// Stalled reports a session that has stayed in a watched state for at
+// least the watchdog's threshold.
+type Stalled[Q comparable, Sigma comparable] struct {
+	Session  *Session[Q, Sigma]
+	State    Q
+	Since    time.Time     // when the session entered State
+	Age      time.Duration // how long it had been there when flagged
+	Injected bool          // the timeout symbol was fed to the session
+	Err      error         // the error of that Feed, if any
+}
+
+// WatchdogStats counts what a Watchdog has done since it was created.
+type WatchdogStats struct {
+	Scans        int // calls to Check
+	Stalled      int // sessions flagged, once per stay in a state
+	Injected     int // timeout symbols fed successfully
+	InjectErrors int // timeout symbols the session rejected
+}
+
+// Watchdog flags registry sessions that remain in a set of states longer
+// than a threshold, e.g. workflows waiting forever for an approval. Each
+// stay is flagged once: a session is flagged again only after it has left
+// the state and come back, or been rebound to another state by a reload.
+// It is safe for concurrent use.
+type Watchdog[Q comparable, Sigma comparable] struct {
+	// OnStall, if not nil, is called for each newly stalled session, after
+	// the timeout symbol was injected. Set it before Check or Run.
+	OnStall func(Stalled[Q, Sigma])
+
+	reg       *Registry[Q, Sigma]
+	threshold time.Duration
+	states    Set[Q]
+	timeout   Sigma
+	inject    bool
+
+	mu      sync.Mutex
+	flagged map[*Session[Q, Sigma]]time.Time // session → the Since it was flagged at
+	stats   WatchdogStats
+}
+
+// NewWatchdog watches the sessions of r in the given states; with no
+// states, every state is watched. Stale sessions are never flagged.
+func NewWatchdog[Q comparable, Sigma comparable](r *Registry[Q, Sigma], threshold time.Duration, states ...Q) *Watchdog[Q, Sigma] {
+	var watched Set[Q]
+	if len(states) > 0 {
+		watched = NewSet(states...)
+	}
+	return &Watchdog[Q, Sigma]{
+		reg:       r,
+		threshold: threshold,
+		states:    watched,
+		flagged:   make(map[*Session[Q, Sigma]]time.Time),
+	}
+}
+
+// InjectTimeout makes the watchdog feed a to every session it flags, so
+// the machine itself can model what a timeout does (retry, escalate,
+// cancel). A session without a transition on a is reported with Err set.
+func (w *Watchdog[Q, Sigma]) InjectTimeout(a Sigma) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeout, w.inject = a, true
+}
+
+// Check scans the open sessions once and returns those newly stalled.
+func (w *Watchdog[Q, Sigma]) Check() []Stalled[Q, Sigma] {
+	w.reg.mu.Lock()
+	now := w.reg.now()
+	sessions := make([]*Session[Q, Sigma], 0, len(w.reg.sessions))
+	for s := range w.reg.sessions {
+		sessions = append(sessions, s)
+	}
+	w.reg.mu.Unlock()
+
+	w.mu.Lock()
+	w.stats.Scans++
+	var out []Stalled[Q, Sigma]
+	flagged := make(map[*Session[Q, Sigma]]time.Time, len(w.flagged))
+	for _, s := range sessions {
+		s.mu.Lock()
+		q, since, stale := s.state, s.since, s.stale
+		s.mu.Unlock()
+		if stale || (w.states != nil && !w.states.Has(q)) || now.Sub(since) < w.threshold {
+			continue
+		}
+		if at, ok := w.flagged[s]; ok && at.Equal(since) {
+			flagged[s] = at
+			continue
+		}
+		flagged[s] = since
+		st := Stalled[Q, Sigma]{Session: s, State: q, Since: since, Age: now.Sub(since)}
+		if w.inject {
+			st.Injected = true
+			if _, st.Err = s.Feed(w.timeout); st.Err != nil {
+				w.stats.InjectErrors++
+			} else {
+				w.stats.Injected++
+			}
+		}
+		w.stats.Stalled++
+		out = append(out, st)
+	}
+	w.flagged = flagged
+	w.mu.Unlock()
+
+	for _, st := range out {
+		if w.OnStall != nil {
+			w.OnStall(st)
+		}
+	}
+	return out
+}
+
+// Run calls Check every interval until ctx is done.
+func (w *Watchdog[Q, Sigma]) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.Check()
+		}
+	}
+}
+
+// Stats returns the counters so far.
+func (w *Watchdog[Q, Sigma]) Stats() WatchdogStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
@@ -0,0 +1,60 @@
+package fsm
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWatchdog flags each stay once, injects the timeout and ignores
+// unwatched states.
+func TestWatchdog(t *testing.T) {
+	r, err := NewRegistry(func() (*DFA[State, Bit], error) { return buildModThree(), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Unix(0, 0)
+	r.now = func() time.Time { return clock }
+
+	waiting := r.Open()
+	waiting.Feed(One) // S1
+	idle := r.Open()  // S0, not watched
+
+	w := NewWatchdog(r, time.Minute, S1)
+	var seen []Stalled[State, Bit]
+	w.OnStall = func(s Stalled[State, Bit]) { seen = append(seen, s) }
+
+	clock = clock.Add(30 * time.Second)
+	if got := w.Check(); len(got) != 0 {
+		t.Fatalf("flagged before the threshold: %+v", got)
+	}
+	clock = clock.Add(time.Minute)
+	got := w.Check()
+	if len(got) != 1 || got[0].Session != waiting || got[0].State != S1 || got[0].Age != 90*time.Second {
+		t.Fatalf("Check = %+v", got)
+	}
+	if len(w.Check()) != 0 {
+		t.Fatal("same stay flagged twice")
+	}
+
+	// Leaving the state and coming back starts a new stay.
+	waiting.Feed(Zero) // S2
+	waiting.Feed(Zero) // S1
+	clock = clock.Add(2 * time.Minute)
+	w.InjectTimeout(One)
+	got = w.Check()
+	if len(got) != 1 || !got[0].Injected || got[0].Err != nil {
+		t.Fatalf("second stay: %+v", got)
+	}
+	if q, _, _ := waiting.State(); q != S0 {
+		t.Errorf("timeout led to %v, want S0", q)
+	}
+	if q, _, _ := idle.State(); q != S0 {
+		t.Errorf("idle session moved to %v", q)
+	}
+	if len(seen) != 2 {
+		t.Errorf("OnStall called %d times, want 2", len(seen))
+	}
+	if s := w.Stats(); s != (WatchdogStats{Scans: 4, Stalled: 2, Injected: 1}) {
+		t.Errorf("stats %+v", s)
+	}
+}
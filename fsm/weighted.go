@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"fmt"
+	"math"
+)
+
+// ---------- Weighted automata ----------
+
+// Semiring is the arithmetic of a weighted automaton: Times extends a path
+// by a transition and Plus combines alternative paths. Zero, the identity
+// of Plus, is the weight of no path; One, the identity of Times, is the
+// weight of the empty path.
+type Semiring[W any] interface {
+	Zero() W
+	One() W
+	Plus(a, b W) W
+	Times(a, b W) W
+}
+
+// CountingSemiring counts paths: (ℕ, +, ×, 0, 1). Counts wrap on overflow.
+type CountingSemiring struct{}
+
+func (CountingSemiring) Zero() uint64             { return 0 }
+func (CountingSemiring) One() uint64              { return 1 }
+func (CountingSemiring) Plus(a, b uint64) uint64  { return a + b }
+func (CountingSemiring) Times(a, b uint64) uint64 { return a * b }
+
+// ProbabilitySemiring multiplies probabilities along a path and adds them
+// across paths: (ℝ≥0, +, ×, 0, 1).
+type ProbabilitySemiring struct{}
+
+func (ProbabilitySemiring) Zero() float64              { return 0 }
+func (ProbabilitySemiring) One() float64               { return 1 }
+func (ProbabilitySemiring) Plus(a, b float64) float64  { return a + b }
+func (ProbabilitySemiring) Times(a, b float64) float64 { return a * b }
+
+// TropicalSemiring adds costs along a path and keeps the cheapest path:
+// (ℝ ∪ {+∞}, min, +, +∞, 0).
+type TropicalSemiring struct{}
+
+func (TropicalSemiring) Zero() float64              { return math.Inf(1) }
+func (TropicalSemiring) One() float64               { return 0 }
+func (TropicalSemiring) Plus(a, b float64) float64  { return math.Min(a, b) }
+func (TropicalSemiring) Times(a, b float64) float64 { return a + b }
+
+// BooleanSemiring is plain acceptance: (𝔹, ∨, ∧, false, true).
+type BooleanSemiring struct{}
+
+func (BooleanSemiring) Zero() bool           { return false }
+func (BooleanSemiring) One() bool            { return true }
+func (BooleanSemiring) Plus(a, b bool) bool  { return a || b }
+func (BooleanSemiring) Times(a, b bool) bool { return a && b }
+
+// WeightedDFA attaches weights from a semiring to the transitions and final
+// states of a DFA. The weight of an accepted input is the product of the
+// weights along its run and the final weight of the state it ends in;
+// every other input weighs Zero. Missing entries of Weight and Final
+// weigh One, so a fresh WeightedDFA over CountingSemiring weighs each
+// accepted input 1.
+type WeightedDFA[Q comparable, Sigma comparable, W any] struct {
+	*DFA[Q, Sigma]
+	Ring   Semiring[W]
+	Weight map[Q]map[Sigma]W
+	Final  map[Q]W
+}
+
+// NewWeightedDFA returns d with every weight One.
+func NewWeightedDFA[Q comparable, Sigma comparable, W any](d *DFA[Q, Sigma], ring Semiring[W]) *WeightedDFA[Q, Sigma, W] {
+	return &WeightedDFA[Q, Sigma, W]{
+		DFA:    d,
+		Ring:   ring,
+		Weight: make(map[Q]map[Sigma]W),
+		Final:  make(map[Q]W),
+	}
+}
+
+// SetWeight sets the weight of the transition δ(q,a), which must exist.
+func (w *WeightedDFA[Q, Sigma, W]) SetWeight(q Q, a Sigma, x W) error {
+	if _, ok := w.next(q, a); !ok {
+		return fmt.Errorf("%w: no transition for (%v,%v)", ErrInvalidInput, q, a)
+	}
+	if w.Weight[q] == nil {
+		w.Weight[q] = make(map[Sigma]W)
+	}
+	w.Weight[q][a] = x
+	return nil
+}
+
+// SetFinal sets the final weight of q, which must be in F.
+func (w *WeightedDFA[Q, Sigma, W]) SetFinal(q Q, x W) error {
+	if !w.F.Has(q) {
+		return fmt.Errorf("%w: %v is not a final state", ErrInvalidInput, q)
+	}
+	w.Final[q] = x
+	return nil
+}
+
+// edge returns the weight of δ(q,a).
+func (w *WeightedDFA[Q, Sigma, W]) edge(q Q, a Sigma) W {
+	if x, ok := w.Weight[q][a]; ok {
+		return x
+	}
+	return w.Ring.One()
+}
+
+// stop returns the final weight of q, Zero outside F.
+func (w *WeightedDFA[Q, Sigma, W]) stop(q Q) W {
+	if !w.F.Has(q) {
+		return w.Ring.Zero()
+	}
+	if x, ok := w.Final[q]; ok {
+		return x
+	}
+	return w.Ring.One()
+}
+
+// Weigh returns the weight of input: Zero if it has no run or is rejected.
+func (w *WeightedDFA[Q, Sigma, W]) Weigh(input []Sigma) W {
+	q, x := w.Q0, w.Ring.One()
+	for _, a := range input {
+		qNext, ok := w.next(q, a)
+		if !ok {
+			return w.Ring.Zero()
+		}
+		x = w.Ring.Times(x, w.edge(q, a))
+		q = qNext
+	}
+	return w.Ring.Times(x, w.stop(q))
+}
+
+// Total returns the sum (Plus) of the weights of all inputs of length n:
+// the number of accepted words over CountingSemiring, the cost of the
+// cheapest one over TropicalSemiring. Like AcceptanceProbability it
+// propagates weights over states n times, in O(n·|δ|) time. It is Zero
+// for negative n.
+func (w *WeightedDFA[Q, Sigma, W]) Total(n int) W {
+	r := w.Ring
+	if n < 0 {
+		return r.Zero()
+	}
+	symbols := w.Sigma.sorted()
+	forward := map[Q]W{w.Q0: r.One()}
+	for i := 0; i < n && len(forward) > 0; i++ {
+		next := make(map[Q]W, len(forward))
+		for q, x := range forward {
+			for _, a := range symbols {
+				qNext, ok := w.next(q, a)
+				if !ok {
+					continue
+				}
+				y := r.Times(x, w.edge(q, a))
+				if prev, ok := next[qNext]; ok {
+					y = r.Plus(prev, y)
+				}
+				next[qNext] = y
+			}
+		}
+		forward = next
+	}
+	total := r.Zero()
+	for _, q := range w.Q.sorted() {
+		if x, ok := forward[q]; ok && w.F.Has(q) {
+			total = r.Plus(total, r.Times(x, w.stop(q)))
+		}
+	}
+	return total
+}
@@ -0,0 +1,99 @@
+package fsm
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestWeightedDFA_Semirings runs one machine under several semirings and
+// compares with brute force.
+func TestWeightedDFA_Semirings(t *testing.T) {
+	div3, _ := buildModThree().PruneToFinals(NewSet(S0))
+	bits := []Bit{Zero, One}
+
+	count := NewWeightedDFA[State, Bit, uint64](div3, CountingSemiring{})
+	prob := NewWeightedDFA[State, Bit, float64](div3, ProbabilitySemiring{})
+	for q := range div3.Q {
+		for _, a := range bits {
+			if err := prob.SetWeight(q, a, 0.5); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A One costs 1, a Zero costs 0: the cheapest word has fewest ones.
+	cost := NewWeightedDFA[State, Bit, float64](div3, TropicalSemiring{})
+	for q := range div3.Q {
+		if err := cost.SetWeight(q, One, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for n := 0; n <= 8; n++ {
+		var accepted uint64
+		cheapest := math.Inf(1)
+		for _, w := range allWords(bits, n) {
+			if len(w) != n {
+				continue
+			}
+			if ok, _, _ := div3.Accepts(w); ok {
+				accepted++
+				ones := 0.0
+				for _, a := range w {
+					if a == One {
+						ones++
+					}
+				}
+				cheapest = math.Min(cheapest, ones)
+				if got := cost.Weigh(w); got != ones {
+					t.Fatalf("Weigh(%v) = %v, want %v", w, got, ones)
+				}
+			} else if got := count.Weigh(w); got != 0 {
+				t.Fatalf("rejected %v weighs %v", w, got)
+			}
+		}
+		if got := count.Total(n); got != accepted {
+			t.Errorf("n=%d: counting %v, want %v", n, got, accepted)
+		}
+		if got, want := prob.Total(n), div3.AcceptanceProbability(n); math.Abs(got-want) > 1e-12 {
+			t.Errorf("n=%d: probability %v, want %v", n, got, want)
+		}
+		if got := cost.Total(n); got != cheapest {
+			t.Errorf("n=%d: tropical %v, want %v", n, got, cheapest)
+		}
+	}
+	if got := count.Total(-1); got != 0 {
+		t.Errorf("n=-1: %v", got)
+	}
+}
+
+// TestWeightedDFA_Final weighs final states and rejects bad settings.
+func TestWeightedDFA_Final(t *testing.T) {
+	d := buildModThree()
+	w := NewWeightedDFA[State, Bit, bool](d, BooleanSemiring{})
+	if err := w.SetFinal(S1, false); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Weigh([]Bit{One, One}) || w.Weigh([]Bit{One}) {
+		t.Fatal("final weights ignored")
+	}
+
+	partial := Must(NewDFA([]int{0}, []Bit{Zero, One}, 0, []int{0},
+		TransitionFn[int, Bit]{0: {Zero: 0}}, false))
+	pw := NewWeightedDFA[int, Bit, uint64](partial, CountingSemiring{})
+	if err := pw.SetWeight(0, One, 2); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("missing transition: got %v", err)
+	}
+	if err := pw.SetFinal(1, 2); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("non-final state: got %v", err)
+	}
+	if err := pw.SetWeight(0, Zero, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := pw.Weigh([]Bit{Zero, Zero, Zero}); got != 8 {
+		t.Fatalf("Weigh = %v, want 8", got)
+	}
+	if got := pw.Weigh([]Bit{One}); got != 0 {
+		t.Fatalf("Weigh without a run = %v", got)
+	}
+}